@@ -27,8 +27,29 @@ type Config struct {
 	DBIdleTimeout      time.Duration
 	DBConnMaxLifetime  time.Duration
 
+	// Apply pending migrations on startup
+	DBAutoMigrate bool
+
 	// Logging
 	LogLevel string
+
+	// Uploads
+	UploadStorage       string // "database" or "filesystem"
+	UploadDir           string
+	UploadMaxBytes      int
+	UploadURLTTL        time.Duration
+	UploadSigningSecret string
+
+	// Background jobs
+	BadgeEvaluationInterval    time.Duration
+	LeaderboardRefreshInterval time.Duration
+	ImpactRefreshInterval      time.Duration
+	WebhookDeliveryInterval    time.Duration
+	ShopMarkdownInterval       time.Duration
+	ShopPriceApplyInterval     time.Duration
+	ShopSurplusInterval        time.Duration
+	ShopSurplusReminderLead    time.Duration
+	ShopAnalyticsInterval      time.Duration
 }
 
 func Load() *Config {
@@ -59,8 +80,25 @@ func Load() *Config {
 		DBMaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 5),
 		DBIdleTimeout:     getEnvDurationMS("DB_IDLE_TIMEOUT_MS", 0),
 		DBConnMaxLifetime: getEnvDurationMS("DB_CONN_MAX_LIFETIME_MS", 5*60*1000), // default 5 minutes
+		DBAutoMigrate:     getEnvBool("DB_AUTO_MIGRATE", true),
 
 		LogLevel: getEnv("LOG_LEVEL", "info"),
+
+		UploadStorage:       getEnv("UPLOAD_STORAGE", "database"),
+		UploadDir:           getEnv("UPLOAD_DIR", "./uploads"),
+		UploadMaxBytes:      getEnvInt("UPLOAD_MAX_BYTES", 5*1024*1024),        // default 5 MB
+		UploadURLTTL:        getEnvDurationMS("UPLOAD_URL_TTL_MS", 15*60*1000), // default 15 minutes
+		UploadSigningSecret: getEnv("UPLOAD_SIGNING_SECRET", ""),               // uploads derives a key from JWT_SECRET when unset
+
+		BadgeEvaluationInterval:    getEnvDurationMS("BADGE_EVALUATION_INTERVAL_MS", 60*60*1000),    // default 1 hour
+		LeaderboardRefreshInterval: getEnvDurationMS("LEADERBOARD_REFRESH_INTERVAL_MS", 15*60*1000), // default 15 minutes
+		ImpactRefreshInterval:      getEnvDurationMS("IMPACT_REFRESH_INTERVAL_MS", 60*60*1000),      // default 1 hour
+		WebhookDeliveryInterval:    getEnvDurationMS("WEBHOOK_DELIVERY_INTERVAL_MS", 10*1000),       // default 10 seconds
+		ShopMarkdownInterval:       getEnvDurationMS("SHOP_MARKDOWN_INTERVAL_MS", 60*60*1000),       // default 1 hour
+		ShopPriceApplyInterval:     getEnvDurationMS("SHOP_PRICE_APPLY_INTERVAL_MS", 60*1000),       // default 1 minute
+		ShopSurplusInterval:        getEnvDurationMS("SHOP_SURPLUS_INTERVAL_MS", 5*60*1000),         // default 5 minutes
+		ShopSurplusReminderLead:    getEnvDurationMS("SHOP_SURPLUS_REMINDER_LEAD_MS", 2*60*60*1000), // default 2 hours
+		ShopAnalyticsInterval:      getEnvDurationMS("SHOP_ANALYTICS_INTERVAL_MS", 60*60*1000),      // default 1 hour
 	}
 }
 
@@ -93,6 +131,19 @@ func getEnvInt(key string, defaultValue int) int {
 	return v
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return defaultValue
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("Warning: %s is not a valid bool (%q); using default %t", key, raw, defaultValue)
+		return defaultValue
+	}
+	return v
+}
+
 func getEnvDurationMS(key string, defaultValueMS int) time.Duration {
 	raw := strings.TrimSpace(os.Getenv(key))
 	if raw == "" {
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"foodlink_backend/errors"
+
+	"github.com/google/uuid"
+)
+
+// HouseholdScope returns a SQL condition matching rows whose column holds the
+// user bound to placeholder $n or any member of that user's household.
+func HouseholdScope(column string, n int) string {
+	return fmt.Sprintf(`(%[1]s = $%[2]d OR %[1]s IN (
+		SELECT member.id FROM users member
+		JOIN users self ON self.household_id = member.household_id
+		WHERE self.id = $%[2]d
+	))`, column, n)
+}
+
+// SameHousehold reports whether two users are the same user or members of the same household
+func SameHousehold(db *sql.DB, a, b uuid.UUID) (bool, error) {
+	if a == b {
+		return true, nil
+	}
+	if db == nil {
+		return false, fmt.Errorf("database connection is not initialized")
+	}
+
+	var shared bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM users ua
+			JOIN users ub ON ub.household_id = ua.household_id
+			WHERE ua.id = $1 AND ub.id = $2
+		)
+	`
+	if err := db.QueryRow(query, a, b).Scan(&shared); err != nil {
+		return false, fmt.Errorf("failed to check household membership: %w", err)
+	}
+
+	return shared, nil
+}
+
+// CheckHouseholdAccess allows the owner of a row and members of the owner's
+// household to act on it, and returns errors.ErrForbidden for anyone else
+func CheckHouseholdAccess(db *sql.DB, ownerID, userID uuid.UUID) error {
+	shared, err := SameHousehold(db, ownerID, userID)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if !shared {
+		return errors.ErrForbidden
+	}
+	return nil
+}
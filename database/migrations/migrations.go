@@ -1,31 +1,143 @@
 package migrations
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"embed"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-// Migration represents a database migration
+// Migration represents a database migration.
+// SQL migrations set UpSQL/DownSQL; Go migrations set Up/Down. Both run inside
+// the transaction opened by the runner.
 type Migration struct {
 	Version int
 	Name    string
-	Up      func(*sql.DB) error
-	Down    func(*sql.DB) error
+	UpSQL   string
+	DownSQL string
+	Up      func(*sql.Tx) error
+	Down    func(*sql.Tx) error
 }
 
+// Checksum returns a stable fingerprint of the migration's up step, used to
+// detect edits to migrations that have already been applied.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%s", m.Version, m.Name, m.UpSQL)))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasDown reports whether the migration can be reverted
+func (m Migration) HasDown() bool {
+	return m.Down != nil || strings.TrimSpace(m.DownSQL) != ""
+}
+
+func (m Migration) runUp(tx *sql.Tx) error {
+	if m.Up != nil {
+		return m.Up(tx)
+	}
+	_, err := tx.Exec(m.UpSQL)
+	return err
+}
+
+func (m Migration) runDown(tx *sql.Tx) error {
+	if m.Down != nil {
+		return m.Down(tx)
+	}
+	_, err := tx.Exec(m.DownSQL)
+	return err
+}
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
 var migrations []Migration
 
+func init() {
+	if err := loadSQLMigrations(sqlFiles, "sql"); err != nil {
+		panic(err)
+	}
+}
+
 // RegisterMigration registers a new migration
 func RegisterMigration(migration Migration) {
 	migrations = append(migrations, migration)
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
 }
 
-// GetMigrations returns all registered migrations
+// GetMigrations returns all registered migrations ordered by version
 func GetMigrations() []Migration {
 	return migrations
 }
 
+// loadSQLMigrations registers every NNNNN_name.up.sql / NNNNN_name.down.sql pair in dir
+func loadSQLMigrations(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return fmt.Errorf("invalid migration file name %q", fileName)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return fmt.Errorf("invalid migration version in %q: %w", fileName, err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return fmt.Errorf("failed to read migration %q: %w", fileName, err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	for _, m := range byVersion {
+		if m.UpSQL == "" {
+			return fmt.Errorf("migration %d (%s) has no up step", m.Version, m.Name)
+		}
+		RegisterMigration(*m)
+	}
+
+	return nil
+}
+
 // CreateMigrationsTable creates the migrations tracking table
 func CreateMigrationsTable(db *sql.DB) error {
 	query := `
@@ -33,7 +145,9 @@ func CreateMigrationsTable(db *sql.DB) error {
 			version INTEGER PRIMARY KEY,
 			name VARCHAR(255) NOT NULL,
 			applied_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-		)
+		);
+		ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64);
+		ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS execution_ms BIGINT DEFAULT 0;
 	`
 
 	_, err := db.Exec(query)
@@ -44,37 +158,44 @@ func CreateMigrationsTable(db *sql.DB) error {
 	return nil
 }
 
-// GetAppliedMigrations returns a list of applied migration versions
-func GetAppliedMigrations(db *sql.DB) (map[int]bool, error) {
-	applied := make(map[int]bool)
+// AppliedMigration is a row of schema_migrations
+type AppliedMigration struct {
+	Version  int
+	Name     string
+	Checksum string
+}
+
+// GetAppliedMigrations returns the applied migrations keyed by version
+func GetAppliedMigrations(q querier) (map[int]AppliedMigration, error) {
+	applied := make(map[int]AppliedMigration)
 
-	query := `SELECT version FROM schema_migrations ORDER BY version`
-	rows, err := db.Query(query)
+	query := `SELECT version, name, COALESCE(checksum, '') FROM schema_migrations ORDER BY version`
+	rows, err := q.QueryContext(context.Background(), query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var version int
-		if err := rows.Scan(&version); err != nil {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum); err != nil {
 			return nil, fmt.Errorf("failed to scan migration version: %w", err)
 		}
-		applied[version] = true
+		applied[a.Version] = a
 	}
 
-	return applied, nil
+	return applied, rows.Err()
 }
 
 // RecordMigration records a migration as applied
-func RecordMigration(db *sql.DB, version int, name string) error {
+func RecordMigration(e execer, migration Migration, executionMS int64) error {
 	query := `
-		INSERT INTO schema_migrations (version, name) 
-		VALUES ($1, $2)
+		INSERT INTO schema_migrations (version, name, checksum, execution_ms)
+		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (version) DO NOTHING
 	`
 
-	_, err := db.Exec(query, version, name)
+	_, err := e.ExecContext(context.Background(), query, migration.Version, migration.Name, migration.Checksum(), executionMS)
 	if err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
 	}
@@ -82,35 +203,19 @@ func RecordMigration(db *sql.DB, version int, name string) error {
 	return nil
 }
 
-// RunMigrations runs all pending migrations
-func RunMigrations(db *sql.DB) error {
-	if err := CreateMigrationsTable(db); err != nil {
-		return err
-	}
-
-	applied, err := GetAppliedMigrations(db)
-	if err != nil {
-		return err
+// removeMigrationRecord deletes the record of a reverted migration
+func removeMigrationRecord(e execer, version int) error {
+	if _, err := e.ExecContext(context.Background(), `DELETE FROM schema_migrations WHERE version = $1`, version); err != nil {
+		return fmt.Errorf("failed to remove migration record: %w", err)
 	}
+	return nil
+}
 
-	for _, migration := range migrations {
-		if applied[migration.Version] {
-			log.Printf("Migration %d (%s) already applied, skipping", migration.Version, migration.Name)
-			continue
-		}
-
-		log.Printf("Running migration %d (%s)", migration.Version, migration.Name)
-
-		if err := migration.Up(db); err != nil {
-			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
-		}
-
-		if err := RecordMigration(db, migration.Version, migration.Name); err != nil {
-			return err
-		}
-
-		log.Printf("Migration %d (%s) completed successfully", migration.Version, migration.Name)
-	}
+// querier and execer are satisfied by *sql.DB, *sql.Conn and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
 
-	return nil
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
@@ -0,0 +1,314 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// advisoryLockKey identifies the migration lock in pg_advisory_lock.
+// Every replica uses the same key so only one of them migrates at a time.
+const advisoryLockKey int64 = 7_302_114_519
+
+// baselineVersion is the migration that mirrors the original schema.sql bootstrap
+const baselineVersion = 1
+
+// MigrationStatus describes a registered migration and whether it has been applied
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+	Drifted bool
+}
+
+// RunMigrations runs all pending migrations
+func RunMigrations(db *sql.DB) error {
+	return Up(db)
+}
+
+// Up applies every pending migration in version order
+func Up(db *sql.DB) error {
+	return withLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := GetAppliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		if err := adoptExistingSchema(ctx, conn, applied); err != nil {
+			return err
+		}
+
+		if err := checkDrift(applied); err != nil {
+			return err
+		}
+
+		if err := backfillChecksums(ctx, conn, applied); err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := apply(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down reverts the most recently applied migrations, up to steps of them
+func Down(db *sql.DB, steps int) error {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	return withLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := GetAppliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		if err := checkDrift(applied); err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// Redo reverts and re-applies the most recently applied migration
+func Redo(db *sql.DB) error {
+	return withLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := GetAppliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		if err := checkDrift(applied); err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			migration := migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			return apply(ctx, conn, migration)
+		}
+
+		return fmt.Errorf("no applied migrations to redo")
+	})
+}
+
+// Status reports every registered migration along with unknown versions found in the database
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	if err := CreateMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	applied, err := GetAppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]bool, len(migrations))
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.Drifted = record.Checksum != "" && record.Checksum != migration.Checksum()
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, record := range applied {
+		if !known[version] {
+			statuses = append(statuses, MigrationStatus{Version: version, Name: record.Name, Applied: true, Drifted: true})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock
+func withLock(db *sql.DB, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	if db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	if err := CreateMigrationsTable(db); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for migrations: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, advisoryLockKey); err != nil {
+			log.Printf("Warning: failed to release migration lock: %v", err)
+		}
+	}()
+
+	return fn(ctx, conn)
+}
+
+// adoptExistingSchema records the baseline as applied on databases that were
+// bootstrapped from schema.sql before the migration runner existed.
+func adoptExistingSchema(ctx context.Context, conn *sql.Conn, applied map[int]AppliedMigration) error {
+	if len(applied) > 0 {
+		return nil
+	}
+
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT FROM information_schema.tables
+			WHERE table_schema = 'public'
+			AND table_name = 'users'
+		)
+	`
+	if err := conn.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check for existing schema: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	for _, migration := range migrations {
+		if migration.Version != baselineVersion {
+			continue
+		}
+		if err := RecordMigration(conn, migration, 0); err != nil {
+			return err
+		}
+		applied[migration.Version] = AppliedMigration{Version: migration.Version, Name: migration.Name, Checksum: migration.Checksum()}
+		log.Printf("Existing schema detected; marked migration %d (%s) as applied", migration.Version, migration.Name)
+	}
+
+	return nil
+}
+
+// checkDrift fails when an applied migration no longer matches its recorded checksum
+func checkDrift(applied map[int]AppliedMigration) error {
+	var drifted []string
+	for _, migration := range migrations {
+		record, ok := applied[migration.Version]
+		if !ok || record.Checksum == "" {
+			continue
+		}
+		if record.Checksum != migration.Checksum() {
+			drifted = append(drifted, fmt.Sprintf("%d (%s)", migration.Version, migration.Name))
+		}
+	}
+
+	if len(drifted) > 0 {
+		return fmt.Errorf("applied migrations have been modified since they ran: %s", strings.Join(drifted, ", "))
+	}
+	return nil
+}
+
+// backfillChecksums stores checksums for migrations recorded before checksums were tracked
+func backfillChecksums(ctx context.Context, conn *sql.Conn, applied map[int]AppliedMigration) error {
+	for _, migration := range migrations {
+		record, ok := applied[migration.Version]
+		if !ok || record.Checksum != "" {
+			continue
+		}
+		query := `UPDATE schema_migrations SET checksum = $1 WHERE version = $2`
+		if _, err := conn.ExecContext(ctx, query, migration.Checksum(), migration.Version); err != nil {
+			return fmt.Errorf("failed to backfill checksum for migration %d: %w", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// apply runs a single migration and records it in the same transaction
+func apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	log.Printf("Running migration %d (%s)", migration.Version, migration.Name)
+	start := time.Now()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if err := migration.runUp(tx); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := RecordMigration(tx, migration, time.Since(start).Milliseconds()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+	}
+
+	log.Printf("Migration %d (%s) completed successfully in %v", migration.Version, migration.Name, time.Since(start))
+	return nil
+}
+
+// revert rolls back a single migration and removes its record in the same transaction
+func revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if !migration.HasDown() {
+		return fmt.Errorf("migration %d (%s) is irreversible", migration.Version, migration.Name)
+	}
+
+	log.Printf("Reverting migration %d (%s)", migration.Version, migration.Name)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin revert of migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if err := migration.runDown(tx); err != nil {
+		return fmt.Errorf("revert of migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := removeMigrationRecord(tx, migration.Version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit revert of migration %d: %w", migration.Version, err)
+	}
+
+	log.Printf("Migration %d (%s) reverted", migration.Version, migration.Name)
+	return nil
+}
@@ -0,0 +1,52 @@
+-- Reverts the baseline schema. Destroys all application data.
+
+DROP FUNCTION IF EXISTS update_updated_at_column() CASCADE;
+
+DROP TABLE IF EXISTS shop_profiles CASCADE;
+DROP TABLE IF EXISTS shop_shifts CASCADE;
+DROP TABLE IF EXISTS shop_staff_tasks CASCADE;
+DROP TABLE IF EXISTS shop_staff_members CASCADE;
+DROP TABLE IF EXISTS shop_analytics_records CASCADE;
+DROP TABLE IF EXISTS shop_surplus_items CASCADE;
+DROP TABLE IF EXISTS shop_discount_suggestions CASCADE;
+DROP TABLE IF EXISTS shop_price_map_entries CASCADE;
+DROP TABLE IF EXISTS shop_inventory_items CASCADE;
+DROP TABLE IF EXISTS ngo_notifications CASCADE;
+DROP TABLE IF EXISTS ngo_impact_stories CASCADE;
+DROP TABLE IF EXISTS ngo_feedback_entries CASCADE;
+DROP TABLE IF EXISTS ngo_partner_profiles CASCADE;
+DROP TABLE IF EXISTS ngo_donation_history CASCADE;
+DROP TABLE IF EXISTS ngo_pickup_schedules CASCADE;
+DROP TABLE IF EXISTS ngo_donation_offers CASCADE;
+DROP TABLE IF EXISTS ngo_capacity_settings CASCADE;
+DROP TABLE IF EXISTS restaurant_preferences CASCADE;
+DROP TABLE IF EXISTS restaurant_shift_schedule CASCADE;
+DROP TABLE IF EXISTS restaurant_staff_tasks CASCADE;
+DROP TABLE IF EXISTS restaurant_impact_metrics CASCADE;
+DROP TABLE IF EXISTS restaurant_donation_logs CASCADE;
+DROP TABLE IF EXISTS restaurant_surplus_items CASCADE;
+DROP TABLE IF EXISTS restaurant_menu_items CASCADE;
+DROP TABLE IF EXISTS restaurant_inventory_items CASCADE;
+DROP TABLE IF EXISTS community_profiles CASCADE;
+DROP TABLE IF EXISTS community_notifications CASCADE;
+DROP TABLE IF EXISTS community_impact CASCADE;
+DROP TABLE IF EXISTS community_leaderboard CASCADE;
+DROP TABLE IF EXISTS community_kitchen_events CASCADE;
+DROP TABLE IF EXISTS leftover_item_claims CASCADE;
+DROP TABLE IF EXISTS leftover_items CASCADE;
+DROP TABLE IF EXISTS surplus_comments CASCADE;
+DROP TABLE IF EXISTS surplus_requests CASCADE;
+DROP TABLE IF EXISTS community_surplus_posts CASCADE;
+DROP TABLE IF EXISTS price_comparisons CASCADE;
+DROP TABLE IF EXISTS nutrition_data CASCADE;
+DROP TABLE IF EXISTS family_preferences CASCADE;
+DROP TABLE IF EXISTS user_xp CASCADE;
+DROP TABLE IF EXISTS badges CASCADE;
+DROP TABLE IF EXISTS uploads CASCADE;
+DROP TABLE IF EXISTS resources CASCADE;
+DROP TABLE IF EXISTS meal_plans CASCADE;
+DROP TABLE IF EXISTS shopping_list_items CASCADE;
+DROP TABLE IF EXISTS consumption_logs CASCADE;
+DROP TABLE IF EXISTS inventory_items CASCADE;
+DROP TABLE IF EXISTS food_items CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
@@ -0,0 +1,37 @@
+-- Households group family users so inventory, shopping lists, meal plans and
+-- preferences are shared. users.household_id mirrors household_members.
+CREATE TABLE IF NOT EXISTS households (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS household_members (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    household_id UUID NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'member')),
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS household_invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    household_id UUID NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    email VARCHAR(255),
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'member')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_household_members_household_id ON household_members(household_id);
+CREATE INDEX IF NOT EXISTS idx_household_invites_household_id ON household_invites(household_id);
+CREATE INDEX IF NOT EXISTS idx_household_invites_email ON household_invites(LOWER(email));
+
+CREATE TRIGGER update_households_updated_at BEFORE UPDATE ON households
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
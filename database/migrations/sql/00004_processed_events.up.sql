@@ -0,0 +1,10 @@
+-- Records which event bus subscribers have handled which events so redelivered
+-- events are not applied twice.
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_id UUID NOT NULL,
+    subscriber VARCHAR(100) NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, subscriber)
+);
+
+CREATE INDEX IF NOT EXISTS idx_processed_events_processed_at ON processed_events(processed_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS xp_transactions;
+DROP FUNCTION IF EXISTS reject_xp_transaction_update();
+DROP TABLE IF EXISTS xp_rules;
@@ -0,0 +1,48 @@
+-- XP is awarded server-side from xp_rules and recorded in the append-only
+-- xp_transactions ledger. user_xp remains the running total per user.
+CREATE TABLE IF NOT EXISTS xp_rules (
+    source_type VARCHAR(50) PRIMARY KEY,
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    daily_cap INTEGER CHECK (daily_cap IS NULL OR daily_cap >= 0), -- max XP per user per day from this source; NULL = uncapped
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    description TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO xp_rules (source_type, amount, daily_cap, description) VALUES
+    ('consumption_log', 10, 50, 'Logged food consumed without waste'),
+    ('surplus_post', 15, 45, 'Shared a community surplus post'),
+    ('surplus_request_approved', 50, 200, 'Approved a request for shared surplus'),
+    ('leftover_claimed', 10, 50, 'Leftover dish claimed by a neighbour'),
+    ('donation_logged', 25, 100, 'Restaurant logged a donation'),
+    ('pickup_delivered', 30, 150, 'NGO delivered a donation pickup')
+ON CONFLICT (source_type) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS xp_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL,
+    source_type VARCHAR(50) NOT NULL,
+    source_id UUID,
+    reason TEXT,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_xp_transactions_user_created ON xp_transactions(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_xp_transactions_user_source_created ON xp_transactions(user_id, source_type, created_at);
+
+-- The ledger is append-only
+CREATE OR REPLACE FUNCTION reject_xp_transaction_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'xp_transactions is append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER xp_transactions_append_only BEFORE UPDATE ON xp_transactions
+    FOR EACH ROW EXECUTE FUNCTION reject_xp_transaction_update();
+
+CREATE TRIGGER update_xp_rules_updated_at BEFORE UPDATE ON xp_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,29 @@
+-- Badge definitions are data: each badge unlocks when the user's value for
+-- metric reaches threshold. Metric names are interpreted by the badges service.
+CREATE TABLE IF NOT EXISTS badge_definitions (
+    badge_id VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    icon VARCHAR(50),
+    xp_reward INTEGER NOT NULL DEFAULT 0 CHECK (xp_reward >= 0),
+    metric VARCHAR(50) NOT NULL, -- account, inventory_items, zero_waste_days, meal_plans, nutrition_days, surplus_posts, level
+    threshold INTEGER NOT NULL CHECK (threshold > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_badge_definitions_updated_at BEFORE UPDATE ON badge_definitions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO badge_definitions (badge_id, name, description, icon, xp_reward, metric, threshold, sort_order) VALUES
+    ('first-login', 'Welcome!', 'Logged in for the first time', '👋', 10, 'account', 1, 10),
+    ('inventory-master', 'Inventory Master', 'Added 50 items to inventory', '📦', 50, 'inventory_items', 50, 20),
+    ('zero-waste', 'Zero Waste Hero', 'No waste for 7 days', '🌱', 100, 'zero_waste_days', 7, 30),
+    ('meal-planner', 'Meal Planner', 'Created 10 meal plans', '🍽️', 75, 'meal_plans', 10, 40),
+    ('nutrition-tracker', 'Nutrition Tracker', 'Logged nutrition for 30 days', '📊', 150, 'nutrition_days', 30, 50),
+    ('community-helper', 'Community Helper', 'Shared 5 surplus items', '🤝', 200, 'surplus_posts', 5, 60),
+    ('level-10', 'Level 10 Achiever', 'Reached level 10', '⭐', 500, 'level', 10, 70),
+    ('level-25', 'Level 25 Champion', 'Reached level 25', '🏆', 1000, 'level', 25, 80)
+ON CONFLICT (badge_id) DO NOTHING;
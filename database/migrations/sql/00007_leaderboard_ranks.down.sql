@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS community_leaderboard_ranks;
+DROP INDEX IF EXISTS idx_community_leaderboard_type_period;
+ALTER TABLE community_leaderboard DROP COLUMN IF EXISTS period;
@@ -0,0 +1,29 @@
+-- Leaderboards are materialized per (type, period). community_leaderboard keeps
+-- the top entries for each board; community_leaderboard_ranks holds every
+-- ranked subject so callers outside the top N can find their own position.
+ALTER TABLE community_leaderboard
+    ADD COLUMN IF NOT EXISTS period VARCHAR(20) NOT NULL DEFAULT 'all-time'
+    CHECK (period IN ('daily', 'weekly', 'monthly', 'all-time'));
+
+-- Keep only the newest row per board before making (type, period) unique
+DELETE FROM community_leaderboard a
+    USING community_leaderboard b
+    WHERE a.type = b.type AND a.period = b.period
+      AND (a.updated_at, a.id) < (b.updated_at, b.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_community_leaderboard_type_period ON community_leaderboard(type, period);
+
+CREATE TABLE IF NOT EXISTS community_leaderboard_ranks (
+    type VARCHAR(50) NOT NULL,
+    period VARCHAR(20) NOT NULL,
+    subject_id UUID NOT NULL, -- user ID, or household ID for building-impact
+    name VARCHAR(255) NOT NULL,
+    household VARCHAR(255),
+    value DECIMAL(12, 2) NOT NULL,
+    rank INTEGER NOT NULL,
+    previous_rank INTEGER,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (type, period, subject_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_community_leaderboard_ranks_rank ON community_leaderboard_ranks(type, period, rank);
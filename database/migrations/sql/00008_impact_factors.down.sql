@@ -0,0 +1,5 @@
+ALTER TABLE restaurant_donation_logs DROP COLUMN IF EXISTS category;
+ALTER TABLE community_impact
+    DROP COLUMN IF EXISTS category_breakdown,
+    DROP COLUMN IF EXISTS monthly_trend;
+DROP TABLE IF EXISTS impact_factors;
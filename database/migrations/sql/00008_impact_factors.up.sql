@@ -0,0 +1,39 @@
+-- Per-category factors used to turn rescued food into environmental impact.
+-- The 'default' row applies to categories without a row of their own.
+CREATE TABLE IF NOT EXISTS impact_factors (
+    category VARCHAR(100) PRIMARY KEY,
+    co2e_per_kg DECIMAL(10, 3) NOT NULL CHECK (co2e_per_kg >= 0),        -- kg CO2e avoided per kg of food
+    water_liters_per_kg DECIMAL(10, 2) NOT NULL CHECK (water_liters_per_kg >= 0),
+    kg_per_meal DECIMAL(10, 3) NOT NULL CHECK (kg_per_meal > 0),          -- also converts portions to kg
+    kg_per_piece DECIMAL(10, 3) NOT NULL CHECK (kg_per_piece > 0),        -- converts pieces and items to kg
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_impact_factors_updated_at BEFORE UPDATE ON impact_factors
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO impact_factors (category, co2e_per_kg, water_liters_per_kg, kg_per_meal, kg_per_piece) VALUES
+    ('default', 2.5, 1000, 0.4, 0.25),
+    ('vegetables', 0.5, 320, 0.4, 0.2),
+    ('fruits', 0.7, 960, 0.4, 0.2),
+    ('produce', 0.6, 640, 0.4, 0.2),
+    ('dairy', 3.2, 1000, 0.4, 0.5),
+    ('eggs', 4.5, 3300, 0.4, 0.06),
+    ('meat', 27.0, 15400, 0.4, 0.5),
+    ('poultry', 6.9, 4300, 0.4, 0.5),
+    ('seafood', 5.4, 2000, 0.4, 0.4),
+    ('bakery', 1.6, 1600, 0.4, 0.1),
+    ('grains', 1.4, 1600, 0.4, 0.5),
+    ('pantry', 1.8, 1200, 0.4, 0.4),
+    ('beverages', 0.6, 300, 0.5, 0.5),
+    ('prepared-meals', 3.0, 1500, 0.4, 0.4)
+ON CONFLICT (category) DO NOTHING;
+
+-- Snapshots are recomputed from source data, so they carry their own trends and breakdowns
+ALTER TABLE community_impact
+    ADD COLUMN IF NOT EXISTS monthly_trend JSONB,
+    ADD COLUMN IF NOT EXISTS category_breakdown JSONB;
+
+-- Donations record a category so their impact uses the right factors
+ALTER TABLE restaurant_donation_logs
+    ADD COLUMN IF NOT EXISTS category VARCHAR(100) NOT NULL DEFAULT 'default';
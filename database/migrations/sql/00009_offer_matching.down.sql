@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS idx_ngo_offers_surplus_item_ngo;
+
+UPDATE ngo_donation_offers SET status = 'declined' WHERE status = 'withdrawn';
+ALTER TABLE ngo_donation_offers DROP CONSTRAINT IF EXISTS ngo_donation_offers_status_check;
+ALTER TABLE ngo_donation_offers ADD CONSTRAINT ngo_donation_offers_status_check
+    CHECK (status IN ('pending', 'accepted', 'declined', 'scheduled', 'completed'));
+
+ALTER TABLE ngo_donation_offers
+    DROP COLUMN IF EXISTS match_score,
+    DROP COLUMN IF EXISTS storage_type,
+    DROP COLUMN IF EXISTS surplus_item_id;
+
+UPDATE restaurant_surplus_items SET status = 'pending' WHERE status = 'claimed';
+ALTER TABLE restaurant_surplus_items DROP CONSTRAINT IF EXISTS restaurant_surplus_items_status_check;
+ALTER TABLE restaurant_surplus_items ADD CONSTRAINT restaurant_surplus_items_status_check
+    CHECK (status IN ('pending', 'picked-up', 'expired'));
+
+ALTER TABLE restaurant_surplus_items
+    DROP COLUMN IF EXISTS geo_point,
+    DROP COLUMN IF EXISTS location;
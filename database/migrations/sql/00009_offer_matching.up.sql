@@ -0,0 +1,24 @@
+-- Restaurant surplus can carry a pickup location used to match nearby NGOs
+ALTER TABLE restaurant_surplus_items
+    ADD COLUMN IF NOT EXISTS location TEXT,
+    ADD COLUMN IF NOT EXISTS geo_point JSONB; -- {lat: number, lng: number}
+
+-- 'claimed' marks surplus an NGO has accepted an offer for
+ALTER TABLE restaurant_surplus_items DROP CONSTRAINT IF EXISTS restaurant_surplus_items_status_check;
+ALTER TABLE restaurant_surplus_items ADD CONSTRAINT restaurant_surplus_items_status_check
+    CHECK (status IN ('pending', 'claimed', 'picked-up', 'expired'));
+
+-- Offers made by the matcher link back to the surplus item they were made for
+ALTER TABLE ngo_donation_offers
+    ADD COLUMN IF NOT EXISTS surplus_item_id UUID REFERENCES restaurant_surplus_items(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS storage_type VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS match_score DECIMAL(5, 2);
+
+-- 'withdrawn' marks offers whose surplus another NGO accepted first
+ALTER TABLE ngo_donation_offers DROP CONSTRAINT IF EXISTS ngo_donation_offers_status_check;
+ALTER TABLE ngo_donation_offers ADD CONSTRAINT ngo_donation_offers_status_check
+    CHECK (status IN ('pending', 'accepted', 'declined', 'scheduled', 'completed', 'withdrawn'));
+
+-- Each NGO gets at most one offer per surplus item, so matching can be retried
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ngo_offers_surplus_item_ngo
+    ON ngo_donation_offers(surplus_item_id, ngo_user_id) WHERE surplus_item_id IS NOT NULL;
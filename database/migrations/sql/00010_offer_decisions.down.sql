@@ -0,0 +1 @@
+ALTER TABLE ngo_donation_offers DROP COLUMN IF EXISTS decision_reason;
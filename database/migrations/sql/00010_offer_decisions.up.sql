@@ -0,0 +1,2 @@
+-- Why an offer was accepted, declined or left for review by auto-acceptance
+ALTER TABLE ngo_donation_offers ADD COLUMN IF NOT EXISTS decision_reason TEXT;
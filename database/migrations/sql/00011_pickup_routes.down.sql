@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_ngo_pickups_route_id;
+ALTER TABLE ngo_pickup_schedules DROP CONSTRAINT IF EXISTS ngo_pickup_schedules_route_id_fkey;
+ALTER TABLE ngo_pickup_schedules DROP COLUMN IF EXISTS sequence_number;
+
+DROP TABLE IF EXISTS ngo_pickup_routes;
@@ -0,0 +1,34 @@
+-- A route is one volunteer's ordered run of pickups on a day
+CREATE TABLE IF NOT EXISTS ngo_pickup_routes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ngo_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    route_date DATE NOT NULL,
+    volunteer_name VARCHAR(255) NOT NULL,
+    volunteer_contact VARCHAR(255) NOT NULL,
+    vehicle_type VARCHAR(20) NOT NULL CHECK (vehicle_type IN ('van', 'bike', 'car', 'on-foot')),
+    capacity_kg DECIMAL(10, 2) NOT NULL,
+    load_kg DECIMAL(10, 2) DEFAULT 0,
+    start_point JSONB, -- {lat: number, lng: number}; routes without one start at their first stop
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    total_distance_km DECIMAL(10, 2) DEFAULT 0,
+    status VARCHAR(20) DEFAULT 'planned' CHECK (status IN ('planned', 'in-progress', 'completed', 'cancelled')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ngo_pickup_routes_ngo_date ON ngo_pickup_routes(ngo_user_id, route_date);
+
+CREATE TRIGGER update_ngo_pickup_routes_updated_at BEFORE UPDATE ON ngo_pickup_routes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Pickups on a route are visited in sequence order
+ALTER TABLE ngo_pickup_schedules
+    ADD COLUMN IF NOT EXISTS sequence_number INTEGER;
+-- route_id was never written by the API, so stray values are dropped before it becomes a foreign key
+UPDATE ngo_pickup_schedules SET route_id = NULL
+    WHERE route_id IS NOT NULL AND route_id NOT IN (SELECT id FROM ngo_pickup_routes);
+ALTER TABLE ngo_pickup_schedules ADD CONSTRAINT ngo_pickup_schedules_route_id_fkey
+    FOREIGN KEY (route_id) REFERENCES ngo_pickup_routes(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ngo_pickups_route_id ON ngo_pickup_schedules(route_id, sequence_number);
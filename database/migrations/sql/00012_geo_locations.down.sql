@@ -0,0 +1,27 @@
+DROP INDEX IF EXISTS idx_ngo_capacity_settings_lat_lng;
+DROP INDEX IF EXISTS idx_ngo_partner_profiles_lat_lng;
+DROP INDEX IF EXISTS idx_leftover_items_lat_lng;
+DROP INDEX IF EXISTS idx_community_surplus_posts_lat_lng;
+
+ALTER TABLE ngo_capacity_settings
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE ngo_partner_profiles
+    ADD COLUMN IF NOT EXISTS distance_km DECIMAL(10, 2),
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE leftover_items
+    ADD COLUMN IF NOT EXISTS distance_km DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE community_surplus_posts
+    ADD COLUMN IF NOT EXISTS distance_km DECIMAL(10, 2),
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS home_longitude,
+    DROP COLUMN IF EXISTS home_latitude;
@@ -0,0 +1,38 @@
+-- Locations are stored as coordinates so the server computes distances from
+-- the caller. The client-supplied distance_km columns go away.
+
+-- A user's home location centres searches that do not pass ?near=
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS home_latitude DOUBLE PRECISION CHECK (home_latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS home_longitude DOUBLE PRECISION CHECK (home_longitude BETWEEN -180 AND 180);
+
+ALTER TABLE community_surplus_posts
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    DROP COLUMN IF EXISTS distance_km;
+
+ALTER TABLE leftover_items
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    DROP COLUMN IF EXISTS distance_km;
+
+ALTER TABLE ngo_partner_profiles
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    DROP COLUMN IF EXISTS distance_km;
+
+-- geo_point stays for existing readers and is kept in step with the new columns
+ALTER TABLE ngo_capacity_settings
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180);
+UPDATE ngo_capacity_settings
+SET latitude = (geo_point->>'lat')::double precision, longitude = (geo_point->>'lng')::double precision
+WHERE jsonb_typeof(geo_point->'lat') = 'number' AND jsonb_typeof(geo_point->'lng') = 'number'
+    AND (geo_point->>'lat')::double precision BETWEEN -90 AND 90
+    AND (geo_point->>'lng')::double precision BETWEEN -180 AND 180;
+
+-- Radius searches prefilter on a latitude/longitude box
+CREATE INDEX IF NOT EXISTS idx_community_surplus_posts_lat_lng ON community_surplus_posts(latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_leftover_items_lat_lng ON leftover_items(latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_ngo_partner_profiles_lat_lng ON ngo_partner_profiles(latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_ngo_capacity_settings_lat_lng ON ngo_capacity_settings(latitude, longitude);
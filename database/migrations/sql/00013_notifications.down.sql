@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_ngo_notifications_user_created;
+DROP INDEX IF EXISTS idx_community_notifications_user_created;
+
+DELETE FROM community_notifications WHERE type = 'message';
+ALTER TABLE community_notifications
+    DROP CONSTRAINT IF EXISTS community_notifications_type_check,
+    ADD CONSTRAINT community_notifications_type_check
+        CHECK (type IN ('claim', 'volunteer', 'announcement', 'surplus', 'reminder')),
+    DROP COLUMN IF EXISTS related_entity_id;
@@ -0,0 +1,11 @@
+-- Community notifications link back to what they are about, like NGO
+-- notifications do, and comments on a post notify its owner as messages
+ALTER TABLE community_notifications
+    ADD COLUMN IF NOT EXISTS related_entity_id UUID,
+    DROP CONSTRAINT IF EXISTS community_notifications_type_check,
+    ADD CONSTRAINT community_notifications_type_check
+        CHECK (type IN ('claim', 'volunteer', 'announcement', 'surplus', 'reminder', 'message'));
+
+-- Lists and unread counts read a user's newest notifications
+CREATE INDEX IF NOT EXISTS idx_community_notifications_user_created ON community_notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ngo_notifications_user_created ON ngo_notifications(ngo_user_id, created_at DESC);
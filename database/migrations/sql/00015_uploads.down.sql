@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_uploads_user_created;
+
+UPDATE uploads SET associated_type = NULL, associated_id = NULL
+    WHERE associated_type NOT IN ('inventory', 'log', 'profile');
+ALTER TABLE uploads
+    DROP CONSTRAINT IF EXISTS uploads_associated_type_check,
+    ADD CONSTRAINT uploads_associated_type_check
+        CHECK (associated_type IN ('inventory', 'log', 'profile'));
+
+-- Files kept on the filesystem backend are not copied back into the table
+ALTER TABLE uploads
+    DROP COLUMN IF EXISTS sha256,
+    DROP COLUMN IF EXISTS storage_key,
+    DROP COLUMN IF EXISTS storage_backend;
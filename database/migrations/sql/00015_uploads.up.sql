@@ -0,0 +1,16 @@
+-- Upload contents live in a storage backend: the data column for 'database',
+-- a file under UPLOAD_DIR named by storage_key for 'filesystem'
+ALTER TABLE uploads
+    ADD COLUMN IF NOT EXISTS storage_backend VARCHAR(20) NOT NULL DEFAULT 'database'
+        CHECK (storage_backend IN ('database', 'filesystem')),
+    ADD COLUMN IF NOT EXISTS storage_key TEXT,
+    ADD COLUMN IF NOT EXISTS sha256 VARCHAR(64);
+
+-- Uploads can now be attached to the features that show images
+ALTER TABLE uploads
+    DROP CONSTRAINT IF EXISTS uploads_associated_type_check,
+    ADD CONSTRAINT uploads_associated_type_check
+        CHECK (associated_type IN ('inventory', 'log', 'profile', 'leftover', 'surplus-post',
+            'restaurant-inventory', 'restaurant-surplus', 'ngo-feedback', 'ngo-story'));
+
+CREATE INDEX IF NOT EXISTS idx_uploads_user_created ON uploads(user_id, created_at DESC);
@@ -0,0 +1,34 @@
+DROP TRIGGER IF EXISTS update_shop_shifts_updated_at ON shop_shifts;
+DROP INDEX IF EXISTS idx_shop_shifts_staff_start;
+DROP INDEX IF EXISTS idx_shop_shifts_user_start;
+DELETE FROM shop_shifts;
+ALTER TABLE shop_shifts
+    DROP CONSTRAINT IF EXISTS shop_shifts_time_check,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS end_time,
+    DROP COLUMN IF EXISTS start_time,
+    ADD COLUMN day VARCHAR(20) NOT NULL,
+    ADD COLUMN start_time TIME NOT NULL,
+    ADD COLUMN end_time TIME NOT NULL;
+
+DROP TRIGGER IF EXISTS update_shop_staff_tasks_updated_at ON shop_staff_tasks;
+DROP INDEX IF EXISTS idx_shop_staff_tasks_assignee_id;
+DROP INDEX IF EXISTS idx_shop_staff_tasks_user_due;
+ALTER TABLE shop_staff_tasks
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS completed_at;
+
+DROP TRIGGER IF EXISTS update_shop_staff_members_updated_at ON shop_staff_members;
+DROP INDEX IF EXISTS idx_shop_staff_members_user_id;
+ALTER TABLE shop_staff_members DROP COLUMN IF EXISTS updated_at;
+
+UPDATE uploads SET associated_type = NULL, associated_id = NULL
+    WHERE associated_type = 'shop-inventory';
+ALTER TABLE uploads
+    DROP CONSTRAINT IF EXISTS uploads_associated_type_check,
+    ADD CONSTRAINT uploads_associated_type_check
+        CHECK (associated_type IN ('inventory', 'log', 'profile', 'leftover', 'surplus-post',
+            'restaurant-inventory', 'restaurant-surplus', 'ngo-feedback', 'ngo-story'));
+
+DROP INDEX IF EXISTS idx_shop_inventory_user_expiry;
+DROP INDEX IF EXISTS idx_shop_inventory_user_barcode;
@@ -0,0 +1,40 @@
+-- A barcode identifies one product within a shop
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shop_inventory_user_barcode ON shop_inventory_items(user_id, barcode);
+CREATE INDEX IF NOT EXISTS idx_shop_inventory_user_expiry ON shop_inventory_items(user_id, expiry_date);
+
+ALTER TABLE uploads
+    DROP CONSTRAINT IF EXISTS uploads_associated_type_check,
+    ADD CONSTRAINT uploads_associated_type_check
+        CHECK (associated_type IN ('inventory', 'log', 'profile', 'leftover', 'surplus-post',
+            'restaurant-inventory', 'restaurant-surplus', 'ngo-feedback', 'ngo-story', 'shop-inventory'));
+
+ALTER TABLE shop_staff_members
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_shop_staff_members_user_id ON shop_staff_members(user_id);
+CREATE TRIGGER update_shop_staff_members_updated_at BEFORE UPDATE ON shop_staff_members
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE shop_staff_tasks
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_shop_staff_tasks_user_due ON shop_staff_tasks(user_id, due);
+CREATE INDEX IF NOT EXISTS idx_shop_staff_tasks_assignee_id ON shop_staff_tasks(assignee_id);
+CREATE TRIGGER update_shop_staff_tasks_updated_at BEFORE UPDATE ON shop_staff_tasks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Shifts were a weekday name with times of day, which cannot describe a dated
+-- or overnight shift. No API ever wrote them, so the old rows are dropped and
+-- shifts become real start and end timestamps.
+DELETE FROM shop_shifts;
+ALTER TABLE shop_shifts
+    DROP COLUMN IF EXISTS day,
+    DROP COLUMN IF EXISTS start_time,
+    DROP COLUMN IF EXISTS end_time,
+    ADD COLUMN start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    ADD COLUMN end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    ADD CONSTRAINT shop_shifts_time_check CHECK (end_time > start_time);
+CREATE INDEX IF NOT EXISTS idx_shop_shifts_user_start ON shop_shifts(user_id, start_time);
+CREATE INDEX IF NOT EXISTS idx_shop_shifts_staff_start ON shop_shifts(staff_id, start_time);
+CREATE TRIGGER update_shop_shifts_updated_at BEFORE UPDATE ON shop_shifts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
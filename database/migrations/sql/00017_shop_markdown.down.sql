@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS update_shop_price_map_entries_updated_at ON shop_price_map_entries;
+DROP INDEX IF EXISTS idx_shop_price_map_user_created;
+DROP INDEX IF EXISTS idx_shop_price_map_due;
+DROP INDEX IF EXISTS idx_shop_price_map_scheduled_sku;
+ALTER TABLE shop_price_map_entries
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS applied_at,
+    DROP COLUMN IF EXISTS status;
+
+DROP TRIGGER IF EXISTS update_shop_discount_suggestions_updated_at ON shop_discount_suggestions;
+DROP INDEX IF EXISTS idx_shop_discount_suggestions_user_status;
+DROP INDEX IF EXISTS idx_shop_discount_suggestions_pending_sku;
+ALTER TABLE shop_discount_suggestions
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS price_map_entry_id,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,28 @@
+-- Suggestions are reviewed once: accepted into a price-map entry, dismissed,
+-- or expired when their window passes. A SKU has at most one pending.
+ALTER TABLE shop_discount_suggestions
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'dismissed', 'expired')),
+    ADD COLUMN IF NOT EXISTS price_map_entry_id UUID REFERENCES shop_price_map_entries(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shop_discount_suggestions_pending_sku
+    ON shop_discount_suggestions(sku_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_shop_discount_suggestions_user_status ON shop_discount_suggestions(user_id, status);
+CREATE TRIGGER update_shop_discount_suggestions_updated_at BEFORE UPDATE ON shop_discount_suggestions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Price changes wait as 'scheduled' until effective_at, then are applied to
+-- the item, or superseded when its price was changed in the meantime. A SKU
+-- has at most one scheduled change.
+ALTER TABLE shop_price_map_entries
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'applied', 'superseded', 'cancelled')),
+    ADD COLUMN IF NOT EXISTS applied_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shop_price_map_scheduled_sku
+    ON shop_price_map_entries(sku_id) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_shop_price_map_due
+    ON shop_price_map_entries(effective_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_shop_price_map_user_created ON shop_price_map_entries(user_id, created_at DESC);
+CREATE TRIGGER update_shop_price_map_entries_updated_at BEFORE UPDATE ON shop_price_map_entries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
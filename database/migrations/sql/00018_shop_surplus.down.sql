@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_shop_surplus_destination_user_id;
+DROP INDEX IF EXISTS idx_shop_surplus_expiry;
+DROP INDEX IF EXISTS idx_shop_surplus_reminder_due;
+DROP INDEX IF EXISTS idx_shop_surplus_pending_sku;
+ALTER TABLE shop_surplus_items
+    DROP COLUMN IF EXISTS picked_at,
+    DROP COLUMN IF EXISTS sku_id,
+    DROP COLUMN IF EXISTS destination_user_id;
+
+UPDATE uploads SET associated_type = NULL, associated_id = NULL
+    WHERE associated_type = 'shop-surplus';
+ALTER TABLE uploads
+    DROP CONSTRAINT IF EXISTS uploads_associated_type_check,
+    ADD CONSTRAINT uploads_associated_type_check
+        CHECK (associated_type IN ('inventory', 'log', 'profile', 'leftover', 'surplus-post',
+            'restaurant-inventory', 'restaurant-surplus', 'ngo-feedback', 'ngo-story', 'shop-inventory'));
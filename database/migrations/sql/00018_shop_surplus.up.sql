@@ -0,0 +1,21 @@
+ALTER TABLE uploads
+    DROP CONSTRAINT IF EXISTS uploads_associated_type_check,
+    ADD CONSTRAINT uploads_associated_type_check
+        CHECK (associated_type IN ('inventory', 'log', 'profile', 'leftover', 'surplus-post',
+            'restaurant-inventory', 'restaurant-surplus', 'ngo-feedback', 'ngo-story', 'shop-inventory',
+            'shop-surplus'));
+
+-- Surplus can be handed to a registered NGO or community kitchen, who is then
+-- reminded before pickup. Items converted from inventory keep a link to their
+-- SKU, which has at most one pending surplus entry.
+ALTER TABLE shop_surplus_items
+    ADD COLUMN IF NOT EXISTS destination_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS sku_id UUID REFERENCES shop_inventory_items(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS picked_at TIMESTAMP WITH TIME ZONE;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shop_surplus_pending_sku
+    ON shop_surplus_items(sku_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_shop_surplus_reminder_due
+    ON shop_surplus_items(pickup_time) WHERE status = 'pending' AND NOT reminder_sent;
+CREATE INDEX IF NOT EXISTS idx_shop_surplus_expiry
+    ON shop_surplus_items(expiry_window_end) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_shop_surplus_destination_user_id ON shop_surplus_items(destination_user_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_shop_surplus_user_status;
+DROP INDEX IF EXISTS idx_shop_price_map_user_applied;
+ALTER TABLE shop_analytics_records
+    DROP COLUMN IF EXISTS written_off_value,
+    DROP COLUMN IF EXISTS markdown_revenue_recovered,
+    DROP COLUMN IF EXISTS period_end,
+    DROP COLUMN IF EXISTS period_start;
+
+ALTER TABLE shop_price_map_entries DROP COLUMN IF EXISTS stock_at_apply;
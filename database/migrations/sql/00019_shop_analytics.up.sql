@@ -0,0 +1,15 @@
+-- Stock on hand when a markdown was applied, so the units sold at the
+-- discounted price can be derived from what is left afterwards
+ALTER TABLE shop_price_map_entries
+    ADD COLUMN IF NOT EXISTS stock_at_apply DECIMAL(10, 2);
+
+-- A shop's analytics snapshot covers the date range it was computed for;
+-- updated_at says how fresh it is
+ALTER TABLE shop_analytics_records
+    ADD COLUMN IF NOT EXISTS period_start TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS period_end TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS markdown_revenue_recovered DECIMAL(12, 2) DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS written_off_value DECIMAL(12, 2) DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_shop_price_map_user_applied
+    ON shop_price_map_entries(user_id, applied_at) WHERE status = 'applied';
+CREATE INDEX IF NOT EXISTS idx_shop_surplus_user_status ON shop_surplus_items(user_id, status);
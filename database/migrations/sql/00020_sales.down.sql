@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sales_daily;
+DROP TABLE IF EXISTS sales_lines;
+DROP TABLE IF EXISTS sales_receipts;
@@ -0,0 +1,45 @@
+-- Point-of-sale receipts, one per external receipt ID and seller, so a batch
+-- sent twice is only counted once
+CREATE TABLE IF NOT EXISTS sales_receipts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    external_receipt_id VARCHAR(255) NOT NULL,
+    sold_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    line_count INTEGER NOT NULL DEFAULT 0,
+    source VARCHAR(10) NOT NULL CHECK (source IN ('json', 'csv')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, external_receipt_id)
+);
+CREATE INDEX IF NOT EXISTS idx_sales_receipts_user_sold_at ON sales_receipts(user_id, sold_at DESC);
+
+-- Receipt lines, keyed by a restaurant menu item or a shop SKU. Lines naming
+-- an item the seller does not have are kept with neither.
+CREATE TABLE IF NOT EXISTS sales_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    receipt_id UUID NOT NULL REFERENCES sales_receipts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    menu_item_id UUID REFERENCES restaurant_menu_items(id) ON DELETE SET NULL,
+    sku_id UUID REFERENCES shop_inventory_items(id) ON DELETE SET NULL,
+    item_name VARCHAR(255) NOT NULL,
+    quantity DECIMAL(10, 2) NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    sold_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_sales_lines_receipt_id ON sales_lines(receipt_id);
+CREATE INDEX IF NOT EXISTS idx_sales_lines_menu_item_sold_at ON sales_lines(menu_item_id, sold_at) WHERE menu_item_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_sales_lines_sku_sold_at ON sales_lines(sku_id, sold_at) WHERE sku_id IS NOT NULL;
+
+-- Units sold and revenue per item per UTC day, for forecasting and reporting
+CREATE TABLE IF NOT EXISTS sales_daily (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_type VARCHAR(20) NOT NULL CHECK (item_type IN ('menu-item', 'sku')),
+    item_id UUID NOT NULL,
+    day DATE NOT NULL,
+    quantity DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    revenue DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, item_type, item_id, day)
+);
+CREATE INDEX IF NOT EXISTS idx_sales_daily_item_day ON sales_daily(item_id, day);
@@ -0,0 +1,11 @@
+ALTER TABLE restaurant_preferences DROP COLUMN IF EXISTS min_margin;
+
+ALTER TABLE restaurant_menu_items
+    DROP COLUMN IF EXISTS costed_at,
+    DROP COLUMN IF EXISTS cost_complete,
+    DROP COLUMN IF EXISTS plate_cost;
+
+DROP TABLE IF EXISTS restaurant_menu_recipe_lines;
+
+DROP INDEX IF EXISTS idx_restaurant_inventory_costed;
+ALTER TABLE restaurant_inventory_items DROP COLUMN IF EXISTS unit_cost;
@@ -0,0 +1,31 @@
+-- What one unit of an inventory batch cost, e.g. per kg
+ALTER TABLE restaurant_inventory_items ADD COLUMN IF NOT EXISTS unit_cost DECIMAL(12, 4) CHECK (unit_cost >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_inventory_costed ON restaurant_inventory_items(user_id, LOWER(name), created_at DESC) WHERE unit_cost IS NOT NULL;
+
+-- Structured recipes: how much of each ingredient one portion uses. Lines are
+-- priced by ingredient name; inventory_item_id records the batch a line was
+-- picked from.
+CREATE TABLE IF NOT EXISTS restaurant_menu_recipe_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    menu_item_id UUID NOT NULL REFERENCES restaurant_menu_items(id) ON DELETE CASCADE,
+    inventory_item_id UUID REFERENCES restaurant_inventory_items(id) ON DELETE SET NULL,
+    ingredient_name VARCHAR(255) NOT NULL,
+    quantity DECIMAL(10, 3) NOT NULL CHECK (quantity > 0),
+    unit VARCHAR(50) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    unit_cost DECIMAL(12, 4),
+    cost DECIMAL(10, 2),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_menu_recipe_lines_item ON restaurant_menu_recipe_lines(menu_item_id, position);
+CREATE INDEX IF NOT EXISTS idx_restaurant_menu_recipe_lines_ingredient ON restaurant_menu_recipe_lines(LOWER(ingredient_name));
+
+ALTER TABLE restaurant_menu_items
+    ADD COLUMN IF NOT EXISTS plate_cost DECIMAL(10, 2),
+    ADD COLUMN IF NOT EXISTS cost_complete BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS costed_at TIMESTAMP WITH TIME ZONE;
+
+-- Margin, in percent, below which menu items are flagged
+ALTER TABLE restaurant_preferences ADD COLUMN IF NOT EXISTS min_margin DECIMAL(5, 2) NOT NULL DEFAULT 30 CHECK (min_margin >= 0 AND min_margin < 100);
@@ -4,37 +4,21 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
+
+	"foodlink_backend/database/migrations"
 )
 
-// InitSchema initializes the database schema from schema.sql file
+// InitSchema brings the database schema up to date by applying pending migrations
 func InitSchema() error {
 	if DB == nil {
 		return fmt.Errorf("database connection is not initialized")
 	}
 
-	// Avoid re-running schema.sql on every server start.
-	// The PostgreSQL schema file creates triggers without IF NOT EXISTS, so repeated runs would error.
-	if exists, err := CheckTableExists("users"); err == nil && exists {
-		log.Println("Database schema already initialized (users table exists); skipping schema.sql execution")
-		return nil
-	}
-
-	// Read schema.sql file
-	schemaPath := filepath.Join("schema.sql")
-	schemaSQL, err := os.ReadFile(schemaPath)
-	if err != nil {
-		return fmt.Errorf("failed to read schema file: %w", err)
-	}
-
-	// Execute schema
-	_, err = DB.Exec(string(schemaSQL))
-	if err != nil {
-		return fmt.Errorf("failed to execute schema: %w", err)
+	if err := migrations.Up(DB); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
-	log.Println("Database schema initialized successfully")
+	log.Println("Database schema is up to date")
 	return nil
 }
 
@@ -5051,8 +5051,7 @@ const docTemplate = `{
                         "family",
                         "restaurant",
                         "shop",
-                        "ngo",
-                        "admin"
+                        "ngo"
                     ]
                 }
             }
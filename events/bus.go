@@ -0,0 +1,215 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Handler processes an event. Handlers run on the bus's worker goroutines and
+// may be called more than once for the same event, so they must be idempotent;
+// Event.EventID is stable across retries and is the key to deduplicate on.
+type Handler func(ctx context.Context, event Event) error
+
+// Bus is an in-process publish/subscribe bus. Publish never blocks the caller:
+// deliveries are queued and processed by a pool of workers, and failed
+// deliveries are retried with exponential backoff.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]subscription
+	queue       chan delivery
+	pending     sync.WaitGroup
+	workers     sync.WaitGroup
+	closed      bool
+
+	maxAttempts int
+	baseBackoff time.Duration
+}
+
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+type delivery struct {
+	event   Event
+	sub     subscription
+	attempt int
+}
+
+// Options configures a Bus
+type Options struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+// NewBus creates a bus and starts its workers
+func NewBus(opts Options) *Bus {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = 200 * time.Millisecond
+	}
+
+	b := &Bus{
+		subscribers: make(map[string][]subscription),
+		queue:       make(chan delivery, opts.QueueSize),
+		maxAttempts: opts.MaxAttempts,
+		baseBackoff: opts.BaseBackoff,
+	}
+	for i := 0; i < opts.Workers; i++ {
+		b.workers.Add(1)
+		go b.work()
+	}
+	return b
+}
+
+// Subscribe registers handler for events with the given name. subscriber names
+// the consumer in logs and in idempotency records, so it must be stable.
+func (b *Bus) Subscribe(eventName, subscriber string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[eventName] = append(b.subscribers[eventName], subscription{name: subscriber, handler: handler})
+}
+
+// Publish queues event for every subscriber of its name. Call it only after the
+// transaction that produced the event has committed.
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		log.Printf("Warning: event bus closed; dropping %s %s", event.EventName(), event.EventID())
+		return
+	}
+
+	for _, sub := range b.subscribers[event.EventName()] {
+		b.pending.Add(1)
+		b.enqueue(delivery{event: event, sub: sub, attempt: 1})
+	}
+}
+
+// Shutdown stops accepting events and waits for queued deliveries, including
+// pending retries, to finish or for ctx to expire.
+func (b *Bus) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.pending.Wait()
+		close(b.queue)
+		b.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue hands a delivery to the workers without blocking the publisher
+func (b *Bus) enqueue(d delivery) {
+	select {
+	case b.queue <- d:
+	default:
+		go func() { b.queue <- d }()
+	}
+}
+
+func (b *Bus) work() {
+	defer b.workers.Done()
+	for d := range b.queue {
+		b.deliver(d)
+	}
+}
+
+func (b *Bus) deliver(d delivery) {
+	err := b.invoke(d)
+	if err == nil {
+		b.pending.Done()
+		return
+	}
+
+	if d.attempt >= b.maxAttempts {
+		log.Printf("Error: %s gave up on %s %s after %d attempts: %v", d.sub.name, d.event.EventName(), d.event.EventID(), d.attempt, err)
+		b.pending.Done()
+		return
+	}
+
+	backoff := b.baseBackoff << (d.attempt - 1)
+	log.Printf("Warning: %s failed on %s %s (attempt %d), retrying in %v: %v", d.sub.name, d.event.EventName(), d.event.EventID(), d.attempt, backoff, err)
+	d.attempt++
+	time.AfterFunc(backoff, func() { b.enqueue(d) })
+}
+
+// invoke runs a handler, turning a panic into an error so the delivery is retried
+func (b *Bus) invoke(d delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError{value: r}
+		}
+	}()
+	return d.sub.handler(context.Background(), d.event)
+}
+
+type panicError struct {
+	value interface{}
+}
+
+func (p panicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", p.value)
+}
+
+var defaultBus = NewBus(Options{})
+
+// Default returns the process-wide bus
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish publishes event on the default bus
+func Publish(event Event) {
+	defaultBus.Publish(event)
+}
+
+// Subscribe registers a handler on the default bus
+func Subscribe(eventName, subscriber string, handler Handler) {
+	defaultBus.Subscribe(eventName, subscriber, handler)
+}
+
+// On registers a handler for a single typed event on bus
+func On[T Event](bus *Bus, subscriber string, handler func(ctx context.Context, event T) error) {
+	var zero T
+	bus.Subscribe(zero.EventName(), subscriber, func(ctx context.Context, event Event) error {
+		typed, ok := event.(T)
+		if !ok {
+			return nil
+		}
+		return handler(ctx, typed)
+	})
+}
+
+// Shutdown drains the default bus
+func Shutdown(ctx context.Context) error {
+	return defaultBus.Shutdown(ctx)
+}
@@ -0,0 +1,273 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a domain event published after the change it describes has committed
+type Event interface {
+	EventName() string
+	EventID() uuid.UUID
+}
+
+// Meta carries the identity shared by every event
+type Meta struct {
+	ID         uuid.UUID `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// NewMeta returns metadata for a new event
+func NewMeta() Meta {
+	return Meta{ID: uuid.New(), OccurredAt: time.Now()}
+}
+
+// EventID returns the event's unique ID, stable across delivery retries
+func (m Meta) EventID() uuid.UUID {
+	return m.ID
+}
+
+// Event names
+const (
+	NameConsumptionLogged      = "consumption.logged"
+	NameSurplusPostCreated     = "community.surplus_post.created"
+	NameSurplusRequestCreated  = "community.surplus_request.created"
+	NameSurplusRequestApproved = "community.surplus_request.approved"
+	NameSurplusCommentAdded    = "community.surplus_comment.created"
+	NameLeftoverClaimed        = "community.leftover.claimed"
+	NameDonationLogged         = "restaurant.donation.logged"
+	NameRestaurantSurplusAdded = "restaurant.surplus.created"
+	NameIngredientCostChanged  = "restaurant.ingredient_cost.changed"
+	NameOfferCreated           = "ngo.offer.created"
+	NameOfferAccepted          = "ngo.offer.accepted"
+	NamePickupStatusChanged    = "ngo.pickup.status_changed"
+	NamePickupDelivered        = "ngo.pickup.delivered"
+	NameFeedbackRecorded       = "ngo.feedback.created"
+	NameShopSurplusPickupDue   = "shop.surplus.pickup_due"
+	NameXPAwarded              = "xp.awarded"
+	NameNotificationCreated    = "notification.created"
+)
+
+// ConsumptionLogged is published when a family member logs consumed or wasted food
+type ConsumptionLogged struct {
+	Meta
+	UserID     uuid.UUID `json:"user_id"`
+	LogID      uuid.UUID `json:"log_id"`
+	FoodName   string    `json:"food_name"`
+	Category   string    `json:"category,omitempty"`
+	Quantity   float64   `json:"quantity"`
+	Unit       string    `json:"unit,omitempty"`
+	WasWasted  bool      `json:"was_wasted"`
+	ConsumedAt time.Time `json:"consumed_at"`
+}
+
+func (ConsumptionLogged) EventName() string { return NameConsumptionLogged }
+
+// SurplusPostCreated is published when a user shares a community surplus post
+type SurplusPostCreated struct {
+	Meta
+	UserID   uuid.UUID `json:"user_id"`
+	PostID   uuid.UUID `json:"post_id"`
+	Category string    `json:"category"`
+	Quantity float64   `json:"quantity"`
+	Unit     string    `json:"unit"`
+}
+
+func (SurplusPostCreated) EventName() string { return NameSurplusPostCreated }
+
+// SurplusRequestCreated is published when a user asks for someone's surplus post
+type SurplusRequestCreated struct {
+	Meta
+	OwnerID       uuid.UUID `json:"owner_id"`
+	RequesterID   uuid.UUID `json:"requester_id"`
+	RequesterName string    `json:"requester_name"`
+	PostID        uuid.UUID `json:"post_id"`
+	PostTitle     string    `json:"post_title"`
+	RequestID     uuid.UUID `json:"request_id"`
+}
+
+func (SurplusRequestCreated) EventName() string { return NameSurplusRequestCreated }
+
+// SurplusRequestApproved is published when a post owner approves a request for their surplus
+type SurplusRequestApproved struct {
+	Meta
+	OwnerID     uuid.UUID `json:"owner_id"`
+	RequesterID uuid.UUID `json:"requester_id"`
+	PostID      uuid.UUID `json:"post_id"`
+	RequestID   uuid.UUID `json:"request_id"`
+	Category    string    `json:"category"`
+	Quantity    float64   `json:"quantity"`
+	Unit        string    `json:"unit"`
+}
+
+func (SurplusRequestApproved) EventName() string { return NameSurplusRequestApproved }
+
+// SurplusCommentAdded is published when a user comments on a surplus post
+type SurplusCommentAdded struct {
+	Meta
+	OwnerID       uuid.UUID `json:"owner_id"`
+	CommenterID   uuid.UUID `json:"commenter_id"`
+	CommenterName string    `json:"commenter_name"`
+	PostID        uuid.UUID `json:"post_id"`
+	PostTitle     string    `json:"post_title"`
+	CommentID     uuid.UUID `json:"comment_id"`
+}
+
+func (SurplusCommentAdded) EventName() string { return NameSurplusCommentAdded }
+
+// LeftoverClaimed is published when a user claims someone's leftover dish
+type LeftoverClaimed struct {
+	Meta
+	OwnerID     uuid.UUID `json:"owner_id"`
+	ClaimerID   uuid.UUID `json:"claimer_id"`
+	ClaimerName string    `json:"claimer_name"`
+	LeftoverID  uuid.UUID `json:"leftover_id"`
+	DishName    string    `json:"dish_name"`
+	ClaimID     uuid.UUID `json:"claim_id"`
+	Portions    int       `json:"portions"`
+}
+
+func (LeftoverClaimed) EventName() string { return NameLeftoverClaimed }
+
+// DonationLogged is published when a restaurant logs a donation
+type DonationLogged struct {
+	Meta
+	UserID        uuid.UUID `json:"user_id"`
+	DonationID    uuid.UUID `json:"donation_id"`
+	RecipientType string    `json:"recipient_type"`
+	Quantity      float64   `json:"quantity"`
+	Unit          string    `json:"unit"`
+	MealsProvided int       `json:"meals_provided"`
+	CO2SavedKg    float64   `json:"co2_saved_kg"`
+}
+
+func (DonationLogged) EventName() string { return NameDonationLogged }
+
+// RestaurantSurplusAdded is published when a restaurant lists a surplus item
+type RestaurantSurplusAdded struct {
+	Meta
+	UserID      uuid.UUID `json:"user_id"`
+	ItemID      uuid.UUID `json:"item_id"`
+	Category    string    `json:"category"`
+	Quantity    float64   `json:"quantity"`
+	Unit        string    `json:"unit"`
+	StorageType string    `json:"storage_type"`
+}
+
+func (RestaurantSurplusAdded) EventName() string { return NameRestaurantSurplusAdded }
+
+// IngredientCostChanged is published when a restaurant adds, reprices, renames
+// or removes a costed inventory batch, naming the ingredients whose latest cost
+// may have changed
+type IngredientCostChanged struct {
+	Meta
+	UserID      uuid.UUID `json:"user_id"`
+	Ingredients []string  `json:"ingredients"`
+}
+
+func (IngredientCostChanged) EventName() string { return NameIngredientCostChanged }
+
+// OfferCreated is published when a donation offer is made to an NGO
+type OfferCreated struct {
+	Meta
+	NGOUserID    uuid.UUID `json:"ngo_user_id"`
+	OfferID      uuid.UUID `json:"offer_id"`
+	Title        string    `json:"title"`
+	UrgencyLevel string    `json:"urgency_level"`
+}
+
+func (OfferCreated) EventName() string { return NameOfferCreated }
+
+// OfferAccepted is published when an NGO accepts a donation offer, by hand or
+// through its auto-acceptance rules
+type OfferAccepted struct {
+	Meta
+	NGOUserID     uuid.UUID  `json:"ngo_user_id"`
+	OfferID       uuid.UUID  `json:"offer_id"`
+	SurplusItemID *uuid.UUID `json:"surplus_item_id,omitempty"`
+	Title         string     `json:"title"`
+	WeightKg      float64    `json:"weight_kg"`
+	AutoAccepted  bool       `json:"auto_accepted"`
+}
+
+func (OfferAccepted) EventName() string { return NameOfferAccepted }
+
+// PickupStatusChanged is published each time an NGO pickup moves along its lifecycle
+type PickupStatusChanged struct {
+	Meta
+	NGOUserID  uuid.UUID `json:"ngo_user_id"`
+	PickupID   uuid.UUID `json:"pickup_id"`
+	OfferID    uuid.UUID `json:"offer_id"`
+	OfferTitle string    `json:"offer_title"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+}
+
+func (PickupStatusChanged) EventName() string { return NamePickupStatusChanged }
+
+// PickupDelivered is published when an NGO pickup reaches the delivered state
+type PickupDelivered struct {
+	Meta
+	NGOUserID      uuid.UUID `json:"ngo_user_id"`
+	PickupID       uuid.UUID `json:"pickup_id"`
+	OfferID        uuid.UUID `json:"offer_id"`
+	WeightKg       float64   `json:"weight_kg"`
+	MealsEstimated int       `json:"meals_estimated"`
+}
+
+func (PickupDelivered) EventName() string { return NamePickupDelivered }
+
+// FeedbackRecorded is published when an NGO records feedback from a recipient
+type FeedbackRecorded struct {
+	Meta
+	NGOUserID     uuid.UUID `json:"ngo_user_id"`
+	FeedbackID    uuid.UUID `json:"feedback_id"`
+	RecipientName string    `json:"recipient_name"`
+	Rating        *int      `json:"rating,omitempty"`
+}
+
+func (FeedbackRecorded) EventName() string { return NameFeedbackRecorded }
+
+// ShopSurplusPickupDue is published once per shop surplus item when its pickup
+// by the assigned NGO or community kitchen is coming up
+type ShopSurplusPickupDue struct {
+	Meta
+	ShopUserID        uuid.UUID `json:"shop_user_id"`
+	SurplusItemID     uuid.UUID `json:"surplus_item_id"`
+	DestinationUserID uuid.UUID `json:"destination_user_id"`
+	DestinationType   string    `json:"destination_type"`
+	ShopName          string    `json:"shop_name"`
+	SKUName           string    `json:"sku_name"`
+	Quantity          float64   `json:"quantity"`
+	Unit              string    `json:"unit"`
+	PickupTime        time.Time `json:"pickup_time"`
+}
+
+func (ShopSurplusPickupDue) EventName() string { return NameShopSurplusPickupDue }
+
+// XPAwarded is published when an entry is added to a user's XP ledger
+type XPAwarded struct {
+	Meta
+	UserID        uuid.UUID `json:"user_id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	SourceType    string    `json:"source_type"`
+	Amount        int       `json:"amount"`
+}
+
+func (XPAwarded) EventName() string { return NameXPAwarded }
+
+// NotificationCreated is published when a notification is stored for a user
+type NotificationCreated struct {
+	Meta
+	UserID          uuid.UUID  `json:"user_id"`
+	NotificationID  uuid.UUID  `json:"notification_id"`
+	Channel         string     `json:"channel"`
+	Type            string     `json:"type"`
+	Title           string     `json:"title"`
+	Message         string     `json:"message"`
+	Severity        string     `json:"severity"`
+	RelatedEntityID *uuid.UUID `json:"related_entity_id,omitempty"`
+}
+
+func (NotificationCreated) EventName() string { return NameNotificationCreated }
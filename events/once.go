@@ -0,0 +1,49 @@
+package events
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// HandleOnce runs fn in a transaction that also records that subscriber has
+// processed eventID. A redelivered event finds the record and is skipped, so
+// side effects written through tx happen exactly once.
+func HandleOnce(db *sql.DB, subscriber string, eventID uuid.UUID, fn func(tx *sql.Tx) error) error {
+	if db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin event transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO processed_events (event_id, subscriber, processed_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (event_id, subscriber) DO NOTHING
+	`
+	result, err := tx.Exec(query, eventID, subscriber)
+	if err != nil {
+		return fmt.Errorf("failed to record processed event: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to record processed event: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit event transaction: %w", err)
+	}
+	return nil
+}
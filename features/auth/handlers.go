@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"foodlink_backend/errors"
 	"foodlink_backend/utils"
+	"io"
 	"net/http"
 )
 
@@ -93,11 +94,12 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 // Logout handles user logout
 // @Summary      Logout user
-// @Description  Logout user (token invalidation handled client-side)
+// @Description  Revoke the current session, or every session of the user when all_sessions is true
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        request  body      LogoutRequest  false  "Logout options"
 // @Success      200      {object}  map[string]string
 // @Failure      401      {object}  errors.AppError
 // @Router       /auth/logout [post]
@@ -107,8 +109,33 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// In a stateless JWT system, logout is handled client-side
-	// Server can maintain a blacklist if needed
+	tokenString, appErr := bearerToken(r)
+	if appErr != nil {
+		utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+		return
+	}
+	claims, err := utils.ValidateToken(tokenString)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Invalid token")
+		return
+	}
+
+	// The body is optional; an empty one logs out the current session only
+	var req LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.service.Logout(claims, &req); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to logout", err.Error())
+		return
+	}
+
 	utils.OKResponse(w, "Logged out successfully", map[string]string{
 		"message": "Logged out successfully",
 	})
@@ -116,12 +143,13 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 
 // RefreshToken handles token refresh
 // @Summary      Refresh access token
-// @Description  Refresh the access token using refresh token
+// @Description  Exchange a refresh token for a new access token. The refresh token is rotated; reusing an old one revokes the session.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
-// @Security     BearerAuth
+// @Param        request  body      RefreshRequest  true  "Refresh request"
 // @Success      200      {object}  AuthResponse
+// @Failure      400      {object}  errors.AppError
 // @Failure      401      {object}  errors.AppError
 // @Router       /auth/refresh [post]
 func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
@@ -130,27 +158,22 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get user from context (set by auth middleware)
-	user, ok := r.Context().Value("user").(*User)
-	if !ok {
-		utils.UnauthorizedResponse(w, "Invalid token")
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
 		return
 	}
 
-	// Generate new token
-	token, err := utils.GenerateToken(user.ID, user.Email, user.Role, h.service.jwtExpiry)
+	response, err := h.service.Refresh(&req)
 	if err != nil {
-		utils.InternalServerErrorResponse(w, "Failed to generate token", err.Error())
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.UnauthorizedResponse(w, "Invalid refresh token")
 		return
 	}
 
-	response := &AuthResponse{
-		User:        user,
-		AccessToken:  token,
-		TokenType:    "Bearer",
-		ExpiresIn:    int64(h.service.jwtExpiry.Seconds()),
-	}
-
 	utils.OKResponse(w, "Token refreshed successfully", response)
 }
 
@@ -179,3 +202,46 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 
 	utils.OKResponse(w, "User retrieved successfully", user.ToUserResponse())
 }
+
+// UpdateHomeLocation handles setting the current user's home location
+// @Summary      Set home location
+// @Description  Save the coordinates that centre the user's nearby searches when no ?near= is given
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      UpdateHomeLocationRequest  true  "Home coordinates"
+// @Success      200      {object}  UserResponse
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Router       /auth/me/location [put]
+func (h *Handler) UpdateHomeLocation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+
+	user, ok := r.Context().Value("user").(*User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Invalid token")
+		return
+	}
+
+	var req UpdateHomeLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+
+	updated, err := h.service.UpdateHomeLocation(user, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update home location", err.Error())
+		return
+	}
+
+	utils.OKResponse(w, "Home location updated successfully", updated.ToUserResponse())
+}
@@ -12,30 +12,9 @@ import (
 func AuthMiddleware(service *Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Get token from Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				utils.UnauthorizedResponse(w, "Authorization header required")
-				return
-			}
-
-			// Check Bearer token format
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				utils.UnauthorizedResponse(w, "Invalid authorization header format")
-				return
-			}
-
-			tokenString := parts[1]
-
-			// Validate token and get user
-			user, err := service.ValidateToken(tokenString)
-			if err != nil {
-				if appErr, ok := err.(*errors.AppError); ok {
-					utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
-					return
-				}
-				utils.UnauthorizedResponse(w, "Invalid token")
+			user, appErr := authenticate(service, r)
+			if appErr != nil {
+				utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
 				return
 			}
 
@@ -48,6 +27,56 @@ func AuthMiddleware(service *Service) func(http.Handler) http.Handler {
 	}
 }
 
+// authenticate resolves the user for a request. A user already placed in the
+// context by an outer middleware is reused so the token is only validated once.
+func authenticate(service *Service, r *http.Request) (*User, *errors.AppError) {
+	if user, ok := r.Context().Value("user").(*User); ok && user != nil {
+		return user, nil
+	}
+
+	tokenString, appErr := bearerToken(r)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// Validate token and get user
+	user, err := service.ValidateToken(tokenString)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			return nil, appErr
+		}
+		return nil, errors.ErrInvalidToken
+	}
+
+	return user, nil
+}
+
+// bearerToken extracts the token from the Authorization header
+func bearerToken(r *http.Request) (string, *errors.AppError) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", errors.NewAppError(http.StatusUnauthorized, "Authorization header required")
+	}
+
+	// Check Bearer token format
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", errors.NewAppError(http.StatusUnauthorized, "Invalid authorization header format")
+	}
+
+	return parts[1], nil
+}
+
+// NearbyQuery reads ?near=lat,lng&radius_km= from a request, falling back to
+// the authenticated user's home location when near is absent
+func NearbyQuery(r *http.Request) (*utils.GeoQuery, error) {
+	var homeLat, homeLng *float64
+	if user, ok := r.Context().Value("user").(*User); ok && user != nil {
+		homeLat, homeLng = user.HomeLatitude, user.HomeLongitude
+	}
+	return utils.ParseGeoQuery(r.URL.Query(), homeLat, homeLng)
+}
+
 // RequireRole middleware checks if user has required role
 func RequireRole(requiredRoles ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -58,25 +87,64 @@ func RequireRole(requiredRoles ...string) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Check if user has required role
-			hasRole := false
-			for _, role := range requiredRoles {
-				if user.Role == role {
-					hasRole = true
-					break
+			if !hasRole(user, requiredRoles) {
+				forbidden(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Authorize enforces a MethodRoles policy. Methods the policy does not cover are
+// rejected; methods open to RolePublic skip authentication entirely.
+func Authorize(service *Service, policy MethodRoles) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			roles := policy.RolesFor(r.Method)
+			if len(roles) == 0 {
+				forbidden(w)
+				return
+			}
+
+			for _, role := range roles {
+				if role == RolePublic {
+					next.ServeHTTP(w, r)
+					return
 				}
 			}
 
-			if !hasRole {
-				utils.ForbiddenResponse(w, "Insufficient permissions")
+			user, appErr := authenticate(service, r)
+			if appErr != nil {
+				utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			if !hasRole(user, roles) {
+				forbidden(w)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "user", user)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+func hasRole(user *User, roles []string) bool {
+	for _, role := range roles {
+		if user.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func forbidden(w http.ResponseWriter) {
+	utils.ErrorResponse(w, errors.ErrInsufficientPerms.Code, errors.ErrInsufficientPerms.Message, nil)
+}
+
 // OptionalAuth middleware validates token if present but doesn't require it
 func OptionalAuth(service *Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -6,6 +6,30 @@ import (
 	"github.com/google/uuid"
 )
 
+// Roles stored in users.role
+const (
+	RoleFamily     = "family"
+	RoleRestaurant = "restaurant"
+	RoleShop       = "shop"
+	RoleNGO        = "ngo"
+	RoleAdmin      = "admin"
+)
+
+// RolePublic marks a route as callable without authentication in a MethodRoles policy
+const RolePublic = "public"
+
+// MethodRoles maps an HTTP method to the roles allowed to call it.
+// The "*" entry applies to every method without an entry of its own.
+type MethodRoles map[string][]string
+
+// RolesFor returns the roles allowed for method, or nil if the policy does not cover it
+func (p MethodRoles) RolesFor(method string) []string {
+	if roles, ok := p[method]; ok {
+		return roles
+	}
+	return p["*"]
+}
+
 // User represents a user in the system
 type User struct {
 	ID          uuid.UUID `json:"id" db:"id"`
@@ -14,6 +38,8 @@ type User struct {
 	PasswordHash string   `json:"-" db:"password_hash"`
 	HouseholdID *uuid.UUID `json:"household_id,omitempty" db:"household_id"`
 	Role        string    `json:"role" db:"role"`
+	HomeLatitude  *float64 `json:"home_latitude,omitempty" db:"home_latitude"`
+	HomeLongitude *float64 `json:"home_longitude,omitempty" db:"home_longitude"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -23,7 +49,13 @@ type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Name     string `json:"name" validate:"required,min=2,max=255"`
 	Password string `json:"password" validate:"required,min=8"`
-	Role     string `json:"role,omitempty" validate:"omitempty,oneof=family restaurant shop ngo admin"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=family restaurant shop ngo"`
+}
+
+// UpdateHomeLocationRequest sets the location that centres the user's nearby searches
+type UpdateHomeLocationRequest struct {
+	Latitude  *float64 `json:"latitude" validate:"required,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"required,gte=-180,lte=180"`
 }
 
 // LoginRequest represents a user login request
@@ -43,24 +75,54 @@ type AuthResponse struct {
 
 // UserResponse represents a user response (without sensitive data)
 type UserResponse struct {
-	ID          uuid.UUID  `json:"id"`
-	Email       string     `json:"email"`
-	Name        string     `json:"name"`
-	HouseholdID *uuid.UUID `json:"household_id,omitempty"`
-	Role        string     `json:"role"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID            uuid.UUID  `json:"id"`
+	Email         string     `json:"email"`
+	Name          string     `json:"name"`
+	HouseholdID   *uuid.UUID `json:"household_id,omitempty"`
+	Role          string     `json:"role"`
+	HomeLatitude  *float64   `json:"home_latitude,omitempty"`
+	HomeLongitude *float64   `json:"home_longitude,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
 // ToUserResponse converts User to UserResponse
 func (u *User) ToUserResponse() *UserResponse {
 	return &UserResponse{
-		ID:          u.ID,
-		Email:       u.Email,
-		Name:        u.Name,
-		HouseholdID: u.HouseholdID,
-		Role:        u.Role,
-		CreatedAt:   u.CreatedAt,
-		UpdatedAt:   u.UpdatedAt,
+		ID:            u.ID,
+		Email:         u.Email,
+		Name:          u.Name,
+		HouseholdID:   u.HouseholdID,
+		Role:          u.Role,
+		HomeLatitude:  u.HomeLatitude,
+		HomeLongitude: u.HomeLongitude,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
 	}
 }
+
+// RefreshToken represents a stored (hashed) refresh token.
+// Tokens issued from the same login share a FamilyID, which identifies the session.
+type RefreshToken struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	FamilyID        uuid.UUID  `json:"family_id" db:"family_id"`
+	TokenHash       string     `json:"-" db:"token_hash"`
+	AccessJTI       string     `json:"-" db:"access_jti"`
+	AccessExpiresAt time.Time  `json:"-" db:"access_expires_at"`
+	ExpiresAt       time.Time  `json:"expires_at" db:"expires_at"`
+	ReplacedBy      *uuid.UUID `json:"replaced_by,omitempty" db:"replaced_by"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedReason   string     `json:"revoked_reason,omitempty" db:"revoked_reason"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// RefreshRequest represents a token refresh request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest represents a logout request
+type LogoutRequest struct {
+	AllSessions bool `json:"all_sessions,omitempty"`
+}
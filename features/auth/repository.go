@@ -30,7 +30,7 @@ func (r *Repository) CreateUser(user *User) error {
 	query := `
 		INSERT INTO users (id, email, name, password_hash, household_id, role, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, email, name, password_hash, household_id, role, created_at, updated_at
+		RETURNING id, email, name, password_hash, household_id, role, home_latitude, home_longitude, created_at, updated_at
 	`
 
 	now := time.Now()
@@ -51,6 +51,8 @@ func (r *Repository) CreateUser(user *User) error {
 		&user.PasswordHash,
 		&user.HouseholdID,
 		&user.Role,
+		&user.HomeLatitude,
+		&user.HomeLongitude,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -73,7 +75,7 @@ func (r *Repository) GetUserByEmail(email string) (*User, error) {
 
 	user := &User{}
 	query := `
-		SELECT id, email, name, password_hash, household_id, role, created_at, updated_at
+		SELECT id, email, name, password_hash, household_id, role, home_latitude, home_longitude, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -85,6 +87,8 @@ func (r *Repository) GetUserByEmail(email string) (*User, error) {
 		&user.PasswordHash,
 		&user.HouseholdID,
 		&user.Role,
+		&user.HomeLatitude,
+		&user.HomeLongitude,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -107,7 +111,7 @@ func (r *Repository) GetUserByID(id uuid.UUID) (*User, error) {
 
 	user := &User{}
 	query := `
-		SELECT id, email, name, password_hash, household_id, role, created_at, updated_at
+		SELECT id, email, name, password_hash, household_id, role, home_latitude, home_longitude, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -119,6 +123,8 @@ func (r *Repository) GetUserByID(id uuid.UUID) (*User, error) {
 		&user.PasswordHash,
 		&user.HouseholdID,
 		&user.Role,
+		&user.HomeLatitude,
+		&user.HomeLongitude,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -143,7 +149,7 @@ func (r *Repository) UpdateUser(user *User) error {
 		UPDATE users
 		SET name = $1, household_id = $2, role = $3, updated_at = $4
 		WHERE id = $5
-		RETURNING id, email, name, password_hash, household_id, role, created_at, updated_at
+		RETURNING id, email, name, password_hash, household_id, role, home_latitude, home_longitude, created_at, updated_at
 	`
 
 	err := r.db.QueryRow(
@@ -160,6 +166,8 @@ func (r *Repository) UpdateUser(user *User) error {
 		&user.PasswordHash,
 		&user.HouseholdID,
 		&user.Role,
+		&user.HomeLatitude,
+		&user.HomeLongitude,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -174,6 +182,30 @@ func (r *Repository) UpdateUser(user *User) error {
 	return nil
 }
 
+// UpdateHomeLocation sets the user's home coordinates
+func (r *Repository) UpdateHomeLocation(user *User) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	query := `
+		UPDATE users
+		SET home_latitude = $1, home_longitude = $2, updated_at = $3
+		WHERE id = $4
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, user.HomeLatitude, user.HomeLongitude, time.Now(), user.ID).Scan(&user.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrUserNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	return nil
+}
+
 // EmailExists checks if an email already exists
 func (r *Repository) EmailExists(email string) (bool, error) {
 	if r.db == nil {
@@ -189,3 +221,229 @@ func (r *Repository) EmailExists(email string) (bool, error) {
 
 	return exists, nil
 }
+
+// CreateRefreshToken stores a new refresh token
+func (r *Repository) CreateRefreshToken(token *RefreshToken) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, access_jti, access_expires_at, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		token.ID,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.AccessJTI,
+		token.AccessExpiresAt,
+		token.ExpiresAt,
+		time.Now(),
+	).Scan(&token.CreatedAt)
+
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	return nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by the hash of its raw value
+func (r *Repository) GetRefreshTokenByHash(tokenHash string) (*RefreshToken, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+
+	token := &RefreshToken{}
+	var revokedReason sql.NullString
+	query := `
+		SELECT id, user_id, family_id, token_hash, access_jti, access_expires_at, expires_at, replaced_by, revoked_at, revoked_reason, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.AccessJTI,
+		&token.AccessExpiresAt,
+		&token.ExpiresAt,
+		&token.ReplacedBy,
+		&token.RevokedAt,
+		&revokedReason,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	token.RevokedReason = revokedReason.String
+
+	return token, nil
+}
+
+// RotateRefreshToken stores next and marks current as replaced by it in one transaction.
+// Returns ErrConflict if current was already rotated or revoked by a concurrent request.
+func (r *Repository) RotateRefreshToken(currentID uuid.UUID, next *RefreshToken) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	insert := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, access_jti, access_expires_at, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+	err = tx.QueryRow(
+		insert,
+		next.ID,
+		next.UserID,
+		next.FamilyID,
+		next.TokenHash,
+		next.AccessJTI,
+		next.AccessExpiresAt,
+		next.ExpiresAt,
+		time.Now(),
+	).Scan(&next.CreatedAt)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	update := `
+		UPDATE refresh_tokens
+		SET replaced_by = $1, revoked_at = $2, revoked_reason = 'rotated'
+		WHERE id = $3 AND revoked_at IS NULL
+	`
+	result, err := tx.Exec(update, next.ID, time.Now(), currentID)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected == 0 {
+		return errors.ErrConflict
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every refresh token in a family and the access tokens issued with them
+func (r *Repository) RevokeFamily(familyID uuid.UUID, reason string) error {
+	return r.revokeWhere(`family_id = $1`, familyID, reason)
+}
+
+// RevokeAllForUser revokes every session belonging to a user
+func (r *Repository) RevokeAllForUser(userID uuid.UUID, reason string) error {
+	return r.revokeWhere(`user_id = $1`, userID, reason)
+}
+
+func (r *Repository) revokeWhere(condition string, arg interface{}, reason string) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	revokeAccess := `
+		INSERT INTO revoked_access_tokens (jti, user_id, expires_at, revoked_at)
+		SELECT access_jti, user_id, access_expires_at, $2
+		FROM refresh_tokens
+		WHERE ` + condition + ` AND access_expires_at > $2
+		ON CONFLICT (jti) DO NOTHING
+	`
+	if _, err := tx.Exec(revokeAccess, arg, now); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	revokeRefresh := `
+		UPDATE refresh_tokens
+		SET revoked_at = $2, revoked_reason = $3
+		WHERE ` + condition + ` AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(revokeRefresh, arg, now, reason); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	return nil
+}
+
+// RevokeAccessToken adds a single access token jti to the revocation list
+func (r *Repository) RevokeAccessToken(jti string, userID uuid.UUID, expiresAt time.Time) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	query := `
+		INSERT INTO revoked_access_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	if _, err := r.db.Exec(query, jti, userID, expiresAt, time.Now()); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	return nil
+}
+
+// IsAccessTokenRevoked checks whether an access token jti has been revoked
+func (r *Repository) IsAccessTokenRevoked(jti string) (bool, error) {
+	if r.db == nil {
+		return false, errors.ErrDatabase
+	}
+
+	var revoked bool
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_access_tokens WHERE jti = $1)`
+	if err := r.db.QueryRow(query, jti).Scan(&revoked); err != nil {
+		return false, errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	return revoked, nil
+}
+
+// PurgeExpiredTokens deletes revocations and refresh tokens that can no longer be presented
+func (r *Repository) PurgeExpiredTokens() error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	now := time.Now()
+	if _, err := r.db.Exec(`DELETE FROM revoked_access_tokens WHERE expires_at < $1`, now); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if _, err := r.db.Exec(`DELETE FROM refresh_tokens WHERE expires_at < $1 AND access_expires_at < $1`, now); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	return nil
+}
@@ -12,12 +12,13 @@ func SetupRoutes(service *Service, handler *Handler) http.Handler {
 	// Public routes
 	mux.HandleFunc("/register", handler.Register)
 	mux.HandleFunc("/login", handler.Login)
+	mux.HandleFunc("/refresh", handler.RefreshToken)
 
 	// Protected routes
 	protectedMux := http.NewServeMux()
 	protectedMux.HandleFunc("/logout", handler.Logout)
-	protectedMux.HandleFunc("/refresh", handler.RefreshToken)
 	protectedMux.HandleFunc("/me", handler.GetMe)
+	protectedMux.HandleFunc("/me/location", handler.UpdateHomeLocation)
 
 	// Apply auth middleware to protected routes
 	protectedHandler := middleware.Chain(
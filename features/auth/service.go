@@ -1,9 +1,15 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"foodlink_backend/config"
 	"foodlink_backend/errors"
 	"foodlink_backend/utils"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,9 +18,11 @@ import (
 
 // Service handles authentication business logic
 type Service struct {
-	repo     *Repository
-	cfg      *config.Config
-	jwtExpiry time.Duration
+	repo          *Repository
+	cfg           *config.Config
+	jwtExpiry     time.Duration
+	refreshExpiry time.Duration
+	refreshSecret []byte
 }
 
 // NewService creates a new auth service
@@ -24,10 +32,26 @@ func NewService(cfg *config.Config) *Service {
 		expiry = 24 * time.Hour // Default 24 hours
 	}
 
+	refreshExpiry, _ := utils.ParseExpiry(cfg.JWTRefreshExpiry)
+	if refreshExpiry == 0 {
+		refreshExpiry = 48 * time.Hour // Default 48 hours
+	}
+
+	refreshSecret := cfg.JWTRefreshSecret
+	if refreshSecret == "" {
+		log.Println("Warning: JWT_REFRESH_SECRET not set, hashing refresh tokens with JWT_SECRET")
+		refreshSecret = cfg.JWTSecret
+	}
+
+	repo := NewRepository()
+	utils.SetRevocationChecker(repo.IsAccessTokenRevoked)
+
 	return &Service{
-		repo:      NewRepository(),
-		cfg:       cfg,
-		jwtExpiry: expiry,
+		repo:          repo,
+		cfg:           cfg,
+		jwtExpiry:     expiry,
+		refreshExpiry: refreshExpiry,
+		refreshSecret: []byte(refreshSecret),
 	}
 }
 
@@ -60,7 +84,7 @@ func (s *Service) Register(req *RegisterRequest) (*AuthResponse, error) {
 	// Set default role if not provided
 	role := req.Role
 	if role == "" {
-		role = "family"
+		role = RoleFamily
 	}
 
 	// Create user
@@ -76,18 +100,8 @@ func (s *Service) Register(req *RegisterRequest) (*AuthResponse, error) {
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, user.Role, s.jwtExpiry)
-	if err != nil {
-		return nil, errors.WrapError(err, errors.ErrInternalServer)
-	}
-
-	return &AuthResponse{
-		User:        user,
-		AccessToken: token,
-		TokenType:   "Bearer",
-		ExpiresIn:   int64(s.jwtExpiry.Seconds()),
-	}, nil
+	// Start a new session (refresh token family)
+	return s.issueTokens(user, uuid.New(), nil)
 }
 
 // Login authenticates a user and returns a token
@@ -113,18 +127,8 @@ func (s *Service) Login(req *LoginRequest) (*AuthResponse, error) {
 		return nil, errors.ErrInvalidCredentials
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, user.Role, s.jwtExpiry)
-	if err != nil {
-		return nil, errors.WrapError(err, errors.ErrInternalServer)
-	}
-
-	return &AuthResponse{
-		User:        user,
-		AccessToken: token,
-		TokenType:   "Bearer",
-		ExpiresIn:   int64(s.jwtExpiry.Seconds()),
-	}, nil
+	// Start a new session (refresh token family)
+	return s.issueTokens(user, uuid.New(), nil)
 }
 
 // GetUserByID retrieves a user by ID
@@ -132,11 +136,167 @@ func (s *Service) GetUserByID(id uuid.UUID) (*User, error) {
 	return s.repo.GetUserByID(id)
 }
 
+// UpdateHomeLocation saves the user's home location, which centres their
+// nearby searches when they do not pass one
+func (s *Service) UpdateHomeLocation(user *User, req *UpdateHomeLocationRequest) (*User, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(
+			errors.ErrValidationFailed.Code,
+			"Validation failed: "+validationErrors[0],
+			nil,
+		)
+	}
+
+	updated := *user
+	updated.HomeLatitude = req.Latitude
+	updated.HomeLongitude = req.Longitude
+	if err := s.repo.UpdateHomeLocation(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
 // GetUserByEmail retrieves a user by email
 func (s *Service) GetUserByEmail(email string) (*User, error) {
 	return s.repo.GetUserByEmail(email)
 }
 
+// Refresh rotates a refresh token and issues a new access token for the same session.
+// Presenting a token that was already rotated or revoked revokes the whole session.
+func (s *Service) Refresh(req *RefreshRequest) (*AuthResponse, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(
+			errors.ErrValidationFailed.Code,
+			"Validation failed: "+validationErrors[0],
+			nil,
+		)
+	}
+
+	current, err := s.repo.GetRefreshTokenByHash(s.hashRefreshToken(req.RefreshToken))
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		if current.ReplacedBy != nil {
+			// A rotated token came back: assume it was stolen and end the session
+			log.Printf("Refresh token reuse detected for user %s (family %s); revoking session", current.UserID, current.FamilyID)
+			if err := s.repo.RevokeFamily(current.FamilyID, "reuse_detected"); err != nil {
+				return nil, err
+			}
+			return nil, errors.NewAppError(errors.ErrInvalidToken.Code, "Refresh token reuse detected; session revoked")
+		}
+		return nil, errors.ErrInvalidToken
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, errors.ErrTokenExpired
+	}
+
+	user, err := s.repo.GetUserByID(current.UserID)
+	if err != nil {
+		return nil, errors.ErrInvalidToken
+	}
+
+	response, err := s.issueTokens(user, current.FamilyID, &current.ID)
+	if err == errors.ErrConflict {
+		// Lost a race with another refresh using the same token
+		if err := s.repo.RevokeFamily(current.FamilyID, "reuse_detected"); err != nil {
+			return nil, err
+		}
+		return nil, errors.NewAppError(errors.ErrInvalidToken.Code, "Refresh token reuse detected; session revoked")
+	}
+	return response, err
+}
+
+// Logout revokes the session the access token belongs to, or every session of the user
+func (s *Service) Logout(claims *utils.Claims, req *LogoutRequest) error {
+	if req.AllSessions {
+		if err := s.repo.RevokeAllForUser(claims.UserID, "logout_all"); err != nil {
+			return err
+		}
+	} else if familyID, err := uuid.Parse(claims.SessionID); err == nil {
+		if err := s.repo.RevokeFamily(familyID, "logout"); err != nil {
+			return err
+		}
+	}
+
+	// Always revoke the presented access token, even if it has no session
+	expiresAt := time.Now().Add(s.jwtExpiry)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := s.repo.RevokeAccessToken(claims.ID, claims.UserID, expiresAt); err != nil {
+		return err
+	}
+
+	if err := s.repo.PurgeExpiredTokens(); err != nil {
+		log.Printf("Warning: failed to purge expired tokens: %v", err)
+	}
+
+	return nil
+}
+
+// issueTokens creates an access token and a refresh token in the given family.
+// When previousID is set the previous refresh token is rotated out atomically.
+func (s *Service) issueTokens(user *User, familyID uuid.UUID, previousID *uuid.UUID) (*AuthResponse, error) {
+	accessToken, claims, err := utils.GenerateSessionToken(user.ID, user.Email, user.Role, familyID.String(), s.jwtExpiry)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrInternalServer)
+	}
+
+	rawRefresh, err := generateRefreshToken()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrInternalServer)
+	}
+
+	refresh := &RefreshToken{
+		ID:              uuid.New(),
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       s.hashRefreshToken(rawRefresh),
+		AccessJTI:       claims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		ExpiresAt:       time.Now().Add(s.refreshExpiry),
+	}
+
+	if previousID != nil {
+		err = s.repo.RotateRefreshToken(*previousID, refresh)
+	} else {
+		err = s.repo.CreateRefreshToken(refresh)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: rawRefresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.jwtExpiry.Seconds()),
+	}, nil
+}
+
+// hashRefreshToken keys the stored hash with the refresh secret so a leaked table cannot be replayed
+func (s *Service) hashRefreshToken(raw string) string {
+	mac := hmac.New(sha256.New, s.refreshSecret)
+	mac.Write([]byte(raw))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateRefreshToken returns a random opaque refresh token
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 // ValidateToken validates a JWT token and returns user info
 func (s *Service) ValidateToken(tokenString string) (*User, error) {
 	claims, err := utils.ValidateToken(tokenString)
@@ -0,0 +1,26 @@
+package badges
+
+import (
+	"context"
+	"foodlink_backend/events"
+)
+
+const eventSubscriber = "badges"
+
+// RegisterEventHandlers re-evaluates a user's badges after activity that can
+// move a badge metric. XP awards cover level badges and most activity; unlocking
+// is idempotent, so redelivered events are harmless.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.ConsumptionLogged) error {
+		_, err := s.Evaluate(e.UserID)
+		return err
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.SurplusPostCreated) error {
+		_, err := s.Evaluate(e.UserID)
+		return err
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.XPAwarded) error {
+		_, err := s.Evaluate(e.UserID)
+		return err
+	})
+}
@@ -73,21 +73,54 @@ func (h *Handler) GetAvailableBadges(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	badges := h.service.GetAvailableBadges()
+	badges, err := h.service.GetAvailableBadges()
+	if err != nil {
+		utils.InternalServerErrorResponse(w, "Failed to retrieve available badges", err.Error())
+		return
+	}
 	utils.OKResponse(w, "Available badges retrieved successfully", badges)
 }
 
+// GetProgress handles GET /api/v1/badges/progress
+// @Summary      Get badge progress
+// @Description  Get the authenticated user's progress toward each badge they have not unlocked
+// @Tags         badges
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   BadgeProgress
+// @Failure      401  {object}  errors.AppError
+// @Router       /badges/progress [get]
+func (h *Handler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	progress, err := h.service.GetProgress(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(w, "Failed to retrieve badge progress", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Badge progress retrieved successfully", progress)
+}
+
 // UnlockBadge handles POST /api/v1/badges/unlock
 // @Summary      Unlock badge
-// @Description  Unlock a badge for the authenticated user (system endpoint)
+// @Description  Check a badge's criteria for the authenticated user and unlock it if they are met
 // @Tags         badges
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        request  body      UnlockBadgeRequest  true  "Badge unlock data"
+// @Param        request  body      UnlockBadgeRequest  true  "Badge to unlock"
 // @Success      201      {object}  Badge
 // @Failure      400      {object}  errors.AppError
 // @Failure      401      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
 // @Failure      409      {object}  errors.AppError
 // @Router       /badges/unlock [post]
 func (h *Handler) UnlockBadge(w http.ResponseWriter, r *http.Request) {
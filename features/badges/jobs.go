@@ -0,0 +1,16 @@
+package badges
+
+import (
+	"foodlink_backend/jobs"
+	"time"
+)
+
+// RegisterJobs schedules a sweep over all users, which catches metrics that do
+// not publish events, such as inventory items, meal plans and nutrition logs
+func (s *Service) RegisterJobs(scheduler *jobs.Scheduler, interval time.Duration) {
+	scheduler.Register(jobs.Job{
+		Name:     "badges.evaluate",
+		Interval: interval,
+		Run:      s.EvaluateAll,
+	})
+}
@@ -19,13 +19,9 @@ type Badge struct {
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
-// UnlockBadgeRequest represents a request to unlock a badge
+// UnlockBadgeRequest asks the server to check a badge's criteria and unlock it if they are met
 type UnlockBadgeRequest struct {
-	BadgeID    string `json:"badge_id" validate:"required"`
-	Name       string `json:"name" validate:"required"`
-	Description string `json:"description,omitempty"`
-	Icon       string `json:"icon,omitempty"`
-	XPReward   int    `json:"xp_reward,omitempty"`
+	BadgeID string `json:"badge_id" validate:"required"`
 }
 
 // AvailableBadge represents an available badge definition
@@ -35,4 +31,31 @@ type AvailableBadge struct {
 	Description string `json:"description"`
 	Icon       string `json:"icon"`
 	XPReward   int    `json:"xp_reward"`
+	Metric      string `json:"metric"`
+	Threshold   int    `json:"threshold"`
+}
+
+// Badge metrics. Each measures one aspect of a user's activity; a badge unlocks
+// when its metric reaches the definition's threshold.
+const (
+	MetricAccount        = "account"
+	MetricInventoryItems = "inventory_items"
+	MetricZeroWasteDays  = "zero_waste_days"
+	MetricMealPlans      = "meal_plans"
+	MetricNutritionDays  = "nutrition_days"
+	MetricSurplusPosts   = "surplus_posts"
+	MetricLevel          = "level"
+)
+
+// BadgeProgress is a user's progress toward a badge
+type BadgeProgress struct {
+	BadgeID     string  `json:"badge_id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Icon        string  `json:"icon"`
+	XPReward    int     `json:"xp_reward"`
+	Metric      string  `json:"metric"`
+	Current     int     `json:"current"`
+	Threshold   int     `json:"threshold"`
+	Percent     float64 `json:"percent"`
 }
@@ -69,3 +69,145 @@ func (r *Repository) Create(badge *Badge) error {
 	}
 	return nil
 }
+
+// metricQueries compute a badge metric for the user in $1. The zero-waste
+// streak is computed separately by zeroWasteStreak.
+var metricQueries = map[string]string{
+	MetricAccount:        `SELECT COUNT(*) FROM users WHERE id = $1`,
+	MetricInventoryItems: `SELECT COUNT(*) FROM inventory_items WHERE user_id = $1`,
+	MetricMealPlans:      `SELECT COUNT(*) FROM meal_plans WHERE user_id = $1`,
+	MetricNutritionDays:  `SELECT COUNT(DISTINCT date) FROM nutrition_data WHERE user_id = $1`,
+	MetricSurplusPosts:   `SELECT COUNT(*) FROM community_surplus_posts WHERE user_id = $1`,
+	MetricLevel:          `SELECT COALESCE((SELECT level FROM user_xp WHERE user_id = $1), 1)`,
+}
+
+// zeroWasteLookbackDays bounds how far back a zero-waste streak is traced
+const zeroWasteLookbackDays = 366
+
+// IsKnownMetric reports whether metric can be evaluated
+func IsKnownMetric(metric string) bool {
+	_, ok := metricQueries[metric]
+	return ok || metric == MetricZeroWasteDays
+}
+
+// ListDefinitions returns the active badge definitions in display order
+func (r *Repository) ListDefinitions() ([]*AvailableBadge, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `SELECT badge_id, name, COALESCE(description, ''), COALESCE(icon, ''), xp_reward, metric, threshold FROM badge_definitions WHERE active = TRUE ORDER BY sort_order, badge_id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	var definitions []*AvailableBadge
+	for rows.Next() {
+		d := &AvailableBadge{}
+		if err := rows.Scan(&d.BadgeID, &d.Name, &d.Description, &d.Icon, &d.XPReward, &d.Metric, &d.Threshold); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		definitions = append(definitions, d)
+	}
+	return definitions, rows.Err()
+}
+
+// GetDefinition returns an active badge definition
+func (r *Repository) GetDefinition(badgeID string) (*AvailableBadge, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	d := &AvailableBadge{}
+	query := `SELECT badge_id, name, COALESCE(description, ''), COALESCE(icon, ''), xp_reward, metric, threshold FROM badge_definitions WHERE badge_id = $1 AND active = TRUE`
+	err := r.db.QueryRow(query, badgeID).Scan(&d.BadgeID, &d.Name, &d.Description, &d.Icon, &d.XPReward, &d.Metric, &d.Threshold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return d, nil
+}
+
+// MetricValue computes the user's current value for metric
+func (r *Repository) MetricValue(userID uuid.UUID, metric string) (int, error) {
+	if r.db == nil {
+		return 0, errors.ErrDatabase
+	}
+	if metric == MetricZeroWasteDays {
+		return r.zeroWasteStreak(userID)
+	}
+	query, ok := metricQueries[metric]
+	if !ok {
+		return 0, errors.NewAppError(errors.ErrBadRequest.Code, "Unknown badge metric: "+metric)
+	}
+	var value int
+	if err := r.db.QueryRow(query, userID).Scan(&value); err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return value, nil
+}
+
+// zeroWasteStreak counts the consecutive days, ending today or yesterday, on
+// which the user logged consumption and logged no waste. Today only breaks the
+// streak once something wasted is logged.
+func (r *Repository) zeroWasteStreak(userID uuid.UUID) (int, error) {
+	query := `
+		SELECT consumed_at::date, BOOL_OR(COALESCE(was_wasted, FALSE)), CURRENT_DATE
+		FROM consumption_logs
+		WHERE user_id = $1 AND consumed_at >= CURRENT_DATE - $2 * INTERVAL '1 day'
+		GROUP BY consumed_at::date
+		ORDER BY consumed_at::date DESC
+	`
+	rows, err := r.db.Query(query, userID, zeroWasteLookbackDays)
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+
+	streak := 0
+	var expected time.Time
+	for rows.Next() {
+		var day, today time.Time
+		var wasted bool
+		if err := rows.Scan(&day, &wasted, &today); err != nil {
+			return 0, errors.WrapError(err, errors.ErrDatabase)
+		}
+		if expected.IsZero() {
+			expected = today
+			if day.Before(today) {
+				expected = today.AddDate(0, 0, -1)
+			}
+		}
+		if !day.Equal(expected) || wasted {
+			break
+		}
+		streak++
+		expected = expected.AddDate(0, 0, -1)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return streak, nil
+}
+
+// ListUserIDs returns up to limit user IDs greater than after, in ID order
+func (r *Repository) ListUserIDs(after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT id FROM users WHERE id > $1 ORDER BY id LIMIT $2`, after, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -1,8 +1,11 @@
 package badges
 
 import (
+	"context"
 	"foodlink_backend/errors"
+	"foodlink_backend/features/xp"
 	"foodlink_backend/utils"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,55 +13,197 @@ import (
 
 type Service struct {
 	repo *Repository
+	xp   *xp.Service
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{repo: NewRepository(), xp: xp.NewService()}
 }
 
 func (s *Service) GetByUserID(userID uuid.UUID) ([]*Badge, error) {
 	return s.repo.GetByUserID(userID)
 }
 
-func (s *Service) GetAvailableBadges() []*AvailableBadge {
-	// Define available badges
-	return []*AvailableBadge{
-		{BadgeID: "first-login", Name: "Welcome!", Description: "Logged in for the first time", Icon: "👋", XPReward: 10},
-		{BadgeID: "inventory-master", Name: "Inventory Master", Description: "Added 50 items to inventory", Icon: "📦", XPReward: 50},
-		{BadgeID: "zero-waste", Name: "Zero Waste Hero", Description: "No waste for 7 days", Icon: "🌱", XPReward: 100},
-		{BadgeID: "meal-planner", Name: "Meal Planner", Description: "Created 10 meal plans", Icon: "🍽️", XPReward: 75},
-		{BadgeID: "nutrition-tracker", Name: "Nutrition Tracker", Description: "Logged nutrition for 30 days", Icon: "📊", XPReward: 150},
-		{BadgeID: "community-helper", Name: "Community Helper", Description: "Shared 5 surplus items", Icon: "🤝", XPReward: 200},
-		{BadgeID: "level-10", Name: "Level 10 Achiever", Description: "Reached level 10", Icon: "⭐", XPReward: 500},
-		{BadgeID: "level-25", Name: "Level 25 Champion", Description: "Reached level 25", Icon: "🏆", XPReward: 1000},
+func (s *Service) GetAvailableBadges() ([]*AvailableBadge, error) {
+	return s.repo.ListDefinitions()
+}
+
+// GetProgress returns the user's progress toward each badge they have not unlocked yet
+func (s *Service) GetProgress(userID uuid.UUID) ([]*BadgeProgress, error) {
+	locked, err := s.lockedDefinitions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int)
+	progress := make([]*BadgeProgress, 0, len(locked))
+	for _, d := range locked {
+		current, err := s.metricValue(userID, d.Metric, values)
+		if err != nil {
+			return nil, err
+		}
+		percent := float64(current) / float64(d.Threshold) * 100
+		if percent > 100 {
+			percent = 100
+		}
+		progress = append(progress, &BadgeProgress{
+			BadgeID:     d.BadgeID,
+			Name:        d.Name,
+			Description: d.Description,
+			Icon:        d.Icon,
+			XPReward:    d.XPReward,
+			Metric:      d.Metric,
+			Current:     current,
+			Threshold:   d.Threshold,
+			Percent:     percent,
+		})
+	}
+	return progress, nil
+}
+
+// Evaluate checks every badge the user has not unlocked and unlocks those whose
+// criteria are met, granting their XP reward. It returns the newly unlocked badges.
+func (s *Service) Evaluate(userID uuid.UUID) ([]*Badge, error) {
+	locked, err := s.lockedDefinitions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int)
+	var unlocked []*Badge
+	for _, d := range locked {
+		current, err := s.metricValue(userID, d.Metric, values)
+		if err != nil {
+			return unlocked, err
+		}
+		if current < d.Threshold {
+			continue
+		}
+		badge, err := s.unlock(userID, d)
+		if err == errors.ErrAlreadyExists {
+			continue
+		}
+		if err != nil {
+			return unlocked, err
+		}
+		unlocked = append(unlocked, badge)
 	}
+	return unlocked, nil
 }
 
+// UnlockBadge unlocks a single badge for the user if its criteria are met
 func (s *Service) UnlockBadge(userID uuid.UUID, req *UnlockBadgeRequest) (*Badge, error) {
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
 	}
-	
+
 	// Check if badge already exists
 	existing, err := s.repo.GetByUserIDAndBadgeID(userID, req.BadgeID)
 	if err == nil && existing != nil {
 		return existing, errors.ErrAlreadyExists
 	}
-	
+
+	definition, err := s.repo.GetDefinition(req.BadgeID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.MetricValue(userID, definition.Metric)
+	if err != nil {
+		return nil, err
+	}
+	if current < definition.Threshold {
+		return nil, errors.NewAppError(errors.ErrBadRequest.Code, "Badge criteria not met")
+	}
+	return s.unlock(userID, definition)
+}
+
+// EvaluateAll evaluates badges for every user in batches, stopping early if ctx is cancelled
+func (s *Service) EvaluateAll(ctx context.Context) error {
+	const batchSize = 500
+	after := uuid.Nil
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		userIDs, err := s.repo.ListUserIDs(after, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			if _, err := s.Evaluate(userID); err != nil {
+				log.Printf("Warning: badge evaluation failed for user %s: %v", userID, err)
+			}
+		}
+		if len(userIDs) < batchSize {
+			return nil
+		}
+		after = userIDs[len(userIDs)-1]
+	}
+}
+
+// unlock grants the definition's XP reward and records the badge. The reward is
+// granted first and is idempotent, so a failed unlock can be retried without
+// losing or doubling the XP.
+func (s *Service) unlock(userID uuid.UUID, d *AvailableBadge) (*Badge, error) {
+	if _, err := s.xp.Reward(userID, xp.SourceBadge, d.BadgeID, d.XPReward, "Unlocked badge: "+d.Name); err != nil {
+		return nil, err
+	}
+
 	badge := &Badge{
 		ID:          uuid.New(),
 		UserID:      userID,
-		BadgeID:     req.BadgeID,
-		Name:        req.Name,
-		Description: req.Description,
-		Icon:        req.Icon,
+		BadgeID:     d.BadgeID,
+		Name:        d.Name,
+		Description: d.Description,
+		Icon:        d.Icon,
 		UnlockedAt:  time.Now(),
-		XPReward:    req.XPReward,
+		XPReward:    d.XPReward,
 	}
-	
 	if err := s.repo.Create(badge); err != nil {
 		return nil, err
 	}
-	
 	return badge, nil
 }
+
+// lockedDefinitions returns the active definitions the user has not unlocked
+func (s *Service) lockedDefinitions(userID uuid.UUID) ([]*AvailableBadge, error) {
+	definitions, err := s.repo.ListDefinitions()
+	if err != nil {
+		return nil, err
+	}
+	owned, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	unlocked := make(map[string]bool, len(owned))
+	for _, b := range owned {
+		unlocked[b.BadgeID] = true
+	}
+
+	var locked []*AvailableBadge
+	for _, d := range definitions {
+		if !unlocked[d.BadgeID] {
+			locked = append(locked, d)
+		}
+	}
+	return locked, nil
+}
+
+// metricValue computes a metric once per evaluation, caching it in values.
+// Definitions with an unknown metric never unlock.
+func (s *Service) metricValue(userID uuid.UUID, metric string, values map[string]int) (int, error) {
+	if value, ok := values[metric]; ok {
+		return value, nil
+	}
+	if !IsKnownMetric(metric) {
+		log.Printf("Warning: badge metric %q is not supported", metric)
+		values[metric] = 0
+		return 0, nil
+	}
+	value, err := s.repo.MetricValue(userID, metric)
+	if err != nil {
+		return 0, err
+	}
+	values[metric] = value
+	return value, nil
+}
@@ -0,0 +1,31 @@
+package leaderboard
+
+import (
+	"context"
+	"foodlink_backend/events"
+)
+
+const eventSubscriber = "community-impact"
+
+// RegisterEventHandlers recomputes the community impact snapshot after the
+// events that move it. Recomputing from source data is idempotent, so
+// redelivered events are harmless.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.SurplusRequestApproved) error {
+		return s.recomputeImpact()
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.LeftoverClaimed) error {
+		return s.recomputeImpact()
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.DonationLogged) error {
+		return s.recomputeImpact()
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.PickupDelivered) error {
+		return s.recomputeImpact()
+	})
+}
+
+func (s *Service) recomputeImpact() error {
+	_, err := s.RecomputeImpact()
+	return err
+}
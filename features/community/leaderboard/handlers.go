@@ -5,6 +5,7 @@ import (
 	"foodlink_backend/features/auth"
 	"foodlink_backend/utils"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 )
@@ -27,22 +28,31 @@ func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
 
 // GetLeaderboard handles GET /api/v1/community/leaderboard
 // @Summary      Get leaderboard
-// @Description  Get community leaderboard by type
+// @Description  Get a community leaderboard for a period, including the caller's own rank
 // @Tags         community-leaderboard
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        type  query     string  false  "Leaderboard type (top-sharers, zero-waste, volunteer-stars, building-impact, weekly-xp)"
-// @Success      200   {object}  Leaderboard
-// @Failure      401   {object}  errors.AppError
+// @Param        type    query     string  false  "Leaderboard type (top-sharers, zero-waste, volunteer-stars, building-impact, weekly-xp)"
+// @Param        period  query     string  false  "Period (daily, weekly, monthly, all-time); defaults to weekly for weekly-xp, otherwise all-time"
+// @Param        limit   query     int     false  "Number of top entries (default 10, max 100)"
+// @Success      200     {object}  Leaderboard
+// @Failure      400     {object}  errors.AppError
+// @Failure      401     {object}  errors.AppError
 // @Router       /community/leaderboard [get]
 func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	leaderboardType := r.URL.Query().Get("type")
-	leaderboard, err := h.service.GetLeaderboard(leaderboardType)
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	leaderboard, err := h.service.GetLeaderboard(query.Get("type"), query.Get("period"), limit, user.ID, user.HouseholdID)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
@@ -0,0 +1,25 @@
+package leaderboard
+
+import (
+	"context"
+	"foodlink_backend/jobs"
+	"time"
+)
+
+// RegisterJobs schedules the periodic materialization of every leaderboard and
+// of the community impact snapshot, which keeps trends current and applies
+// changed impact factors
+func (s *Service) RegisterJobs(scheduler *jobs.Scheduler, leaderboardInterval, impactInterval time.Duration) {
+	scheduler.Register(jobs.Job{
+		Name:     "leaderboard.refresh",
+		Interval: leaderboardInterval,
+		Run:      s.RefreshAll,
+	})
+	scheduler.Register(jobs.Job{
+		Name:     "community.impact",
+		Interval: impactInterval,
+		Run: func(ctx context.Context) error {
+			return s.recomputeImpact()
+		},
+	})
+}
@@ -3,6 +3,7 @@ package leaderboard
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"foodlink_backend/features/impact"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,21 +30,69 @@ func (j *JSONB) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// Leaderboard types
+const (
+	TypeTopSharers     = "top-sharers"
+	TypeZeroWaste      = "zero-waste"
+	TypeVolunteerStars = "volunteer-stars"
+	TypeBuildingImpact = "building-impact"
+	TypeWeeklyXP       = "weekly-xp"
+)
+
+// Leaderboard periods
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+	PeriodAllTime = "all-time"
+)
+
+// Trends compare an entry's rank with the previous refresh
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendSame = "same"
+	TrendNew  = "new"
+)
+
 type Leaderboard struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	Type      string    `json:"type" db:"type"`
-	Entries   JSONB     `json:"entries" db:"entries"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID        uuid.UUID           `json:"id" db:"id"`
+	Type      string              `json:"type" db:"type"`
+	Period    string              `json:"period" db:"period"`
+	Entries   []*LeaderboardEntry `json:"entries" db:"entries"`
+	Me        *LeaderboardEntry   `json:"me,omitempty"`
+	UpdatedAt time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// LeaderboardEntry is one ranked user, or household for building-impact
+type LeaderboardEntry struct {
+	Rank      int       `json:"rank"`
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Household string    `json:"household,omitempty"`
+	Value     float64   `json:"value"`
+	Unit      string    `json:"unit"`
+	Trend     string    `json:"trend"`
 }
 
 type Impact struct {
-	ID                  uuid.UUID `json:"id" db:"id"`
-	TotalSurplusKg     float64   `json:"total_surplus_kg" db:"total_surplus_kg"`
-	Donations           int       `json:"donations" db:"donations"`
-	CO2PreventedKg      float64   `json:"co2_prevented_kg" db:"co2_prevented_kg"`
-	WaterSavedLiters   float64   `json:"water_saved_liters" db:"water_saved_liters"`
-	MealsProvided       int       `json:"meals_provided" db:"meals_provided"`
-	WeeklyTrend         JSONB     `json:"weekly_trend,omitempty" db:"weekly_trend"`
-	PersonalContribution JSONB    `json:"personal_contribution,omitempty" db:"personal_contribution"`
-	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+	ID                   uuid.UUID                `json:"id" db:"id"`
+	TotalSurplusKg       float64                  `json:"total_surplus_kg" db:"total_surplus_kg"`
+	Donations            int                      `json:"donations" db:"donations"`
+	CO2PreventedKg       float64                  `json:"co2_prevented_kg" db:"co2_prevented_kg"`
+	WaterSavedLiters     float64                  `json:"water_saved_liters" db:"water_saved_liters"`
+	MealsProvided        int                      `json:"meals_provided" db:"meals_provided"`
+	WeeklyTrend          []impact.TrendPoint      `json:"weekly_trend,omitempty" db:"weekly_trend"`
+	MonthlyTrend         []impact.TrendPoint      `json:"monthly_trend,omitempty" db:"monthly_trend"`
+	CategoryBreakdown    []*impact.CategoryImpact `json:"category_breakdown,omitempty" db:"category_breakdown"`
+	PersonalContribution JSONB                    `json:"personal_contribution,omitempty" db:"personal_contribution"`
+	UpdatedAt            time.Time                `json:"updated_at" db:"updated_at"`
 }
+
+// Impact sources, one per kind of rescued food
+const (
+	SourceSurplusShared   = "surplus_shared"
+	SourceLeftoverClaimed = "leftover_claimed"
+	SourceDonation        = "donation"
+	SourceDelivery        = "delivery"
+)
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"foodlink_backend/database"
 	"foodlink_backend/errors"
+	"foodlink_backend/features/impact"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,14 +19,15 @@ func NewRepository() *Repository {
 	return &Repository{db: database.GetDB()}
 }
 
-func (r *Repository) GetByType(leaderboardType string) (*Leaderboard, error) {
+// GetBoard returns the materialized top entries for a leaderboard
+func (r *Repository) GetBoard(leaderboardType, period string) (*Leaderboard, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
 	lb := &Leaderboard{}
 	var entriesJSON []byte
-	query := `SELECT id, type, entries, updated_at FROM community_leaderboard WHERE type = $1 ORDER BY updated_at DESC LIMIT 1`
-	err := r.db.QueryRow(query, leaderboardType).Scan(&lb.ID, &lb.Type, &entriesJSON, &lb.UpdatedAt)
+	query := `SELECT id, type, period, entries, updated_at FROM community_leaderboard WHERE type = $1 AND period = $2`
+	err := r.db.QueryRow(query, leaderboardType, period).Scan(&lb.ID, &lb.Type, &lb.Period, &entriesJSON, &lb.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
@@ -37,14 +40,139 @@ func (r *Repository) GetByType(leaderboardType string) (*Leaderboard, error) {
 	return lb, nil
 }
 
+// GetRank returns a subject's entry on a leaderboard
+func (r *Repository) GetRank(leaderboardType, period string, subjectID uuid.UUID) (*LeaderboardEntry, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `SELECT rank, previous_rank, subject_id, name, COALESCE(household, ''), value FROM community_leaderboard_ranks WHERE type = $1 AND period = $2 AND subject_id = $3`
+	entry, err := scanEntry(r.db.QueryRow(query, leaderboardType, period, subjectID), leaderboardType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return entry, nil
+}
+
+// Refresh recomputes a leaderboard from scoreQuery, counting activity since
+// since (nil for all time), and stores every rank plus the top topN entries.
+// Ranks that already existed keep their previous rank so trends can be shown.
+func (r *Repository) Refresh(leaderboardType, period string, since *time.Time, topN int) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	scoreQuery, ok := scoreQueries[leaderboardType]
+	if !ok {
+		return errors.NewAppError(errors.ErrBadRequest.Code, "Invalid leaderboard type")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	// Serialise refreshes of the same board
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "leaderboard:"+leaderboardType+":"+period); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	var sinceArg interface{}
+	if since != nil {
+		sinceArg = *since
+	}
+	refreshedAt := time.Now()
+	upsert := `
+		INSERT INTO community_leaderboard_ranks (type, period, subject_id, name, household, value, rank, refreshed_at)
+		SELECT $2::varchar, $3::varchar, subject_id, name, NULLIF(household, ''), ROUND(value::numeric, 2), RANK() OVER (ORDER BY ROUND(value::numeric, 2) DESC), $4::timestamptz
+		FROM (` + scoreQuery + `) AS scores (subject_id, name, household, value)
+		ON CONFLICT (type, period, subject_id) DO UPDATE SET
+			previous_rank = community_leaderboard_ranks.rank,
+			name = EXCLUDED.name,
+			household = EXCLUDED.household,
+			value = EXCLUDED.value,
+			rank = EXCLUDED.rank,
+			refreshed_at = EXCLUDED.refreshed_at
+	`
+	if _, err := tx.Exec(upsert, sinceArg, leaderboardType, period, refreshedAt); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	// Subjects with no activity left in the window drop off the board
+	if _, err := tx.Exec(`DELETE FROM community_leaderboard_ranks WHERE type = $1 AND period = $2 AND refreshed_at < $3`, leaderboardType, period, refreshedAt); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	query := `SELECT rank, previous_rank, subject_id, name, COALESCE(household, ''), value FROM community_leaderboard_ranks WHERE type = $1 AND period = $2 ORDER BY rank, name LIMIT $3`
+	rows, err := tx.Query(query, leaderboardType, period, topN)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	entries := []*LeaderboardEntry{}
+	for rows.Next() {
+		entry, err := scanEntry(rows, leaderboardType)
+		if err != nil {
+			rows.Close()
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+		entries = append(entries, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	entriesJSON, err := json.Marshal(entries)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrInternalServer)
+	}
+	board := `
+		INSERT INTO community_leaderboard (id, type, period, entries, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (type, period) DO UPDATE SET entries = EXCLUDED.entries, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := tx.Exec(board, uuid.New(), leaderboardType, period, entriesJSON, refreshedAt); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row rowScanner, leaderboardType string) (*LeaderboardEntry, error) {
+	entry := &LeaderboardEntry{Unit: units[leaderboardType]}
+	var previousRank sql.NullInt64
+	if err := row.Scan(&entry.Rank, &previousRank, &entry.ID, &entry.Name, &entry.Household, &entry.Value); err != nil {
+		return nil, err
+	}
+	switch {
+	case !previousRank.Valid:
+		entry.Trend = TrendNew
+	case int64(entry.Rank) < previousRank.Int64:
+		entry.Trend = TrendUp
+	case int64(entry.Rank) > previousRank.Int64:
+		entry.Trend = TrendDown
+	default:
+		entry.Trend = TrendSame
+	}
+	return entry, nil
+}
+
 func (r *Repository) GetImpact() (*Impact, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	impact := &Impact{}
-	var weeklyTrendJSON, personalContributionJSON []byte
-	query := `SELECT id, total_surplus_kg, donations, co2_prevented_kg, water_saved_liters, meals_provided, weekly_trend, personal_contribution, updated_at FROM community_impact ORDER BY updated_at DESC LIMIT 1`
-	err := r.db.QueryRow(query).Scan(&impact.ID, &impact.TotalSurplusKg, &impact.Donations, &impact.CO2PreventedKg, &impact.WaterSavedLiters, &impact.MealsProvided, &weeklyTrendJSON, &personalContributionJSON, &impact.UpdatedAt)
+	snapshot := &Impact{}
+	var weeklyTrendJSON, monthlyTrendJSON, categoryBreakdownJSON, personalContributionJSON []byte
+	query := `SELECT id, total_surplus_kg, donations, co2_prevented_kg, water_saved_liters, meals_provided, weekly_trend, monthly_trend, category_breakdown, personal_contribution, updated_at FROM community_impact ORDER BY updated_at DESC LIMIT 1`
+	err := r.db.QueryRow(query).Scan(&snapshot.ID, &snapshot.TotalSurplusKg, &snapshot.Donations, &snapshot.CO2PreventedKg, &snapshot.WaterSavedLiters, &snapshot.MealsProvided, &weeklyTrendJSON, &monthlyTrendJSON, &categoryBreakdownJSON, &personalContributionJSON, &snapshot.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
@@ -52,21 +180,140 @@ func (r *Repository) GetImpact() (*Impact, error) {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
 	if len(weeklyTrendJSON) > 0 {
-		json.Unmarshal(weeklyTrendJSON, &impact.WeeklyTrend)
+		json.Unmarshal(weeklyTrendJSON, &snapshot.WeeklyTrend)
+	}
+	if len(monthlyTrendJSON) > 0 {
+		json.Unmarshal(monthlyTrendJSON, &snapshot.MonthlyTrend)
+	}
+	if len(categoryBreakdownJSON) > 0 {
+		json.Unmarshal(categoryBreakdownJSON, &snapshot.CategoryBreakdown)
 	}
 	if len(personalContributionJSON) > 0 {
-		json.Unmarshal(personalContributionJSON, &impact.PersonalContribution)
+		json.Unmarshal(personalContributionJSON, &snapshot.PersonalContribution)
+	}
+	return snapshot, nil
+}
+
+// SaveImpact replaces the community impact snapshot, creating it if none exists yet
+func (r *Repository) SaveImpact(snapshot *Impact) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	weeklyTrendJSON, _ := json.Marshal(snapshot.WeeklyTrend)
+	monthlyTrendJSON, _ := json.Marshal(snapshot.MonthlyTrend)
+	categoryBreakdownJSON, _ := json.Marshal(snapshot.CategoryBreakdown)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	// Serialise writers so concurrent first snapshots do not create two rows
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('community_impact'))`); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	update := `
+		UPDATE community_impact SET
+			total_surplus_kg = $1,
+			donations = $2,
+			co2_prevented_kg = $3,
+			water_saved_liters = $4,
+			meals_provided = $5,
+			weekly_trend = $6,
+			monthly_trend = $7,
+			category_breakdown = $8
+		WHERE id = (SELECT id FROM community_impact ORDER BY updated_at DESC LIMIT 1)
+		RETURNING id, updated_at
+	`
+	err = tx.QueryRow(update, snapshot.TotalSurplusKg, snapshot.Donations, snapshot.CO2PreventedKg, snapshot.WaterSavedLiters, snapshot.MealsProvided, weeklyTrendJSON, monthlyTrendJSON, categoryBreakdownJSON).Scan(&snapshot.ID, &snapshot.UpdatedAt)
+	if err == sql.ErrNoRows {
+		insert := `
+			INSERT INTO community_impact (id, total_surplus_kg, donations, co2_prevented_kg, water_saved_liters, meals_provided, weekly_trend, monthly_trend, category_breakdown)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, updated_at
+		`
+		err = tx.QueryRow(insert, uuid.New(), snapshot.TotalSurplusKg, snapshot.Donations, snapshot.CO2PreventedKg, snapshot.WaterSavedLiters, snapshot.MealsProvided, weeklyTrendJSON, monthlyTrendJSON, categoryBreakdownJSON).Scan(&snapshot.ID, &snapshot.UpdatedAt)
+	}
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// contributionsQuery lists all rescued food as (source, owner, category,
+// quantity, unit, meals, occurred_at). Approval times are not recorded, so
+// approved surplus requests are dated by their request. NGO deliveries from
+// restaurants are left out because the restaurant's own donation log already
+// counts them.
+const contributionsQuery = `
+	SELECT source, owner_id, category, quantity, unit, meals, occurred_at FROM (
+		SELECT 'surplus_shared' AS source, p.user_id AS owner_id, p.category, p.quantity, p.unit, 0 AS meals, r.created_at AS occurred_at
+		FROM surplus_requests r
+		JOIN community_surplus_posts p ON p.id = r.post_id
+		WHERE r.status = 'approved'
+		UNION ALL
+		SELECT 'leftover_claimed', li.user_id, 'prepared-meals', li.portions, 'portions', li.portions, c.created_at
+		FROM leftover_item_claims c
+		JOIN leftover_items li ON li.id = c.leftover_item_id
+		UNION ALL
+		SELECT 'donation', user_id, category, quantity, unit, COALESCE(meals_provided, 0), date::timestamptz
+		FROM restaurant_donation_logs
+		UNION ALL
+		SELECT 'delivery', ngo_user_id, 'default', weight_kg, 'kg', COALESCE(meals_provided, 0), COALESCE(delivered_at, pickup_time)
+		FROM ngo_donation_history
+		WHERE status IN ('delivered', 'partial') AND donor_type <> 'restaurant'
+	) contributions
+	WHERE $1::uuid IS NULL OR owner_id = $1
+`
+
+// ListContributions returns rescued food grouped by source. A nil ownerID lists
+// the whole community's contributions.
+func (r *Repository) ListContributions(ownerID *uuid.UUID) (map[string][]impact.Contribution, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(contributionsQuery, ownerID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
-	return impact, nil
+	defer rows.Close()
+	contributions := make(map[string][]impact.Contribution)
+	for rows.Next() {
+		var source string
+		var owner uuid.UUID
+		var c impact.Contribution
+		if err := rows.Scan(&source, &owner, &c.Category, &c.Quantity, &c.Unit, &c.Meals, &c.OccurredAt); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		contributions[source] = append(contributions[source], c)
+	}
+	return contributions, rows.Err()
 }
 
-func (r *Repository) GetPersonalImpact(userID uuid.UUID) (*Impact, error) {
-	impact := &Impact{}
-	impact.PersonalContribution = JSONB{
-		"surplus_shared": 0,
-		"leftovers_shared": 0,
-		"volunteer_hours": 0,
-		"meals_provided": 0,
+// CountVolunteerEvents counts the past kitchen events the user volunteered at
+func (r *Repository) CountVolunteerEvents(userID uuid.UUID) (int, error) {
+	if r.db == nil {
+		return 0, errors.ErrDatabase
+	}
+	query := `
+		SELECT COUNT(DISTINCT e.id)
+		FROM community_kitchen_events e
+		CROSS JOIN LATERAL jsonb_array_elements(CASE
+			WHEN jsonb_typeof(e.volunteers) = 'array' THEN e.volunteers
+			WHEN jsonb_typeof(e.volunteers->'volunteers') = 'array' THEN e.volunteers->'volunteers'
+			ELSE '[]'::jsonb
+		END) v
+		WHERE v->>'userId' = $1 AND e.date <= CURRENT_DATE
+	`
+	var count int
+	if err := r.db.QueryRow(query, userID.String()).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
 	}
-	return impact, nil
+	return count, nil
 }
@@ -0,0 +1,83 @@
+package leaderboard
+
+import "fmt"
+
+// sharedItems lists surplus posts and leftover dishes with their owner and creation time
+const sharedItems = `
+	SELECT user_id, created_at FROM community_surplus_posts
+	UNION ALL
+	SELECT user_id, created_at FROM leftover_items
+`
+
+// userSubjects names the users in a (user_id, value) score subquery
+const userSubjects = `
+	SELECT u.id, COALESCE(p.username, u.name), COALESCE(h.name, ''), s.value
+	FROM (%s) s
+	JOIN users u ON u.id = s.user_id
+	LEFT JOIN community_profiles p ON p.user_id = u.id
+	LEFT JOIN households h ON h.id = u.household_id
+`
+
+// scoreQueries compute each leaderboard as (subject_id, name, household, value)
+// rows. $1 is the start of the period, or NULL for all time. Zero-waste only
+// ranks users with at least five logs in the period, so a single log cannot
+// top the board. Building-impact ranks households; users without one count as
+// a household of their own.
+var scoreQueries = map[string]string{
+	TypeTopSharers: withUserNames(`
+		SELECT user_id, COUNT(*) AS value
+		FROM (` + sharedItems + `) shared
+		WHERE $1::timestamptz IS NULL OR created_at >= $1
+		GROUP BY user_id
+	`),
+	TypeZeroWaste: withUserNames(`
+		SELECT user_id, 100.0 * COUNT(*) FILTER (WHERE NOT COALESCE(was_wasted, FALSE)) / COUNT(*) AS value
+		FROM consumption_logs
+		WHERE $1::timestamptz IS NULL OR consumed_at >= $1
+		GROUP BY user_id
+		HAVING COUNT(*) >= 5
+	`),
+	TypeVolunteerStars: withUserNames(`
+		SELECT (v->>'userId')::uuid AS user_id, COUNT(DISTINCT e.id) AS value
+		FROM community_kitchen_events e
+		CROSS JOIN LATERAL jsonb_array_elements(CASE
+			WHEN jsonb_typeof(e.volunteers) = 'array' THEN e.volunteers
+			WHEN jsonb_typeof(e.volunteers->'volunteers') = 'array' THEN e.volunteers->'volunteers'
+			ELSE '[]'::jsonb
+		END) v
+		WHERE v->>'userId' ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$'
+			AND e.date <= CURRENT_DATE
+			AND ($1::timestamptz IS NULL OR e.date >= $1::date)
+		GROUP BY 1
+	`),
+	TypeBuildingImpact: `
+		SELECT COALESCE(u.household_id, u.id), COALESCE(h.name, p.username, u.name), COALESCE(h.name, ''), COUNT(*)
+		FROM (` + sharedItems + `) shared
+		JOIN users u ON u.id = shared.user_id
+		LEFT JOIN households h ON h.id = u.household_id
+		LEFT JOIN community_profiles p ON p.user_id = u.id
+		WHERE $1::timestamptz IS NULL OR shared.created_at >= $1
+		GROUP BY 1, 2, 3
+	`,
+	TypeWeeklyXP: withUserNames(`
+		SELECT user_id, SUM(amount) AS value
+		FROM xp_transactions
+		WHERE $1::timestamptz IS NULL OR created_at >= $1
+		GROUP BY user_id
+		HAVING SUM(amount) > 0
+	`),
+}
+
+// units describes each leaderboard's value
+var units = map[string]string{
+	TypeTopSharers:     "items",
+	TypeZeroWaste:      "%",
+	TypeVolunteerStars: "events",
+	TypeBuildingImpact: "items",
+	TypeWeeklyXP:       "XP",
+}
+
+// withUserNames wraps a (user_id, value) score subquery in userSubjects
+func withUserNames(scores string) string {
+	return fmt.Sprintf(userSubjects, scores)
+}
@@ -1,28 +1,213 @@
 package leaderboard
 
 import (
+	"context"
+	"fmt"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/impact"
+	"log"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// Leaderboards store their top materializedEntries; requests may ask for up to that many
+const (
+	defaultLimit        = 10
+	materializedEntries = 100
+)
+
+// Types and Periods list every leaderboard that is materialized
+var (
+	Types   = []string{TypeTopSharers, TypeZeroWaste, TypeVolunteerStars, TypeBuildingImpact, TypeWeeklyXP}
+	Periods = []string{PeriodDaily, PeriodWeekly, PeriodMonthly, PeriodAllTime}
+)
+
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	impact *impact.Service
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{repo: NewRepository(), impact: impact.NewService()}
 }
 
-func (s *Service) GetLeaderboard(leaderboardType string) (*Leaderboard, error) {
+// GetLeaderboard returns the top limit entries of a leaderboard together with
+// the caller's own entry. Period defaults to weekly for weekly-xp and all-time
+// otherwise. A board that has never been materialized is computed on demand.
+func (s *Service) GetLeaderboard(leaderboardType, period string, limit int, userID uuid.UUID, householdID *uuid.UUID) (*Leaderboard, error) {
 	if leaderboardType == "" {
-		leaderboardType = "top-sharers"
+		leaderboardType = TypeTopSharers
+	}
+	if !contains(Types, leaderboardType) {
+		return nil, errors.NewAppError(errors.ErrBadRequest.Code, "Invalid leaderboard type")
+	}
+	if period == "" {
+		period = PeriodAllTime
+		if leaderboardType == TypeWeeklyXP {
+			period = PeriodWeekly
+		}
+	}
+	if !contains(Periods, period) {
+		return nil, errors.NewAppError(errors.ErrBadRequest.Code, "Invalid leaderboard period")
+	}
+	if limit <= 0 || limit > materializedEntries {
+		limit = defaultLimit
+	}
+
+	lb, err := s.repo.GetBoard(leaderboardType, period)
+	if err == errors.ErrNotFound {
+		if err := s.Refresh(leaderboardType, period); err != nil {
+			return nil, err
+		}
+		lb, err = s.repo.GetBoard(leaderboardType, period)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(lb.Entries) > limit {
+		lb.Entries = lb.Entries[:limit]
+	}
+
+	subjectID := userID
+	if leaderboardType == TypeBuildingImpact && householdID != nil {
+		subjectID = *householdID
+	}
+	me, err := s.repo.GetRank(leaderboardType, period, subjectID)
+	if err != nil && err != errors.ErrNotFound {
+		return nil, err
+	}
+	lb.Me = me
+	return lb, nil
+}
+
+// Refresh recomputes one leaderboard
+func (s *Service) Refresh(leaderboardType, period string) error {
+	return s.repo.Refresh(leaderboardType, period, periodStart(period, time.Now()), materializedEntries)
+}
+
+// RefreshAll recomputes every leaderboard, continuing past failures
+func (s *Service) RefreshAll(ctx context.Context) error {
+	var failed int
+	for _, leaderboardType := range Types {
+		for _, period := range Periods {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.Refresh(leaderboardType, period); err != nil {
+				log.Printf("Warning: failed to refresh %s %s leaderboard: %v", period, leaderboardType, err)
+				failed++
+			}
+		}
 	}
-	return s.repo.GetByType(leaderboardType)
+	if failed > 0 {
+		return fmt.Errorf("%d leaderboards failed to refresh", failed)
+	}
+	return nil
 }
 
+// GetImpact returns the community impact snapshot, computing it if none exists yet
 func (s *Service) GetImpact() (*Impact, error) {
-	return s.repo.GetImpact()
+	snapshot, err := s.repo.GetImpact()
+	if err == errors.ErrNotFound {
+		return s.RecomputeImpact()
+	}
+	return snapshot, err
 }
 
+// RecomputeImpact rebuilds the community impact snapshot from every contribution
+func (s *Service) RecomputeImpact() (*Impact, error) {
+	contributions, err := s.repo.ListContributions(nil)
+	if err != nil {
+		return nil, err
+	}
+	summary := s.impact.Summarize(flatten(contributions), time.Now())
+	snapshot := &Impact{
+		TotalSurplusKg:    summary.RescuedKg,
+		Donations:         summary.Contributions,
+		CO2PreventedKg:    summary.CO2Kg,
+		WaterSavedLiters:  summary.WaterLiters,
+		MealsProvided:     summary.Meals,
+		WeeklyTrend:       summary.WeeklyTrend,
+		MonthlyTrend:      summary.MonthlyTrend,
+		CategoryBreakdown: summary.Categories,
+	}
+	if err := s.repo.SaveImpact(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetPersonalImpact computes the user's own contribution to the community impact
 func (s *Service) GetPersonalImpact(userID uuid.UUID) (*Impact, error) {
-	return s.repo.GetPersonalImpact(userID)
+	contributions, err := s.repo.ListContributions(&userID)
+	if err != nil {
+		return nil, err
+	}
+	volunteerEvents, err := s.repo.CountVolunteerEvents(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	summary := s.impact.Summarize(flatten(contributions), now)
+	surplus := s.impact.Summarize(contributions[SourceSurplusShared], now)
+	leftoverPortions := 0
+	for _, c := range contributions[SourceLeftoverClaimed] {
+		leftoverPortions += c.Meals
+	}
+
+	return &Impact{
+		TotalSurplusKg:    summary.RescuedKg,
+		Donations:         summary.Contributions,
+		CO2PreventedKg:    summary.CO2Kg,
+		WaterSavedLiters:  summary.WaterLiters,
+		MealsProvided:     summary.Meals,
+		WeeklyTrend:       summary.WeeklyTrend,
+		MonthlyTrend:      summary.MonthlyTrend,
+		CategoryBreakdown: summary.Categories,
+		PersonalContribution: JSONB{
+			"surplus_shared_kg": surplus.RescuedKg,
+			"leftovers_shared":  leftoverPortions,
+			"donations":         len(contributions[SourceDonation]) + len(contributions[SourceDelivery]),
+			"volunteer_events":  volunteerEvents,
+			"meals_provided":    summary.Meals,
+		},
+		UpdatedAt: now,
+	}, nil
+}
+
+// periodStart returns the start of the calendar period containing now, or nil for all time.
+// Weeks start on Monday.
+func periodStart(period string, now time.Time) *time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var start time.Time
+	switch period {
+	case PeriodDaily:
+		start = today
+	case PeriodWeekly:
+		start = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	case PeriodMonthly:
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		return nil
+	}
+	return &start
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func flatten(bySource map[string][]impact.Contribution) []impact.Contribution {
+	var all []impact.Contribution
+	for _, contributions := range bySource {
+		all = append(all, contributions...)
+	}
+	return all
 }
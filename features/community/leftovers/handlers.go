@@ -29,22 +29,30 @@ func (h *Handler) getUserID(r *http.Request) (uuid.UUID, string, error) {
 
 // GetAll handles GET /api/v1/community/leftovers
 // @Summary      List leftover items
-// @Description  Get all leftover items, optionally filtered by status
+// @Description  Get all leftover items, optionally filtered by status. With a location each item carries its distance and the list is sorted nearest first.
 // @Tags         community-leftovers
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        status  query     string  false  "Filter by status (available, claimed)"
-// @Success      200     {array}   LeftoverItem
-// @Failure      401     {object}  errors.AppError
+// @Param        status     query     string  false  "Filter by status (available, claimed)"
+// @Param        near       query     string  false  "Search around lat,lng; defaults to the caller's home location"
+// @Param        radius_km  query     number  false  "Only items within this many km, nearest first"
+// @Success      200        {array}   LeftoverItem
+// @Failure      400        {object}  errors.AppError
+// @Failure      401        {object}  errors.AppError
 // @Router       /community/leftovers [get]
 func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
+	geo, err := auth.NearbyQuery(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid location query", err.Error())
+		return
+	}
 	status := r.URL.Query().Get("status")
-	items, err := h.service.GetAll(status)
+	items, err := h.service.GetAll(status, geo)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
@@ -73,7 +81,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/community/leftovers/")
+	idStr := strings.Trim(r.URL.Path, "/")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
@@ -155,7 +163,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/community/leftovers/")
+	idStr := strings.Trim(r.URL.Path, "/")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
@@ -201,7 +209,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/community/leftovers/")
+	idStr := strings.Trim(r.URL.Path, "/")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
@@ -241,7 +249,7 @@ func (h *Handler) CreateClaim(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/community/leftovers/"), "/")
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
 	if len(pathParts) < 2 || pathParts[1] != "claim" {
 		utils.BadRequestResponse(w, "Invalid path", nil)
 		return
@@ -284,7 +292,7 @@ func (h *Handler) GetClaims(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/community/leftovers/"), "/")
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
 	if len(pathParts) < 2 || pathParts[1] != "claims" {
 		utils.BadRequestResponse(w, "Invalid path", nil)
 		return
@@ -14,7 +14,9 @@ type LeftoverItem struct {
 	DishName     string    `json:"dish_name" db:"dish_name"`
 	Description  string    `json:"description" db:"description"`
 	Portions     int       `json:"portions" db:"portions"`
-	DistanceKm   float64   `json:"distance_km" db:"distance_km"`
+	Latitude     *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude    *float64  `json:"longitude,omitempty" db:"longitude"`
+	DistanceKm   *float64  `json:"distance_km,omitempty"`
 	DietaryTags  []string  `json:"dietary_tags,omitempty" db:"dietary_tags"`
 	Allergens    []string  `json:"allergens,omitempty" db:"allergens"`
 	PickupWindow string    `json:"pickup_window" db:"pickup_window"`
@@ -37,10 +39,12 @@ type CreateLeftoverItemRequest struct {
 	DishName     string   `json:"dish_name" validate:"required,min=1,max=255"`
 	Description  string   `json:"description" validate:"required,min=1"`
 	Portions     int      `json:"portions" validate:"required,gt=0"`
-	DistanceKm   float64  `json:"distance_km" validate:"required,gt=0"`
+	Latitude     *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude    *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 	DietaryTags  []string `json:"dietary_tags,omitempty"`
 	Allergens    []string `json:"allergens,omitempty"`
 	PickupWindow string   `json:"pickup_window" validate:"required,min=1"`
+	// An image URL, or the ID of an upload from POST /uploads
 	Image        string   `json:"image,omitempty"`
 }
 
@@ -48,7 +52,8 @@ type UpdateLeftoverItemRequest struct {
 	DishName     string   `json:"dish_name,omitempty" validate:"omitempty,min=1,max=255"`
 	Description  string   `json:"description,omitempty" validate:"omitempty,min=1"`
 	Portions     *int     `json:"portions,omitempty" validate:"omitempty,gt=0"`
-	DistanceKm   *float64 `json:"distance_km,omitempty" validate:"omitempty,gt=0"`
+	Latitude     *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude    *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 	DietaryTags  []string `json:"dietary_tags,omitempty"`
 	Allergens    []string `json:"allergens,omitempty"`
 	PickupWindow string   `json:"pickup_window,omitempty" validate:"omitempty,min=1"`
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"foodlink_backend/database"
 	"foodlink_backend/errors"
+	"foodlink_backend/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,28 +19,28 @@ func NewRepository() *Repository {
 	return &Repository{db: database.GetDB()}
 }
 
-func (r *Repository) GetAll(status string) ([]*LeftoverItem, error) {
+// leftoverColumns lists the columns scanned by scanLeftover
+const leftoverColumns = `id, user_id, user_name, COALESCE(avatar_url, ''), dish_name, description, portions, latitude, longitude, dietary_tags, allergens, pickup_window, status, COALESCE(image, ''), created_at, updated_at`
+
+// GetAll lists leftover items, newest first. A non-nil box limits them to
+// items located inside it.
+func (r *Repository) GetAll(status string, box *utils.GeoBox) ([]*LeftoverItem, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	var query string
-	var rows *sql.Rows
-	var err error
-	if status != "" {
-		query = `SELECT id, user_id, user_name, avatar_url, dish_name, description, portions, distance_km, dietary_tags, allergens, pickup_window, status, image, created_at, updated_at FROM leftover_items WHERE status = $1 ORDER BY created_at DESC`
-		rows, err = r.db.Query(query, status)
-	} else {
-		query = `SELECT id, user_id, user_name, avatar_url, dish_name, description, portions, distance_km, dietary_tags, allergens, pickup_window, status, image, created_at, updated_at FROM leftover_items ORDER BY created_at DESC`
-		rows, err = r.db.Query(query)
-	}
+	query := `SELECT ` + leftoverColumns + ` FROM leftover_items
+		WHERE ($1 = '' OR status = $1)
+			AND ($2::float8 IS NULL OR (latitude BETWEEN $2 AND $3 AND longitude BETWEEN $4 AND $5))
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(query, append([]interface{}{status}, box.Args()...)...)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
 	defer rows.Close()
 	var items []*LeftoverItem
 	for rows.Next() {
-		item := &LeftoverItem{}
-		if err := rows.Scan(&item.ID, &item.UserID, &item.UserName, &item.AvatarURL, &item.DishName, &item.Description, &item.Portions, &item.DistanceKm, pq.Array(&item.DietaryTags), pq.Array(&item.Allergens), &item.PickupWindow, &item.Status, &item.Image, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		item, err := scanLeftover(rows)
+		if err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
 		items = append(items, item)
@@ -51,9 +52,8 @@ func (r *Repository) GetByID(id uuid.UUID) (*LeftoverItem, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	item := &LeftoverItem{}
-	query := `SELECT id, user_id, user_name, avatar_url, dish_name, description, portions, distance_km, dietary_tags, allergens, pickup_window, status, image, created_at, updated_at FROM leftover_items WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.UserID, &item.UserName, &item.AvatarURL, &item.DishName, &item.Description, &item.Portions, &item.DistanceKm, pq.Array(&item.DietaryTags), pq.Array(&item.Allergens), &item.PickupWindow, &item.Status, &item.Image, &item.CreatedAt, &item.UpdatedAt)
+	query := `SELECT ` + leftoverColumns + ` FROM leftover_items WHERE id = $1`
+	item, err := scanLeftover(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
@@ -67,17 +67,30 @@ func (r *Repository) Create(item *LeftoverItem) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `INSERT INTO leftover_items (id, user_id, user_name, avatar_url, dish_name, description, portions, distance_km, dietary_tags, allergens, pickup_window, status, image, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, user_id, user_name, avatar_url, dish_name, description, portions, distance_km, dietary_tags, allergens, pickup_window, status, image, created_at, updated_at`
+	query := `INSERT INTO leftover_items (id, user_id, user_name, avatar_url, dish_name, description, portions, latitude, longitude, dietary_tags, allergens, pickup_window, status, image, created_at, updated_at) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''), $15, $16) RETURNING ` + leftoverColumns
 	now := time.Now()
-	return r.db.QueryRow(query, item.ID, item.UserID, item.UserName, item.AvatarURL, item.DishName, item.Description, item.Portions, item.DistanceKm, pq.Array(item.DietaryTags), pq.Array(item.Allergens), item.PickupWindow, item.Status, item.Image, now, now).Scan(&item.ID, &item.UserID, &item.UserName, &item.AvatarURL, &item.DishName, &item.Description, &item.Portions, &item.DistanceKm, pq.Array(&item.DietaryTags), pq.Array(&item.Allergens), &item.PickupWindow, &item.Status, &item.Image, &item.CreatedAt, &item.UpdatedAt)
+	created, err := scanLeftover(r.db.QueryRow(query, item.ID, item.UserID, item.UserName, item.AvatarURL, item.DishName, item.Description, item.Portions, item.Latitude, item.Longitude, pq.Array(item.DietaryTags), pq.Array(item.Allergens), item.PickupWindow, item.Status, item.Image, now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*item = *created
+	return nil
 }
 
 func (r *Repository) Update(item *LeftoverItem) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `UPDATE leftover_items SET dish_name=$1, description=$2, portions=$3, distance_km=$4, dietary_tags=$5, allergens=$6, pickup_window=$7, status=$8, image=$9, updated_at=$10 WHERE id=$11 RETURNING id, user_id, user_name, avatar_url, dish_name, description, portions, distance_km, dietary_tags, allergens, pickup_window, status, image, created_at, updated_at`
-	return r.db.QueryRow(query, item.DishName, item.Description, item.Portions, item.DistanceKm, pq.Array(item.DietaryTags), pq.Array(item.Allergens), item.PickupWindow, item.Status, item.Image, time.Now(), item.ID).Scan(&item.ID, &item.UserID, &item.UserName, &item.AvatarURL, &item.DishName, &item.Description, &item.Portions, &item.DistanceKm, pq.Array(&item.DietaryTags), pq.Array(&item.Allergens), &item.PickupWindow, &item.Status, &item.Image, &item.CreatedAt, &item.UpdatedAt)
+	query := `UPDATE leftover_items SET dish_name=$1, description=$2, portions=$3, latitude=$4, longitude=$5, dietary_tags=$6, allergens=$7, pickup_window=$8, status=$9, image=NULLIF($10, ''), updated_at=$11 WHERE id=$12 RETURNING ` + leftoverColumns
+	updated, err := scanLeftover(r.db.QueryRow(query, item.DishName, item.Description, item.Portions, item.Latitude, item.Longitude, pq.Array(item.DietaryTags), pq.Array(item.Allergens), item.PickupWindow, item.Status, item.Image, time.Now(), item.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*item = *updated
+	return nil
 }
 
 func (r *Repository) Delete(id uuid.UUID) error {
@@ -123,3 +136,15 @@ func (r *Repository) GetClaimsByLeftoverID(leftoverID uuid.UUID) ([]*LeftoverCla
 	}
 	return claims, nil
 }
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLeftover(row rowScanner) (*LeftoverItem, error) {
+	item := &LeftoverItem{}
+	if err := row.Scan(&item.ID, &item.UserID, &item.UserName, &item.AvatarURL, &item.DishName, &item.Description, &item.Portions, &item.Latitude, &item.Longitude, pq.Array(&item.DietaryTags), pq.Array(&item.Allergens), &item.PickupWindow, &item.Status, &item.Image, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
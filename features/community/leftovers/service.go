@@ -2,21 +2,33 @@ package leftovers
 
 import (
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/uploads"
 	"foodlink_backend/utils"
 
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	images *uploads.Linker
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{repo: NewRepository(), images: uploads.NewLinker()}
 }
 
-func (s *Service) GetAll(status string) ([]*LeftoverItem, error) {
-	return s.repo.GetAll(status)
+// GetAll lists leftover items. With a geo query each item carries its distance
+// from the search point and the list is sorted nearest first.
+func (s *Service) GetAll(status string, geo *utils.GeoQuery) ([]*LeftoverItem, error) {
+	items, err := s.repo.GetAll(status, geo.Box())
+	if err != nil {
+		return nil, err
+	}
+	return utils.NearestFirst(geo, items,
+		func(item *LeftoverItem) (lat, lng *float64) { return item.Latitude, item.Longitude },
+		func(item *LeftoverItem, d *float64) { item.DistanceKm = d },
+	), nil
 }
 
 func (s *Service) GetByID(id uuid.UUID) (*LeftoverItem, error) {
@@ -34,16 +46,25 @@ func (s *Service) Create(userID uuid.UUID, userName string, req *CreateLeftoverI
 		DishName:     req.DishName,
 		Description:  req.Description,
 		Portions:     req.Portions,
-		DistanceKm:   req.DistanceKm,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
 		DietaryTags:  req.DietaryTags,
 		Allergens:    req.Allergens,
 		PickupWindow: req.PickupWindow,
 		Status:       "available",
 		Image:        req.Image,
 	}
+	image, err := s.images.Resolve(userID, item.Image, uploads.AssociatedLeftover, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	item.Image = image
 	if err := s.repo.Create(item); err != nil {
 		return nil, err
 	}
+	if err := s.images.Sync(item.Image, uploads.AssociatedLeftover, item.ID); err != nil {
+		return nil, err
+	}
 	return item, nil
 }
 
@@ -67,8 +88,8 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateLeftoverItem
 	if req.Portions != nil {
 		item.Portions = *req.Portions
 	}
-	if req.DistanceKm != nil {
-		item.DistanceKm = *req.DistanceKm
+	if req.Latitude != nil && req.Longitude != nil {
+		item.Latitude, item.Longitude = req.Latitude, req.Longitude
 	}
 	if req.DietaryTags != nil {
 		item.DietaryTags = req.DietaryTags
@@ -83,11 +104,18 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateLeftoverItem
 		item.Status = req.Status
 	}
 	if req.Image != "" {
-		item.Image = req.Image
+		if item.Image, err = s.images.Resolve(userID, req.Image, uploads.AssociatedLeftover, item.ID); err != nil {
+			return nil, err
+		}
 	}
 	if err := s.repo.Update(item); err != nil {
 		return nil, err
 	}
+	if req.Image != "" {
+		if err := s.images.Sync(item.Image, uploads.AssociatedLeftover, item.ID); err != nil {
+			return nil, err
+		}
+	}
 	return item, nil
 }
 
@@ -99,11 +127,14 @@ func (s *Service) Delete(id uuid.UUID, userID uuid.UUID) error {
 	if item.UserID != userID {
 		return errors.ErrForbidden
 	}
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	return s.images.Release(uploads.AssociatedLeftover, id)
 }
 
 func (s *Service) CreateClaim(leftoverID uuid.UUID, userID uuid.UUID, userName string, req *CreateLeftoverClaimRequest) (*LeftoverClaim, error) {
-	_, err := s.repo.GetByID(leftoverID)
+	item, err := s.repo.GetByID(leftoverID)
 	if err != nil {
 		return nil, err
 	}
@@ -117,6 +148,16 @@ func (s *Service) CreateClaim(leftoverID uuid.UUID, userID uuid.UUID, userName s
 	if err := s.repo.CreateClaim(claim); err != nil {
 		return nil, err
 	}
+	events.Publish(events.LeftoverClaimed{
+		Meta:       events.NewMeta(),
+		OwnerID:     item.UserID,
+		ClaimerID:   claim.UserID,
+		ClaimerName: claim.UserName,
+		LeftoverID:  item.ID,
+		DishName:    item.DishName,
+		ClaimID:     claim.ID,
+		Portions:    item.Portions,
+	})
 	return claim, nil
 }
 
@@ -42,7 +42,9 @@ type SurplusPost struct {
 	Unit         string    `json:"unit" db:"unit"`
 	PickupWindow JSONB     `json:"pickup_window" db:"pickup_window"`
 	PickupLocation string  `json:"pickup_location" db:"pickup_location"`
-	DistanceKm   *float64  `json:"distance_km,omitempty" db:"distance_km"`
+	Latitude     *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude    *float64  `json:"longitude,omitempty" db:"longitude"`
+	DistanceKm   *float64  `json:"distance_km,omitempty"`
 	Image        string    `json:"image,omitempty" db:"image"`
 	Status       string    `json:"status" db:"status"`
 	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
@@ -78,7 +80,9 @@ type CreateSurplusPostRequest struct {
 	Unit          string                 `json:"unit" validate:"required,min=1,max=50"`
 	PickupWindow  map[string]interface{} `json:"pickup_window" validate:"required"`
 	PickupLocation string                `json:"pickup_location" validate:"required,min=1"`
-	DistanceKm    *float64               `json:"distance_km,omitempty"`
+	Latitude      *float64               `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude     *float64               `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
+	// An image URL, or the ID of an upload from POST /uploads
 	Image         string                 `json:"image,omitempty"`
 	ExpiresAt     time.Time              `json:"expires_at" validate:"required"`
 }
@@ -92,6 +96,8 @@ type UpdateSurplusPostRequest struct {
 	Unit          string                 `json:"unit,omitempty" validate:"omitempty,min=1,max=50"`
 	PickupWindow  map[string]interface{} `json:"pickup_window,omitempty"`
 	PickupLocation string                `json:"pickup_location,omitempty" validate:"omitempty,min=1"`
+	Latitude      *float64               `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude     *float64               `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 	Status        string                 `json:"status,omitempty"`
 	Image         string                 `json:"image,omitempty"`
 }
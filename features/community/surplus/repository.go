@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"foodlink_backend/database"
 	"foodlink_backend/errors"
+	"foodlink_backend/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,34 +20,30 @@ func NewRepository() *Repository {
 	return &Repository{db: database.GetDB()}
 }
 
-func (r *Repository) GetAll(status string) ([]*SurplusPost, error) {
+// postColumns lists the columns scanned by scanPost
+const postColumns = `id, user_id, user_name, COALESCE(avatar_url, ''), title, description, category, tags, quantity, unit, pickup_window, pickup_location, latitude, longitude, COALESCE(image, ''), status, expires_at, created_at, updated_at`
+
+// GetAll lists surplus posts, newest first. A non-nil box limits them to
+// posts located inside it.
+func (r *Repository) GetAll(status string, box *utils.GeoBox) ([]*SurplusPost, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	var query string
-	var rows *sql.Rows
-	var err error
-	if status != "" {
-		query = `SELECT id, user_id, user_name, avatar_url, title, description, category, tags, quantity, unit, pickup_window, pickup_location, distance_km, image, status, expires_at, created_at, updated_at FROM community_surplus_posts WHERE status = $1 ORDER BY created_at DESC`
-		rows, err = r.db.Query(query, status)
-	} else {
-		query = `SELECT id, user_id, user_name, avatar_url, title, description, category, tags, quantity, unit, pickup_window, pickup_location, distance_km, image, status, expires_at, created_at, updated_at FROM community_surplus_posts ORDER BY created_at DESC`
-		rows, err = r.db.Query(query)
-	}
+	query := `SELECT ` + postColumns + ` FROM community_surplus_posts
+		WHERE ($1 = '' OR status = $1)
+			AND ($2::float8 IS NULL OR (latitude BETWEEN $2 AND $3 AND longitude BETWEEN $4 AND $5))
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(query, append([]interface{}{status}, box.Args()...)...)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
 	defer rows.Close()
 	var posts []*SurplusPost
 	for rows.Next() {
-		post := &SurplusPost{}
-		var pickupWindowJSON []byte
-		if err := rows.Scan(&post.ID, &post.UserID, &post.UserName, &post.AvatarURL, &post.Title, &post.Description, &post.Category, pq.Array(&post.Tags), &post.Quantity, &post.Unit, &pickupWindowJSON, &post.PickupLocation, &post.DistanceKm, &post.Image, &post.Status, &post.ExpiresAt, &post.CreatedAt, &post.UpdatedAt); err != nil {
+		post, err := scanPost(rows)
+		if err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
-		if len(pickupWindowJSON) > 0 {
-			json.Unmarshal(pickupWindowJSON, &post.PickupWindow)
-		}
 		posts = append(posts, post)
 	}
 	return posts, nil
@@ -56,19 +53,14 @@ func (r *Repository) GetByID(id uuid.UUID) (*SurplusPost, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	post := &SurplusPost{}
-	var pickupWindowJSON []byte
-	query := `SELECT id, user_id, user_name, avatar_url, title, description, category, tags, quantity, unit, pickup_window, pickup_location, distance_km, image, status, expires_at, created_at, updated_at FROM community_surplus_posts WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&post.ID, &post.UserID, &post.UserName, &post.AvatarURL, &post.Title, &post.Description, &post.Category, pq.Array(&post.Tags), &post.Quantity, &post.Unit, &pickupWindowJSON, &post.PickupLocation, &post.DistanceKm, &post.Image, &post.Status, &post.ExpiresAt, &post.CreatedAt, &post.UpdatedAt)
+	query := `SELECT ` + postColumns + ` FROM community_surplus_posts WHERE id = $1`
+	post, err := scanPost(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
 		}
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(pickupWindowJSON) > 0 {
-		json.Unmarshal(pickupWindowJSON, &post.PickupWindow)
-	}
 	return post, nil
 }
 
@@ -77,16 +69,13 @@ func (r *Repository) Create(post *SurplusPost) error {
 		return errors.ErrDatabase
 	}
 	pickupWindowJSON, _ := json.Marshal(post.PickupWindow)
-	query := `INSERT INTO community_surplus_posts (id, user_id, user_name, avatar_url, title, description, category, tags, quantity, unit, pickup_window, pickup_location, distance_km, image, status, expires_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING id, user_id, user_name, avatar_url, title, description, category, tags, quantity, unit, pickup_window, pickup_location, distance_km, image, status, expires_at, created_at, updated_at`
+	query := `INSERT INTO community_surplus_posts (id, user_id, user_name, avatar_url, title, description, category, tags, quantity, unit, pickup_window, pickup_location, latitude, longitude, image, status, expires_at, created_at, updated_at) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), $16, $17, $18, $19) RETURNING ` + postColumns
 	now := time.Now()
-	var pickupWindowJSONOut []byte
-	err := r.db.QueryRow(query, post.ID, post.UserID, post.UserName, post.AvatarURL, post.Title, post.Description, post.Category, pq.Array(post.Tags), post.Quantity, post.Unit, pickupWindowJSON, post.PickupLocation, post.DistanceKm, post.Image, post.Status, post.ExpiresAt, now, now).Scan(&post.ID, &post.UserID, &post.UserName, &post.AvatarURL, &post.Title, &post.Description, &post.Category, pq.Array(&post.Tags), &post.Quantity, &post.Unit, &pickupWindowJSONOut, &post.PickupLocation, &post.DistanceKm, &post.Image, &post.Status, &post.ExpiresAt, &post.CreatedAt, &post.UpdatedAt)
+	created, err := scanPost(r.db.QueryRow(query, post.ID, post.UserID, post.UserName, post.AvatarURL, post.Title, post.Description, post.Category, pq.Array(post.Tags), post.Quantity, post.Unit, pickupWindowJSON, post.PickupLocation, post.Latitude, post.Longitude, post.Image, post.Status, post.ExpiresAt, now, now))
 	if err != nil {
 		return errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(pickupWindowJSONOut) > 0 {
-		json.Unmarshal(pickupWindowJSONOut, &post.PickupWindow)
-	}
+	*post = *created
 	return nil
 }
 
@@ -95,18 +84,15 @@ func (r *Repository) Update(post *SurplusPost) error {
 		return errors.ErrDatabase
 	}
 	pickupWindowJSON, _ := json.Marshal(post.PickupWindow)
-	query := `UPDATE community_surplus_posts SET title=$1, description=$2, category=$3, tags=$4, quantity=$5, unit=$6, pickup_window=$7, pickup_location=$8, distance_km=$9, image=$10, status=$11, updated_at=$12 WHERE id=$13 RETURNING id, user_id, user_name, avatar_url, title, description, category, tags, quantity, unit, pickup_window, pickup_location, distance_km, image, status, expires_at, created_at, updated_at`
-	var pickupWindowJSONOut []byte
-	err := r.db.QueryRow(query, post.Title, post.Description, post.Category, pq.Array(post.Tags), post.Quantity, post.Unit, pickupWindowJSON, post.PickupLocation, post.DistanceKm, post.Image, post.Status, time.Now(), post.ID).Scan(&post.ID, &post.UserID, &post.UserName, &post.AvatarURL, &post.Title, &post.Description, &post.Category, pq.Array(&post.Tags), &post.Quantity, &post.Unit, &pickupWindowJSONOut, &post.PickupLocation, &post.DistanceKm, &post.Image, &post.Status, &post.ExpiresAt, &post.CreatedAt, &post.UpdatedAt)
+	query := `UPDATE community_surplus_posts SET title=$1, description=$2, category=$3, tags=$4, quantity=$5, unit=$6, pickup_window=$7, pickup_location=$8, latitude=$9, longitude=$10, image=NULLIF($11, ''), status=$12, updated_at=$13 WHERE id=$14 RETURNING ` + postColumns
+	updated, err := scanPost(r.db.QueryRow(query, post.Title, post.Description, post.Category, pq.Array(post.Tags), post.Quantity, post.Unit, pickupWindowJSON, post.PickupLocation, post.Latitude, post.Longitude, post.Image, post.Status, time.Now(), post.ID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return errors.ErrNotFound
 		}
 		return errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(pickupWindowJSONOut) > 0 {
-		json.Unmarshal(pickupWindowJSONOut, &post.PickupWindow)
-	}
+	*post = *updated
 	return nil
 }
 
@@ -206,3 +192,19 @@ func (r *Repository) GetCommentsByPostID(postID uuid.UUID) ([]*SurplusComment, e
 	}
 	return comments, nil
 }
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPost(row rowScanner) (*SurplusPost, error) {
+	post := &SurplusPost{}
+	var pickupWindowJSON []byte
+	if err := row.Scan(&post.ID, &post.UserID, &post.UserName, &post.AvatarURL, &post.Title, &post.Description, &post.Category, pq.Array(&post.Tags), &post.Quantity, &post.Unit, &pickupWindowJSON, &post.PickupLocation, &post.Latitude, &post.Longitude, &post.Image, &post.Status, &post.ExpiresAt, &post.CreatedAt, &post.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(pickupWindowJSON) > 0 {
+		json.Unmarshal(pickupWindowJSON, &post.PickupWindow)
+	}
+	return post, nil
+}
@@ -9,9 +9,9 @@ import (
 func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.TrimPrefix(r.URL.Path, "/api/v1/community/surplus/")
+		path := strings.Trim(r.URL.Path, "/")
 		pathParts := strings.Split(path, "/")
-		
+
 		switch {
 		case path == "" && r.Method == http.MethodGet:
 			handler.GetAll(w, r)
@@ -2,21 +2,33 @@ package surplus
 
 import (
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/uploads"
 	"foodlink_backend/utils"
 
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	images *uploads.Linker
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{repo: NewRepository(), images: uploads.NewLinker()}
 }
 
-func (s *Service) GetAll(status string) ([]*SurplusPost, error) {
-	return s.repo.GetAll(status)
+// GetAll lists surplus posts. With a geo query each post carries its distance
+// from the search point and the list is sorted nearest first.
+func (s *Service) GetAll(status string, geo *utils.GeoQuery) ([]*SurplusPost, error) {
+	posts, err := s.repo.GetAll(status, geo.Box())
+	if err != nil {
+		return nil, err
+	}
+	return utils.NearestFirst(geo, posts,
+		func(post *SurplusPost) (lat, lng *float64) { return post.Latitude, post.Longitude },
+		func(post *SurplusPost, d *float64) { post.DistanceKm = d },
+	), nil
 }
 
 func (s *Service) GetByID(id uuid.UUID) (*SurplusPost, error) {
@@ -40,14 +52,31 @@ func (s *Service) Create(userID uuid.UUID, userName string, avatarURL string, re
 		Unit:          req.Unit,
 		PickupWindow:  JSONB(req.PickupWindow),
 		PickupLocation: req.PickupLocation,
-		DistanceKm:    req.DistanceKm,
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
 		Image:         req.Image,
 		Status:        "available",
 		ExpiresAt:     req.ExpiresAt,
 	}
+	image, err := s.images.Resolve(userID, post.Image, uploads.AssociatedSurplusPost, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	post.Image = image
 	if err := s.repo.Create(post); err != nil {
 		return nil, err
 	}
+	if err := s.images.Sync(post.Image, uploads.AssociatedSurplusPost, post.ID); err != nil {
+		return nil, err
+	}
+	events.Publish(events.SurplusPostCreated{
+		Meta:     events.NewMeta(),
+		UserID:   post.UserID,
+		PostID:   post.ID,
+		Category: post.Category,
+		Quantity: post.Quantity,
+		Unit:     post.Unit,
+	})
 	return post, nil
 }
 
@@ -86,15 +115,25 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateSurplusPostR
 	if req.PickupLocation != "" {
 		post.PickupLocation = req.PickupLocation
 	}
+	if req.Latitude != nil && req.Longitude != nil {
+		post.Latitude, post.Longitude = req.Latitude, req.Longitude
+	}
 	if req.Status != "" {
 		post.Status = req.Status
 	}
 	if req.Image != "" {
-		post.Image = req.Image
+		if post.Image, err = s.images.Resolve(userID, req.Image, uploads.AssociatedSurplusPost, post.ID); err != nil {
+			return nil, err
+		}
 	}
 	if err := s.repo.Update(post); err != nil {
 		return nil, err
 	}
+	if req.Image != "" {
+		if err := s.images.Sync(post.Image, uploads.AssociatedSurplusPost, post.ID); err != nil {
+			return nil, err
+		}
+	}
 	return post, nil
 }
 
@@ -106,11 +145,14 @@ func (s *Service) Delete(id uuid.UUID, userID uuid.UUID) error {
 	if post.UserID != userID {
 		return errors.ErrForbidden
 	}
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	return s.images.Release(uploads.AssociatedSurplusPost, id)
 }
 
 func (s *Service) CreateRequest(postID uuid.UUID, userID uuid.UUID, userName string, req *CreateSurplusRequestRequest) (*SurplusRequest, error) {
-	_, err := s.repo.GetByID(postID)
+	post, err := s.repo.GetByID(postID)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +167,15 @@ func (s *Service) CreateRequest(postID uuid.UUID, userID uuid.UUID, userName str
 	if err := s.repo.CreateRequest(request); err != nil {
 		return nil, err
 	}
+	events.Publish(events.SurplusRequestCreated{
+		Meta:          events.NewMeta(),
+		OwnerID:       post.UserID,
+		RequesterID:   request.UserID,
+		RequesterName: request.UserName,
+		PostID:        post.ID,
+		PostTitle:     post.Title,
+		RequestID:     request.ID,
+	})
 	return request, nil
 }
 
@@ -154,10 +205,23 @@ func (s *Service) UpdateRequest(requestID uuid.UUID, postID uuid.UUID, userID uu
 	if request.PostID != postID {
 		return nil, errors.ErrNotFound
 	}
+	wasApproved := request.Status == "approved"
 	request.Status = req.Status
 	if err := s.repo.UpdateRequest(request); err != nil {
 		return nil, err
 	}
+	if request.Status == "approved" && !wasApproved {
+		events.Publish(events.SurplusRequestApproved{
+			Meta:        events.NewMeta(),
+			OwnerID:     post.UserID,
+			RequesterID: request.UserID,
+			PostID:      post.ID,
+			RequestID:   request.ID,
+			Category:    post.Category,
+			Quantity:    post.Quantity,
+			Unit:        post.Unit,
+		})
+	}
 	return request, nil
 }
 
@@ -165,7 +229,7 @@ func (s *Service) CreateComment(postID uuid.UUID, userID uuid.UUID, userName str
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
 	}
-	_, err := s.repo.GetByID(postID)
+	post, err := s.repo.GetByID(postID)
 	if err != nil {
 		return nil, err
 	}
@@ -179,6 +243,15 @@ func (s *Service) CreateComment(postID uuid.UUID, userID uuid.UUID, userName str
 	if err := s.repo.CreateComment(comment); err != nil {
 		return nil, err
 	}
+	events.Publish(events.SurplusCommentAdded{
+		Meta:          events.NewMeta(),
+		OwnerID:       post.UserID,
+		CommenterID:   comment.UserID,
+		CommenterName: comment.UserName,
+		PostID:        post.ID,
+		PostTitle:     post.Title,
+		CommentID:     comment.ID,
+	})
 	return comment, nil
 }
 
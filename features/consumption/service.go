@@ -2,6 +2,7 @@ package consumption
 
 import (
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
 	"foodlink_backend/utils"
 	"time"
 
@@ -46,6 +47,17 @@ func (s *Service) Create(userID uuid.UUID, req *CreateConsumptionLogRequest) (*C
 	if err := s.repo.Create(log); err != nil {
 		return nil, err
 	}
+	events.Publish(events.ConsumptionLogged{
+		Meta:       events.NewMeta(),
+		UserID:     log.UserID,
+		LogID:      log.ID,
+		FoodName:   log.FoodName,
+		Category:   log.Category,
+		Quantity:   log.Quantity,
+		Unit:       log.Unit,
+		WasWasted:  log.WasWasted,
+		ConsumedAt: log.ConsumedAt,
+	})
 	return log, nil
 }
 
@@ -0,0 +1,407 @@
+package households
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Get handles GET /api/v1/households
+// @Summary      Get my household
+// @Description  Get the authenticated user's household with its members
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  Household
+// @Failure      401  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /households [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	household, err := h.service.Get(user.ID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve household", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Household retrieved successfully", household)
+}
+
+// Create handles POST /api/v1/households
+// @Summary      Create a household
+// @Description  Create a household owned by the authenticated user. The user's shopping list, meal plans and preferences move into it.
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreateHouseholdRequest  true  "Household data"
+// @Success      201      {object}  Household
+// @Failure      400      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /households [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	var req CreateHouseholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+
+	household, err := h.service.Create(user.ID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to create household", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Household created successfully", household)
+}
+
+// Rename handles PUT /api/v1/households
+// @Summary      Rename household
+// @Description  Rename the authenticated user's household (owners only)
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      UpdateHouseholdRequest  true  "New name"
+// @Success      200      {object}  Household
+// @Failure      400      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Router       /households [put]
+func (h *Handler) Rename(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	var req UpdateHouseholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+
+	household, err := h.service.Rename(user.ID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to rename household", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Household updated successfully", household)
+}
+
+// Leave handles POST /api/v1/households/leave
+// @Summary      Leave household
+// @Description  Leave the current household. The last owner hands ownership to the longest-standing member; the last member deletes the household.
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  errors.AppError
+// @Router       /households/leave [post]
+func (h *Handler) Leave(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	if err := h.service.Leave(user.ID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to leave household", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Left household successfully", nil)
+}
+
+// Join handles POST /api/v1/households/join
+// @Summary      Join household
+// @Description  Join a household by redeeming an invite code
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      JoinHouseholdRequest  true  "Invite code"
+// @Success      200      {object}  Household
+// @Failure      400      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /households/join [post]
+func (h *Handler) Join(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	var req JoinHouseholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+
+	household, err := h.service.Join(user.ID, user.Email, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to join household", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Joined household successfully", household)
+}
+
+// ListInvites handles GET /api/v1/households/invites
+// @Summary      List pending invites
+// @Description  List invites that can still be redeemed (owners only)
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   Invite
+// @Failure      403  {object}  errors.AppError
+// @Router       /households/invites [get]
+func (h *Handler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	invites, err := h.service.ListInvites(user.ID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve invites", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Invites retrieved successfully", invites)
+}
+
+// CreateInvite handles POST /api/v1/households/invites
+// @Summary      Invite to household
+// @Description  Create an invite code, optionally bound to an email address (owners only)
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreateInviteRequest  true  "Invite options"
+// @Success      201      {object}  Invite
+// @Failure      400      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Router       /households/invites [post]
+func (h *Handler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+
+	invite, err := h.service.CreateInvite(user.ID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to create invite", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Invite created successfully", invite)
+}
+
+// RevokeInvite handles DELETE /api/v1/households/invites/:id
+// @Summary      Revoke invite
+// @Description  Revoke a pending invite (owners only)
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Invite ID"
+// @Success      200  {object}  map[string]string
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /households/invites/{id} [delete]
+func (h *Handler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	id, err := uuid.Parse(strings.TrimPrefix(r.URL.Path, "/invites/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+
+	if err := h.service.RevokeInvite(user.ID, id); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to revoke invite", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Invite revoked successfully", nil)
+}
+
+// UpdateMember handles PUT /api/v1/households/members/:userId
+// @Summary      Change member role
+// @Description  Promote or demote a household member (owners only)
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId   path      string               true  "Member user ID"
+// @Param        request  body      UpdateMemberRequest  true  "New role"
+// @Success      200      {object}  Household
+// @Failure      403      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /households/members/{userId} [put]
+func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	memberID, err := uuid.Parse(strings.TrimPrefix(r.URL.Path, "/members/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+
+	household, err := h.service.UpdateMemberRole(user.ID, memberID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update member", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Member updated successfully", household)
+}
+
+// RemoveMember handles DELETE /api/v1/households/members/:userId
+// @Summary      Remove member
+// @Description  Remove a member from the household (owners only, or yourself)
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId  path      string  true  "Member user ID"
+// @Success      200     {object}  map[string]string
+// @Failure      403     {object}  errors.AppError
+// @Failure      404     {object}  errors.AppError
+// @Router       /households/members/{userId} [delete]
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+
+	memberID, err := uuid.Parse(strings.TrimPrefix(r.URL.Path, "/members/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+
+	if err := h.service.RemoveMember(user.ID, memberID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to remove member", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Member removed successfully", nil)
+}
@@ -0,0 +1,76 @@
+package households
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Household member roles
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+// Household represents a group of users sharing inventory, shopping lists and meal plans
+type Household struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	Members   []*Member  `json:"members,omitempty"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Member represents a user's membership in a household
+type Member struct {
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	HouseholdID uuid.UUID `json:"household_id" db:"household_id"`
+	Name        string    `json:"name" db:"name"`
+	Email       string    `json:"email" db:"email"`
+	Role        string    `json:"role" db:"role"`
+	JoinedAt    time.Time `json:"joined_at" db:"joined_at"`
+}
+
+// Invite represents an invitation to join a household, redeemed by code
+type Invite struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	HouseholdID uuid.UUID  `json:"household_id" db:"household_id"`
+	Code        string     `json:"code" db:"code"`
+	Email       *string    `json:"email,omitempty" db:"email"`
+	Role        string     `json:"role" db:"role"`
+	InvitedBy   *uuid.UUID `json:"invited_by,omitempty" db:"invited_by"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedBy  *uuid.UUID `json:"accepted_by,omitempty" db:"accepted_by"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateHouseholdRequest represents a request to create a household
+type CreateHouseholdRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=255"`
+}
+
+// UpdateHouseholdRequest represents a request to rename a household
+type UpdateHouseholdRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=255"`
+}
+
+// CreateInviteRequest represents a request to invite someone to a household.
+// When Email is set only the user with that email can redeem the invite.
+type CreateInviteRequest struct {
+	Email          string `json:"email,omitempty" validate:"omitempty,email"`
+	Role           string `json:"role,omitempty" validate:"omitempty,oneof=owner member"`
+	ExpiresInHours int    `json:"expires_in_hours,omitempty" validate:"omitempty,min=1,max=720"`
+}
+
+// JoinHouseholdRequest represents a request to join a household with an invite code
+type JoinHouseholdRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// UpdateMemberRequest represents a request to change a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner member"`
+}
@@ -0,0 +1,418 @@
+package households
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+func (r *Repository) GetByID(id uuid.UUID) (*Household, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+
+	h := &Household{}
+	query := `SELECT id, name, created_by, created_at, updated_at FROM households WHERE id = $1`
+	err := r.db.QueryRow(query, id).Scan(&h.ID, &h.Name, &h.CreatedBy, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return h, nil
+}
+
+func (r *Repository) GetMembers(householdID uuid.UUID) ([]*Member, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+
+	query := `
+		SELECT m.user_id, m.household_id, u.name, u.email, m.role, m.joined_at
+		FROM household_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.household_id = $1
+		ORDER BY m.joined_at ASC
+	`
+	rows, err := r.db.Query(query, householdID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+
+	var members []*Member
+	for rows.Next() {
+		m := &Member{}
+		if err := rows.Scan(&m.UserID, &m.HouseholdID, &m.Name, &m.Email, &m.Role, &m.JoinedAt); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// GetMembership returns the household membership of a user, or ErrNotFound if they have none
+func (r *Repository) GetMembership(userID uuid.UUID) (*Member, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+
+	m := &Member{}
+	query := `
+		SELECT m.user_id, m.household_id, u.name, u.email, m.role, m.joined_at
+		FROM household_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.user_id = $1
+	`
+	err := r.db.QueryRow(query, userID).Scan(&m.UserID, &m.HouseholdID, &m.Name, &m.Email, &m.Role, &m.JoinedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return m, nil
+}
+
+// Create inserts a household with ownerID as its owner and moves the owner's
+// personal shopping list, meal plans and preferences into it.
+func (r *Repository) Create(h *Household, ownerID uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `
+		INSERT INTO households (id, name, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+	if err := tx.QueryRow(query, h.ID, h.Name, ownerID, now, now).Scan(&h.CreatedAt, &h.UpdatedAt); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	h.CreatedBy = &ownerID
+
+	if err := addMember(tx, h.ID, ownerID, RoleOwner, now); err != nil {
+		return err
+	}
+
+	// Preferences were keyed by the user ID before households existed
+	adoptPreferences := `
+		UPDATE family_preferences SET household_id = $1
+		WHERE household_id = $2
+		AND NOT EXISTS (SELECT 1 FROM family_preferences WHERE household_id = $1)
+	`
+	if _, err := tx.Exec(adoptPreferences, h.ID, ownerID); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+func (r *Repository) UpdateName(id uuid.UUID, name string) (*Household, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+
+	h := &Household{}
+	query := `
+		UPDATE households SET name = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING id, name, created_by, created_at, updated_at
+	`
+	err := r.db.QueryRow(query, name, time.Now(), id).Scan(&h.ID, &h.Name, &h.CreatedBy, &h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return h, nil
+}
+
+// Delete removes a household; memberships and invites cascade. Users,
+// shopping list items and meal plans have no foreign key to it, so they are
+// released here.
+func (r *Repository) Delete(id uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`UPDATE users SET household_id = NULL WHERE household_id = $1`,
+		`UPDATE shopping_list_items SET household_id = NULL WHERE household_id = $1`,
+		`UPDATE meal_plans SET household_id = NULL WHERE household_id = $1`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, id); err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM households WHERE id = $1`, id); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// JoinWithInvite adds userID to the invite's household and marks the invite accepted.
+// Returns ErrConflict if the invite was redeemed, revoked or expired concurrently.
+func (r *Repository) JoinWithInvite(invite *Invite, userID uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	accept := `
+		UPDATE household_invites SET accepted_by = $1, accepted_at = $2
+		WHERE id = $3 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+	`
+	result, err := tx.Exec(accept, userID, now, invite.ID)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected == 0 {
+		return errors.ErrConflict
+	}
+
+	if err := addMember(tx, invite.HouseholdID, userID, invite.Role, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// RemoveMember removes a user from a household. Their own rows stay with them.
+func (r *Repository) RemoveMember(householdID, userID uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM household_members WHERE household_id = $1 AND user_id = $2`, householdID, userID)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+
+	statements := []string{
+		`UPDATE users SET household_id = NULL WHERE id = $1`,
+		`UPDATE shopping_list_items SET household_id = NULL WHERE user_id = $1`,
+		`UPDATE meal_plans SET household_id = NULL WHERE user_id = $1`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, userID); err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+func (r *Repository) SetMemberRole(householdID, userID uuid.UUID, role string) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	result, err := r.db.Exec(`UPDATE household_members SET role = $1 WHERE household_id = $2 AND user_id = $3`, role, householdID, userID)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) CreateInvite(invite *Invite) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	query := `
+		INSERT INTO household_invites (id, household_id, code, email, role, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+	err := r.db.QueryRow(
+		query,
+		invite.ID,
+		invite.HouseholdID,
+		invite.Code,
+		invite.Email,
+		invite.Role,
+		invite.InvitedBy,
+		invite.ExpiresAt,
+		time.Now(),
+	).Scan(&invite.CreatedAt)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+const inviteColumns = `id, household_id, code, email, role, invited_by, expires_at, accepted_by, accepted_at, revoked_at, created_at`
+
+func scanInvite(row interface{ Scan(...interface{}) error }) (*Invite, error) {
+	inv := &Invite{}
+	err := row.Scan(
+		&inv.ID,
+		&inv.HouseholdID,
+		&inv.Code,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.ExpiresAt,
+		&inv.AcceptedBy,
+		&inv.AcceptedAt,
+		&inv.RevokedAt,
+		&inv.CreatedAt,
+	)
+	return inv, err
+}
+
+func (r *Repository) GetInviteByCode(code string) (*Invite, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+
+	inv, err := scanInvite(r.db.QueryRow(`SELECT `+inviteColumns+` FROM household_invites WHERE code = $1`, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return inv, nil
+}
+
+// ListPendingInvites returns invites that can still be redeemed
+func (r *Repository) ListPendingInvites(householdID uuid.UUID) ([]*Invite, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+
+	query := `SELECT ` + inviteColumns + ` FROM household_invites
+		WHERE household_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(query, householdID, time.Now())
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+
+	var invites []*Invite
+	for rows.Next() {
+		inv, err := scanInvite(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		invites = append(invites, inv)
+	}
+	return invites, nil
+}
+
+func (r *Repository) RevokeInvite(householdID, inviteID uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+
+	query := `
+		UPDATE household_invites SET revoked_at = $1
+		WHERE id = $2 AND household_id = $3 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	result, err := r.db.Exec(query, time.Now(), inviteID, householdID)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// addMember inserts a membership, points users.household_id at the household and
+// moves the user's unassigned shopping list items and meal plans into it.
+func addMember(tx *sql.Tx, householdID, userID uuid.UUID, role string, joinedAt time.Time) error {
+	query := `INSERT INTO household_members (user_id, household_id, role, joined_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(query, userID, householdID, role, joinedAt); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	statements := []string{
+		`UPDATE users SET household_id = $1 WHERE id = $2`,
+		`UPDATE shopping_list_items SET household_id = $1 WHERE user_id = $2 AND (household_id IS NULL OR household_id = $2)`,
+		`UPDATE meal_plans SET household_id = $1 WHERE user_id = $2 AND (household_id IS NULL OR household_id = $2)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, householdID, userID); err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package households
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+// SetupRoutes sets up household routes
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+
+		switch {
+		case path == "/" && r.Method == http.MethodGet:
+			handler.Get(w, r)
+		case path == "/" && r.Method == http.MethodPost:
+			handler.Create(w, r)
+		case path == "/" && (r.Method == http.MethodPut || r.Method == http.MethodPatch):
+			handler.Rename(w, r)
+		case path == "/leave" && r.Method == http.MethodPost:
+			handler.Leave(w, r)
+		case path == "/join" && r.Method == http.MethodPost:
+			handler.Join(w, r)
+		case path == "/invites" && r.Method == http.MethodGet:
+			handler.ListInvites(w, r)
+		case path == "/invites" && r.Method == http.MethodPost:
+			handler.CreateInvite(w, r)
+		case strings.HasPrefix(path, "/invites/") && r.Method == http.MethodDelete:
+			handler.RevokeInvite(w, r)
+		case strings.HasPrefix(path, "/members/"):
+			switch r.Method {
+			case http.MethodPut, http.MethodPatch:
+				handler.UpdateMember(w, r)
+			case http.MethodDelete:
+				handler.RemoveMember(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	return middleware.Chain(authMiddleware)(mux)
+}
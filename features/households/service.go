@@ -0,0 +1,294 @@
+package households
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"foodlink_backend/errors"
+	"foodlink_backend/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultInviteExpiry applies when an invite request does not set expires_in_hours
+const defaultInviteExpiry = 72 * time.Hour
+
+var (
+	errNotInHousehold     = errors.NewAppError(http.StatusNotFound, "You are not a member of a household")
+	errAlreadyInHousehold = errors.NewAppError(http.StatusConflict, "You already belong to a household")
+	errOwnerRequired      = errors.NewAppError(http.StatusForbidden, "Only household owners can do this")
+	errInviteInvalid      = errors.NewAppError(http.StatusBadRequest, "Invite is invalid or has expired")
+	errLastOwner          = errors.NewAppError(http.StatusConflict, "A household must keep at least one owner")
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository()}
+}
+
+// Get returns the caller's household with its members
+func (s *Service) Get(userID uuid.UUID) (*Household, error) {
+	membership, err := s.membership(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.load(membership.HouseholdID)
+}
+
+func (s *Service) Create(userID uuid.UUID, req *CreateHouseholdRequest) (*Household, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+
+	if _, err := s.repo.GetMembership(userID); err == nil {
+		return nil, errAlreadyInHousehold
+	} else if err != errors.ErrNotFound {
+		return nil, err
+	}
+
+	household := &Household{
+		ID:   uuid.New(),
+		Name: strings.TrimSpace(req.Name),
+	}
+	if err := s.repo.Create(household, userID); err != nil {
+		return nil, err
+	}
+	return s.load(household.ID)
+}
+
+func (s *Service) Rename(userID uuid.UUID, req *UpdateHouseholdRequest) (*Household, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+
+	membership, err := s.requireOwner(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.UpdateName(membership.HouseholdID, strings.TrimSpace(req.Name)); err != nil {
+		return nil, err
+	}
+	return s.load(membership.HouseholdID)
+}
+
+// Leave removes the caller from their household. When the last owner leaves the
+// longest-standing member is promoted; when the last member leaves the household is deleted.
+func (s *Service) Leave(userID uuid.UUID) error {
+	membership, err := s.membership(userID)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.repo.GetMembers(membership.HouseholdID)
+	if err != nil {
+		return err
+	}
+	if len(members) <= 1 {
+		return s.repo.Delete(membership.HouseholdID)
+	}
+
+	if membership.Role == RoleOwner && countOwners(members) == 1 {
+		for _, m := range members {
+			if m.UserID != userID {
+				if err := s.repo.SetMemberRole(membership.HouseholdID, m.UserID, RoleOwner); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	return s.repo.RemoveMember(membership.HouseholdID, userID)
+}
+
+func (s *Service) CreateInvite(userID uuid.UUID, req *CreateInviteRequest) (*Invite, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+
+	membership, err := s.requireOwner(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrInternalServer)
+	}
+
+	role := req.Role
+	if role == "" {
+		role = RoleMember
+	}
+	expiry := defaultInviteExpiry
+	if req.ExpiresInHours > 0 {
+		expiry = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	invite := &Invite{
+		ID:          uuid.New(),
+		HouseholdID: membership.HouseholdID,
+		Code:        code,
+		Role:        role,
+		InvitedBy:   &userID,
+		ExpiresAt:   time.Now().Add(expiry),
+	}
+	if email := strings.TrimSpace(req.Email); email != "" {
+		invite.Email = &email
+	}
+
+	if err := s.repo.CreateInvite(invite); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+func (s *Service) ListInvites(userID uuid.UUID) ([]*Invite, error) {
+	membership, err := s.requireOwner(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListPendingInvites(membership.HouseholdID)
+}
+
+func (s *Service) RevokeInvite(userID, inviteID uuid.UUID) error {
+	membership, err := s.requireOwner(userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.RevokeInvite(membership.HouseholdID, inviteID)
+}
+
+// Join redeems an invite code for the caller
+func (s *Service) Join(userID uuid.UUID, email string, req *JoinHouseholdRequest) (*Household, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+
+	if _, err := s.repo.GetMembership(userID); err == nil {
+		return nil, errAlreadyInHousehold
+	} else if err != errors.ErrNotFound {
+		return nil, err
+	}
+
+	invite, err := s.repo.GetInviteByCode(strings.TrimSpace(req.Code))
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errInviteInvalid
+		}
+		return nil, err
+	}
+	if invite.AcceptedAt != nil || invite.RevokedAt != nil || time.Now().After(invite.ExpiresAt) {
+		return nil, errInviteInvalid
+	}
+	if invite.Email != nil && !strings.EqualFold(*invite.Email, email) {
+		return nil, errors.NewAppError(http.StatusForbidden, "This invite was issued to a different email address")
+	}
+
+	if err := s.repo.JoinWithInvite(invite, userID); err != nil {
+		if err == errors.ErrConflict {
+			return nil, errInviteInvalid
+		}
+		return nil, err
+	}
+	return s.load(invite.HouseholdID)
+}
+
+// RemoveMember removes another member from the caller's household
+func (s *Service) RemoveMember(userID, memberID uuid.UUID) error {
+	if userID == memberID {
+		return s.Leave(userID)
+	}
+
+	membership, err := s.requireOwner(userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.RemoveMember(membership.HouseholdID, memberID)
+}
+
+func (s *Service) UpdateMemberRole(userID, memberID uuid.UUID, req *UpdateMemberRequest) (*Household, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+
+	membership, err := s.requireOwner(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Role != RoleOwner {
+		members, err := s.repo.GetMembers(membership.HouseholdID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			if m.UserID == memberID && m.Role == RoleOwner && countOwners(members) == 1 {
+				return nil, errLastOwner
+			}
+		}
+	}
+
+	if err := s.repo.SetMemberRole(membership.HouseholdID, memberID, req.Role); err != nil {
+		return nil, err
+	}
+	return s.load(membership.HouseholdID)
+}
+
+func (s *Service) membership(userID uuid.UUID) (*Member, error) {
+	membership, err := s.repo.GetMembership(userID)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errNotInHousehold
+		}
+		return nil, err
+	}
+	return membership, nil
+}
+
+func (s *Service) requireOwner(userID uuid.UUID) (*Member, error) {
+	membership, err := s.membership(userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership.Role != RoleOwner {
+		return nil, errOwnerRequired
+	}
+	return membership, nil
+}
+
+func (s *Service) load(householdID uuid.UUID) (*Household, error) {
+	household, err := s.repo.GetByID(householdID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.GetMembers(householdID)
+	if err != nil {
+		return nil, err
+	}
+	household.Members = members
+	return household, nil
+}
+
+func countOwners(members []*Member) int {
+	owners := 0
+	for _, m := range members {
+		if m.Role == RoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
+
+func generateInviteCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
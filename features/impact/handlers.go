@@ -0,0 +1,80 @@
+package impact
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/utils"
+	"net/http"
+	"strings"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetFactors handles GET /api/v1/impact/factors
+// @Summary      Get impact factors
+// @Description  Get the per-category CO2e, water and meal factors used to calculate impact
+// @Tags         impact
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   Factor
+// @Failure      401  {object}  errors.AppError
+// @Router       /impact/factors [get]
+func (h *Handler) GetFactors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	factors, err := h.service.GetFactors()
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve impact factors", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Impact factors retrieved successfully", factors)
+}
+
+// UpdateFactor handles PUT /api/v1/impact/factors/:category
+// @Summary      Update impact factor
+// @Description  Create or replace the impact factors for a food category (admin only)
+// @Tags         impact
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        category  path      string               true  "Food category"
+// @Param        request   body      UpdateFactorRequest  true  "Factors"
+// @Success      200       {object}  Factor
+// @Failure      400       {object}  errors.AppError
+// @Failure      403       {object}  errors.AppError
+// @Router       /impact/factors/{category} [put]
+func (h *Handler) UpdateFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	category := strings.TrimPrefix(r.URL.Path, "/factors/")
+	var req UpdateFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	factor, err := h.service.UpdateFactor(category, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update impact factor", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Impact factor updated successfully", factor)
+}
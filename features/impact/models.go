@@ -0,0 +1,74 @@
+package impact
+
+import (
+	"time"
+)
+
+// DefaultCategory names the factors used for categories without their own
+const DefaultCategory = "default"
+
+// Factor converts rescued food of one category into environmental impact
+type Factor struct {
+	Category         string    `json:"category" db:"category"`
+	CO2ePerKg        float64   `json:"co2e_per_kg" db:"co2e_per_kg"`
+	WaterLitersPerKg float64   `json:"water_liters_per_kg" db:"water_liters_per_kg"`
+	KgPerMeal        float64   `json:"kg_per_meal" db:"kg_per_meal"`
+	KgPerPiece       float64   `json:"kg_per_piece" db:"kg_per_piece"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateFactorRequest creates or replaces the factors for a category
+type UpdateFactorRequest struct {
+	CO2ePerKg        float64 `json:"co2e_per_kg" validate:"min=0"`
+	WaterLitersPerKg float64 `json:"water_liters_per_kg" validate:"min=0"`
+	KgPerMeal        float64 `json:"kg_per_meal" validate:"required,gt=0"`
+	KgPerPiece       float64 `json:"kg_per_piece" validate:"required,gt=0"`
+}
+
+// Measure is the impact of a quantity of food
+type Measure struct {
+	Kg          float64 `json:"kg"`
+	CO2Kg       float64 `json:"co2_kg"`
+	WaterLiters float64 `json:"water_liters"`
+	Meals       int     `json:"meals"`
+}
+
+// Contribution is one quantity of food that was rescued, or wasted, at a point in time.
+// Meals, when positive, is a known meal count that overrides the estimate.
+type Contribution struct {
+	Category   string
+	Quantity   float64
+	Unit       string
+	Meals      int
+	Wasted     bool
+	OccurredAt time.Time
+}
+
+// TrendPoint is the food rescued in one week or month
+type TrendPoint struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+}
+
+// CategoryImpact is the impact of one category
+type CategoryImpact struct {
+	Category    string  `json:"category"`
+	RescuedKg   float64 `json:"rescued_kg"`
+	WasteKg     float64 `json:"waste_kg"`
+	CO2Kg       float64 `json:"co2_kg"`
+	WaterLiters float64 `json:"water_liters"`
+}
+
+// Summary aggregates contributions into totals, trends and a category breakdown.
+// Wasted contributions only count toward WasteKg.
+type Summary struct {
+	RescuedKg     float64           `json:"rescued_kg"`
+	WasteKg       float64           `json:"waste_kg"`
+	CO2Kg         float64           `json:"co2_kg"`
+	WaterLiters   float64           `json:"water_liters"`
+	Meals         int               `json:"meals"`
+	Contributions int               `json:"contributions"`
+	WeeklyTrend   []TrendPoint      `json:"weekly_trend"`
+	MonthlyTrend  []TrendPoint      `json:"monthly_trend"`
+	Categories    []*CategoryImpact `json:"categories"`
+}
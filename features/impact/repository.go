@@ -0,0 +1,57 @@
+package impact
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+func (r *Repository) ListFactors() ([]*Factor, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `SELECT category, co2e_per_kg, water_liters_per_kg, kg_per_meal, kg_per_piece, updated_at FROM impact_factors ORDER BY category`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	var factors []*Factor
+	for rows.Next() {
+		f := &Factor{}
+		if err := rows.Scan(&f.Category, &f.CO2ePerKg, &f.WaterLitersPerKg, &f.KgPerMeal, &f.KgPerPiece, &f.UpdatedAt); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		factors = append(factors, f)
+	}
+	return factors, rows.Err()
+}
+
+func (r *Repository) UpsertFactor(f *Factor) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `
+		INSERT INTO impact_factors (category, co2e_per_kg, water_liters_per_kg, kg_per_meal, kg_per_piece)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (category) DO UPDATE SET
+			co2e_per_kg = EXCLUDED.co2e_per_kg,
+			water_liters_per_kg = EXCLUDED.water_liters_per_kg,
+			kg_per_meal = EXCLUDED.kg_per_meal,
+			kg_per_piece = EXCLUDED.kg_per_piece
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(query, f.Category, f.CO2ePerKg, f.WaterLitersPerKg, f.KgPerMeal, f.KgPerPiece).Scan(&f.UpdatedAt)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
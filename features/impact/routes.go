@@ -0,0 +1,25 @@
+package impact
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/factors", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			handler.GetFactors(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/factors/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			handler.UpdateFactor(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
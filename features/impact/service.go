@@ -0,0 +1,284 @@
+package impact
+
+import (
+	"foodlink_backend/errors"
+	"foodlink_backend/utils"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trend windows, ending with the current week or month
+const (
+	trendWeeks  = 8
+	trendMonths = 6
+)
+
+// factorsTTL bounds how long factors are cached. Updates through this process
+// invalidate the cache immediately; other replicas pick them up within the TTL.
+const factorsTTL = time.Minute
+
+// fallbackFactor is used when no factors can be loaded
+var fallbackFactor = Factor{Category: DefaultCategory, CO2ePerKg: 2.5, WaterLitersPerKg: 1000, KgPerMeal: 0.4, KgPerPiece: 0.25}
+
+// factorCache is shared by every Service so an update is seen by all features
+var factorCache struct {
+	sync.RWMutex
+	factors  map[string]*Factor
+	loadedAt time.Time
+}
+
+// Unit conversions to kilograms. Volumes assume the density of water.
+var kgPerUnit = map[string]float64{
+	"kg": 1, "kgs": 1, "kilo": 1, "kilos": 1, "kilogram": 1, "kilograms": 1,
+	"g": 0.001, "gram": 0.001, "grams": 0.001,
+	"lb": 0.453592, "lbs": 0.453592, "pound": 0.453592, "pounds": 0.453592,
+	"oz": 0.0283495, "ounce": 0.0283495, "ounces": 0.0283495,
+	"t": 1000, "tonne": 1000, "tonnes": 1000,
+	"l": 1, "liter": 1, "liters": 1, "litre": 1, "litres": 1,
+	"ml": 0.001, "milliliter": 0.001, "milliliters": 0.001, "millilitre": 0.001, "millilitres": 0.001,
+}
+
+// Units counted in meals or pieces, converted with the category's factors
+var (
+	mealUnits  = map[string]bool{"meal": true, "meals": true, "portion": true, "portions": true, "serving": true, "servings": true, "plate": true, "plates": true}
+	pieceUnits = map[string]bool{"": true, "pc": true, "pcs": true, "piece": true, "pieces": true, "item": true, "items": true, "unit": true, "units": true, "each": true}
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository()}
+}
+
+func (s *Service) GetFactors() ([]*Factor, error) {
+	return s.repo.ListFactors()
+}
+
+func (s *Service) UpdateFactor(category string, req *UpdateFactorRequest) (*Factor, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	category = NormalizeCategory(category)
+	if category == "" {
+		return nil, errors.NewAppError(errors.ErrBadRequest.Code, "Category is required")
+	}
+
+	factor := &Factor{
+		Category:         category,
+		CO2ePerKg:        req.CO2ePerKg,
+		WaterLitersPerKg: req.WaterLitersPerKg,
+		KgPerMeal:        req.KgPerMeal,
+		KgPerPiece:       req.KgPerPiece,
+	}
+	if err := s.repo.UpsertFactor(factor); err != nil {
+		return nil, err
+	}
+
+	factorCache.Lock()
+	factorCache.factors = nil
+	factorCache.Unlock()
+	return factor, nil
+}
+
+// Factor returns the factors for category, falling back to the default category
+func (s *Service) Factor(category string) *Factor {
+	factors := s.factors()
+	if f, ok := factors[NormalizeCategory(category)]; ok {
+		return f
+	}
+	if f, ok := factors[DefaultCategory]; ok {
+		return f
+	}
+	return &fallbackFactor
+}
+
+// ToKg converts a quantity to kilograms. Meal and piece units use the
+// category's factors; ok is false for units that cannot be converted.
+func (s *Service) ToKg(category string, quantity float64, unit string) (kg float64, ok bool) {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if perUnit, found := kgPerUnit[unit]; found {
+		return quantity * perUnit, true
+	}
+	f := s.Factor(category)
+	switch {
+	case mealUnits[unit]:
+		return quantity * f.KgPerMeal, true
+	case pieceUnits[unit]:
+		return quantity * f.KgPerPiece, true
+	default:
+		return 0, false
+	}
+}
+
+// Measure computes the impact of a quantity of food. Quantities in units that
+// cannot be converted have no impact.
+func (s *Service) Measure(category string, quantity float64, unit string) Measure {
+	kg, ok := s.ToKg(category, quantity, unit)
+	if !ok {
+		return Measure{}
+	}
+	f := s.Factor(category)
+	return Measure{
+		Kg:          round2(kg),
+		CO2Kg:       round2(kg * f.CO2ePerKg),
+		WaterLiters: round2(kg * f.WaterLitersPerKg),
+		Meals:       int(kg / f.KgPerMeal),
+	}
+}
+
+// Summarize aggregates contributions into totals, weekly and monthly trends of
+// rescued kg ending at now, and a per-category breakdown sorted by rescued kg
+func (s *Service) Summarize(contributions []Contribution, now time.Time) *Summary {
+	summary := &Summary{Contributions: len(contributions)}
+
+	thisWeek := weekStart(now)
+	weekly := make([]TrendPoint, trendWeeks)
+	for i := range weekly {
+		weekly[i].Label = thisWeek.AddDate(0, 0, -7*(trendWeeks-1-i)).Format("2006-01-02")
+	}
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	monthly := make([]TrendPoint, trendMonths)
+	for i := range monthly {
+		monthly[i].Label = thisMonth.AddDate(0, -(trendMonths - 1 - i), 0).Format("2006-01")
+	}
+
+	categories := make(map[string]*CategoryImpact)
+	for _, c := range contributions {
+		category := NormalizeCategory(c.Category)
+		if category == "" {
+			category = DefaultCategory
+		}
+		breakdown, ok := categories[category]
+		if !ok {
+			breakdown = &CategoryImpact{Category: category}
+			categories[category] = breakdown
+		}
+
+		m := s.Measure(category, c.Quantity, c.Unit)
+		if c.Wasted {
+			summary.WasteKg += m.Kg
+			breakdown.WasteKg += m.Kg
+			continue
+		}
+
+		meals := m.Meals
+		if c.Meals > 0 {
+			meals = c.Meals
+		}
+		summary.RescuedKg += m.Kg
+		summary.CO2Kg += m.CO2Kg
+		summary.WaterLiters += m.WaterLiters
+		summary.Meals += meals
+		breakdown.RescuedKg += m.Kg
+		breakdown.CO2Kg += m.CO2Kg
+		breakdown.WaterLiters += m.WaterLiters
+
+		at := c.OccurredAt.In(now.Location())
+		if week := int(math.Round(thisWeek.Sub(weekStart(at)).Hours()/24)) / 7; week >= 0 && week < trendWeeks {
+			weekly[trendWeeks-1-week].Value += m.Kg
+		}
+		if month := (now.Year()-at.Year())*12 + int(now.Month()-at.Month()); month >= 0 && month < trendMonths {
+			monthly[trendMonths-1-month].Value += m.Kg
+		}
+	}
+
+	for i := range weekly {
+		weekly[i].Value = round2(weekly[i].Value)
+	}
+	for i := range monthly {
+		monthly[i].Value = round2(monthly[i].Value)
+	}
+	summary.WeeklyTrend = weekly
+	summary.MonthlyTrend = monthly
+
+	summary.Categories = make([]*CategoryImpact, 0, len(categories))
+	for _, breakdown := range categories {
+		breakdown.RescuedKg = round2(breakdown.RescuedKg)
+		breakdown.WasteKg = round2(breakdown.WasteKg)
+		breakdown.CO2Kg = round2(breakdown.CO2Kg)
+		breakdown.WaterLiters = round2(breakdown.WaterLiters)
+		summary.Categories = append(summary.Categories, breakdown)
+	}
+	sort.Slice(summary.Categories, func(i, j int) bool {
+		if summary.Categories[i].RescuedKg != summary.Categories[j].RescuedKg {
+			return summary.Categories[i].RescuedKg > summary.Categories[j].RescuedKg
+		}
+		return summary.Categories[i].Category < summary.Categories[j].Category
+	})
+
+	summary.RescuedKg = round2(summary.RescuedKg)
+	summary.WasteKg = round2(summary.WasteKg)
+	summary.CO2Kg = round2(summary.CO2Kg)
+	summary.WaterLiters = round2(summary.WaterLiters)
+	return summary
+}
+
+// factors returns the cached factors by category, reloading them once the TTL
+// has passed. If loading fails the previous factors are kept until the next reload.
+func (s *Service) factors() map[string]*Factor {
+	factorCache.RLock()
+	factors, loadedAt := factorCache.factors, factorCache.loadedAt
+	factorCache.RUnlock()
+	if factors != nil && time.Since(loadedAt) < factorsTTL {
+		return factors
+	}
+
+	list, err := s.repo.ListFactors()
+	if err != nil {
+		log.Printf("Warning: failed to load impact factors: %v", err)
+		if factors == nil {
+			factors = map[string]*Factor{}
+		}
+	} else {
+		factors = make(map[string]*Factor, len(list))
+		for _, f := range list {
+			factors[f.Category] = f
+		}
+	}
+
+	factorCache.Lock()
+	factorCache.factors = factors
+	factorCache.loadedAt = time.Now()
+	factorCache.Unlock()
+	return factors
+}
+
+// NormalizeCategory lower-cases a category and joins words with hyphens, so
+// "Prepared Meals" uses the factors for "prepared-meals"
+func NormalizeCategory(category string) string {
+	return strings.Join(strings.Fields(strings.ToLower(category)), "-")
+}
+
+// ConvertUnit converts a quantity between units of the same kind: weights and
+// volumes through kilograms, pieces to pieces and meals to meals. ok is false
+// when the units cannot be converted without a category's factors.
+func ConvertUnit(quantity float64, from, to string) (converted float64, ok bool) {
+	from = strings.ToLower(strings.TrimSpace(from))
+	to = strings.ToLower(strings.TrimSpace(to))
+	fromKg, fromWeight := kgPerUnit[from]
+	toKg, toWeight := kgPerUnit[to]
+	switch {
+	case from == to, pieceUnits[from] && pieceUnits[to], mealUnits[from] && mealUnits[to]:
+		return quantity, true
+	case fromWeight && toWeight:
+		return quantity * fromKg / toKg, true
+	default:
+		return 0, false
+	}
+}
+
+// weekStart returns midnight on the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
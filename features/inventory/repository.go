@@ -30,7 +30,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*InventoryItem, error)
 	query := `
 		SELECT id, user_id, name, quantity, unit, expiry_date, category, location, food_item_id, created_at, updated_at
 		FROM inventory_items
-		WHERE user_id = $1
+		WHERE ` + database.HouseholdScope("user_id", 1) + `
 		ORDER BY expiry_date NULLS LAST, created_at DESC
 	`
 
@@ -230,7 +230,7 @@ func (r *Repository) GetExpiring(userID uuid.UUID, days int) ([]*InventoryItem,
 	query := `
 		SELECT id, user_id, name, quantity, unit, expiry_date, category, location, food_item_id, created_at, updated_at
 		FROM inventory_items
-		WHERE user_id = $1
+		WHERE ` + database.HouseholdScope("user_id", 1) + `
 		AND expiry_date IS NOT NULL
 		AND expiry_date BETWEEN CURRENT_TIMESTAMP AND CURRENT_TIMESTAMP + INTERVAL '1 day' * $2
 		ORDER BY expiry_date ASC
@@ -276,7 +276,7 @@ func (r *Repository) GetExpired(userID uuid.UUID) ([]*InventoryItem, error) {
 	query := `
 		SELECT id, user_id, name, quantity, unit, expiry_date, category, location, food_item_id, created_at, updated_at
 		FROM inventory_items
-		WHERE user_id = $1
+		WHERE ` + database.HouseholdScope("user_id", 1) + `
 		AND expiry_date IS NOT NULL
 		AND expiry_date < CURRENT_TIMESTAMP
 		ORDER BY expiry_date ASC
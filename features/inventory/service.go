@@ -1,6 +1,7 @@
 package inventory
 
 import (
+	"foodlink_backend/database"
 	"foodlink_backend/errors"
 	"foodlink_backend/utils"
 
@@ -65,9 +66,9 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateInventoryIte
 		return nil, err
 	}
 
-	// Check ownership
-	if item.UserID != userID {
-		return nil, errors.ErrForbidden
+	// Check household access
+	if err := database.CheckHouseholdAccess(s.repo.db, item.UserID, userID); err != nil {
+		return nil, err
 	}
 
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
@@ -114,9 +115,9 @@ func (s *Service) Delete(id uuid.UUID, userID uuid.UUID) error {
 		return err
 	}
 
-	// Check ownership
-	if item.UserID != userID {
-		return errors.ErrForbidden
+	// Check household access
+	if err := database.CheckHouseholdAccess(s.repo.db, item.UserID, userID); err != nil {
+		return err
 	}
 
 	return s.repo.Delete(id)
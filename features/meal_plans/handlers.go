@@ -24,12 +24,8 @@ func (h *Handler) getUserAndHousehold(r *http.Request) (uuid.UUID, *uuid.UUID, e
 	if !ok || user == nil {
 		return uuid.Nil, nil, errors.ErrUnauthorized
 	}
-	userID := user.ID
-	if user.HouseholdID != nil {
-		return userID, user.HouseholdID, nil
-	}
-	hh := userID
-	return userID, &hh, nil
+	// Plans created outside a household are moved into one when the user joins
+	return user.ID, user.HouseholdID, nil
 }
 
 // GetWeekly handles GET /api/v1/meal-plans/weekly
@@ -60,7 +60,7 @@ func (r *Repository) GetRangeByUserID(userID uuid.UUID, start time.Time, end tim
 	query := `
 		SELECT id, user_id, household_id, date, meal_type, name, description, ingredients, servings, created_at, updated_at
 		FROM meal_plans
-		WHERE user_id = $1
+		WHERE ` + database.HouseholdScope("user_id", 1) + `
 		  AND date >= $2
 		  AND date < $3
 		ORDER BY date ASC, meal_type ASC
@@ -101,7 +101,7 @@ func (r *Repository) FindByUserDateType(userID uuid.UUID, date time.Time, mealTy
 		return nil, errors.ErrDatabase
 	}
 	var id uuid.UUID
-	err := r.db.QueryRow(`SELECT id FROM meal_plans WHERE user_id=$1 AND date=$2 AND meal_type=$3 LIMIT 1`, userID, date, mealType).Scan(&id)
+	err := r.db.QueryRow(`SELECT id FROM meal_plans WHERE `+database.HouseholdScope("user_id", 1)+` AND date=$2 AND meal_type=$3 LIMIT 1`, userID, date, mealType).Scan(&id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -218,4 +218,3 @@ func (r *Repository) Delete(id uuid.UUID) error {
 	}
 	return nil
 }
-
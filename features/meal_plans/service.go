@@ -1,6 +1,7 @@
 package meal_plans
 
 import (
+	"foodlink_backend/database"
 	"foodlink_backend/errors"
 	"foodlink_backend/utils"
 	"time"
@@ -78,9 +79,9 @@ func (s *Service) Upsert(userID uuid.UUID, householdID *uuid.UUID, req *UpsertMe
 		if err != nil {
 			return nil, err
 		}
-		// ownership is enforced by query, but double-check
-		if mp.UserID != userID {
-			return nil, errors.ErrForbidden
+		// household scope is enforced by query, but double-check
+		if err := database.CheckHouseholdAccess(s.repo.db, mp.UserID, userID); err != nil {
+			return nil, err
 		}
 		mp.Name = req.Name
 		mp.Description = req.Description
@@ -114,9 +115,8 @@ func (s *Service) Delete(id uuid.UUID, userID uuid.UUID) error {
 	if err != nil {
 		return err
 	}
-	if mp.UserID != userID {
-		return errors.ErrForbidden
+	if err := database.CheckHouseholdAccess(s.repo.db, mp.UserID, userID); err != nil {
+		return err
 	}
 	return s.repo.Delete(id)
 }
-
@@ -98,3 +98,38 @@ func (h *Handler) CreateOrUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 	utils.OKResponse(w, "Capacity settings saved successfully", settings)
 }
+
+// ListNearby handles GET /api/v1/ngos
+// @Summary      Find NGOs
+// @Description  List NGOs accepting donations. With a location each NGO carries its distance and the list is sorted nearest first.
+// @Tags         ngo-capacity
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        near       query     string  false  "Search around lat,lng; defaults to the caller's home location"
+// @Param        radius_km  query     number  false  "Only NGOs within this many km, nearest first"
+// @Success      200        {array}   NGODirectoryEntry
+// @Failure      400        {object}  errors.AppError
+// @Failure      401        {object}  errors.AppError
+// @Router       /ngos [get]
+func (h *Handler) ListNearby(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	geo, err := auth.NearbyQuery(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid location query", err.Error())
+		return
+	}
+	entries, err := h.service.ListNearby(geo)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve NGOs", err.Error())
+		return
+	}
+	utils.OKResponse(w, "NGOs retrieved successfully", entries)
+}
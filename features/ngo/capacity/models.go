@@ -35,6 +35,8 @@ type NGOCapacitySettings struct {
 	OrgName                 string    `json:"org_name" db:"org_name"`
 	Location                string    `json:"location" db:"location"`
 	GeoPoint                JSONB     `json:"geo_point,omitempty" db:"geo_point"`
+	Latitude                *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude               *float64  `json:"longitude,omitempty" db:"longitude"`
 	ManagerName             string    `json:"manager_name" db:"manager_name"`
 	ContactPhone            string    `json:"contact_phone" db:"contact_phone"`
 	ContactEmail            string    `json:"contact_email,omitempty" db:"contact_email"`
@@ -60,6 +62,8 @@ type CreateNGOCapacitySettingsRequest struct {
 	OrgName                 string                 `json:"org_name" validate:"required,min=1,max=255"`
 	Location                string                 `json:"location" validate:"required,min=1"`
 	GeoPoint                map[string]interface{} `json:"geo_point,omitempty"`
+	Latitude                *float64               `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude               *float64               `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 	ManagerName             string                 `json:"manager_name" validate:"required,min=1,max=255"`
 	ContactPhone            string                 `json:"contact_phone" validate:"required,min=1,max=50"`
 	ContactEmail            string                 `json:"contact_email,omitempty" validate:"omitempty,email"`
@@ -75,3 +79,55 @@ type CreateNGOCapacitySettingsRequest struct {
 	AutoAcceptance          map[string]interface{} `json:"auto_acceptance,omitempty"`
 	PreferredPickupRadiusKm float64                `json:"preferred_pickup_radius_km,omitempty"`
 }
+
+// NGODirectoryEntry is the public part of an NGO's capacity settings, listed
+// so donors can find NGOs near them
+type NGODirectoryEntry struct {
+	UserID                  uuid.UUID `json:"user_id"`
+	OrgName                 string    `json:"org_name"`
+	Location                string    `json:"location"`
+	Latitude                *float64  `json:"latitude,omitempty"`
+	Longitude               *float64  `json:"longitude,omitempty"`
+	DistanceKm              *float64  `json:"distance_km,omitempty"`
+	PreferredFoodTypes      []string  `json:"preferred_food_types,omitempty"`
+	StorageTypes            []string  `json:"storage_types,omitempty"`
+	PickupWindow            JSONB     `json:"pickup_window"`
+	PreferredPickupRadiusKm float64   `json:"preferred_pickup_radius_km"`
+}
+
+// AutoAcceptanceRules are the conditions under which an NGO's offers are
+// accepted without review. They are stored in the auto_acceptance settings;
+// food types and max distance fall back to the preferred food types and
+// pickup radius when unset.
+type AutoAcceptanceRules struct {
+	Enabled           bool     `json:"enabled"`
+	FoodTypes         []string `json:"foodTypes,omitempty"`
+	MaxDistanceKm     float64  `json:"maxDistanceKm,omitempty"`
+	MinFreshnessScore int      `json:"minFreshnessScore,omitempty"`
+	AllowPork         bool     `json:"allowPork"`
+	RejectExpired     bool     `json:"rejectExpired"`
+	TemperatureChecks bool     `json:"temperatureChecks"`
+}
+
+// Rules reads the auto-acceptance rules. Missing or malformed settings leave
+// auto-acceptance disabled.
+func (s *NGOCapacitySettings) Rules() AutoAcceptanceRules {
+	var rules AutoAcceptanceRules
+	if len(s.AutoAcceptance) == 0 {
+		return rules
+	}
+	data, err := json.Marshal(s.AutoAcceptance)
+	if err != nil {
+		return rules
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return AutoAcceptanceRules{}
+	}
+	if len(rules.FoodTypes) == 0 {
+		rules.FoodTypes = s.PreferredFoodTypes
+	}
+	if rules.MaxDistanceKm == 0 {
+		rules.MaxDistanceKm = s.PreferredPickupRadiusKm
+	}
+	return rules
+}
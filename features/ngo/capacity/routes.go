@@ -3,6 +3,7 @@ package capacity
 import (
 	"foodlink_backend/middleware"
 	"net/http"
+	"strings"
 )
 
 func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
@@ -19,3 +20,17 @@ func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Ha
 	})
 	return middleware.Chain(authMiddleware)(mux)
 }
+
+// SetupDirectoryRoutes serves the NGO directory that donors search
+func SetupDirectoryRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Trim(r.URL.Path, "/") == "" && r.Method == http.MethodGet:
+			handler.ListNearby(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
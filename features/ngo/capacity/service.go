@@ -19,6 +19,38 @@ func (s *Service) GetByUserID(userID uuid.UUID) (*NGOCapacitySettings, error) {
 	return s.repo.GetByUserID(userID)
 }
 
+// ListAll returns the capacity settings of every NGO
+func (s *Service) ListAll() ([]*NGOCapacitySettings, error) {
+	return s.repo.ListAll()
+}
+
+// ListNearby lists NGOs for donors. With a geo query each NGO carries its
+// distance from the search point and the list is sorted nearest first.
+func (s *Service) ListNearby(geo *utils.GeoQuery) ([]*NGODirectoryEntry, error) {
+	all, err := s.repo.ListNearby(geo.Box())
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*NGODirectoryEntry, 0, len(all))
+	for _, settings := range all {
+		entries = append(entries, &NGODirectoryEntry{
+			UserID:                  settings.UserID,
+			OrgName:                 settings.OrgName,
+			Location:                settings.Location,
+			Latitude:                settings.Latitude,
+			Longitude:               settings.Longitude,
+			PreferredFoodTypes:      settings.PreferredFoodTypes,
+			StorageTypes:            settings.StorageTypes,
+			PickupWindow:            settings.PickupWindow,
+			PreferredPickupRadiusKm: settings.PreferredPickupRadiusKm,
+		})
+	}
+	return utils.NearestFirst(geo, entries,
+		func(entry *NGODirectoryEntry) (lat, lng *float64) { return entry.Latitude, entry.Longitude },
+		func(entry *NGODirectoryEntry, d *float64) { entry.DistanceKm = d },
+	), nil
+}
+
 func (s *Service) CreateOrUpdate(userID uuid.UUID, req *CreateNGOCapacitySettingsRequest) (*NGOCapacitySettings, error) {
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
@@ -51,8 +83,25 @@ func (s *Service) CreateOrUpdate(userID uuid.UUID, req *CreateNGOCapacitySetting
 	if settings.PreferredPickupRadiusKm == 0 {
 		settings.PreferredPickupRadiusKm = 5.0
 	}
+	syncLocation(settings, req.Latitude, req.Longitude)
 	if err := s.repo.CreateOrUpdate(settings); err != nil {
 		return nil, err
 	}
 	return settings, nil
 }
+
+// syncLocation keeps geo_point and the latitude/longitude columns in step.
+// Coordinates given directly win; otherwise they are read from geo_point.
+func syncLocation(settings *NGOCapacitySettings, lat, lng *float64) {
+	if lat != nil && lng != nil {
+		if settings.GeoPoint == nil {
+			settings.GeoPoint = JSONB{}
+		}
+		settings.GeoPoint["lat"], settings.GeoPoint["lng"] = *lat, *lng
+		settings.Latitude, settings.Longitude = lat, lng
+		return
+	}
+	if pointLat, pointLng, ok := utils.ParseGeoPoint(settings.GeoPoint); ok {
+		settings.Latitude, settings.Longitude = &pointLat, &pointLng
+	}
+}
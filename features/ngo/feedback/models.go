@@ -42,6 +42,7 @@ type CreateNGOFeedbackRequest struct {
 	Rating        *int      `json:"rating,omitempty" validate:"omitempty,min=1,max=5"`
 	Comment       string    `json:"comment" validate:"required,min=1"`
 	Tags          []string  `json:"tags,omitempty"`
+	// An image URL, or the ID of an upload from POST /uploads
 	Photo         string    `json:"photo,omitempty"`
 }
 
@@ -51,5 +52,6 @@ type CreateNGOImpactStoryRequest struct {
 	Beneficiaries int      `json:"beneficiaries,omitempty"`
 	MealsProvided int      `json:"meals_provided,omitempty"`
 	Tags          []string `json:"tags,omitempty"`
+	// An image URL, or the ID of an upload from POST /uploads
 	Photo         string   `json:"photo,omitempty"`
 }
@@ -2,17 +2,20 @@ package feedback
 
 import (
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/uploads"
 	"foodlink_backend/utils"
 
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	images *uploads.Linker
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{repo: NewRepository(), images: uploads.NewLinker()}
 }
 
 func (s *Service) GetAllFeedback(ngoUserID uuid.UUID) ([]*NGOFeedbackEntry, error) {
@@ -35,9 +38,24 @@ func (s *Service) CreateFeedback(ngoUserID uuid.UUID, req *CreateNGOFeedbackRequ
 		Photo:         req.Photo,
 		Status:        "pending",
 	}
+	photo, err := s.images.Resolve(ngoUserID, feedback.Photo, uploads.AssociatedNGOFeedback, feedback.ID)
+	if err != nil {
+		return nil, err
+	}
+	feedback.Photo = photo
 	if err := s.repo.CreateFeedback(feedback); err != nil {
 		return nil, err
 	}
+	if err := s.images.Sync(feedback.Photo, uploads.AssociatedNGOFeedback, feedback.ID); err != nil {
+		return nil, err
+	}
+	events.Publish(events.FeedbackRecorded{
+		Meta:          events.NewMeta(),
+		NGOUserID:     feedback.NGOUserID,
+		FeedbackID:    feedback.ID,
+		RecipientName: feedback.RecipientName,
+		Rating:        feedback.Rating,
+	})
 	return feedback, nil
 }
 
@@ -59,8 +77,16 @@ func (s *Service) CreateStory(ngoUserID uuid.UUID, req *CreateNGOImpactStoryRequ
 		Tags:          req.Tags,
 		Photo:         req.Photo,
 	}
+	photo, err := s.images.Resolve(ngoUserID, story.Photo, uploads.AssociatedNGOStory, story.ID)
+	if err != nil {
+		return nil, err
+	}
+	story.Photo = photo
 	if err := s.repo.CreateStory(story); err != nil {
 		return nil, err
 	}
+	if err := s.images.Sync(story.Photo, uploads.AssociatedNGOStory, story.ID); err != nil {
+		return nil, err
+	}
 	return story, nil
 }
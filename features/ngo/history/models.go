@@ -24,3 +24,6 @@ type NGODonationHistory struct {
 	Photo          string     `json:"photo,omitempty" db:"photo"`
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 }
+
+// StatusDelivered marks a donation that reached the NGO in full
+const StatusDelivered = "delivered"
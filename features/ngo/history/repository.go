@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"foodlink_backend/database"
 	"foodlink_backend/errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -21,7 +22,7 @@ func (r *Repository) GetAllByNGOUserID(ngoUserID uuid.UUID) ([]*NGODonationHisto
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	query := `SELECT id, ngo_user_id, offer_id, donor_name, donor_type, items_summary, weight_kg, meals_provided, co2_prevented_kg, beneficiaries, pickup_time, delivered_at, status, tags, photo, created_at FROM ngo_donation_history WHERE ngo_user_id = $1 ORDER BY pickup_time DESC, created_at DESC`
+	query := `SELECT id, ngo_user_id, offer_id, donor_name, donor_type, items_summary, weight_kg, meals_provided, co2_prevented_kg, beneficiaries, pickup_time, delivered_at, status, tags, COALESCE(photo, ''), created_at FROM ngo_donation_history WHERE ngo_user_id = $1 ORDER BY pickup_time DESC, created_at DESC`
 	rows, err := r.db.Query(query, ngoUserID)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
@@ -43,7 +44,7 @@ func (r *Repository) GetByID(id uuid.UUID) (*NGODonationHistory, error) {
 		return nil, errors.ErrDatabase
 	}
 	history := &NGODonationHistory{}
-	query := `SELECT id, ngo_user_id, offer_id, donor_name, donor_type, items_summary, weight_kg, meals_provided, co2_prevented_kg, beneficiaries, pickup_time, delivered_at, status, tags, photo, created_at FROM ngo_donation_history WHERE id = $1`
+	query := `SELECT id, ngo_user_id, offer_id, donor_name, donor_type, items_summary, weight_kg, meals_provided, co2_prevented_kg, beneficiaries, pickup_time, delivered_at, status, tags, COALESCE(photo, ''), created_at FROM ngo_donation_history WHERE id = $1`
 	err := r.db.QueryRow(query, id).Scan(&history.ID, &history.NGOUserID, &history.OfferID, &history.DonorName, &history.DonorType, &history.ItemsSummary, &history.WeightKg, &history.MealsProvided, &history.CO2PreventedKg, &history.Beneficiaries, &history.PickupTime, &history.DeliveredAt, &history.Status, pq.Array(&history.Tags), &history.Photo, &history.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -53,3 +54,15 @@ func (r *Repository) GetByID(id uuid.UUID) (*NGODonationHistory, error) {
 	}
 	return history, nil
 }
+
+// CreateTx records a delivery inside tx, so it commits together with the
+// pickup that produced it
+func CreateTx(tx *sql.Tx, history *NGODonationHistory) error {
+	query := `INSERT INTO ngo_donation_history (id, ngo_user_id, offer_id, donor_name, donor_type, items_summary, weight_kg, meals_provided, co2_prevented_kg, beneficiaries, pickup_time, delivered_at, status, tags, photo, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), $16)`
+	history.CreatedAt = time.Now()
+	_, err := tx.Exec(query, history.ID, history.NGOUserID, history.OfferID, history.DonorName, history.DonorType, history.ItemsSummary, history.WeightKg, history.MealsProvided, history.CO2PreventedKg, history.Beneficiaries, history.PickupTime, history.DeliveredAt, history.Status, pq.Array(history.Tags), history.Photo, history.CreatedAt)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package offers
+
+import (
+	"fmt"
+	"foodlink_backend/features/ngo/capacity"
+	"foodlink_backend/utils"
+	"math"
+	"strings"
+	"time"
+)
+
+// Auto-acceptance rule names, as reported in AutoAcceptCheck.Rule
+const (
+	RulePending     = "pending"
+	RuleNotExpired  = "not_expired"
+	RuleFoodType    = "food_type"
+	RuleDistance    = "max_distance"
+	RuleFreshness   = "min_freshness"
+	RuleNoPork      = "no_pork"
+	RuleTemperature = "temperature"
+	RuleCapacity    = "capacity"
+)
+
+// declineRules are the rules whose failure declines an offer outright rather
+// than leaving it for review
+var declineRules = map[string]bool{RuleCapacity: true, RuleNotExpired: true}
+
+// evaluateAutoAccept applies an NGO's auto-acceptance rules to an offer.
+// coldKg and ambientKg are the loads the NGO has already committed to its
+// refrigerated and dry storage.
+func evaluateAutoAccept(offer *NGODonationOffer, s *capacity.NGOCapacitySettings, coldKg, ambientKg float64, now time.Time) *AutoAcceptDecision {
+	rules := s.Rules()
+	d := &AutoAcceptDecision{OfferID: offer.ID, Enabled: rules.Enabled, Checks: []AutoAcceptCheck{}}
+	check := func(rule string, passed bool, detail string) {
+		d.Checks = append(d.Checks, AutoAcceptCheck{Rule: rule, Passed: passed, Detail: detail})
+	}
+
+	check(RulePending, offer.Status == StatusPending, "Offer is "+offer.Status)
+
+	if rules.RejectExpired {
+		if offer.ExpiresAt.After(now) {
+			check(RuleNotExpired, true, "Expires in "+formatDuration(offer.ExpiresAt.Sub(now).Truncate(time.Minute)))
+		} else {
+			check(RuleNotExpired, false, "Offer expired at "+offer.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+
+	types := offerFoodTypes(offer)
+	switch {
+	case len(rules.FoodTypes) == 0:
+		check(RuleFoodType, true, "Any food type is accepted")
+	case len(types) == 0:
+		check(RuleFoodType, false, "Offer does not state a food type")
+	default:
+		matched := ""
+		for _, t := range types {
+			if containsFold(rules.FoodTypes, t) {
+				matched = t
+				break
+			}
+		}
+		if matched != "" {
+			check(RuleFoodType, true, "Accepts "+matched+" food")
+		} else {
+			check(RuleFoodType, false, fmt.Sprintf("Offer is %s; accepts %s", strings.Join(types, ", "), strings.Join(rules.FoodTypes, ", ")))
+		}
+	}
+
+	if rules.MaxDistanceKm > 0 {
+		_, _, located := utils.ParseGeoPoint(offer.GeoPoint)
+		_, _, ngoLocated := utils.ParseGeoPoint(s.GeoPoint)
+		switch {
+		case !located || !ngoLocated:
+			check(RuleDistance, false, "Distance is unknown without both locations")
+		default:
+			check(RuleDistance, offer.DistanceKm <= rules.MaxDistanceKm, fmt.Sprintf("%.1f km away; limit is %.1f km", offer.DistanceKm, rules.MaxDistanceKm))
+		}
+	}
+
+	if rules.MinFreshnessScore > 0 {
+		check(RuleFreshness, offer.FreshnessScore >= rules.MinFreshnessScore, fmt.Sprintf("Freshness %d; minimum is %d", offer.FreshnessScore, rules.MinFreshnessScore))
+	}
+
+	if !rules.AllowPork {
+		if mentionsPork(offer) {
+			check(RuleNoPork, false, "Offer mentions pork")
+		} else {
+			check(RuleNoPork, true, "No pork mentioned")
+		}
+	}
+
+	storage, fits := fittingStorage(s, offer.StorageType)
+	if rules.TemperatureChecks && (offer.StorageType == "chilled" || offer.StorageType == "frozen") {
+		if fits && storage != "" {
+			check(RuleTemperature, true, offer.StorageType+" food goes to "+storage+" storage")
+		} else {
+			check(RuleTemperature, false, "No "+strings.Join(storageFits[offer.StorageType], " or ")+" storage for "+offer.StorageType+" food")
+		}
+	}
+
+	if !fits {
+		check(RuleCapacity, false, "No storage for "+offer.StorageType+" food")
+	} else {
+		remaining, pool := remainingCapacity(s, storage, coldKg, ambientKg)
+		if math.IsInf(remaining, 1) {
+			check(RuleCapacity, true, "No capacity limit is set")
+		} else {
+			check(RuleCapacity, offer.WeightKg <= remaining, fmt.Sprintf("Needs %.1f kg; %.1f kg of %s capacity free", offer.WeightKg, math.Max(0, remaining), pool))
+		}
+	}
+
+	d.Outcome, d.Reason = decide(d)
+	return d
+}
+
+// decide turns the checks into an outcome and a reason
+func decide(d *AutoAcceptDecision) (outcome, reason string) {
+	var failed, declined []string
+	for _, c := range d.Checks {
+		if c.Passed {
+			continue
+		}
+		if c.Rule == RulePending {
+			return OutcomeReview, c.Detail + "; only pending offers are auto-accepted"
+		}
+		failed = append(failed, c.Detail)
+		if declineRules[c.Rule] {
+			declined = append(declined, c.Detail)
+		}
+	}
+	switch {
+	case !d.Enabled:
+		return OutcomeReview, "Auto-acceptance is disabled"
+	case len(declined) > 0:
+		return OutcomeDecline, "Auto-declined: " + strings.Join(declined, "; ")
+	case len(failed) > 0:
+		return OutcomeReview, "Needs review: " + strings.Join(failed, "; ")
+	default:
+		return OutcomeAccept, "Auto-accepted: all rules passed"
+	}
+}
+
+// offerFoodTypes returns the food types stated on an offer's items
+func offerFoodTypes(offer *NGODonationOffer) []string {
+	var types []string
+	for _, item := range offer.ItemList() {
+		if t, ok := item["type"].(string); ok && t != "" && !containsFold(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func mentionsPork(offer *NGODonationOffer) bool {
+	text := offer.OfferTitle + " " + offer.DietaryNotes
+	for _, item := range offer.ItemList() {
+		if name, ok := item["name"].(string); ok {
+			text += " " + name
+		}
+	}
+	text = strings.ToLower(text)
+	return strings.Contains(text, "pork") && !strings.Contains(text, "pork-free") && !strings.Contains(text, "no pork")
+}
@@ -0,0 +1,25 @@
+package offers
+
+import (
+	"context"
+	"foodlink_backend/events"
+)
+
+const (
+	matcherSubscriber    = "ngo-offer-matcher"
+	autoAcceptSubscriber = "ngo-auto-accept"
+)
+
+// RegisterEventHandlers matches each new restaurant surplus item to NGOs and
+// runs auto-acceptance on each offer made. Offers are unique per item and NGO,
+// and only pending offers are auto-accepted, so redelivered events are harmless.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	events.On(bus, matcherSubscriber, func(ctx context.Context, e events.RestaurantSurplusAdded) error {
+		_, err := s.MatchSurplus(e.ItemID)
+		return err
+	})
+	events.On(bus, autoAcceptSubscriber, func(ctx context.Context, e events.OfferCreated) error {
+		_, err := s.AutoAccept(e.OfferID)
+		return err
+	})
+}
@@ -77,8 +77,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/offers/")
-	id, err := uuid.Parse(idStr)
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
 		return
@@ -107,6 +106,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 // @Failure      401  {object}  errors.AppError
 // @Failure      403  {object}  errors.AppError
 // @Failure      404  {object}  errors.AppError
+// @Failure      409  {object}  errors.AppError
 // @Router       /ngo/offers/{id}/accept [put]
 func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
@@ -118,7 +118,7 @@ func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/offers/"), "/")
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
 	if len(pathParts) < 2 || pathParts[1] != "accept" {
 		utils.BadRequestResponse(w, "Invalid path", nil)
 		return
@@ -152,6 +152,7 @@ func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
 // @Failure      401  {object}  errors.AppError
 // @Failure      403  {object}  errors.AppError
 // @Failure      404  {object}  errors.AppError
+// @Failure      409  {object}  errors.AppError
 // @Router       /ngo/offers/{id}/decline [put]
 func (h *Handler) Decline(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
@@ -163,7 +164,7 @@ func (h *Handler) Decline(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/offers/"), "/")
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
 	if len(pathParts) < 2 || pathParts[1] != "decline" {
 		utils.BadRequestResponse(w, "Invalid path", nil)
 		return
@@ -184,3 +185,94 @@ func (h *Handler) Decline(w http.ResponseWriter, r *http.Request) {
 	}
 	utils.OKResponse(w, "Offer declined successfully", offer)
 }
+
+// Complete handles PUT /api/v1/ngo/offers/:id/complete
+// @Summary      Complete offer
+// @Description  Mark an accepted donation offer as received, releasing its weight from current utilization
+// @Tags         ngo-offers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Offer ID"
+// @Success      200  {object}  NGODonationOffer
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Failure      409  {object}  errors.AppError
+// @Router       /ngo/offers/{id}/complete [put]
+func (h *Handler) Complete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	ngoUserID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) < 2 || pathParts[1] != "complete" {
+		utils.BadRequestResponse(w, "Invalid path", nil)
+		return
+	}
+	id, err := uuid.Parse(pathParts[0])
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	offer, err := h.service.Complete(id, ngoUserID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to complete offer", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Offer completed successfully", offer)
+}
+
+// AutoAcceptDryRun handles GET /api/v1/ngo/offers/:id/auto-accept
+// @Summary      Explain auto-acceptance
+// @Description  Evaluate the NGO's auto-acceptance rules against an offer without changing it
+// @Tags         ngo-offers
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Offer ID"
+// @Success      200  {object}  AutoAcceptDecision
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /ngo/offers/{id}/auto-accept [get]
+func (h *Handler) AutoAcceptDryRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	ngoUserID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) < 2 || pathParts[1] != "auto-accept" {
+		utils.BadRequestResponse(w, "Invalid path", nil)
+		return
+	}
+	id, err := uuid.Parse(pathParts[0])
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	decision, err := h.service.DryRunAutoAccept(id, ngoUserID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to evaluate auto-acceptance", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Auto-acceptance evaluated successfully", decision)
+}
@@ -0,0 +1,352 @@
+package offers
+
+import (
+	"fmt"
+	"foodlink_backend/features/ngo/capacity"
+	restaurant_surplus "foodlink_backend/features/restaurant/surplus"
+	"foodlink_backend/utils"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxOffersPerItem caps how many NGOs are offered the same surplus item
+const maxOffersPerItem = 3
+
+// Score weights. Each factor scores 0-1 and the weighted sum is scaled to 0-100.
+const (
+	weightFoodType = 0.30
+	weightCapacity = 0.25
+	weightDistance = 0.25
+	weightWindow   = 0.20
+)
+
+// neutralScore is used for a factor that cannot be judged, such as the
+// distance when either side has no geo point
+const neutralScore = 0.5
+
+// fullOverlapMinutes is the pickup window overlap that earns the full window score
+const fullOverlapMinutes = 120
+
+// shelfLife is how long surplus in each storage type stays good enough to offer
+var shelfLife = map[string]time.Duration{
+	"fresh":   12 * time.Hour,
+	"chilled": 48 * time.Hour,
+	"frozen":  7 * 24 * time.Hour,
+}
+
+// storageFits lists the NGO storage types that can hold each surplus storage type
+var storageFits = map[string][]string{
+	"fresh":   {"dry", "refrigerated"},
+	"chilled": {"refrigerated"},
+	"frozen":  {"frozen"},
+}
+
+// foodTypeKeywords maps words in a surplus item's category and tags to the
+// food types NGOs set as preferences
+var foodTypeKeywords = []struct {
+	foodType string
+	keywords []string
+}{
+	{"cooked", []string{"cooked", "prepared", "meal", "curry", "rice", "soup", "stew", "biryani"}},
+	{"bakery", []string{"bakery", "bread", "pastry", "pastries", "cake", "bun", "roll"}},
+	{"produce", []string{"produce", "vegetable", "fruit", "salad", "greens"}},
+	{"protein", []string{"protein", "meat", "poultry", "chicken", "beef", "mutton", "fish", "seafood", "egg", "dairy", "lentil", "dal"}},
+	{"raw", []string{"raw", "grain", "flour", "pantry", "uncooked"}},
+}
+
+// candidate is an NGO that can take a surplus item, with its score and the
+// reasons behind it
+type candidate struct {
+	settings   *capacity.NGOCapacitySettings
+	score      float64
+	distanceKm float64
+	reasons    []string
+}
+
+// surplusFacts are the properties of a surplus item the matcher compares
+type surplusFacts struct {
+	item     *restaurant_surplus.RestaurantSurplusItem
+	weightKg float64
+	foodType string
+	text     string
+}
+
+// rankCandidates scores every NGO against the item and returns the best
+// candidates, highest score first. cold and ambient are the loads each NGO has
+// already committed to its refrigerated and dry storage.
+func rankCandidates(facts surplusFacts, all []*capacity.NGOCapacitySettings, cold, ambient map[uuid.UUID]float64, now time.Time) []*candidate {
+	var candidates []*candidate
+	for _, settings := range all {
+		if c, ok := evaluate(facts, settings, cold[settings.UserID], ambient[settings.UserID], now); ok {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if candidates[i].distanceKm != candidates[j].distanceKm {
+			return candidates[i].distanceKm < candidates[j].distanceKm
+		}
+		return candidates[i].settings.UserID.String() < candidates[j].settings.UserID.String()
+	})
+	if len(candidates) > maxOffersPerItem {
+		candidates = candidates[:maxOffersPerItem]
+	}
+	return candidates
+}
+
+// evaluate scores one NGO for the item. ok is false when the NGO cannot take
+// it: a restricted item, no suitable storage, not enough capacity, too far
+// away or no overlap between the pickup windows.
+func evaluate(facts surplusFacts, s *capacity.NGOCapacitySettings, coldKg, ambientKg float64, now time.Time) (*candidate, bool) {
+	c := &candidate{settings: s}
+	item := facts.item
+
+	for _, restricted := range s.RestrictedItems {
+		if term := strings.ToLower(strings.TrimSpace(restricted)); term != "" && strings.Contains(facts.text, term) {
+			return nil, false
+		}
+	}
+
+	// Food type preference
+	var foodScore float64
+	switch {
+	case len(s.PreferredFoodTypes) == 0:
+		foodScore = neutralScore
+		c.reasons = append(c.reasons, "accepts any food type")
+	case facts.foodType == "":
+		foodScore = neutralScore
+	case containsFold(s.PreferredFoodTypes, facts.foodType):
+		foodScore = 1
+		c.reasons = append(c.reasons, "prefers "+facts.foodType+" food")
+	default:
+		foodScore = 0.2
+	}
+
+	// Storage type and remaining capacity
+	storage, ok := fittingStorage(s, item.StorageType)
+	if !ok {
+		return nil, false
+	}
+	remaining, poolLabel := remainingCapacity(s, storage, coldKg, ambientKg)
+	if facts.weightKg > remaining {
+		return nil, false
+	}
+	capacityScore := neutralScore
+	if !math.IsInf(remaining, 1) && remaining > 0 {
+		capacityScore = (remaining - facts.weightKg) / remaining
+		c.reasons = append(c.reasons, fmt.Sprintf("%s capacity for %.1f kg with %.1f kg free", poolLabel, facts.weightKg, remaining))
+	} else if storage != "" {
+		c.reasons = append(c.reasons, "has "+storage+" storage")
+	}
+
+	// Distance against the preferred pickup radius
+	distanceScore := neutralScore
+	itemLat, itemLng, itemOK := utils.ParseGeoPoint(item.GeoPoint)
+	ngoLat, ngoLng, ngoOK := utils.ParseGeoPoint(s.GeoPoint)
+	if itemOK && ngoOK {
+		c.distanceKm = utils.HaversineKm(itemLat, itemLng, ngoLat, ngoLng)
+		if s.PreferredPickupRadiusKm > 0 {
+			if c.distanceKm > s.PreferredPickupRadiusKm {
+				return nil, false
+			}
+			distanceScore = 1 - c.distanceKm/s.PreferredPickupRadiusKm
+			c.reasons = append(c.reasons, fmt.Sprintf("%.1f km away, within its %.0f km radius", c.distanceKm, s.PreferredPickupRadiusKm))
+		} else {
+			c.reasons = append(c.reasons, fmt.Sprintf("%.1f km away", c.distanceKm))
+		}
+	}
+
+	// Pickup window overlap
+	windowScore := neutralScore
+	if overlap, ok := windowOverlap(item.PickupWindow, s.PickupWindow, now); ok {
+		if overlap <= 0 {
+			return nil, false
+		}
+		windowScore = math.Min(1, overlap.Minutes()/fullOverlapMinutes)
+		c.reasons = append(c.reasons, "pickup windows overlap by "+formatDuration(overlap))
+	}
+
+	c.score = 100 * (weightFoodType*foodScore + weightCapacity*capacityScore + weightDistance*distanceScore + weightWindow*windowScore)
+	c.score = math.Round(c.score*100) / 100
+	c.distanceKm = math.Round(c.distanceKm*100) / 100
+	return c, true
+}
+
+// fittingStorage returns the NGO storage type that can hold surplus of the
+// given storage type. An NGO that lists no storage types is assumed to handle
+// anything, so storage is empty and ok is true.
+func fittingStorage(s *capacity.NGOCapacitySettings, storageType string) (storage string, ok bool) {
+	if len(s.StorageTypes) == 0 {
+		return "", true
+	}
+	for _, fit := range storageFits[storageType] {
+		if containsFold(s.StorageTypes, fit) {
+			return fit, true
+		}
+	}
+	return "", false
+}
+
+// remainingCapacity returns how many kg an NGO can still take into storage,
+// and which limit is the tightest. The daily capacity always applies, less the
+// current utilization; refrigerated and dry capacity apply when set, less the
+// load already committed to them. remaining is +Inf when nothing is limited.
+func remainingCapacity(s *capacity.NGOCapacitySettings, storage string, coldKg, ambientKg float64) (remaining float64, pool string) {
+	remaining, pool = math.Inf(1), "daily"
+	if s.DailyCapacityKg > 0 {
+		remaining = s.DailyCapacityKg - s.CurrentUtilizationKg
+	}
+	switch storage {
+	case "refrigerated", "frozen":
+		if s.RefrigeratedCapacityKg > 0 && s.RefrigeratedCapacityKg-coldKg < remaining {
+			remaining, pool = s.RefrigeratedCapacityKg-coldKg, storage
+		}
+	case "dry":
+		if s.DryCapacityKg > 0 && s.DryCapacityKg-ambientKg < remaining {
+			remaining, pool = s.DryCapacityKg-ambientKg, "dry"
+		}
+	}
+	return remaining, pool
+}
+
+// foodTypeOf infers the NGO food type of a surplus item from its category and tags
+func foodTypeOf(item *restaurant_surplus.RestaurantSurplusItem) string {
+	text := strings.ToLower(item.Category + " " + strings.Join(item.Tags, " "))
+	for _, ft := range foodTypeKeywords {
+		for _, keyword := range ft.keywords {
+			if strings.Contains(text, keyword) {
+				return ft.foodType
+			}
+		}
+	}
+	return ""
+}
+
+// expiryOf returns when an offer for the item lapses: the end of its pickup
+// window, or the storage type's shelf life when the window has no usable end
+func expiryOf(item *restaurant_surplus.RestaurantSurplusItem, now time.Time) time.Time {
+	if _, end, ok := windowBounds(item.PickupWindow, now); ok {
+		endStr, _ := item.PickupWindow["end"].(string)
+		if _, err := time.Parse(time.RFC3339, strings.TrimSpace(endStr)); err != nil && !end.After(now) {
+			// A daily window that has closed today opens again tomorrow
+			end = end.Add(24 * time.Hour)
+		}
+		if end.After(now) {
+			return end
+		}
+	}
+	life, ok := shelfLife[item.StorageType]
+	if !ok {
+		life = shelfLife["fresh"]
+	}
+	return now.Add(life)
+}
+
+// urgencyOf grades how soon an offer must be picked up
+func urgencyOf(expiresAt, now time.Time) string {
+	switch left := expiresAt.Sub(now); {
+	case left <= 6*time.Hour:
+		return UrgencyHigh
+	case left <= 24*time.Hour:
+		return UrgencyMedium
+	default:
+		return UrgencyLow
+	}
+}
+
+// freshnessOf scores 0-100 how much of its storage type's shelf life the item has left
+func freshnessOf(storageType string, expiresAt, now time.Time) int {
+	life, ok := shelfLife[storageType]
+	if !ok {
+		life = shelfLife["fresh"]
+	}
+	score := 100 * expiresAt.Sub(now).Hours() / life.Hours()
+	return int(math.Max(0, math.Min(100, math.Round(score))))
+}
+
+// windowOverlap returns how long two pickup windows overlap. Windows given as
+// clock times repeat daily, so they are compared as times of day; ok is false
+// when either window cannot be parsed.
+func windowOverlap(a, b map[string]interface{}, now time.Time) (time.Duration, bool) {
+	aStart, aEnd, aOK := windowBounds(a, now)
+	bStart, bEnd, bOK := windowBounds(b, now)
+	if !aOK || !bOK {
+		return 0, false
+	}
+	as, ae := minuteOfDay(aStart), minuteOfDay(aStart)+int(aEnd.Sub(aStart).Minutes())
+	bs, be := minuteOfDay(bStart), minuteOfDay(bStart)+int(bEnd.Sub(bStart).Minutes())
+
+	best := 0
+	for _, shift := range []int{-1440, 0, 1440} {
+		if overlap := min(ae, be+shift) - max(as, bs+shift); overlap > best {
+			best = overlap
+		}
+	}
+	return time.Duration(best) * time.Minute, true
+}
+
+// windowBounds parses a {start, end} window. Each bound may be an RFC 3339
+// timestamp or a clock time such as "18:00" or "6:30 PM", which is placed on
+// now's date. An end before its start is taken to run past midnight.
+func windowBounds(window map[string]interface{}, now time.Time) (start, end time.Time, ok bool) {
+	startStr, _ := window["start"].(string)
+	endStr, _ := window["end"].(string)
+	start, startOK := parseWindowTime(startStr, now)
+	end, endOK := parseWindowTime(endStr, now)
+	if !startOK || !endOK {
+		return time.Time{}, time.Time{}, false
+	}
+	if !end.After(start) {
+		end = end.Add(24 * time.Hour)
+	}
+	return start, end, true
+}
+
+var clockLayouts = []string{"15:04", "15:04:05", "3:04 PM", "3:04PM", "3 PM", "3PM", "3:04 pm", "3:04pm", "3 pm", "3pm"}
+
+func parseWindowTime(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(now.Location()), true
+	}
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location()), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func minuteOfDay(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+func formatDuration(d time.Duration) string {
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	}
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -52,6 +52,80 @@ type NGODonationOffer struct {
 	Images        []string  `json:"images,omitempty" db:"images"`
 	Status        string    `json:"status" db:"status"`
 	MatchReason   string    `json:"match_reason,omitempty" db:"match_reason"`
+	MatchScore    float64   `json:"match_score,omitempty" db:"match_score"`
+	SurplusItemID *uuid.UUID `json:"surplus_item_id,omitempty" db:"surplus_item_id"`
+	StorageType   string    `json:"storage_type,omitempty" db:"storage_type"`
+	DecisionReason string   `json:"decision_reason,omitempty" db:"decision_reason"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// Offer statuses. Pending offers are withdrawn when another NGO accepts the
+// same surplus item first.
+const (
+	StatusPending   = "pending"
+	StatusAccepted  = "accepted"
+	StatusDeclined  = "declined"
+	StatusScheduled = "scheduled"
+	StatusCompleted = "completed"
+	StatusWithdrawn = "withdrawn"
+)
+
+// Urgency levels
+const (
+	UrgencyLow    = "low"
+	UrgencyMedium = "medium"
+	UrgencyHigh   = "high"
+)
+
+// DonorTypeRestaurant marks offers made for restaurant surplus
+const DonorTypeRestaurant = "restaurant"
+
+// Auto-acceptance outcomes. Offers that fail a rule other than capacity or
+// expiry are left pending for the NGO to review.
+const (
+	OutcomeAccept  = "accept"
+	OutcomeDecline = "decline"
+	OutcomeReview  = "review"
+)
+
+// AutoAcceptCheck is the result of one auto-acceptance rule
+type AutoAcceptCheck struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// AutoAcceptDecision explains what auto-acceptance does, or would do, with an offer
+type AutoAcceptDecision struct {
+	OfferID uuid.UUID         `json:"offer_id"`
+	Enabled bool              `json:"enabled"`
+	Outcome string            `json:"outcome"`
+	Reason  string            `json:"reason"`
+	Checks  []AutoAcceptCheck `json:"checks"`
+}
+
+// ItemList reads the items of an offer, stored either as {"items": [...]} or
+// as a single item object
+func (offer *NGODonationOffer) ItemList() []map[string]interface{} {
+	list, ok := offer.Items["items"].([]interface{})
+	if !ok {
+		if len(offer.Items) == 0 {
+			return nil
+		}
+		return []map[string]interface{}{offer.Items}
+	}
+	var items []map[string]interface{}
+	for _, entry := range list {
+		if item, ok := entry.(map[string]interface{}); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// PickupWindowOn returns the offer's pickup window on the given day. Windows
+// given as clock times repeat daily; dated windows keep their own date.
+func (offer *NGODonationOffer) PickupWindowOn(day time.Time) (start, end time.Time, ok bool) {
+	return windowBounds(offer.PickupWindow, day)
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/lib/pq"
 )
 
+// offerColumns lists the columns scanned by scanOffer
+const offerColumns = `id, ngo_user_id, donor_name, donor_type, partner_id, distance_km, location_label, geo_point, offer_title, items, weight_kg, COALESCE(meals_estimated, 0), COALESCE(freshness_score, 0), pickup_window, expires_at, COALESCE(urgency_level, 'medium'), COALESCE(dietary_notes, ''), safety_flags, contact, images, status, COALESCE(match_reason, ''), COALESCE(match_score, 0), surplus_item_id, COALESCE(storage_type, ''), COALESCE(decision_reason, ''), created_at, updated_at`
+
 type Repository struct {
 	db *sql.DB
 }
@@ -26,10 +29,10 @@ func (r *Repository) GetAllByNGOUserID(ngoUserID uuid.UUID, status string) ([]*N
 	var rows *sql.Rows
 	var err error
 	if status != "" {
-		query = `SELECT id, ngo_user_id, donor_name, donor_type, partner_id, distance_km, location_label, geo_point, offer_title, items, weight_kg, meals_estimated, freshness_score, pickup_window, expires_at, urgency_level, dietary_notes, safety_flags, contact, images, status, match_reason, created_at, updated_at FROM ngo_donation_offers WHERE ngo_user_id = $1 AND status = $2 ORDER BY created_at DESC`
+		query = `SELECT ` + offerColumns + ` FROM ngo_donation_offers WHERE ngo_user_id = $1 AND status = $2 ORDER BY created_at DESC`
 		rows, err = r.db.Query(query, ngoUserID, status)
 	} else {
-		query = `SELECT id, ngo_user_id, donor_name, donor_type, partner_id, distance_km, location_label, geo_point, offer_title, items, weight_kg, meals_estimated, freshness_score, pickup_window, expires_at, urgency_level, dietary_notes, safety_flags, contact, images, status, match_reason, created_at, updated_at FROM ngo_donation_offers WHERE ngo_user_id = $1 ORDER BY created_at DESC`
+		query = `SELECT ` + offerColumns + ` FROM ngo_donation_offers WHERE ngo_user_id = $1 ORDER BY created_at DESC`
 		rows, err = r.db.Query(query, ngoUserID)
 	}
 	if err != nil {
@@ -38,23 +41,10 @@ func (r *Repository) GetAllByNGOUserID(ngoUserID uuid.UUID, status string) ([]*N
 	defer rows.Close()
 	var offers []*NGODonationOffer
 	for rows.Next() {
-		offer := &NGODonationOffer{}
-		var geoPointJSON, itemsJSON, pickupWindowJSON, contactJSON []byte
-		if err := rows.Scan(&offer.ID, &offer.NGOUserID, &offer.DonorName, &offer.DonorType, &offer.PartnerID, &offer.DistanceKm, &offer.LocationLabel, &geoPointJSON, &offer.OfferTitle, &itemsJSON, &offer.WeightKg, &offer.MealsEstimated, &offer.FreshnessScore, &pickupWindowJSON, &offer.ExpiresAt, &offer.UrgencyLevel, &offer.DietaryNotes, pq.Array(&offer.SafetyFlags), &contactJSON, pq.Array(&offer.Images), &offer.Status, &offer.MatchReason, &offer.CreatedAt, &offer.UpdatedAt); err != nil {
+		offer, err := scanOffer(rows)
+		if err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
-		if len(geoPointJSON) > 0 {
-			json.Unmarshal(geoPointJSON, &offer.GeoPoint)
-		}
-		if len(itemsJSON) > 0 {
-			json.Unmarshal(itemsJSON, &offer.Items)
-		}
-		if len(pickupWindowJSON) > 0 {
-			json.Unmarshal(pickupWindowJSON, &offer.PickupWindow)
-		}
-		if len(contactJSON) > 0 {
-			json.Unmarshal(contactJSON, &offer.Contact)
-		}
 		offers = append(offers, offer)
 	}
 	return offers, nil
@@ -64,43 +54,320 @@ func (r *Repository) GetByID(id uuid.UUID) (*NGODonationOffer, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	offer := &NGODonationOffer{}
-	var geoPointJSON, itemsJSON, pickupWindowJSON, contactJSON []byte
-	query := `SELECT id, ngo_user_id, donor_name, donor_type, partner_id, distance_km, location_label, geo_point, offer_title, items, weight_kg, meals_estimated, freshness_score, pickup_window, expires_at, urgency_level, dietary_notes, safety_flags, contact, images, status, match_reason, created_at, updated_at FROM ngo_donation_offers WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&offer.ID, &offer.NGOUserID, &offer.DonorName, &offer.DonorType, &offer.PartnerID, &offer.DistanceKm, &offer.LocationLabel, &geoPointJSON, &offer.OfferTitle, &itemsJSON, &offer.WeightKg, &offer.MealsEstimated, &offer.FreshnessScore, &pickupWindowJSON, &offer.ExpiresAt, &offer.UrgencyLevel, &offer.DietaryNotes, pq.Array(&offer.SafetyFlags), &contactJSON, pq.Array(&offer.Images), &offer.Status, &offer.MatchReason, &offer.CreatedAt, &offer.UpdatedAt)
+	query := `SELECT ` + offerColumns + ` FROM ngo_donation_offers WHERE id = $1`
+	offer, err := scanOffer(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
 		}
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(geoPointJSON) > 0 {
-		json.Unmarshal(geoPointJSON, &offer.GeoPoint)
+	return offer, nil
+}
+
+// Create inserts a matched offer. created is false when the NGO already has an
+// offer for the same surplus item.
+func (r *Repository) Create(offer *NGODonationOffer) (created bool, err error) {
+	if r.db == nil {
+		return false, errors.ErrDatabase
 	}
-	if len(itemsJSON) > 0 {
-		json.Unmarshal(itemsJSON, &offer.Items)
+	itemsJSON, _ := json.Marshal(offer.Items)
+	pickupWindowJSON, _ := json.Marshal(offer.PickupWindow)
+	contactJSON, _ := json.Marshal(offer.Contact)
+	query := `INSERT INTO ngo_donation_offers (id, ngo_user_id, donor_name, donor_type, partner_id, distance_km, location_label, geo_point, offer_title, items, weight_kg, meals_estimated, freshness_score, pickup_window, expires_at, urgency_level, dietary_notes, safety_flags, contact, images, status, match_reason, match_score, surplus_item_id, storage_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NULLIF($17, ''), $18, $19, $20, $21, NULLIF($22, ''), $23, $24, NULLIF($25, ''))
+		ON CONFLICT (surplus_item_id, ngo_user_id) WHERE surplus_item_id IS NOT NULL DO NOTHING
+		RETURNING ` + offerColumns
+	saved, err := scanOffer(r.db.QueryRow(query, offer.ID, offer.NGOUserID, offer.DonorName, offer.DonorType, offer.PartnerID, offer.DistanceKm, offer.LocationLabel, offer.GeoPoint, offer.OfferTitle, itemsJSON, offer.WeightKg, offer.MealsEstimated, offer.FreshnessScore, pickupWindowJSON, offer.ExpiresAt, offer.UrgencyLevel, offer.DietaryNotes, pq.Array(offer.SafetyFlags), contactJSON, pq.Array(offer.Images), offer.Status, offer.MatchReason, offer.MatchScore, offer.SurplusItemID, offer.StorageType))
+	if err == sql.ErrNoRows {
+		return false, nil
 	}
-	if len(pickupWindowJSON) > 0 {
-		json.Unmarshal(pickupWindowJSON, &offer.PickupWindow)
+	if err != nil {
+		return false, errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(contactJSON) > 0 {
-		json.Unmarshal(contactJSON, &offer.Contact)
+	*offer = *saved
+	return true, nil
+}
+
+// CommittedLoad returns the weight each NGO has accepted or scheduled but not
+// yet received, split into cold (chilled and frozen) and ambient storage
+func (r *Repository) CommittedLoad() (cold, ambient map[uuid.UUID]float64, err error) {
+	if r.db == nil {
+		return nil, nil, errors.ErrDatabase
 	}
-	return offer, nil
+	rows, err := r.db.Query(`
+		SELECT ngo_user_id,
+			COALESCE(SUM(weight_kg) FILTER (WHERE storage_type IN ('chilled', 'frozen')), 0),
+			COALESCE(SUM(weight_kg) FILTER (WHERE storage_type IS NULL OR storage_type NOT IN ('chilled', 'frozen')), 0)
+		FROM ngo_donation_offers
+		WHERE status IN ($1, $2)
+		GROUP BY ngo_user_id
+	`, StatusAccepted, StatusScheduled)
+	if err != nil {
+		return nil, nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	cold = make(map[uuid.UUID]float64)
+	ambient = make(map[uuid.UUID]float64)
+	for rows.Next() {
+		var ngoUserID uuid.UUID
+		var coldKg, ambientKg float64
+		if err := rows.Scan(&ngoUserID, &coldKg, &ambientKg); err != nil {
+			return nil, nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		cold[ngoUserID] = coldKg
+		ambient[ngoUserID] = ambientKg
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return cold, ambient, nil
+}
+
+// GetDonor returns the name and email of the user donating the surplus
+func (r *Repository) GetDonor(userID uuid.UUID) (name, email string, err error) {
+	if r.db == nil {
+		return "", "", errors.ErrDatabase
+	}
+	err = r.db.QueryRow(`SELECT name, email FROM users WHERE id = $1`, userID).Scan(&name, &email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", "", errors.ErrUserNotFound
+		}
+		return "", "", errors.WrapError(err, errors.ErrDatabase)
+	}
+	return name, email, nil
+}
+
+// Accept marks a pending offer accepted and adds its weight to the NGO's
+// current utilization. For matched offers it also claims the surplus item for
+// the NGO and withdraws the other NGOs' pending offers for it, all in one
+// transaction so two NGOs cannot claim the same item. With enforceCapacity the
+// NGO's daily capacity is rechecked under lock, so concurrent auto-accepts
+// cannot overfill it.
+func (r *Repository) Accept(offer *NGODonationOffer, reason string, enforceCapacity bool) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	var dailyKg, utilizationKg float64
+	err = tx.QueryRow(`SELECT daily_capacity_kg, COALESCE(current_utilization_kg, 0) FROM ngo_capacity_settings WHERE user_id = $1 FOR UPDATE`, offer.NGOUserID).Scan(&dailyKg, &utilizationKg)
+	if err != nil && err != sql.ErrNoRows {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if enforceCapacity && dailyKg > 0 && utilizationKg+offer.WeightKg > dailyKg {
+		return errCapacityExceeded
+	}
+
+	if offer.SurplusItemID != nil {
+		var status string
+		var assignedTo sql.NullString
+		err := tx.QueryRow(`SELECT status, assigned_to FROM restaurant_surplus_items WHERE id = $1 FOR UPDATE`, *offer.SurplusItemID).Scan(&status, &assignedTo)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.ErrNotFound
+			}
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+		if status != "pending" || assignedTo.Valid {
+			return errSurplusUnavailable
+		}
+		_, err = tx.Exec(`
+			UPDATE restaurant_surplus_items
+			SET status = 'claimed', assigned_to = 'ngo',
+				recipient_name = COALESCE((SELECT org_name FROM ngo_capacity_settings WHERE user_id = $2), (SELECT name FROM users WHERE id = $2)),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, *offer.SurplusItemID, offer.NGOUserID)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+		_, err = tx.Exec(`UPDATE ngo_donation_offers SET status = $1 WHERE surplus_item_id = $2 AND id <> $3 AND status = $4`, StatusWithdrawn, *offer.SurplusItemID, offer.ID, StatusPending)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+	}
+
+	result, err := tx.Exec(`UPDATE ngo_donation_offers SET status = $1, decision_reason = NULLIF($2, '') WHERE id = $3 AND status = $4`, StatusAccepted, reason, offer.ID, StatusPending)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return errOfferNotPending
+	}
+	_, err = tx.Exec(`UPDATE ngo_capacity_settings SET current_utilization_kg = COALESCE(current_utilization_kg, 0) + $2 WHERE user_id = $1`, offer.NGOUserID, offer.WeightKg)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// Decline marks a pending offer declined, recording the reason if one is given
+func (r *Repository) Decline(id uuid.UUID, reason string) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	result, err := r.db.Exec(`UPDATE ngo_donation_offers SET status = $1, decision_reason = NULLIF($2, '') WHERE id = $3 AND status = $4`, StatusDeclined, reason, id, StatusPending)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return errOfferNotPending
+	}
+	return nil
+}
+
+// SetDecisionReason records why a pending offer was left for review
+func (r *Repository) SetDecisionReason(id uuid.UUID, reason string) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	_, err := r.db.Exec(`UPDATE ngo_donation_offers SET decision_reason = $1 WHERE id = $2 AND status = $3`, reason, id, StatusPending)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
 }
 
-func (r *Repository) UpdateStatus(id uuid.UUID, status string) error {
+// Complete marks an accepted or scheduled offer completed and releases its
+// weight from the NGO's current utilization
+func (r *Repository) Complete(id uuid.UUID) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `UPDATE ngo_donation_offers SET status=$1, updated_at=CURRENT_TIMESTAMP WHERE id=$2`
-	result, err := r.db.Exec(query, status, id)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+	if err := CompleteTx(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// CompleteTx completes an offer inside tx, so callers can complete it together
+// with their own changes. The offer's surplus item is marked picked up.
+// Completing an offer that is already completed is a no-op.
+func CompleteTx(tx *sql.Tx, offerID uuid.UUID) error {
+	var ngoUserID uuid.UUID
+	var weightKg float64
+	var surplusItemID *uuid.UUID
+	err := tx.QueryRow(`
+		UPDATE ngo_donation_offers SET status = $1
+		WHERE id = $2 AND status IN ($3, $4)
+		RETURNING ngo_user_id, weight_kg, surplus_item_id
+	`, StatusCompleted, offerID, StatusAccepted, StatusScheduled).Scan(&ngoUserID, &weightKg, &surplusItemID)
+	if err == sql.ErrNoRows {
+		var status string
+		if err := tx.QueryRow(`SELECT status FROM ngo_donation_offers WHERE id = $1`, offerID).Scan(&status); err != nil {
+			if err == sql.ErrNoRows {
+				return errors.ErrNotFound
+			}
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+		if status == StatusCompleted {
+			return nil
+		}
+		return errOfferNotAccepted
+	}
 	if err != nil {
 		return errors.WrapError(err, errors.ErrDatabase)
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return errors.ErrNotFound
+	_, err = tx.Exec(`UPDATE ngo_capacity_settings SET current_utilization_kg = GREATEST(COALESCE(current_utilization_kg, 0) - $2, 0) WHERE user_id = $1`, ngoUserID, weightKg)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if surplusItemID != nil {
+		_, err = tx.Exec(`UPDATE restaurant_surplus_items SET status = 'picked-up', updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = 'claimed'`, *surplusItemID)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
 	}
 	return nil
 }
+
+// ScheduleTx marks an accepted offer as scheduled for pickup inside tx, so it
+// commits together with the pickup that collects it
+func ScheduleTx(tx *sql.Tx, offerID uuid.UUID) error {
+	result, err := tx.Exec(`UPDATE ngo_donation_offers SET status = $1 WHERE id = $2 AND status = $3`, StatusScheduled, offerID, StatusAccepted)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return errOfferNotSchedulable
+	}
+	return nil
+}
+
+// ReleaseTx returns an accepted or scheduled offer to pending inside tx, taking
+// its weight back off the NGO's current utilization and freeing the surplus
+// item it claimed. Releasing an offer that is no longer accepted or scheduled
+// is a no-op.
+func ReleaseTx(tx *sql.Tx, offerID uuid.UUID) error {
+	var ngoUserID uuid.UUID
+	var weightKg float64
+	var surplusItemID *uuid.UUID
+	err := tx.QueryRow(`
+		UPDATE ngo_donation_offers SET status = $1
+		WHERE id = $2 AND status IN ($3, $4)
+		RETURNING ngo_user_id, weight_kg, surplus_item_id
+	`, StatusPending, offerID, StatusAccepted, StatusScheduled).Scan(&ngoUserID, &weightKg, &surplusItemID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	_, err = tx.Exec(`UPDATE ngo_capacity_settings SET current_utilization_kg = GREATEST(COALESCE(current_utilization_kg, 0) - $2, 0) WHERE user_id = $1`, ngoUserID, weightKg)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if surplusItemID != nil {
+		_, err = tx.Exec(`
+			UPDATE restaurant_surplus_items
+			SET status = 'pending', assigned_to = NULL, recipient_name = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND status = 'claimed' AND assigned_to = 'ngo'
+		`, *surplusItemID)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOffer(row rowScanner) (*NGODonationOffer, error) {
+	offer := &NGODonationOffer{}
+	var geoPointJSON, itemsJSON, pickupWindowJSON, contactJSON []byte
+	if err := row.Scan(&offer.ID, &offer.NGOUserID, &offer.DonorName, &offer.DonorType, &offer.PartnerID, &offer.DistanceKm, &offer.LocationLabel, &geoPointJSON, &offer.OfferTitle, &itemsJSON, &offer.WeightKg, &offer.MealsEstimated, &offer.FreshnessScore, &pickupWindowJSON, &offer.ExpiresAt, &offer.UrgencyLevel, &offer.DietaryNotes, pq.Array(&offer.SafetyFlags), &contactJSON, pq.Array(&offer.Images), &offer.Status, &offer.MatchReason, &offer.MatchScore, &offer.SurplusItemID, &offer.StorageType, &offer.DecisionReason, &offer.CreatedAt, &offer.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(geoPointJSON) > 0 {
+		json.Unmarshal(geoPointJSON, &offer.GeoPoint)
+	}
+	if len(itemsJSON) > 0 {
+		json.Unmarshal(itemsJSON, &offer.Items)
+	}
+	if len(pickupWindowJSON) > 0 {
+		json.Unmarshal(pickupWindowJSON, &offer.PickupWindow)
+	}
+	if len(contactJSON) > 0 {
+		json.Unmarshal(contactJSON, &offer.Contact)
+	}
+	return offer, nil
+}
@@ -9,7 +9,7 @@ import (
 func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/offers/")
+		path := strings.Trim(r.URL.Path, "/")
 		pathParts := strings.Split(path, "/")
 		switch {
 		case path == "" && r.Method == http.MethodGet:
@@ -18,6 +18,10 @@ func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Ha
 			handler.Accept(w, r)
 		case len(pathParts) == 2 && pathParts[1] == "decline" && r.Method == http.MethodPut:
 			handler.Decline(w, r)
+		case len(pathParts) == 2 && pathParts[1] == "complete" && r.Method == http.MethodPut:
+			handler.Complete(w, r)
+		case len(pathParts) == 2 && pathParts[1] == "auto-accept" && r.Method == http.MethodGet:
+			handler.AutoAcceptDryRun(w, r)
 		case len(pathParts) == 1 && len(pathParts[0]) == 36 && r.Method == http.MethodGet:
 			handler.GetByID(w, r)
 		default:
@@ -2,16 +2,40 @@ package offers
 
 import (
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/impact"
+	"foodlink_backend/features/ngo/capacity"
+	restaurant_surplus "foodlink_backend/features/restaurant/surplus"
+	"log"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	errOfferNotPending     = errors.NewAppError(http.StatusConflict, "Offer is no longer pending")
+	errOfferNotAccepted    = errors.NewAppError(http.StatusConflict, "Only accepted or scheduled offers can be completed")
+	errOfferNotSchedulable = errors.NewAppError(http.StatusConflict, "Only accepted offers can be scheduled")
+	errSurplusUnavailable  = errors.NewAppError(http.StatusConflict, "Surplus item has already been claimed")
+	errCapacityExceeded    = errors.NewAppError(http.StatusConflict, "Accepting this offer would exceed the daily capacity")
+)
+
 type Service struct {
-	repo *Repository
+	repo     *Repository
+	capacity *capacity.Service
+	surplus  *restaurant_surplus.Service
+	impact   *impact.Service
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{
+		repo:     NewRepository(),
+		capacity: capacity.NewService(),
+		surplus:  restaurant_surplus.NewService(),
+		impact:   impact.NewService(),
+	}
 }
 
 func (s *Service) GetAllByNGOUserID(ngoUserID uuid.UUID, status string) ([]*NGODonationOffer, error) {
@@ -22,6 +46,66 @@ func (s *Service) GetByID(id uuid.UUID) (*NGODonationOffer, error) {
 	return s.repo.GetByID(id)
 }
 
+// MatchSurplus scores every NGO's capacity settings against a restaurant surplus
+// item and offers it to the best candidates. Items that are no longer pending
+// are skipped, and NGOs that already have an offer for the item keep it, so
+// matching an item again only fills in missing offers.
+func (s *Service) MatchSurplus(itemID uuid.UUID) ([]*NGODonationOffer, error) {
+	item, err := s.surplus.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != restaurant_surplus.StatusPending || item.AssignedTo != "" {
+		return nil, nil
+	}
+
+	settings, err := s.capacity.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	cold, ambient, err := s.repo.CommittedLoad()
+	if err != nil {
+		return nil, err
+	}
+	donorName, donorEmail, err := s.repo.GetDonor(item.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	measure := s.impact.Measure(item.Category, item.Quantity, item.Unit)
+	facts := surplusFacts{
+		item:     item,
+		weightKg: measure.Kg,
+		foodType: foodTypeOf(item),
+		text:     strings.ToLower(strings.Join(append([]string{item.Title, item.Description, item.Category}, item.Tags...), " ")),
+	}
+	if _, ok := s.impact.ToKg(item.Category, item.Quantity, item.Unit); !ok {
+		log.Printf("Warning: surplus item %s has unit %q that cannot be converted to kg; matching without weight", item.ID, item.Unit)
+	}
+
+	expiresAt := expiryOf(item, now)
+	var created []*NGODonationOffer
+	for _, c := range rankCandidates(facts, settings, cold, ambient, now) {
+		offer := s.newOffer(item, facts, c, donorName, donorEmail, measure.Meals, expiresAt, now)
+		ok, err := s.repo.Create(offer)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created = append(created, offer)
+			events.Publish(events.OfferCreated{
+				Meta:         events.NewMeta(),
+				NGOUserID:    offer.NGOUserID,
+				OfferID:      offer.ID,
+				Title:        offer.OfferTitle,
+				UrgencyLevel: offer.UrgencyLevel,
+			})
+		}
+	}
+	return created, nil
+}
+
 func (s *Service) Accept(id uuid.UUID, ngoUserID uuid.UUID) (*NGODonationOffer, error) {
 	offer, err := s.repo.GetByID(id)
 	if err != nil {
@@ -30,11 +114,14 @@ func (s *Service) Accept(id uuid.UUID, ngoUserID uuid.UUID) (*NGODonationOffer,
 	if offer.NGOUserID != ngoUserID {
 		return nil, errors.ErrForbidden
 	}
-	if err := s.repo.UpdateStatus(id, "accepted"); err != nil {
+	if offer.Status != StatusPending {
+		return nil, errOfferNotPending
+	}
+	if err := s.repo.Accept(offer, "", false); err != nil {
 		return nil, err
 	}
-	offer.Status = "accepted"
-	return offer, nil
+	publishAccepted(offer, false)
+	return s.repo.GetByID(id)
 }
 
 func (s *Service) Decline(id uuid.UUID, ngoUserID uuid.UUID) (*NGODonationOffer, error) {
@@ -45,9 +132,172 @@ func (s *Service) Decline(id uuid.UUID, ngoUserID uuid.UUID) (*NGODonationOffer,
 	if offer.NGOUserID != ngoUserID {
 		return nil, errors.ErrForbidden
 	}
-	if err := s.repo.UpdateStatus(id, "declined"); err != nil {
+	if err := s.repo.Decline(id, ""); err != nil {
+		return nil, err
+	}
+	offer.Status = StatusDeclined
+	return offer, nil
+}
+
+// Complete marks an accepted offer as received, releasing its weight from the
+// NGO's current utilization
+func (s *Service) Complete(id uuid.UUID, ngoUserID uuid.UUID) (*NGODonationOffer, error) {
+	offer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if offer.NGOUserID != ngoUserID {
+		return nil, errors.ErrForbidden
+	}
+	if err := s.repo.Complete(id); err != nil {
 		return nil, err
 	}
-	offer.Status = "declined"
+	offer.Status = StatusCompleted
 	return offer, nil
 }
+
+// AutoAccept applies the NGO's auto-acceptance rules to a new offer. It
+// accepts the offer when every rule holds, declines it with the reason when
+// capacity is exceeded or it has expired, and otherwise leaves it pending with
+// the reason it needs review. Offers that are no longer pending are left alone.
+func (s *Service) AutoAccept(id uuid.UUID) (*AutoAcceptDecision, error) {
+	offer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	decision, err := s.evaluate(offer)
+	if err != nil || !decision.Enabled || offer.Status != StatusPending {
+		return decision, err
+	}
+
+	switch decision.Outcome {
+	case OutcomeAccept:
+		err = s.repo.Accept(offer, decision.Reason, true)
+		if err == errCapacityExceeded {
+			decision.Outcome, decision.Reason = OutcomeDecline, "Auto-declined: "+errCapacityExceeded.Message
+			err = s.repo.Decline(id, decision.Reason)
+		} else if err == nil {
+			publishAccepted(offer, true)
+		}
+	case OutcomeDecline:
+		err = s.repo.Decline(id, decision.Reason)
+	default:
+		err = s.repo.SetDecisionReason(id, decision.Reason)
+	}
+	// Another NGO claimed the surplus, or the offer was decided meanwhile
+	if err == errOfferNotPending || err == errSurplusUnavailable {
+		return decision, nil
+	}
+	return decision, err
+}
+
+// DryRunAutoAccept explains what auto-acceptance would do with an offer
+// without changing it
+func (s *Service) DryRunAutoAccept(id uuid.UUID, ngoUserID uuid.UUID) (*AutoAcceptDecision, error) {
+	offer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if offer.NGOUserID != ngoUserID {
+		return nil, errors.ErrForbidden
+	}
+	return s.evaluate(offer)
+}
+
+// evaluate applies the offer's NGO's auto-acceptance rules. An NGO without
+// capacity settings has auto-acceptance disabled.
+func (s *Service) evaluate(offer *NGODonationOffer) (*AutoAcceptDecision, error) {
+	settings, err := s.capacity.GetByUserID(offer.NGOUserID)
+	if err == errors.ErrNotFound {
+		return &AutoAcceptDecision{
+			OfferID: offer.ID,
+			Outcome: OutcomeReview,
+			Reason:  "Auto-acceptance is disabled until capacity settings are saved",
+			Checks:  []AutoAcceptCheck{},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cold, ambient, err := s.repo.CommittedLoad()
+	if err != nil {
+		return nil, err
+	}
+	return evaluateAutoAccept(offer, settings, cold[offer.NGOUserID], ambient[offer.NGOUserID], time.Now()), nil
+}
+
+func publishAccepted(offer *NGODonationOffer, auto bool) {
+	events.Publish(events.OfferAccepted{
+		Meta:          events.NewMeta(),
+		NGOUserID:     offer.NGOUserID,
+		OfferID:       offer.ID,
+		SurplusItemID: offer.SurplusItemID,
+		Title:         offer.OfferTitle,
+		WeightKg:      offer.WeightKg,
+		AutoAccepted:  auto,
+	})
+}
+
+// newOffer builds the offer made to a candidate NGO for a surplus item
+func (s *Service) newOffer(item *restaurant_surplus.RestaurantSurplusItem, facts surplusFacts, c *candidate, donorName, donorEmail string, meals int, expiresAt, now time.Time) *NGODonationOffer {
+	locationLabel := item.Location
+	if locationLabel == "" {
+		locationLabel = donorName
+	}
+	var safetyFlags []string
+	switch item.StorageType {
+	case "chilled":
+		safetyFlags = append(safetyFlags, "keep-refrigerated")
+	case "frozen":
+		safetyFlags = append(safetyFlags, "keep-frozen")
+	}
+	var images []string
+	if item.Image != "" {
+		images = append(images, item.Image)
+	}
+	itemID := item.ID
+
+	return &NGODonationOffer{
+		ID:            uuid.New(),
+		NGOUserID:     c.settings.UserID,
+		DonorName:     donorName,
+		DonorType:     DonorTypeRestaurant,
+		DistanceKm:    c.distanceKm,
+		LocationLabel: locationLabel,
+		GeoPoint:      JSONB(item.GeoPoint),
+		OfferTitle:    item.Title,
+		Items: JSONB{"items": []map[string]interface{}{{
+			"name":        item.Title,
+			"category":    item.Category,
+			"quantity":    item.Quantity,
+			"unit":        item.Unit,
+			"type":        facts.foodType,
+			"temperature": item.StorageType,
+		}}},
+		WeightKg:       facts.weightKg,
+		MealsEstimated: meals,
+		FreshnessScore: freshnessOf(item.StorageType, expiresAt, now),
+		PickupWindow:   JSONB(item.PickupWindow),
+		ExpiresAt:      expiresAt,
+		UrgencyLevel:   urgencyOf(expiresAt, now),
+		DietaryNotes:   strings.Join(item.Tags, ", "),
+		SafetyFlags:    safetyFlags,
+		Contact:        JSONB{"name": donorName, "email": donorEmail, "channel": "email"},
+		Images:         images,
+		Status:         StatusPending,
+		MatchReason:    matchReason(c),
+		MatchScore:     c.score,
+		SurplusItemID:  &itemID,
+		StorageType:    item.StorageType,
+	}
+}
+
+// matchReason joins a candidate's reasons into a readable sentence, e.g.
+// "Prefers bakery food; 2.4 km away, within its 5 km radius"
+func matchReason(c *candidate) string {
+	if len(c.reasons) == 0 {
+		return "Has capacity for this item"
+	}
+	reason := strings.Join(c.reasons, "; ")
+	return strings.ToUpper(reason[:1]) + reason[1:]
+}
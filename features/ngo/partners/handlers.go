@@ -37,7 +37,12 @@ func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	partners, err := h.service.GetAllByNGOUserID(ngoUserID)
+	geo, err := auth.NearbyQuery(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid location query", err.Error())
+		return
+	}
+	partners, err := h.service.GetAllByNGOUserID(ngoUserID, geo)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
@@ -54,7 +59,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/partners/")
+	idStr := strings.Trim(r.URL.Path, "/")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
@@ -109,7 +114,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/partners/")
+	idStr := strings.Trim(r.URL.Path, "/")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
@@ -12,7 +12,9 @@ type NGOPartnerProfile struct {
 	Name               string     `json:"name" db:"name"`
 	Type               string     `json:"type" db:"type"`
 	Location           string     `json:"location" db:"location"`
-	DistanceKm         *float64   `json:"distance_km,omitempty" db:"distance_km"`
+	Latitude           *float64   `json:"latitude,omitempty" db:"latitude"`
+	Longitude          *float64   `json:"longitude,omitempty" db:"longitude"`
+	DistanceKm         *float64   `json:"distance_km,omitempty"`
 	ContactName        string     `json:"contact_name" db:"contact_name"`
 	ContactPhone       string     `json:"contact_phone" db:"contact_phone"`
 	ContactEmail       string     `json:"contact_email,omitempty" db:"contact_email"`
@@ -31,7 +33,8 @@ type CreateNGOPartnerRequest struct {
 	Name               string   `json:"name" validate:"required,min=1,max=255"`
 	Type               string   `json:"type" validate:"required,oneof=community-kitchen building restaurant ngo"`
 	Location           string   `json:"location" validate:"required,min=1"`
-	DistanceKm         *float64 `json:"distance_km,omitempty"`
+	Latitude           *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude          *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 	ContactName        string   `json:"contact_name" validate:"required,min=1,max=255"`
 	ContactPhone       string   `json:"contact_phone" validate:"required,min=1,max=50"`
 	ContactEmail       string   `json:"contact_email,omitempty" validate:"omitempty,email"`
@@ -44,7 +47,8 @@ type CreateNGOPartnerRequest struct {
 type UpdateNGOPartnerRequest struct {
 	Name               string   `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
 	Location           string   `json:"location,omitempty" validate:"omitempty,min=1"`
-	DistanceKm         *float64 `json:"distance_km,omitempty"`
+	Latitude           *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude          *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 	ContactName        string   `json:"contact_name,omitempty" validate:"omitempty,min=1,max=255"`
 	ContactPhone       string   `json:"contact_phone,omitempty" validate:"omitempty,min=1,max=50"`
 	ContactEmail       string   `json:"contact_email,omitempty" validate:"omitempty,email"`
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"foodlink_backend/database"
 	"foodlink_backend/errors"
+	"foodlink_backend/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,20 +19,28 @@ func NewRepository() *Repository {
 	return &Repository{db: database.GetDB()}
 }
 
-func (r *Repository) GetAllByNGOUserID(ngoUserID uuid.UUID) ([]*NGOPartnerProfile, error) {
+// partnerColumns lists the columns scanned by scanPartner
+const partnerColumns = `id, ngo_user_id, name, type, location, latitude, longitude, contact_name, contact_phone, COALESCE(contact_email, ''), COALESCE(operating_hours, ''), COALESCE(acceptance_rate, 0), last_donation_at, COALESCE(avg_donation_kg, 0), storage_capabilities, COALESCE(notes, ''), COALESCE(avatar, ''), created_at, updated_at`
+
+// GetAllByNGOUserID lists an NGO's partners, newest first. A non-nil box
+// limits them to partners located inside it.
+func (r *Repository) GetAllByNGOUserID(ngoUserID uuid.UUID, box *utils.GeoBox) ([]*NGOPartnerProfile, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	query := `SELECT id, ngo_user_id, name, type, location, distance_km, contact_name, contact_phone, contact_email, operating_hours, acceptance_rate, last_donation_at, avg_donation_kg, storage_capabilities, notes, avatar, created_at, updated_at FROM ngo_partner_profiles WHERE ngo_user_id = $1 ORDER BY created_at DESC`
-	rows, err := r.db.Query(query, ngoUserID)
+	query := `SELECT ` + partnerColumns + ` FROM ngo_partner_profiles
+		WHERE ngo_user_id = $1
+			AND ($2::float8 IS NULL OR (latitude BETWEEN $2 AND $3 AND longitude BETWEEN $4 AND $5))
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(query, append([]interface{}{ngoUserID}, box.Args()...)...)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
 	defer rows.Close()
 	var partners []*NGOPartnerProfile
 	for rows.Next() {
-		partner := &NGOPartnerProfile{}
-		if err := rows.Scan(&partner.ID, &partner.NGOUserID, &partner.Name, &partner.Type, &partner.Location, &partner.DistanceKm, &partner.ContactName, &partner.ContactPhone, &partner.ContactEmail, &partner.OperatingHours, &partner.AcceptanceRate, &partner.LastDonationAt, &partner.AvgDonationKg, pq.Array(&partner.StorageCapabilities), &partner.Notes, &partner.Avatar, &partner.CreatedAt, &partner.UpdatedAt); err != nil {
+		partner, err := scanPartner(rows)
+		if err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
 		partners = append(partners, partner)
@@ -43,9 +52,8 @@ func (r *Repository) GetByID(id uuid.UUID) (*NGOPartnerProfile, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	partner := &NGOPartnerProfile{}
-	query := `SELECT id, ngo_user_id, name, type, location, distance_km, contact_name, contact_phone, contact_email, operating_hours, acceptance_rate, last_donation_at, avg_donation_kg, storage_capabilities, notes, avatar, created_at, updated_at FROM ngo_partner_profiles WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&partner.ID, &partner.NGOUserID, &partner.Name, &partner.Type, &partner.Location, &partner.DistanceKm, &partner.ContactName, &partner.ContactPhone, &partner.ContactEmail, &partner.OperatingHours, &partner.AcceptanceRate, &partner.LastDonationAt, &partner.AvgDonationKg, pq.Array(&partner.StorageCapabilities), &partner.Notes, &partner.Avatar, &partner.CreatedAt, &partner.UpdatedAt)
+	query := `SELECT ` + partnerColumns + ` FROM ngo_partner_profiles WHERE id = $1`
+	partner, err := scanPartner(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
@@ -59,15 +67,40 @@ func (r *Repository) Create(partner *NGOPartnerProfile) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `INSERT INTO ngo_partner_profiles (id, ngo_user_id, name, type, location, distance_km, contact_name, contact_phone, contact_email, operating_hours, acceptance_rate, last_donation_at, avg_donation_kg, storage_capabilities, notes, avatar, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING id, ngo_user_id, name, type, location, distance_km, contact_name, contact_phone, contact_email, operating_hours, acceptance_rate, last_donation_at, avg_donation_kg, storage_capabilities, notes, avatar, created_at, updated_at`
+	query := `INSERT INTO ngo_partner_profiles (id, ngo_user_id, name, type, location, latitude, longitude, contact_name, contact_phone, contact_email, operating_hours, acceptance_rate, last_donation_at, avg_donation_kg, storage_capabilities, notes, avatar, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), $12, $13, $14, $15, NULLIF($16, ''), NULLIF($17, ''), $18, $19) RETURNING ` + partnerColumns
 	now := time.Now()
-	return r.db.QueryRow(query, partner.ID, partner.NGOUserID, partner.Name, partner.Type, partner.Location, partner.DistanceKm, partner.ContactName, partner.ContactPhone, partner.ContactEmail, partner.OperatingHours, partner.AcceptanceRate, partner.LastDonationAt, partner.AvgDonationKg, pq.Array(partner.StorageCapabilities), partner.Notes, partner.Avatar, now, now).Scan(&partner.ID, &partner.NGOUserID, &partner.Name, &partner.Type, &partner.Location, &partner.DistanceKm, &partner.ContactName, &partner.ContactPhone, &partner.ContactEmail, &partner.OperatingHours, &partner.AcceptanceRate, &partner.LastDonationAt, &partner.AvgDonationKg, pq.Array(&partner.StorageCapabilities), &partner.Notes, &partner.Avatar, &partner.CreatedAt, &partner.UpdatedAt)
+	created, err := scanPartner(r.db.QueryRow(query, partner.ID, partner.NGOUserID, partner.Name, partner.Type, partner.Location, partner.Latitude, partner.Longitude, partner.ContactName, partner.ContactPhone, partner.ContactEmail, partner.OperatingHours, partner.AcceptanceRate, partner.LastDonationAt, partner.AvgDonationKg, pq.Array(partner.StorageCapabilities), partner.Notes, partner.Avatar, now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*partner = *created
+	return nil
 }
 
 func (r *Repository) Update(partner *NGOPartnerProfile) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `UPDATE ngo_partner_profiles SET name=$1, location=$2, distance_km=$3, contact_name=$4, contact_phone=$5, contact_email=$6, operating_hours=$7, storage_capabilities=$8, notes=$9, avatar=$10, updated_at=$11 WHERE id=$12 RETURNING id, ngo_user_id, name, type, location, distance_km, contact_name, contact_phone, contact_email, operating_hours, acceptance_rate, last_donation_at, avg_donation_kg, storage_capabilities, notes, avatar, created_at, updated_at`
-	return r.db.QueryRow(query, partner.Name, partner.Location, partner.DistanceKm, partner.ContactName, partner.ContactPhone, partner.ContactEmail, partner.OperatingHours, pq.Array(partner.StorageCapabilities), partner.Notes, partner.Avatar, time.Now(), partner.ID).Scan(&partner.ID, &partner.NGOUserID, &partner.Name, &partner.Type, &partner.Location, &partner.DistanceKm, &partner.ContactName, &partner.ContactPhone, &partner.ContactEmail, &partner.OperatingHours, &partner.AcceptanceRate, &partner.LastDonationAt, &partner.AvgDonationKg, pq.Array(&partner.StorageCapabilities), &partner.Notes, &partner.Avatar, &partner.CreatedAt, &partner.UpdatedAt)
+	query := `UPDATE ngo_partner_profiles SET name=$1, location=$2, latitude=$3, longitude=$4, contact_name=$5, contact_phone=$6, contact_email=NULLIF($7, ''), operating_hours=NULLIF($8, ''), storage_capabilities=$9, notes=NULLIF($10, ''), avatar=NULLIF($11, ''), updated_at=$12 WHERE id=$13 RETURNING ` + partnerColumns
+	updated, err := scanPartner(r.db.QueryRow(query, partner.Name, partner.Location, partner.Latitude, partner.Longitude, partner.ContactName, partner.ContactPhone, partner.ContactEmail, partner.OperatingHours, pq.Array(partner.StorageCapabilities), partner.Notes, partner.Avatar, time.Now(), partner.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*partner = *updated
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPartner(row rowScanner) (*NGOPartnerProfile, error) {
+	partner := &NGOPartnerProfile{}
+	if err := row.Scan(&partner.ID, &partner.NGOUserID, &partner.Name, &partner.Type, &partner.Location, &partner.Latitude, &partner.Longitude, &partner.ContactName, &partner.ContactPhone, &partner.ContactEmail, &partner.OperatingHours, &partner.AcceptanceRate, &partner.LastDonationAt, &partner.AvgDonationKg, pq.Array(&partner.StorageCapabilities), &partner.Notes, &partner.Avatar, &partner.CreatedAt, &partner.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return partner, nil
 }
@@ -15,8 +15,18 @@ func NewService() *Service {
 	return &Service{repo: NewRepository()}
 }
 
-func (s *Service) GetAllByNGOUserID(ngoUserID uuid.UUID) ([]*NGOPartnerProfile, error) {
-	return s.repo.GetAllByNGOUserID(ngoUserID)
+// GetAllByNGOUserID lists an NGO's partners. With a geo query each partner
+// carries its distance from the search point and the list is sorted nearest
+// first.
+func (s *Service) GetAllByNGOUserID(ngoUserID uuid.UUID, geo *utils.GeoQuery) ([]*NGOPartnerProfile, error) {
+	partners, err := s.repo.GetAllByNGOUserID(ngoUserID, geo.Box())
+	if err != nil {
+		return nil, err
+	}
+	return utils.NearestFirst(geo, partners,
+		func(partner *NGOPartnerProfile) (lat, lng *float64) { return partner.Latitude, partner.Longitude },
+		func(partner *NGOPartnerProfile, d *float64) { partner.DistanceKm = d },
+	), nil
 }
 
 func (s *Service) GetByID(id uuid.UUID) (*NGOPartnerProfile, error) {
@@ -33,7 +43,8 @@ func (s *Service) Create(ngoUserID uuid.UUID, req *CreateNGOPartnerRequest) (*NG
 		Name:               req.Name,
 		Type:               req.Type,
 		Location:           req.Location,
-		DistanceKm:         req.DistanceKm,
+		Latitude:           req.Latitude,
+		Longitude:          req.Longitude,
 		ContactName:        req.ContactName,
 		ContactPhone:       req.ContactPhone,
 		ContactEmail:       req.ContactEmail,
@@ -67,8 +78,8 @@ func (s *Service) Update(id uuid.UUID, ngoUserID uuid.UUID, req *UpdateNGOPartne
 	if req.Location != "" {
 		partner.Location = req.Location
 	}
-	if req.DistanceKm != nil {
-		partner.DistanceKm = req.DistanceKm
+	if req.Latitude != nil && req.Longitude != nil {
+		partner.Latitude, partner.Longitude = req.Latitude, req.Longitude
 	}
 	if req.ContactName != "" {
 		partner.ContactName = req.ContactName
@@ -19,12 +19,12 @@ func NewHandler(service *Service) *Handler {
 	return &Handler{service: service}
 }
 
-func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+func (h *Handler) getUser(r *http.Request) (*auth.User, error) {
 	user, ok := r.Context().Value("user").(*auth.User)
 	if !ok || user == nil {
-		return uuid.Nil, errors.ErrUnauthorized
+		return nil, errors.ErrUnauthorized
 	}
-	return user.ID, nil
+	return user, nil
 }
 
 func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
@@ -59,7 +59,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/pickups/")
+	idStr := strings.Trim(r.URL.Path, "/")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
@@ -82,12 +82,17 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
 	var req CreateNGOPickupScheduleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.BadRequestResponse(w, "Invalid request body", err.Error())
 		return
 	}
-	schedule, err := h.service.Create(&req)
+	schedule, err := h.service.Create(&req, user.ID, user.Name)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
@@ -104,18 +109,23 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/pickups/")
+	idStr := strings.Trim(r.URL.Path, "/")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
 		return
 	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
 	var req UpdateNGOPickupScheduleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.BadRequestResponse(w, "Invalid request body", err.Error())
 		return
 	}
-	schedule, err := h.service.Update(id, &req)
+	schedule, err := h.service.Update(id, user.ID, &req)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
@@ -132,7 +142,7 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/pickups/"), "/")
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
 	if len(pathParts) < 2 || pathParts[1] != "status" {
 		utils.BadRequestResponse(w, "Invalid path", nil)
 		return
@@ -142,12 +152,17 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
 		return
 	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
 	var req UpdatePickupStatusRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.BadRequestResponse(w, "Invalid request body", err.Error())
 		return
 	}
-	schedule, err := h.service.UpdateStatus(id, &req)
+	schedule, err := h.service.UpdateStatus(id, user.ID, user.Name, &req)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
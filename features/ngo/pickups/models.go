@@ -3,6 +3,7 @@ package pickups
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -35,11 +36,12 @@ type NGOPickupSchedule struct {
 	RouteID        *uuid.UUID `json:"route_id,omitempty" db:"route_id"`
 	ScheduledFor  time.Time `json:"scheduled_for" db:"scheduled_for"`
 	ETAMinutes     *int      `json:"eta_minutes,omitempty" db:"eta_minutes"`
+	SequenceNumber *int      `json:"sequence_number,omitempty" db:"sequence_number"`
 	VolunteerName  string    `json:"volunteer_name" db:"volunteer_name"`
 	VolunteerContact string  `json:"volunteer_contact" db:"volunteer_contact"`
 	VehicleType    string    `json:"vehicle_type,omitempty" db:"vehicle_type"`
 	Status         string    `json:"status" db:"status"`
-	Checkpoints    JSONB     `json:"checkpoints,omitempty" db:"checkpoints"`
+	Checkpoints    Checkpoints `json:"checkpoints" db:"checkpoints"`
 	Reminders      JSONB     `json:"reminders,omitempty" db:"reminders"`
 	Notes          string    `json:"notes,omitempty" db:"notes"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
@@ -53,7 +55,6 @@ type CreateNGOPickupScheduleRequest struct {
 	VolunteerName  string                 `json:"volunteer_name" validate:"required,min=1"`
 	VolunteerContact string               `json:"volunteer_contact" validate:"required,min=1"`
 	VehicleType    string                 `json:"vehicle_type,omitempty" validate:"omitempty,oneof=van bike car on-foot"`
-	Checkpoints    map[string]interface{} `json:"checkpoints,omitempty"`
 	Reminders      map[string]interface{} `json:"reminders,omitempty"`
 	Notes          string                 `json:"notes,omitempty"`
 }
@@ -63,12 +64,116 @@ type UpdateNGOPickupScheduleRequest struct {
 	ETAMinutes     *int                   `json:"eta_minutes,omitempty"`
 	VolunteerName  string                 `json:"volunteer_name,omitempty"`
 	VolunteerContact string               `json:"volunteer_contact,omitempty"`
-	VehicleType    string                 `json:"vehicle_type,omitempty"`
-	Checkpoints    map[string]interface{} `json:"checkpoints,omitempty"`
+	VehicleType    string                 `json:"vehicle_type,omitempty" validate:"omitempty,oneof=van bike car on-foot"`
 	Reminders      map[string]interface{} `json:"reminders,omitempty"`
 	Notes          string                 `json:"notes,omitempty"`
 }
 
+// UpdatePickupStatusRequest moves a pickup to its next status. WeightKg,
+// MealsProvided and Beneficiaries are only used on delivery and default to the
+// offer's estimates.
 type UpdatePickupStatusRequest struct {
-	Status string `json:"status" validate:"required,oneof=scheduled en-route picked-up delivered failed"`
+	Status        string   `json:"status" validate:"required,oneof=scheduled en-route picked-up delivered failed"`
+	Note          string   `json:"note,omitempty"`
+	WeightKg      *float64 `json:"weight_kg,omitempty" validate:"omitempty,gte=0"`
+	MealsProvided *int     `json:"meals_provided,omitempty" validate:"omitempty,gte=0"`
+	Beneficiaries *int     `json:"beneficiaries,omitempty" validate:"omitempty,gte=0"`
+}
+
+// Pickup statuses
+const (
+	StatusScheduled = "scheduled"
+	StatusEnRoute   = "en-route"
+	StatusPickedUp  = "picked-up"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// transitions lists the statuses a pickup may move to from each status.
+// Delivered and failed pickups are final.
+var transitions = map[string][]string{
+	StatusScheduled: {StatusEnRoute, StatusFailed},
+	StatusEnRoute:   {StatusPickedUp, StatusFailed},
+	StatusPickedUp:  {StatusDelivered, StatusFailed},
+}
+
+// CanTransition reports whether a pickup may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkpointLabels describe each status in a pickup's checkpoint trail
+var checkpointLabels = map[string]string{
+	StatusScheduled: "Pickup scheduled",
+	StatusEnRoute:   "Volunteer en route",
+	StatusPickedUp:  "Food picked up",
+	StatusDelivered: "Food delivered",
+	StatusFailed:    "Pickup failed",
+}
+
+// Checkpoint records a pickup entering a status, who moved it there and why
+type Checkpoint struct {
+	Label     string     `json:"label"`
+	Status    string     `json:"status"`
+	From      string     `json:"from,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+	Actor     string     `json:"actor,omitempty"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	Note      string     `json:"note,omitempty"`
+}
+
+// NewCheckpoint records a pickup entering status from another status, moved
+// there by the actor
+func NewCheckpoint(status, from string, actorID uuid.UUID, actorName, note string, at time.Time) Checkpoint {
+	return Checkpoint{
+		Label:     checkpointLabels[status],
+		Status:    status,
+		From:      from,
+		Timestamp: at,
+		Actor:     actorName,
+		ActorID:   &actorID,
+		Note:      strings.TrimSpace(note),
+	}
+}
+
+// Checkpoints is a pickup's status trail, oldest first
+type Checkpoints []Checkpoint
+
+func (c Checkpoints) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *Checkpoints) Scan(value interface{}) error {
+	if value == nil {
+		*c = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	// Older pickups stored client-supplied objects rather than a list
+	if len(bytes) > 0 && bytes[0] != '[' {
+		*c = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// ReachedAt returns when the pickup last entered status
+func (c Checkpoints) ReachedAt(status string) (time.Time, bool) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].Status == status {
+			return c[i].Timestamp, true
+		}
+	}
+	return time.Time{}, false
 }
@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"foodlink_backend/database"
 	"foodlink_backend/errors"
+	"foodlink_backend/features/ngo/history"
+	"foodlink_backend/features/ngo/offers"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// pickupColumns lists the columns scanned by scanPickup
+const pickupColumns = `id, offer_id, route_id, scheduled_for, eta_minutes, sequence_number, volunteer_name, volunteer_contact, COALESCE(vehicle_type, ''), status, checkpoints, reminders, COALESCE(notes, ''), created_at, updated_at`
+
 type Repository struct {
 	db *sql.DB
 }
@@ -22,7 +27,7 @@ func (r *Repository) GetAllByOfferID(offerID uuid.UUID) ([]*NGOPickupSchedule, e
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	query := `SELECT id, offer_id, route_id, scheduled_for, eta_minutes, volunteer_name, volunteer_contact, vehicle_type, status, checkpoints, reminders, notes, created_at, updated_at FROM ngo_pickup_schedules WHERE offer_id = $1 ORDER BY scheduled_for ASC`
+	query := `SELECT ` + pickupColumns + ` FROM ngo_pickup_schedules WHERE offer_id = $1 ORDER BY scheduled_for ASC`
 	rows, err := r.db.Query(query, offerID)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
@@ -30,17 +35,10 @@ func (r *Repository) GetAllByOfferID(offerID uuid.UUID) ([]*NGOPickupSchedule, e
 	defer rows.Close()
 	var schedules []*NGOPickupSchedule
 	for rows.Next() {
-		schedule := &NGOPickupSchedule{}
-		var checkpointsJSON, remindersJSON []byte
-		if err := rows.Scan(&schedule.ID, &schedule.OfferID, &schedule.RouteID, &schedule.ScheduledFor, &schedule.ETAMinutes, &schedule.VolunteerName, &schedule.VolunteerContact, &schedule.VehicleType, &schedule.Status, &checkpointsJSON, &remindersJSON, &schedule.Notes, &schedule.CreatedAt, &schedule.UpdatedAt); err != nil {
+		schedule, err := scanPickup(rows)
+		if err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
-		if len(checkpointsJSON) > 0 {
-			json.Unmarshal(checkpointsJSON, &schedule.Checkpoints)
-		}
-		if len(remindersJSON) > 0 {
-			json.Unmarshal(remindersJSON, &schedule.Reminders)
-		}
 		schedules = append(schedules, schedule)
 	}
 	return schedules, nil
@@ -50,67 +48,167 @@ func (r *Repository) GetByID(id uuid.UUID) (*NGOPickupSchedule, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	schedule := &NGOPickupSchedule{}
-	var checkpointsJSON, remindersJSON []byte
-	query := `SELECT id, offer_id, route_id, scheduled_for, eta_minutes, volunteer_name, volunteer_contact, vehicle_type, status, checkpoints, reminders, notes, created_at, updated_at FROM ngo_pickup_schedules WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&schedule.ID, &schedule.OfferID, &schedule.RouteID, &schedule.ScheduledFor, &schedule.ETAMinutes, &schedule.VolunteerName, &schedule.VolunteerContact, &schedule.VehicleType, &schedule.Status, &checkpointsJSON, &remindersJSON, &schedule.Notes, &schedule.CreatedAt, &schedule.UpdatedAt)
+	query := `SELECT ` + pickupColumns + ` FROM ngo_pickup_schedules WHERE id = $1`
+	schedule, err := scanPickup(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
 		}
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(checkpointsJSON) > 0 {
-		json.Unmarshal(checkpointsJSON, &schedule.Checkpoints)
-	}
-	if len(remindersJSON) > 0 {
-		json.Unmarshal(remindersJSON, &schedule.Reminders)
-	}
 	return schedule, nil
 }
 
+// insertPickup inserts a pickup and returns it as stored
+const insertPickup = `INSERT INTO ngo_pickup_schedules (id, offer_id, route_id, scheduled_for, eta_minutes, sequence_number, volunteer_name, volunteer_contact, vehicle_type, status, checkpoints, reminders, notes, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12, NULLIF($13, ''), $14, $15) RETURNING ` + pickupColumns
+
 func (r *Repository) Create(schedule *NGOPickupSchedule) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	checkpointsJSON, _ := json.Marshal(schedule.Checkpoints)
 	remindersJSON, _ := json.Marshal(schedule.Reminders)
-	query := `INSERT INTO ngo_pickup_schedules (id, offer_id, route_id, scheduled_for, eta_minutes, volunteer_name, volunteer_contact, vehicle_type, status, checkpoints, reminders, notes, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, offer_id, route_id, scheduled_for, eta_minutes, volunteer_name, volunteer_contact, vehicle_type, status, checkpoints, reminders, notes, created_at, updated_at`
 	now := time.Now()
-	var checkpointsJSONOut, remindersJSONOut []byte
-	err := r.db.QueryRow(query, schedule.ID, schedule.OfferID, schedule.RouteID, schedule.ScheduledFor, schedule.ETAMinutes, schedule.VolunteerName, schedule.VolunteerContact, schedule.VehicleType, schedule.Status, checkpointsJSON, remindersJSON, schedule.Notes, now, now).Scan(&schedule.ID, &schedule.OfferID, &schedule.RouteID, &schedule.ScheduledFor, &schedule.ETAMinutes, &schedule.VolunteerName, &schedule.VolunteerContact, &schedule.VehicleType, &schedule.Status, &checkpointsJSONOut, &remindersJSONOut, &schedule.Notes, &schedule.CreatedAt, &schedule.UpdatedAt)
+	created, err := scanPickup(r.db.QueryRow(insertPickup, schedule.ID, schedule.OfferID, schedule.RouteID, schedule.ScheduledFor, schedule.ETAMinutes, schedule.SequenceNumber, schedule.VolunteerName, schedule.VolunteerContact, schedule.VehicleType, schedule.Status, schedule.Checkpoints, remindersJSON, schedule.Notes, now, now))
 	if err != nil {
 		return errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(checkpointsJSONOut) > 0 {
-		json.Unmarshal(checkpointsJSONOut, &schedule.Checkpoints)
+	*schedule = *created
+	return nil
+}
+
+// ScheduleTx places a pickup on a route inside tx. A pickup already scheduled
+// for the offer but not yet routed is moved onto the route, keeping its
+// checkpoints; otherwise schedule is inserted as a new pickup.
+func ScheduleTx(tx *sql.Tx, schedule *NGOPickupSchedule) error {
+	now := time.Now()
+	query := `
+		UPDATE ngo_pickup_schedules
+		SET route_id = $2, scheduled_for = $3, eta_minutes = $4, sequence_number = $5,
+			volunteer_name = $6, volunteer_contact = $7, vehicle_type = NULLIF($8, ''), updated_at = $9
+		WHERE id = (
+			SELECT id FROM ngo_pickup_schedules
+			WHERE offer_id = $1 AND status = $10 AND route_id IS NULL
+			ORDER BY created_at LIMIT 1
+		)
+		RETURNING ` + pickupColumns
+	moved, err := scanPickup(tx.QueryRow(query, schedule.OfferID, schedule.RouteID, schedule.ScheduledFor, schedule.ETAMinutes, schedule.SequenceNumber, schedule.VolunteerName, schedule.VolunteerContact, schedule.VehicleType, now, StatusScheduled))
+	if err == nil {
+		*schedule = *moved
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(remindersJSONOut) > 0 {
-		json.Unmarshal(remindersJSONOut, &schedule.Reminders)
+	remindersJSON, _ := json.Marshal(schedule.Reminders)
+	created, err := scanPickup(tx.QueryRow(insertPickup, schedule.ID, schedule.OfferID, schedule.RouteID, schedule.ScheduledFor, schedule.ETAMinutes, schedule.SequenceNumber, schedule.VolunteerName, schedule.VolunteerContact, schedule.VehicleType, schedule.Status, schedule.Checkpoints, remindersJSON, schedule.Notes, now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
 	}
+	*schedule = *created
 	return nil
 }
 
+// Update saves a pickup's details. Status and checkpoints only change through
+// Transition.
 func (r *Repository) Update(schedule *NGOPickupSchedule) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	checkpointsJSON, _ := json.Marshal(schedule.Checkpoints)
 	remindersJSON, _ := json.Marshal(schedule.Reminders)
-	query := `UPDATE ngo_pickup_schedules SET scheduled_for=$1, eta_minutes=$2, volunteer_name=$3, volunteer_contact=$4, vehicle_type=$5, status=$6, checkpoints=$7, reminders=$8, notes=$9, updated_at=$10 WHERE id=$11 RETURNING id, offer_id, route_id, scheduled_for, eta_minutes, volunteer_name, volunteer_contact, vehicle_type, status, checkpoints, reminders, notes, created_at, updated_at`
-	var checkpointsJSONOut, remindersJSONOut []byte
-	err := r.db.QueryRow(query, schedule.ScheduledFor, schedule.ETAMinutes, schedule.VolunteerName, schedule.VolunteerContact, schedule.VehicleType, schedule.Status, checkpointsJSON, remindersJSON, schedule.Notes, time.Now(), schedule.ID).Scan(&schedule.ID, &schedule.OfferID, &schedule.RouteID, &schedule.ScheduledFor, &schedule.ETAMinutes, &schedule.VolunteerName, &schedule.VolunteerContact, &schedule.VehicleType, &schedule.Status, &checkpointsJSONOut, &remindersJSONOut, &schedule.Notes, &schedule.CreatedAt, &schedule.UpdatedAt)
+	query := `UPDATE ngo_pickup_schedules SET scheduled_for=$1, eta_minutes=$2, volunteer_name=$3, volunteer_contact=$4, vehicle_type=NULLIF($5, ''), reminders=$6, notes=NULLIF($7, ''), updated_at=$8 WHERE id=$9 RETURNING ` + pickupColumns
+	updated, err := scanPickup(r.db.QueryRow(query, schedule.ScheduledFor, schedule.ETAMinutes, schedule.VolunteerName, schedule.VolunteerContact, schedule.VehicleType, remindersJSON, schedule.Notes, time.Now(), schedule.ID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return errors.ErrNotFound
 		}
 		return errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(checkpointsJSONOut) > 0 {
-		json.Unmarshal(checkpointsJSONOut, &schedule.Checkpoints)
+	*schedule = *updated
+	return nil
+}
+
+// Transition moves a pickup from one status to the next and appends the
+// checkpoint to its trail. When delivery is set it is recorded in the NGO's
+// donation history and the pickup's offer is completed, all in one transaction.
+// A failed pickup releases its offer the same way when no other pickup is
+// still active for it.
+// The pickup is locked first, so a concurrent transition makes this one fail
+// with a conflict instead of skipping a step.
+func (r *Repository) Transition(id uuid.UUID, from string, checkpoint Checkpoint, delivery *history.NGODonationHistory) (*NGOPickupSchedule, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
 	}
-	if len(remindersJSONOut) > 0 {
-		json.Unmarshal(remindersJSONOut, &schedule.Reminders)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
-	return nil
+	defer tx.Rollback()
+
+	var current string
+	if err := tx.QueryRow(`SELECT status FROM ngo_pickup_schedules WHERE id = $1 FOR UPDATE`, id).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	if current != from || !CanTransition(current, checkpoint.Status) {
+		return nil, errIllegalTransition(current, checkpoint.Status)
+	}
+
+	// Pickups written before checkpoints were tracked may hold an object, which is replaced
+	query := `
+		UPDATE ngo_pickup_schedules
+		SET status = $2,
+			checkpoints = CASE WHEN jsonb_typeof(checkpoints) = 'array' THEN checkpoints ELSE '[]'::jsonb END || $3::jsonb,
+			updated_at = $4
+		WHERE id = $1
+		RETURNING ` + pickupColumns
+	schedule, err := scanPickup(tx.QueryRow(query, id, checkpoint.Status, Checkpoints{checkpoint}, checkpoint.Timestamp))
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	if delivery != nil {
+		if err := history.CreateTx(tx, delivery); err != nil {
+			return nil, err
+		}
+		if err := offers.CompleteTx(tx, schedule.OfferID); err != nil {
+			return nil, err
+		}
+	}
+
+	// A failed pickup hands the offer back unless another pickup is still collecting it
+	if checkpoint.Status == StatusFailed {
+		var active bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM ngo_pickup_schedules WHERE offer_id = $1 AND id <> $2 AND status NOT IN ($3, $4))`, schedule.OfferID, id, StatusDelivered, StatusFailed).Scan(&active)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		if !active {
+			if err := offers.ReleaseTx(tx, schedule.OfferID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return schedule, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPickup(row rowScanner) (*NGOPickupSchedule, error) {
+	schedule := &NGOPickupSchedule{}
+	var remindersJSON []byte
+	if err := row.Scan(&schedule.ID, &schedule.OfferID, &schedule.RouteID, &schedule.ScheduledFor, &schedule.ETAMinutes, &schedule.SequenceNumber, &schedule.VolunteerName, &schedule.VolunteerContact, &schedule.VehicleType, &schedule.Status, &schedule.Checkpoints, &remindersJSON, &schedule.Notes, &schedule.CreatedAt, &schedule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(remindersJSON) > 0 {
+		json.Unmarshal(remindersJSON, &schedule.Reminders)
+	}
+	return schedule, nil
 }
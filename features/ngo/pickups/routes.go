@@ -9,7 +9,7 @@ import (
 func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.TrimPrefix(r.URL.Path, "/api/v1/ngo/pickups/")
+		path := strings.Trim(r.URL.Path, "/")
 		pathParts := strings.Split(path, "/")
 		switch {
 		case path == "" && r.Method == http.MethodGet:
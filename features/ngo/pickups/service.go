@@ -1,18 +1,39 @@
 package pickups
 
 import (
+	"fmt"
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/impact"
+	"foodlink_backend/features/ngo/history"
+	"foodlink_backend/features/ngo/offers"
 	"foodlink_backend/utils"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var errOfferNotAccepted = errors.NewAppError(http.StatusConflict, "Pickups can only be scheduled for accepted offers")
+
+// errIllegalTransition reports a status change the pickup lifecycle does not allow
+func errIllegalTransition(from, to string) error {
+	return errors.NewAppError(http.StatusConflict, fmt.Sprintf("Cannot move a pickup from %s to %s", from, to))
+}
+
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	offers *offers.Service
+	impact *impact.Service
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{
+		repo:   NewRepository(),
+		offers: offers.NewService(),
+		impact: impact.NewService(),
+	}
 }
 
 func (s *Service) GetAllByOfferID(offerID uuid.UUID) ([]*NGOPickupSchedule, error) {
@@ -23,10 +44,23 @@ func (s *Service) GetByID(id uuid.UUID) (*NGOPickupSchedule, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *Service) Create(req *CreateNGOPickupScheduleRequest) (*NGOPickupSchedule, error) {
+// Create schedules a pickup, starting its checkpoint trail with the actor who
+// scheduled it. Only the NGO that owns the offer may schedule it, and only once
+// the offer is accepted or already scheduled.
+func (s *Service) Create(req *CreateNGOPickupScheduleRequest, actorID uuid.UUID, actorName string) (*NGOPickupSchedule, error) {
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
 	}
+	offer, err := s.offers.GetByID(req.OfferID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.NGOUserID != actorID {
+		return nil, errors.ErrForbidden
+	}
+	if offer.Status != offers.StatusAccepted && offer.Status != offers.StatusScheduled {
+		return nil, errOfferNotAccepted
+	}
 	schedule := &NGOPickupSchedule{
 		ID:              uuid.New(),
 		OfferID:         req.OfferID,
@@ -35,8 +69,8 @@ func (s *Service) Create(req *CreateNGOPickupScheduleRequest) (*NGOPickupSchedul
 		VolunteerName:   req.VolunteerName,
 		VolunteerContact: req.VolunteerContact,
 		VehicleType:     req.VehicleType,
-		Status:          "scheduled",
-		Checkpoints:     JSONB(req.Checkpoints),
+		Status:          StatusScheduled,
+		Checkpoints:     Checkpoints{NewCheckpoint(StatusScheduled, "", actorID, actorName, "", time.Now())},
 		Reminders:       JSONB(req.Reminders),
 		Notes:           req.Notes,
 	}
@@ -46,11 +80,20 @@ func (s *Service) Create(req *CreateNGOPickupScheduleRequest) (*NGOPickupSchedul
 	return schedule, nil
 }
 
-func (s *Service) Update(id uuid.UUID, req *UpdateNGOPickupScheduleRequest) (*NGOPickupSchedule, error) {
+// Update changes a pickup's details. Only the NGO that owns the pickup's offer
+// may change them.
+func (s *Service) Update(id uuid.UUID, actorID uuid.UUID, req *UpdateNGOPickupScheduleRequest) (*NGOPickupSchedule, error) {
 	schedule, err := s.repo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	offer, err := s.offers.GetByID(schedule.OfferID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.NGOUserID != actorID {
+		return nil, errors.ErrForbidden
+	}
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
 	}
@@ -69,9 +112,6 @@ func (s *Service) Update(id uuid.UUID, req *UpdateNGOPickupScheduleRequest) (*NG
 	if req.VehicleType != "" {
 		schedule.VehicleType = req.VehicleType
 	}
-	if req.Checkpoints != nil {
-		schedule.Checkpoints = JSONB(req.Checkpoints)
-	}
 	if req.Reminders != nil {
 		schedule.Reminders = JSONB(req.Reminders)
 	}
@@ -84,17 +124,117 @@ func (s *Service) Update(id uuid.UUID, req *UpdateNGOPickupScheduleRequest) (*NG
 	return schedule, nil
 }
 
-func (s *Service) UpdateStatus(id uuid.UUID, req *UpdatePickupStatusRequest) (*NGOPickupSchedule, error) {
+// UpdateStatus moves a pickup one step along its lifecycle and records a
+// checkpoint for it. Only the NGO that owns the offer may move its pickups.
+// Delivering a pickup records the donation in the NGO's history and completes
+// the offer in the same transaction; a failed pickup releases the offer back to
+// pending and frees its capacity.
+func (s *Service) UpdateStatus(id uuid.UUID, actorID uuid.UUID, actorName string, req *UpdatePickupStatusRequest) (*NGOPickupSchedule, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
 	schedule, err := s.repo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
-	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
-		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	offer, err := s.offers.GetByID(schedule.OfferID)
+	if err != nil {
+		return nil, err
 	}
-	schedule.Status = req.Status
-	if err := s.repo.Update(schedule); err != nil {
+	if offer.NGOUserID != actorID {
+		return nil, errors.ErrForbidden
+	}
+	if !CanTransition(schedule.Status, req.Status) {
+		return nil, errIllegalTransition(schedule.Status, req.Status)
+	}
+
+	now := time.Now()
+	checkpoint := NewCheckpoint(req.Status, schedule.Status, actorID, actorName, req.Note, now)
+	var delivery *history.NGODonationHistory
+	if req.Status == StatusDelivered {
+		delivery = s.deliveryRecord(schedule, offer, req, now)
+	}
+
+	updated, err := s.repo.Transition(id, schedule.Status, checkpoint, delivery)
+	if err != nil {
 		return nil, err
 	}
-	return schedule, nil
+	events.Publish(events.PickupStatusChanged{
+		Meta:       events.NewMeta(),
+		NGOUserID:  offer.NGOUserID,
+		PickupID:   updated.ID,
+		OfferID:    offer.ID,
+		OfferTitle: offer.OfferTitle,
+		From:       schedule.Status,
+		To:         updated.Status,
+	})
+	if delivery != nil {
+		events.Publish(events.PickupDelivered{
+			Meta:           events.NewMeta(),
+			NGOUserID:      offer.NGOUserID,
+			PickupID:       updated.ID,
+			OfferID:        offer.ID,
+			WeightKg:       delivery.WeightKg,
+			MealsEstimated: delivery.MealsProvided,
+		})
+	}
+	return updated, nil
+}
+
+// deliveryRecord builds the history entry for a delivered pickup. Weight, meals
+// and beneficiaries reported by the volunteer override the offer's estimates;
+// beneficiaries default to one per meal.
+func (s *Service) deliveryRecord(schedule *NGOPickupSchedule, offer *offers.NGODonationOffer, req *UpdatePickupStatusRequest, now time.Time) *history.NGODonationHistory {
+	category := impact.DefaultCategory
+	var names []string
+	for _, item := range offer.ItemList() {
+		if c, ok := item["category"].(string); ok && c != "" && category == impact.DefaultCategory {
+			category = c
+		}
+		if name, ok := item["name"].(string); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	summary := strings.Join(names, ", ")
+	if summary == "" {
+		summary = offer.OfferTitle
+	}
+
+	weightKg := offer.WeightKg
+	if req.WeightKg != nil {
+		weightKg = *req.WeightKg
+	}
+	measure := s.impact.Measure(category, weightKg, "kg")
+	meals := offer.MealsEstimated
+	switch {
+	case req.MealsProvided != nil:
+		meals = *req.MealsProvided
+	case req.WeightKg != nil || meals == 0:
+		meals = measure.Meals
+	}
+	beneficiaries := meals
+	if req.Beneficiaries != nil {
+		beneficiaries = *req.Beneficiaries
+	}
+
+	pickupTime, ok := schedule.Checkpoints.ReachedAt(StatusPickedUp)
+	if !ok {
+		pickupTime = schedule.ScheduledFor
+	}
+	offerID := offer.ID
+	return &history.NGODonationHistory{
+		ID:             uuid.New(),
+		NGOUserID:      offer.NGOUserID,
+		OfferID:        &offerID,
+		DonorName:      offer.DonorName,
+		DonorType:      offer.DonorType,
+		ItemsSummary:   summary,
+		WeightKg:       weightKg,
+		MealsProvided:  meals,
+		CO2PreventedKg: measure.CO2Kg,
+		Beneficiaries:  beneficiaries,
+		PickupTime:     pickupTime,
+		DeliveredAt:    &now,
+		Status:         history.StatusDelivered,
+	}
 }
@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUser(r *http.Request) (*auth.User, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return user, nil
+}
+
+// Plan handles POST /api/v1/ngo/routes/plan
+// @Summary      Plan pickup routes
+// @Description  Order the NGO's accepted offers for a day into one route per volunteer and schedule their pickups
+// @Tags         ngo-routes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      PlanRoutesRequest  true  "Day and volunteers to plan"
+// @Success      201      {object}  RoutePlan
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /ngo/routes/plan [post]
+func (h *Handler) Plan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var req PlanRoutesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	plan, err := h.service.Plan(user.ID, user.Name, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to plan routes", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Routes planned successfully", plan)
+}
+
+// GetByID handles GET /api/v1/ngo/routes/:id
+// @Summary      Get route details
+// @Description  Get a pickup route with its stops in visiting order
+// @Tags         ngo-routes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Route ID"
+// @Success      200  {object}  PickupRoute
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /ngo/routes/{id} [get]
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	route, err := h.service.GetByID(id, user.ID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve route", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Route retrieved successfully", route)
+}
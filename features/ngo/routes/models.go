@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JSONB map[string]interface{}
+
+func (j JSONB) Value() (driver.Value, error) {
+	if j == nil {
+		return nil, nil
+	}
+	return json.Marshal(j)
+}
+
+func (j *JSONB) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+// Route statuses
+const (
+	StatusPlanned    = "planned"
+	StatusInProgress = "in-progress"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+)
+
+// PickupRoute is one volunteer's ordered run of pickups on a day
+type PickupRoute struct {
+	ID               uuid.UUID    `json:"id" db:"id"`
+	NGOUserID        uuid.UUID    `json:"ngo_user_id" db:"ngo_user_id"`
+	RouteDate        time.Time    `json:"route_date" db:"route_date"`
+	VolunteerName    string       `json:"volunteer_name" db:"volunteer_name"`
+	VolunteerContact string       `json:"volunteer_contact" db:"volunteer_contact"`
+	VehicleType      string       `json:"vehicle_type" db:"vehicle_type"`
+	CapacityKg       float64      `json:"capacity_kg" db:"capacity_kg"`
+	LoadKg           float64      `json:"load_kg" db:"load_kg"`
+	StartPoint       JSONB        `json:"start_point,omitempty" db:"start_point"`
+	StartsAt         time.Time    `json:"starts_at" db:"starts_at"`
+	EndsAt           time.Time    `json:"ends_at" db:"ends_at"`
+	TotalDistanceKm  float64      `json:"total_distance_km" db:"total_distance_km"`
+	Status           string       `json:"status" db:"status"`
+	Stops            []*RouteStop `json:"stops"`
+	CreatedAt        time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// RouteStop is a pickup on a route together with the offer it collects
+type RouteStop struct {
+	PickupID       uuid.UUID `json:"pickup_id"`
+	OfferID        uuid.UUID `json:"offer_id"`
+	SequenceNumber int       `json:"sequence_number"`
+	ScheduledFor   time.Time `json:"scheduled_for"`
+	ETAMinutes     int       `json:"eta_minutes"`
+	Status         string    `json:"status"`
+	OfferTitle     string    `json:"offer_title"`
+	DonorName      string    `json:"donor_name"`
+	LocationLabel  string    `json:"location_label"`
+	GeoPoint       JSONB     `json:"geo_point,omitempty"`
+	WeightKg       float64   `json:"weight_kg"`
+}
+
+// VolunteerShift is a volunteer available to drive a route. Start and end are
+// clock times on the planned day and default to 09:00 and 18:00.
+type VolunteerShift struct {
+	Name        string   `json:"name" validate:"required,min=1,max=255"`
+	Contact     string   `json:"contact" validate:"required,min=1,max=255"`
+	VehicleType string   `json:"vehicle_type" validate:"required,oneof=van bike car on-foot"`
+	CapacityKg  *float64 `json:"capacity_kg,omitempty" validate:"omitempty,gt=0"`
+	StartTime   string   `json:"start_time,omitempty"`
+	EndTime     string   `json:"end_time,omitempty"`
+}
+
+// PlanRoutesRequest plans the NGO's accepted offers for a day. OfferIDs limits
+// planning to some of them, and StartPoint overrides the NGO's own location as
+// the place routes start and end.
+type PlanRoutesRequest struct {
+	Date           string                 `json:"date" validate:"required,datetime=2006-01-02"`
+	Volunteers     []VolunteerShift       `json:"volunteers" validate:"required,min=1,dive"`
+	OfferIDs       []uuid.UUID            `json:"offer_ids,omitempty"`
+	StartPoint     map[string]interface{} `json:"start_point,omitempty"`
+	ServiceMinutes *int                   `json:"service_minutes,omitempty" validate:"omitempty,gte=0,lte=120"`
+}
+
+// UnplannedOffer is an offer the planner could not fit on any route
+type UnplannedOffer struct {
+	OfferID    uuid.UUID `json:"offer_id"`
+	OfferTitle string    `json:"offer_title"`
+	Reason     string    `json:"reason"`
+}
+
+// RoutePlan is the outcome of planning a day
+type RoutePlan struct {
+	Date      string           `json:"date"`
+	Routes    []*PickupRoute   `json:"routes"`
+	Unplanned []UnplannedOffer `json:"unplanned"`
+}
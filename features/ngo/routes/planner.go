@@ -0,0 +1,210 @@
+package routes
+
+import (
+	"foodlink_backend/features/ngo/offers"
+	"foodlink_backend/utils"
+	"time"
+)
+
+// vehicleProfiles are the average urban speed and default load of each vehicle type
+var vehicleProfiles = map[string]struct{ speedKmh, capacityKg float64 }{
+	"on-foot": {5, 10},
+	"bike":    {15, 25},
+	"car":     {30, 150},
+	"van":     {25, 600},
+}
+
+const (
+	defaultServiceMinutes = 10
+	defaultShiftStart     = "09:00"
+	defaultShiftEnd       = "18:00"
+	// maxTwoOptPasses bounds local search on long routes
+	maxTwoOptPasses = 50
+)
+
+type point struct {
+	lat, lng float64
+}
+
+// stop is an accepted offer waiting to be collected between opens and closes
+type stop struct {
+	offer    *offers.NGODonationOffer
+	at       point
+	opens    time.Time
+	closes   time.Time
+	weightKg float64
+}
+
+// vehicle is a volunteer's shift and what they can carry
+type vehicle struct {
+	shift      VolunteerShift
+	speedKmh   float64
+	capacityKg float64
+	start      time.Time
+	end        time.Time
+}
+
+// visit is when a route reaches a stop and when the pickup can begin, which is
+// later if the route has to wait for the pickup window to open
+type visit struct {
+	arrive time.Time
+	begin  time.Time
+}
+
+// plannedRoute is the order a vehicle visits stops in, with its timings
+type plannedRoute struct {
+	vehicle *vehicle
+	order   []int
+	visits  []visit
+	km      float64
+	departs time.Time
+	returns time.Time
+	loadKg  float64
+}
+
+// planner orders stops into routes over straight-line distances. Routes leave
+// from and return to the depot; without a depot they start at their first stop
+// and end at their last.
+type planner struct {
+	stops   []*stop
+	depot   *point
+	service time.Duration
+}
+
+// travel returns the distance between two points and how long the vehicle
+// takes to cover it
+func (p *planner) travel(v *vehicle, from, to *point) (km float64, d time.Duration) {
+	if from == nil || to == nil {
+		return 0, 0
+	}
+	km = utils.HaversineKm(from.lat, from.lng, to.lat, to.lng)
+	return km, time.Duration(km / v.speedKmh * float64(time.Hour))
+}
+
+// simulate drives a vehicle through stops in order. ok is false when a pickup
+// window closes before the vehicle gets there or the vehicle cannot be back
+// before its shift ends.
+func (p *planner) simulate(v *vehicle, order []int) (visits []visit, km float64, returns time.Time, ok bool) {
+	pos, now := p.depot, v.start
+	for _, i := range order {
+		s := p.stops[i]
+		legKm, d := p.travel(v, pos, &s.at)
+		km += legKm
+		arrive := now.Add(d)
+		begin := arrive
+		if begin.Before(s.opens) {
+			begin = s.opens
+		}
+		if begin.After(s.closes) {
+			return nil, 0, time.Time{}, false
+		}
+		visits = append(visits, visit{arrive: arrive, begin: begin})
+		now = begin.Add(p.service)
+		pos = &s.at
+	}
+	legKm, d := p.travel(v, pos, p.depot)
+	km += legKm
+	now = now.Add(d)
+	if now.After(v.end) {
+		return nil, 0, time.Time{}, false
+	}
+	return visits, km, now, true
+}
+
+// nearestNeighbour builds a route by repeatedly visiting the unplanned stop the
+// vehicle can start picking up soonest, counting both travel and waiting for
+// its window, until no remaining stop fits in time or load
+func (p *planner) nearestNeighbour(v *vehicle, planned []bool) []int {
+	var order []int
+	load := 0.0
+	for {
+		best := -1
+		var bestBegin time.Time
+		for i, s := range p.stops {
+			if planned[i] || containsIndex(order, i) || load+s.weightKg > v.capacityKg {
+				continue
+			}
+			visits, _, _, ok := p.simulate(v, append(order[:len(order):len(order)], i))
+			if !ok {
+				continue
+			}
+			begin := visits[len(visits)-1].begin
+			if best < 0 || begin.Before(bestBegin) || (begin.Equal(bestBegin) && s.closes.Before(p.stops[best].closes)) {
+				best, bestBegin = i, begin
+			}
+		}
+		if best < 0 {
+			return order
+		}
+		order = append(order, best)
+		load += p.stops[best].weightKg
+	}
+}
+
+// twoOpt shortens a route by reversing segments of it, keeping only changes
+// that still meet every pickup window
+func (p *planner) twoOpt(v *vehicle, order []int) []int {
+	_, bestKm, _, ok := p.simulate(v, order)
+	if !ok {
+		return order
+	}
+	for pass := 0; pass < maxTwoOptPasses; pass++ {
+		improved := false
+		for i := 0; i < len(order)-1; i++ {
+			for k := i + 1; k < len(order); k++ {
+				candidate := reverseSegment(order, i, k)
+				if _, km, _, ok := p.simulate(v, candidate); ok && km < bestKm-1e-9 {
+					order, bestKm, improved = candidate, km, true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return order
+}
+
+// plan fills vehicles in the order given. planned reports which stops made it
+// onto a route.
+func (p *planner) plan(vehicles []*vehicle) (routes []*plannedRoute, planned []bool) {
+	planned = make([]bool, len(p.stops))
+	for _, v := range vehicles {
+		order := p.nearestNeighbour(v, planned)
+		if len(order) == 0 {
+			continue
+		}
+		order = p.twoOpt(v, order)
+		visits, km, returns, _ := p.simulate(v, order)
+		route := &plannedRoute{vehicle: v, order: order, visits: visits, km: km, returns: returns}
+		for _, i := range order {
+			planned[i] = true
+			route.loadKg += p.stops[i].weightKg
+		}
+		// Leave as late as the first pickup allows rather than at the start of the shift
+		_, firstLeg := p.travel(v, p.depot, &p.stops[order[0]].at)
+		route.departs = visits[0].begin.Add(-firstLeg)
+		if route.departs.Before(v.start) {
+			route.departs = v.start
+		}
+		routes = append(routes, route)
+	}
+	return routes, planned
+}
+
+func reverseSegment(order []int, i, k int) []int {
+	reversed := append([]int(nil), order...)
+	for ; i < k; i, k = i+1, k-1 {
+		reversed[i], reversed[k] = reversed[k], reversed[i]
+	}
+	return reversed
+}
+
+func containsIndex(order []int, i int) bool {
+	for _, j := range order {
+		if j == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var shiftStart = time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+func testVehicle(capacityKg float64) *vehicle {
+	return &vehicle{speedKmh: 30, capacityKg: capacityKg, start: shiftStart, end: shiftStart.Add(9 * time.Hour)}
+}
+
+// openStop is a stop whose window spans the whole shift
+func openStop(lat, lng, weightKg float64) *stop {
+	return &stop{at: point{lat, lng}, opens: shiftStart, closes: shiftStart.Add(9 * time.Hour), weightKg: weightKg}
+}
+
+func TestPlanLeavesInfeasibleWindowsUnplanned(t *testing.T) {
+	depot := point{0, 0}
+	p := &planner{
+		depot:   &depot,
+		service: defaultServiceMinutes * time.Minute,
+		stops: []*stop{
+			openStop(0.01, 0.01, 5),
+			// About 15 km away at 30 km/h, so its 10 minute window closes before anyone arrives
+			{at: point{0.1, 0.1}, opens: shiftStart, closes: shiftStart.Add(10 * time.Minute), weightKg: 5},
+			// Closed before the shift starts
+			{at: point{0.01, 0}, opens: shiftStart.Add(-2 * time.Hour), closes: shiftStart.Add(-time.Hour), weightKg: 5},
+			openStop(0, 0.01, 5),
+		},
+	}
+	routes, planned := p.plan([]*vehicle{testVehicle(100), testVehicle(100)})
+
+	want := []bool{true, false, false, true}
+	for i := range want {
+		if planned[i] != want[i] {
+			t.Fatalf("planned = %v, want %v", planned, want)
+		}
+	}
+	if len(routes) != 1 {
+		t.Fatalf("got %d routes, want the feasible stops on one", len(routes))
+	}
+	for j, i := range routes[0].order {
+		s := p.stops[i]
+		if begin := routes[0].visits[j].begin; begin.Before(s.opens) || begin.After(s.closes) {
+			t.Fatalf("stop %d begins at %v outside its window %v-%v", i, begin, s.opens, s.closes)
+		}
+	}
+}
+
+func TestPlanWaitsForLateWindow(t *testing.T) {
+	depot := point{0, 0}
+	opens := shiftStart.Add(2 * time.Hour)
+	p := &planner{
+		depot:   &depot,
+		service: defaultServiceMinutes * time.Minute,
+		stops:   []*stop{{at: point{0.01, 0}, opens: opens, closes: opens.Add(30 * time.Minute), weightKg: 5}},
+	}
+	routes, planned := p.plan([]*vehicle{testVehicle(100)})
+	if !planned[0] || len(routes) != 1 {
+		t.Fatalf("planned = %v, want the stop planned", planned)
+	}
+	if begin := routes[0].visits[0].begin; !begin.Equal(opens) {
+		t.Fatalf("pickup begins at %v, want when the window opens at %v", begin, opens)
+	}
+	if !routes[0].departs.After(shiftStart) {
+		t.Fatalf("route departs at %v, want it to leave later than the start of the shift", routes[0].departs)
+	}
+}
+
+func TestTwoOptNeverLengthensRoute(t *testing.T) {
+	depot := point{0, 0}
+	rng := rand.New(rand.NewSource(1))
+	v := testVehicle(1000)
+	for trial := 0; trial < 50; trial++ {
+		p := &planner{depot: &depot, service: time.Minute}
+		order := make([]int, 3+rng.Intn(6))
+		for i := range order {
+			p.stops = append(p.stops, openStop(rng.Float64()*0.05, rng.Float64()*0.05, 1))
+			order[i] = i
+		}
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+		_, beforeKm, _, ok := p.simulate(v, order)
+		if !ok {
+			t.Fatalf("trial %d: starting route is infeasible", trial)
+		}
+		improved := p.twoOpt(v, order)
+		_, afterKm, _, ok := p.simulate(v, improved)
+		if !ok {
+			t.Fatalf("trial %d: 2-opt produced an infeasible route %v", trial, improved)
+		}
+		if afterKm > beforeKm+1e-9 {
+			t.Fatalf("trial %d: 2-opt lengthened the route from %.3f km to %.3f km", trial, beforeKm, afterKm)
+		}
+		seen := map[int]bool{}
+		for _, i := range improved {
+			seen[i] = true
+		}
+		if len(improved) != len(order) || len(seen) != len(order) {
+			t.Fatalf("trial %d: 2-opt changed the stops from %v to %v", trial, order, improved)
+		}
+	}
+}
+
+func TestTwoOptUncrossesRoute(t *testing.T) {
+	depot := point{0, 0}
+	p := &planner{
+		depot:   &depot,
+		service: time.Minute,
+		stops: []*stop{
+			openStop(0, 0.02, 1),
+			openStop(0.02, 0.02, 1),
+			openStop(0.02, 0, 1),
+		},
+	}
+	v := testVehicle(100)
+	crossed := []int{1, 0, 2}
+	_, crossedKm, _, _ := p.simulate(v, crossed)
+	_, km, _, _ := p.simulate(v, p.twoOpt(v, crossed))
+	if km >= crossedKm {
+		t.Fatalf("2-opt kept a crossed route of %.3f km, got %.3f km", crossedKm, km)
+	}
+}
+
+func TestPlanRespectsVehicleCapacity(t *testing.T) {
+	depot := point{0, 0}
+	p := &planner{
+		depot:   &depot,
+		service: defaultServiceMinutes * time.Minute,
+		stops: []*stop{
+			openStop(0.01, 0, 10),
+			openStop(0.02, 0, 10),
+			openStop(0.03, 0, 10),
+			openStop(0.04, 0, 30),
+		},
+	}
+	vehicles := []*vehicle{testVehicle(25), testVehicle(25)}
+	routes, planned := p.plan(vehicles)
+
+	for _, route := range routes {
+		load := 0.0
+		for _, i := range route.order {
+			load += p.stops[i].weightKg
+		}
+		if load > route.vehicle.capacityKg || route.loadKg != load {
+			t.Fatalf("route carries %v kg (reported %v) on a %v kg vehicle", load, route.loadKg, route.vehicle.capacityKg)
+		}
+	}
+	if planned[3] {
+		t.Fatal("a 30 kg stop was planned though no vehicle carries more than 25 kg")
+	}
+	count := 0
+	for _, ok := range planned[:3] {
+		if ok {
+			count++
+		}
+	}
+	if count != 3 || len(routes) != 2 {
+		t.Fatalf("planned %d of the three 10 kg stops on %d routes, want all three on two", count, len(routes))
+	}
+}
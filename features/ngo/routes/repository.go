@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/ngo/offers"
+	"foodlink_backend/features/ngo/pickups"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// routeColumns lists the columns scanned by scanRoute
+const routeColumns = `id, ngo_user_id, route_date, volunteer_name, volunteer_contact, vehicle_type, capacity_kg, COALESCE(load_kg, 0), start_point, starts_at, ends_at, COALESCE(total_distance_km, 0), status, created_at, updated_at`
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+func (r *Repository) GetByID(id uuid.UUID) (*PickupRoute, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	route, err := scanRoute(r.db.QueryRow(`SELECT `+routeColumns+` FROM ngo_pickup_routes WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	query := `
+		SELECT p.id, p.offer_id, COALESCE(p.sequence_number, 0), p.scheduled_for, COALESCE(p.eta_minutes, 0), p.status,
+			o.offer_title, o.donor_name, o.location_label, o.geo_point, o.weight_kg
+		FROM ngo_pickup_schedules p
+		JOIN ngo_donation_offers o ON o.id = p.offer_id
+		WHERE p.route_id = $1
+		ORDER BY p.sequence_number, p.scheduled_for
+	`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	route.Stops = []*RouteStop{}
+	for rows.Next() {
+		stop := &RouteStop{}
+		if err := rows.Scan(&stop.PickupID, &stop.OfferID, &stop.SequenceNumber, &stop.ScheduledFor, &stop.ETAMinutes, &stop.Status, &stop.OfferTitle, &stop.DonorName, &stop.LocationLabel, &stop.GeoPoint, &stop.WeightKg); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		route.Stops = append(route.Stops, stop)
+	}
+	return route, rows.Err()
+}
+
+// CreatePlan saves planned routes and their pickups in one transaction. Each
+// pickup's offer moves from accepted to scheduled; if any offer is no longer
+// accepted nothing is saved.
+func (r *Repository) CreatePlan(routes []*PickupRoute, stops [][]*pickups.NGOPickupSchedule) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO ngo_pickup_routes (id, ngo_user_id, route_date, volunteer_name, volunteer_contact, vehicle_type, capacity_kg, load_kg, start_point, starts_at, ends_at, total_distance_km, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING ` + routeColumns
+	now := time.Now()
+	for i, route := range routes {
+		created, err := scanRoute(tx.QueryRow(query, route.ID, route.NGOUserID, route.RouteDate.Format("2006-01-02"), route.VolunteerName, route.VolunteerContact, route.VehicleType, route.CapacityKg, route.LoadKg, route.StartPoint, route.StartsAt, route.EndsAt, route.TotalDistanceKm, route.Status, now, now))
+		if err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+		created.Stops = route.Stops
+		*route = *created
+		for _, schedule := range stops[i] {
+			if err := offers.ScheduleTx(tx, schedule.OfferID); err != nil {
+				return err
+			}
+			if err := pickups.ScheduleTx(tx, schedule); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRoute(row rowScanner) (*PickupRoute, error) {
+	route := &PickupRoute{}
+	if err := row.Scan(&route.ID, &route.NGOUserID, &route.RouteDate, &route.VolunteerName, &route.VolunteerContact, &route.VehicleType, &route.CapacityKg, &route.LoadKg, &route.StartPoint, &route.StartsAt, &route.EndsAt, &route.TotalDistanceKm, &route.Status, &route.CreatedAt, &route.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
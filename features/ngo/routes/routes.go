@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		pathParts := strings.Split(path, "/")
+		switch {
+		case path == "plan" && r.Method == http.MethodPost:
+			handler.Plan(w, r)
+		case len(pathParts) == 1 && len(pathParts[0]) == 36 && r.Method == http.MethodGet:
+			handler.GetByID(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
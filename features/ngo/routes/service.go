@@ -0,0 +1,281 @@
+package routes
+
+import (
+	"fmt"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/ngo/capacity"
+	"foodlink_backend/features/ngo/offers"
+	"foodlink_backend/features/ngo/pickups"
+	"foodlink_backend/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	repo     *Repository
+	offers   *offers.Service
+	capacity *capacity.Service
+}
+
+func NewService() *Service {
+	return &Service{
+		repo:     NewRepository(),
+		offers:   offers.NewService(),
+		capacity: capacity.NewService(),
+	}
+}
+
+func (s *Service) GetByID(id uuid.UUID, ngoUserID uuid.UUID) (*PickupRoute, error) {
+	route, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if route.NGOUserID != ngoUserID {
+		return nil, errors.ErrForbidden
+	}
+	return route, nil
+}
+
+// Plan orders the NGO's accepted offers for a day into routes, one per
+// volunteer, and schedules a pickup for every offer that fits. Offers that do
+// not fit stay accepted and are returned with the reason, so planning the day
+// again with more volunteers picks them up.
+func (s *Service) Plan(ngoUserID uuid.UUID, actorName string, req *PlanRoutesRequest) (*RoutePlan, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	day, _ := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	vehicles := make([]*vehicle, 0, len(req.Volunteers))
+	for _, shift := range req.Volunteers {
+		v, err := newVehicle(shift, day)
+		if err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, v)
+	}
+	depot, err := s.depot(ngoUserID, req.StartPoint)
+	if err != nil {
+		return nil, err
+	}
+	serviceMinutes := defaultServiceMinutes
+	if req.ServiceMinutes != nil {
+		serviceMinutes = *req.ServiceMinutes
+	}
+
+	accepted, err := s.offers.GetAllByNGOUserID(ngoUserID, offers.StatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+	plan := &RoutePlan{Date: req.Date, Routes: []*PickupRoute{}, Unplanned: []UnplannedOffer{}}
+	unplanned := func(offer *offers.NGODonationOffer, reason string) {
+		plan.Unplanned = append(plan.Unplanned, UnplannedOffer{OfferID: offer.ID, OfferTitle: offer.OfferTitle, Reason: reason})
+	}
+	p := &planner{depot: depot, service: time.Duration(serviceMinutes) * time.Minute}
+	for _, offer := range selectOffers(accepted, req.OfferIDs, plan) {
+		lat, lng, ok := utils.ParseGeoPoint(offer.GeoPoint)
+		if !ok {
+			unplanned(offer, "Offer has no pickup location")
+			continue
+		}
+		opens, closes, ok := pickupWindow(offer, day)
+		if !ok {
+			unplanned(offer, "Pickup window is not on "+req.Date)
+			continue
+		}
+		p.stops = append(p.stops, &stop{offer: offer, at: point{lat: lat, lng: lng}, opens: opens, closes: closes, weightKg: offer.WeightKg})
+	}
+
+	planned, placed := p.plan(vehicles)
+	maxCapacity := 0.0
+	for _, v := range vehicles {
+		if v.capacityKg > maxCapacity {
+			maxCapacity = v.capacityKg
+		}
+	}
+	for i, st := range p.stops {
+		switch {
+		case placed[i]:
+		case st.weightKg > maxCapacity:
+			unplanned(st.offer, fmt.Sprintf("Weighs %.1f kg, more than any volunteer can carry", st.weightKg))
+		default:
+			unplanned(st.offer, "No volunteer can reach it within its pickup window")
+		}
+	}
+	if len(planned) == 0 {
+		return plan, nil
+	}
+
+	routes := make([]*PickupRoute, 0, len(planned))
+	schedules := make([][]*pickups.NGOPickupSchedule, 0, len(planned))
+	for _, pr := range planned {
+		route, routeSchedules := s.newRoute(ngoUserID, actorName, day, p, pr)
+		routes = append(routes, route)
+		schedules = append(schedules, routeSchedules)
+	}
+	if err := s.repo.CreatePlan(routes, schedules); err != nil {
+		return nil, err
+	}
+	for i, route := range routes {
+		for j, schedule := range schedules[i] {
+			route.Stops[j].PickupID = schedule.ID
+		}
+	}
+	plan.Routes = routes
+	return plan, nil
+}
+
+// newRoute turns a planned route into the route and pickups to save
+func (s *Service) newRoute(ngoUserID uuid.UUID, actorName string, day time.Time, p *planner, pr *plannedRoute) (*PickupRoute, []*pickups.NGOPickupSchedule) {
+	v := pr.vehicle
+	route := &PickupRoute{
+		ID:               uuid.New(),
+		NGOUserID:        ngoUserID,
+		RouteDate:        day,
+		VolunteerName:    v.shift.Name,
+		VolunteerContact: v.shift.Contact,
+		VehicleType:      v.shift.VehicleType,
+		CapacityKg:       v.capacityKg,
+		LoadKg:           pr.loadKg,
+		StartsAt:         pr.departs,
+		EndsAt:           pr.returns,
+		TotalDistanceKm:  pr.km,
+		Status:           StatusPlanned,
+		Stops:            make([]*RouteStop, 0, len(pr.order)),
+	}
+	if p.depot != nil {
+		route.StartPoint = JSONB{"lat": p.depot.lat, "lng": p.depot.lng}
+	}
+
+	now := time.Now()
+	schedules := make([]*pickups.NGOPickupSchedule, 0, len(pr.order))
+	for seq, i := range pr.order {
+		st := p.stops[i]
+		begin := pr.visits[seq].begin
+		eta := int(begin.Sub(pr.departs).Minutes())
+		sequence := seq + 1
+		routeID := route.ID
+		schedules = append(schedules, &pickups.NGOPickupSchedule{
+			ID:               uuid.New(),
+			OfferID:          st.offer.ID,
+			RouteID:          &routeID,
+			ScheduledFor:     begin,
+			ETAMinutes:       &eta,
+			SequenceNumber:   &sequence,
+			VolunteerName:    v.shift.Name,
+			VolunteerContact: v.shift.Contact,
+			VehicleType:      v.shift.VehicleType,
+			Status:           pickups.StatusScheduled,
+			Checkpoints:      pickups.Checkpoints{pickups.NewCheckpoint(pickups.StatusScheduled, "", ngoUserID, actorName, "Planned on route", now)},
+		})
+		route.Stops = append(route.Stops, &RouteStop{
+			OfferID:        st.offer.ID,
+			SequenceNumber: sequence,
+			ScheduledFor:   begin,
+			ETAMinutes:     eta,
+			Status:         pickups.StatusScheduled,
+			OfferTitle:     st.offer.OfferTitle,
+			DonorName:      st.offer.DonorName,
+			LocationLabel:  st.offer.LocationLabel,
+			GeoPoint:       JSONB(st.offer.GeoPoint),
+			WeightKg:       st.weightKg,
+		})
+	}
+	return route, schedules
+}
+
+// depot is where routes start and end: the requested start point, or else the
+// NGO's own location. Without either, routes start at their first stop.
+func (s *Service) depot(ngoUserID uuid.UUID, startPoint map[string]interface{}) (*point, error) {
+	if startPoint != nil {
+		lat, lng, ok := utils.ParseGeoPoint(startPoint)
+		if !ok {
+			return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: start_point must have a valid lat and lng", nil)
+		}
+		return &point{lat: lat, lng: lng}, nil
+	}
+	settings, err := s.capacity.GetByUserID(ngoUserID)
+	if err == errors.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lat, lng, ok := utils.ParseGeoPoint(settings.GeoPoint); ok {
+		return &point{lat: lat, lng: lng}, nil
+	}
+	return nil, nil
+}
+
+// selectOffers narrows the accepted offers to the requested ones, noting any
+// requested offer that is not an accepted offer of the NGO
+func selectOffers(accepted []*offers.NGODonationOffer, ids []uuid.UUID, plan *RoutePlan) []*offers.NGODonationOffer {
+	if len(ids) == 0 {
+		return accepted
+	}
+	byID := make(map[uuid.UUID]*offers.NGODonationOffer, len(accepted))
+	for _, offer := range accepted {
+		byID[offer.ID] = offer
+	}
+	var selected []*offers.NGODonationOffer
+	for _, id := range ids {
+		offer, ok := byID[id]
+		if !ok {
+			plan.Unplanned = append(plan.Unplanned, UnplannedOffer{OfferID: id, Reason: "Not an accepted offer of this NGO"})
+			continue
+		}
+		delete(byID, id)
+		selected = append(selected, offer)
+	}
+	return selected
+}
+
+// pickupWindow returns when an offer can be collected on day. Offers without
+// a readable window can be collected all day; none can be collected after
+// they expire.
+func pickupWindow(offer *offers.NGODonationOffer, day time.Time) (opens, closes time.Time, ok bool) {
+	dayEnd := day.AddDate(0, 0, 1)
+	opens, closes, ok = offer.PickupWindowOn(day)
+	if !ok {
+		opens, closes = day, dayEnd
+	}
+	if !offer.ExpiresAt.IsZero() && offer.ExpiresAt.Before(closes) {
+		closes = offer.ExpiresAt
+	}
+	if !closes.After(opens) || !opens.Before(dayEnd) || !closes.After(day) {
+		return time.Time{}, time.Time{}, false
+	}
+	return opens, closes, true
+}
+
+// newVehicle reads a volunteer's shift on day, filling in the vehicle's
+// default speed and load
+func newVehicle(shift VolunteerShift, day time.Time) (*vehicle, error) {
+	profile := vehicleProfiles[shift.VehicleType]
+	v := &vehicle{shift: shift, speedKmh: profile.speedKmh, capacityKg: profile.capacityKg}
+	if shift.CapacityKg != nil {
+		v.capacityKg = *shift.CapacityKg
+	}
+	var err error
+	if v.start, err = shiftTime(shift.StartTime, defaultShiftStart, day); err != nil {
+		return nil, err
+	}
+	if v.end, err = shiftTime(shift.EndTime, defaultShiftEnd, day); err != nil {
+		return nil, err
+	}
+	if !v.end.After(v.start) {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+shift.Name+"'s shift must end after it starts", nil)
+	}
+	return v, nil
+}
+
+func shiftTime(value, fallback string, day time.Time) (time.Time, error) {
+	if value == "" {
+		value = fallback
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return time.Time{}, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: shift times must be HH:MM, got "+value, nil)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
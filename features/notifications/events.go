@@ -0,0 +1,167 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"foodlink_backend/events"
+	"foodlink_backend/features/ngo/offers"
+	"foodlink_backend/features/ngo/pickups"
+	shop_surplus "foodlink_backend/features/shop/surplus"
+
+	"github.com/google/uuid"
+)
+
+const eventSubscriber = "notifications"
+
+// pickupMessages describe each pickup status to the donor, e.g. "A volunteer
+// is on the way to collect 12 sandwiches"
+var pickupMessages = map[string]string{
+	pickups.StatusEnRoute:   "A volunteer is on the way to collect %s",
+	pickups.StatusPickedUp:  "%s has been collected",
+	pickups.StatusDelivered: "%s was delivered to the NGO",
+	pickups.StatusFailed:    "The pickup of %s could not be completed",
+}
+
+// RegisterEventHandlers turns domain events into notifications for the users
+// they concern, honouring each user's notification preferences. Each event is
+// stored at most once, so redelivered events are harmless.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.SurplusRequestCreated) error {
+		if e.RequesterID == e.OwnerID {
+			return nil
+		}
+		return s.notifyCommunity(e.EventID(), e.OwnerID, TypeClaim, e.PostID,
+			"New request for "+e.PostTitle, e.RequesterName+" asked for your surplus post")
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.SurplusCommentAdded) error {
+		if e.CommenterID == e.OwnerID {
+			return nil
+		}
+		return s.notifyCommunity(e.EventID(), e.OwnerID, TypeMessage, e.PostID,
+			"New comment on "+e.PostTitle, e.CommenterName+" commented on your surplus post")
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.LeftoverClaimed) error {
+		if e.ClaimerID == e.OwnerID {
+			return nil
+		}
+		return s.notifyCommunity(e.EventID(), e.OwnerID, TypeClaim, e.LeftoverID,
+			e.DishName+" was claimed", e.ClaimerName+" claimed your leftover")
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.OfferCreated) error {
+		if e.UrgencyLevel != offers.UrgencyHigh {
+			return nil
+		}
+		return s.notifyNGO(e.EventID(), e.NGOUserID, TypeUrgentOffer, SeverityCritical, e.OfferID,
+			"Urgent offer: "+e.Title, "This donation expires soon; accept it now to arrange a pickup")
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.PickupStatusChanged) error {
+		return s.notifyPickupDonor(e)
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.FeedbackRecorded) error {
+		severity, message := SeverityInfo, "Feedback was recorded"
+		if e.Rating != nil {
+			message = fmt.Sprintf("Rated %d/5", *e.Rating)
+			if *e.Rating <= 2 {
+				severity, message = SeverityWarning, message+"; follow up with a corrective action"
+			}
+		}
+		return s.notifyNGO(e.EventID(), e.NGOUserID, TypeFeedback, severity, e.FeedbackID,
+			"New feedback from "+e.RecipientName, message)
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.ShopSurplusPickupDue) error {
+		shop := e.ShopName
+		if shop == "" {
+			shop = "the shop"
+		}
+		title := "Pickup reminder: " + e.SKUName
+		message := fmt.Sprintf("Collect %g %s of %s from %s at %s", e.Quantity, e.Unit, e.SKUName, shop, e.PickupTime.Format("Jan 2 15:04"))
+		if e.DestinationType == shop_surplus.DestinationNGO {
+			return s.notifyNGO(e.EventID(), e.DestinationUserID, TypePickup, SeverityWarning, e.SurplusItemID, title, message)
+		}
+		return s.notifyCommunity(e.EventID(), e.DestinationUserID, TypeReminder, e.SurplusItemID, title, message)
+	})
+}
+
+// notifyCommunity notifies a community member unless their profile turns the
+// notification off. Claims and requests follow notify_on_claim, comments
+// follow notify_on_messages.
+func (s *Service) notifyCommunity(eventID, userID uuid.UUID, kind string, relatedID uuid.UUID, title, message string) error {
+	prefs, err := s.repo.GetCommunityPreferences(userID)
+	if err != nil {
+		return err
+	}
+	if !prefs.Enabled || (kind == TypeClaim && !prefs.OnClaim) || (kind == TypeMessage && !prefs.OnMessages) {
+		return nil
+	}
+	return s.create(eventID, &Notification{
+		UserID:          userID,
+		Channel:         ChannelCommunity,
+		Type:            kind,
+		Title:           title,
+		Message:         message,
+		RelatedEntityID: &relatedID,
+		Severity:        SeverityInfo,
+	})
+}
+
+func (s *Service) notifyNGO(eventID, ngoUserID uuid.UUID, kind, severity string, relatedID uuid.UUID, title, message string) error {
+	return s.create(eventID, &Notification{
+		UserID:          ngoUserID,
+		Channel:         ChannelNGO,
+		Type:            kind,
+		Title:           title,
+		Message:         message,
+		RelatedEntityID: &relatedID,
+		Severity:        severity,
+	})
+}
+
+// notifyPickupDonor tells the restaurant whose surplus is being collected how
+// the pickup is going, unless its preferences turn pickup notifications off
+func (s *Service) notifyPickupDonor(e events.PickupStatusChanged) error {
+	format, ok := pickupMessages[e.To]
+	if !ok {
+		return nil
+	}
+	donorID, err := s.repo.GetOfferDonor(e.OfferID)
+	if err != nil || donorID == nil {
+		return err
+	}
+	prefs, err := s.repo.GetRestaurantPreferences(*donorID)
+	if err != nil {
+		return err
+	}
+	if !prefs.Enabled || !prefs.OnPickup {
+		return nil
+	}
+	return s.create(e.EventID(), &Notification{
+		UserID:          *donorID,
+		Channel:         ChannelCommunity,
+		Type:            TypeSurplus,
+		Title:           "Pickup " + e.To + ": " + e.OfferTitle,
+		Message:         fmt.Sprintf(format, e.OfferTitle),
+		RelatedEntityID: &e.PickupID,
+		Severity:        SeverityInfo,
+	})
+}
+
+// create stores a notification once per event and announces it, so live
+// clients see it without polling
+func (s *Service) create(eventID uuid.UUID, n *Notification) error {
+	created, err := s.repo.CreateOnce(eventID, n)
+	if err != nil || !created {
+		return err
+	}
+	events.Publish(events.NotificationCreated{
+		Meta:            events.NewMeta(),
+		UserID:          n.UserID,
+		NotificationID:  n.ID,
+		Channel:         n.Channel,
+		Type:            n.Type,
+		Title:           n.Title,
+		Message:         n.Message,
+		Severity:        n.Severity,
+		RelatedEntityID: n.RelatedEntityID,
+	})
+	return nil
+}
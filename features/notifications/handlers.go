@@ -0,0 +1,169 @@
+package notifications
+
+import (
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// List handles GET /api/v1/notifications
+// @Summary      List notifications
+// @Description  List the authenticated user's community and NGO notifications, newest first
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        unread  query     bool  false  "Only unread notifications"
+// @Param        limit   query     int   false  "Number of notifications (default: 50, max: 100)"
+// @Success      200     {array}   Notification
+// @Failure      401     {object}  errors.AppError
+// @Router       /notifications [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	unread := r.URL.Query().Get("unread")
+	unreadOnly := unread == "1" || strings.ToLower(unread) == "true"
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	list, err := h.service.List(userID, unreadOnly, limit)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve notifications", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Notifications retrieved successfully", list)
+}
+
+// UnreadCount handles GET /api/v1/notifications/unread-count
+// @Summary      Count unread notifications
+// @Description  Count the authenticated user's unread notifications across channels
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  UnreadCount
+// @Failure      401  {object}  errors.AppError
+// @Router       /notifications/unread-count [get]
+func (h *Handler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	count, err := h.service.UnreadCount(userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to count notifications", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Unread notifications counted successfully", count)
+}
+
+// MarkRead handles PUT /api/v1/notifications/:id/read
+// @Summary      Mark a notification read
+// @Description  Mark one of the authenticated user's notifications as read
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Notification ID"
+// @Success      200  {object}  Notification
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /notifications/{id}/read [put]
+func (h *Handler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Split(strings.Trim(r.URL.Path, "/"), "/")[0])
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	notification, err := h.service.MarkRead(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update notification", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Notification marked as read", notification)
+}
+
+// MarkAllRead handles PUT /api/v1/notifications/read-all
+// @Summary      Mark all notifications read
+// @Description  Mark every unread notification of the authenticated user as read
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  MarkAllReadResult
+// @Failure      401  {object}  errors.AppError
+// @Router       /notifications/read-all [put]
+func (h *Handler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	result, err := h.service.MarkAllRead(userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update notifications", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Notifications marked as read", result)
+}
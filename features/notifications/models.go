@@ -0,0 +1,68 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Channels, naming the table a notification is stored in
+const (
+	ChannelCommunity = "community"
+	ChannelNGO       = "ngo"
+)
+
+// Notification types. Community notifications use claim, surplus, message and
+// reminder; NGO notifications use urgent-offer, pickup and feedback.
+const (
+	TypeClaim       = "claim"
+	TypeSurplus     = "surplus"
+	TypeMessage     = "message"
+	TypeReminder    = "reminder"
+	TypeUrgentOffer = "urgent-offer"
+	TypePickup      = "pickup"
+	TypeFeedback    = "feedback"
+)
+
+// Severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+type Notification struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	Channel         string     `json:"channel"`
+	Type            string     `json:"type" db:"type"`
+	Title           string     `json:"title" db:"title"`
+	Message         string     `json:"message" db:"message"`
+	RelatedEntityID *uuid.UUID `json:"related_entity_id,omitempty" db:"related_entity_id"`
+	Severity        string     `json:"severity" db:"severity"`
+	Read            bool       `json:"read" db:"read"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+type UnreadCount struct {
+	Unread int `json:"unread"`
+}
+
+type MarkAllReadResult struct {
+	Marked int64 `json:"marked"`
+}
+
+// CommunityPreferences are the notification flags on a user's community
+// profile. Users without a profile get every notification.
+type CommunityPreferences struct {
+	Enabled    bool
+	OnClaim    bool
+	OnMessages bool
+}
+
+// RestaurantPreferences are the notification flags in a restaurant's
+// preferences. Restaurants without preferences get every notification.
+type RestaurantPreferences struct {
+	Enabled  bool
+	OnPickup bool
+}
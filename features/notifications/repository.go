@@ -0,0 +1,204 @@
+package notifications
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+// Both tables are read through these column lists so they scan alike
+const (
+	communityColumns = `id, user_id, 'community', type, title, message, related_entity_id, 'info', COALESCE(read, FALSE), COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at`
+	ngoColumns       = `id, ngo_user_id, 'ngo', type, title, description, related_entity_id, COALESCE(severity, 'info'), COALESCE(read, FALSE), COALESCE(created_at, CURRENT_TIMESTAMP) AS created_at`
+)
+
+// List returns a user's notifications from both channels, newest first
+func (r *Repository) List(userID uuid.UUID, unreadOnly bool, limit int) ([]*Notification, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT ` + communityColumns + ` FROM community_notifications
+		WHERE user_id = $1 AND (NOT $2 OR NOT COALESCE(read, FALSE))
+		UNION ALL
+		SELECT ` + ngoColumns + ` FROM ngo_notifications
+		WHERE ngo_user_id = $1 AND (NOT $2 OR NOT COALESCE(read, FALSE))
+		ORDER BY created_at DESC
+		LIMIT $3`
+	rows, err := r.db.Query(query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	list := []*Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		list = append(list, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return list, nil
+}
+
+func (r *Repository) CountUnread(userID uuid.UUID) (int, error) {
+	if r.db == nil {
+		return 0, errors.ErrDatabase
+	}
+	query := `
+		SELECT (SELECT COUNT(*) FROM community_notifications WHERE user_id = $1 AND NOT COALESCE(read, FALSE))
+			+ (SELECT COUNT(*) FROM ngo_notifications WHERE ngo_user_id = $1 AND NOT COALESCE(read, FALSE))`
+	var count int
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of a user's notifications as read, whichever channel it
+// is in
+func (r *Repository) MarkRead(id, userID uuid.UUID) (*Notification, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	n, err := scanNotification(r.db.QueryRow(`UPDATE community_notifications SET read = TRUE WHERE id = $1 AND user_id = $2 RETURNING `+communityColumns, id, userID))
+	if err == sql.ErrNoRows {
+		n, err = scanNotification(r.db.QueryRow(`UPDATE ngo_notifications SET read = TRUE WHERE id = $1 AND ngo_user_id = $2 RETURNING `+ngoColumns, id, userID))
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return n, nil
+}
+
+// MarkAllRead marks every unread notification of a user as read and returns
+// how many there were
+func (r *Repository) MarkAllRead(userID uuid.UUID) (int64, error) {
+	if r.db == nil {
+		return 0, errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	var marked int64
+	for _, query := range []string{
+		`UPDATE community_notifications SET read = TRUE WHERE user_id = $1 AND NOT COALESCE(read, FALSE)`,
+		`UPDATE ngo_notifications SET read = TRUE WHERE ngo_user_id = $1 AND NOT COALESCE(read, FALSE)`,
+	} {
+		result, err := tx.Exec(query, userID)
+		if err != nil {
+			return 0, errors.WrapError(err, errors.ErrDatabase)
+		}
+		n, _ := result.RowsAffected()
+		marked += n
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return marked, nil
+}
+
+// CreateOnce stores a notification for an event and reports whether it did.
+// A redelivered event finds it already handled and creates nothing.
+func (r *Repository) CreateOnce(eventID uuid.UUID, n *Notification) (bool, error) {
+	if r.db == nil {
+		return false, errors.ErrDatabase
+	}
+	created := false
+	err := events.HandleOnce(r.db, eventSubscriber, eventID, func(tx *sql.Tx) error {
+		n.ID, n.CreatedAt = uuid.New(), time.Now()
+		var err error
+		if n.Channel == ChannelNGO {
+			_, err = tx.Exec(`INSERT INTO ngo_notifications (id, ngo_user_id, type, title, description, related_entity_id, severity, read, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, FALSE, $8)`,
+				n.ID, n.UserID, n.Type, n.Title, n.Message, n.RelatedEntityID, n.Severity, n.CreatedAt)
+		} else {
+			_, err = tx.Exec(`INSERT INTO community_notifications (id, user_id, type, title, message, related_entity_id, read, created_at) VALUES ($1, $2, $3, $4, $5, $6, FALSE, $7)`,
+				n.ID, n.UserID, n.Type, n.Title, n.Message, n.RelatedEntityID, n.CreatedAt)
+		}
+		if err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+		created = true
+		return nil
+	})
+	return created && err == nil, err
+}
+
+func (r *Repository) GetCommunityPreferences(userID uuid.UUID) (CommunityPreferences, error) {
+	prefs := CommunityPreferences{Enabled: true, OnClaim: true, OnMessages: true}
+	if r.db == nil {
+		return prefs, errors.ErrDatabase
+	}
+	query := `SELECT COALESCE(notifications_enabled, TRUE), COALESCE(notify_on_claim, TRUE), COALESCE(notify_on_messages, TRUE) FROM community_profiles WHERE user_id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&prefs.Enabled, &prefs.OnClaim, &prefs.OnMessages)
+	if err != nil && err != sql.ErrNoRows {
+		return prefs, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return prefs, nil
+}
+
+func (r *Repository) GetRestaurantPreferences(userID uuid.UUID) (RestaurantPreferences, error) {
+	prefs := RestaurantPreferences{Enabled: true, OnPickup: true}
+	if r.db == nil {
+		return prefs, errors.ErrDatabase
+	}
+	query := `SELECT COALESCE(notifications_enabled, TRUE), COALESCE(notify_on_pickup, TRUE) FROM restaurant_preferences WHERE user_id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&prefs.Enabled, &prefs.OnPickup)
+	if err != nil && err != sql.ErrNoRows {
+		return prefs, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return prefs, nil
+}
+
+// GetOfferDonor returns the restaurant whose surplus item an NGO offer was
+// made for, or nil when the offer did not come from a restaurant surplus item
+func (r *Repository) GetOfferDonor(offerID uuid.UUID) (*uuid.UUID, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT s.user_id FROM ngo_donation_offers o
+		JOIN restaurant_surplus_items s ON s.id = o.surplus_item_id
+		WHERE o.id = $1`
+	var donorID uuid.UUID
+	if err := r.db.QueryRow(query, offerID).Scan(&donorID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return &donorID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNotification(row rowScanner) (*Notification, error) {
+	n := &Notification{}
+	if err := row.Scan(&n.ID, &n.UserID, &n.Channel, &n.Type, &n.Title, &n.Message, &n.RelatedEntityID, &n.Severity, &n.Read, &n.CreatedAt); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		pathParts := strings.Split(path, "/")
+
+		switch {
+		case path == "" && r.Method == http.MethodGet:
+			handler.List(w, r)
+		case path == "unread-count" && r.Method == http.MethodGet:
+			handler.UnreadCount(w, r)
+		case path == "read-all" && r.Method == http.MethodPut:
+			handler.MarkAllRead(w, r)
+		case len(pathParts) == 2 && pathParts[1] == "read" && r.Method == http.MethodPut:
+			handler.MarkRead(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
@@ -0,0 +1,41 @@
+package notifications
+
+import (
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository()}
+}
+
+// List returns a user's notifications, newest first
+func (s *Service) List(userID uuid.UUID, unreadOnly bool, limit int) ([]*Notification, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.repo.List(userID, unreadOnly, limit)
+}
+
+func (s *Service) UnreadCount(userID uuid.UUID) (*UnreadCount, error) {
+	count, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &UnreadCount{Unread: count}, nil
+}
+
+func (s *Service) MarkRead(id, userID uuid.UUID) (*Notification, error) {
+	return s.repo.MarkRead(id, userID)
+}
+
+func (s *Service) MarkAllRead(userID uuid.UUID) (*MarkAllReadResult, error) {
+	marked, err := s.repo.MarkAllRead(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MarkAllReadResult{Marked: marked}, nil
+}
@@ -93,13 +93,11 @@ func (h *Handler) CreateOrUpdate(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Invalid request body", err.Error())
 		return
 	}
-	// Set household_id from user if not provided
-	if req.HouseholdID == uuid.Nil {
-		if user, ok := r.Context().Value("user").(*auth.User); ok && user.HouseholdID != nil {
-			req.HouseholdID = *user.HouseholdID
-		} else {
-			req.HouseholdID = userID
-		}
+	// Preferences always belong to the caller's household (or the user when not in one);
+	// a client-supplied household_id is ignored
+	req.HouseholdID = userID
+	if user, ok := r.Context().Value("user").(*auth.User); ok && user.HouseholdID != nil {
+		req.HouseholdID = *user.HouseholdID
 	}
 	prefs, err := h.service.CreateOrUpdate(&req)
 	if err != nil {
@@ -61,7 +61,7 @@ type FamilyPreferences struct {
 
 // CreatePreferencesRequest represents a request to create/update preferences
 type CreatePreferencesRequest struct {
-	HouseholdID             uuid.UUID              `json:"household_id" validate:"required"`
+	HouseholdID             uuid.UUID              `json:"household_id,omitempty"`
 	HouseholdSize           int                    `json:"household_size" validate:"required,min=1"`
 	AgeGroups               map[string]interface{} `json:"age_groups,omitempty"`
 	CookingFrequency        string                 `json:"cooking_frequency,omitempty"`
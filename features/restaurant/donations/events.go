@@ -0,0 +1,17 @@
+package donations
+
+import (
+	"context"
+	"foodlink_backend/events"
+)
+
+const eventSubscriber = "restaurant-impact"
+
+// RegisterEventHandlers recomputes a restaurant's impact snapshot after it logs
+// a donation. Recomputing is idempotent, so redelivered events are harmless.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.DonationLogged) error {
+		_, err := s.RecomputeImpact(e.UserID)
+		return err
+	})
+}
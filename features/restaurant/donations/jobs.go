@@ -0,0 +1,16 @@
+package donations
+
+import (
+	"foodlink_backend/jobs"
+	"time"
+)
+
+// RegisterJobs schedules a periodic recompute of every restaurant's impact,
+// which keeps trends current and applies changed impact factors
+func (s *Service) RegisterJobs(scheduler *jobs.Scheduler, interval time.Duration) {
+	scheduler.Register(jobs.Job{
+		Name:     "restaurant.impact",
+		Interval: interval,
+		Run:      s.RecomputeAllImpact,
+	})
+}
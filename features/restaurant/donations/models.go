@@ -3,6 +3,7 @@ package donations
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"foodlink_backend/features/impact"
 	"time"
 
 	"github.com/google/uuid"
@@ -38,6 +39,7 @@ type DonationLog struct {
 	Items         string    `json:"items" db:"items"`
 	Quantity      float64   `json:"quantity" db:"quantity"`
 	Unit          string    `json:"unit" db:"unit"`
+	Category      string    `json:"category" db:"category"`
 	MealsProvided int       `json:"meals_provided" db:"meals_provided"`
 	CO2SavedKg    float64   `json:"co2_saved_kg" db:"co2_saved_kg"`
 	Notes         string    `json:"notes,omitempty" db:"notes"`
@@ -45,17 +47,17 @@ type DonationLog struct {
 }
 
 type ImpactMetrics struct {
-	ID                 uuid.UUID `json:"id" db:"id"`
-	UserID             uuid.UUID `json:"user_id" db:"user_id"`
-	WastePreventedKg   float64   `json:"waste_prevented_kg" db:"waste_prevented_kg"`
-	SurplusDonationRate float64  `json:"surplus_donation_rate" db:"surplus_donation_rate"`
-	WaterSavedLiters   float64   `json:"water_saved_liters" db:"water_saved_liters"`
-	CO2PreventedKg     float64   `json:"co2_prevented_kg" db:"co2_prevented_kg"`
-	SustainabilityScore int      `json:"sustainability_score" db:"sustainability_score"`
-	WeeklyTrend        JSONB     `json:"weekly_trend,omitempty" db:"weekly_trend"`
-	MonthlyTrend       JSONB     `json:"monthly_trend,omitempty" db:"monthly_trend"`
-	CategoryBreakdown  JSONB     `json:"category_breakdown,omitempty" db:"category_breakdown"`
-	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	ID                  uuid.UUID                `json:"id" db:"id"`
+	UserID              uuid.UUID                `json:"user_id" db:"user_id"`
+	WastePreventedKg    float64                  `json:"waste_prevented_kg" db:"waste_prevented_kg"`
+	SurplusDonationRate float64                  `json:"surplus_donation_rate" db:"surplus_donation_rate"`
+	WaterSavedLiters    float64                  `json:"water_saved_liters" db:"water_saved_liters"`
+	CO2PreventedKg      float64                  `json:"co2_prevented_kg" db:"co2_prevented_kg"`
+	SustainabilityScore int                      `json:"sustainability_score" db:"sustainability_score"`
+	WeeklyTrend         []impact.TrendPoint      `json:"weekly_trend,omitempty" db:"weekly_trend"`
+	MonthlyTrend        []impact.TrendPoint      `json:"monthly_trend,omitempty" db:"monthly_trend"`
+	CategoryBreakdown   []*impact.CategoryImpact `json:"category_breakdown,omitempty" db:"category_breakdown"`
+	UpdatedAt           time.Time                `json:"updated_at" db:"updated_at"`
 }
 
 type CreateDonationLogRequest struct {
@@ -65,7 +67,7 @@ type CreateDonationLogRequest struct {
 	Items         string    `json:"items" validate:"required,min=1"`
 	Quantity      float64   `json:"quantity" validate:"required,gt=0"`
 	Unit          string    `json:"unit" validate:"required,min=1"`
-	MealsProvided int       `json:"meals_provided,omitempty"`
-	CO2SavedKg    float64   `json:"co2_saved_kg,omitempty"`
+	Category      string    `json:"category,omitempty" validate:"omitempty,max=100"`
+	MealsProvided int       `json:"meals_provided,omitempty" validate:"omitempty,min=0"`
 	Notes         string    `json:"notes,omitempty"`
 }
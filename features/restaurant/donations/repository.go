@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"foodlink_backend/database"
 	"foodlink_backend/errors"
+	"foodlink_backend/features/impact"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,7 +23,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*DonationLog, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	query := `SELECT id, user_id, date, recipient_type, recipient_name, items, quantity, unit, meals_provided, co2_saved_kg, notes, created_at FROM restaurant_donation_logs WHERE user_id = $1 ORDER BY date DESC, created_at DESC`
+	query := `SELECT id, user_id, date, recipient_type, recipient_name, items, quantity, unit, category, meals_provided, co2_saved_kg, notes, created_at FROM restaurant_donation_logs WHERE user_id = $1 ORDER BY date DESC, created_at DESC`
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
@@ -31,7 +32,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*DonationLog, error) {
 	var logs []*DonationLog
 	for rows.Next() {
 		log := &DonationLog{}
-		if err := rows.Scan(&log.ID, &log.UserID, &log.Date, &log.RecipientType, &log.RecipientName, &log.Items, &log.Quantity, &log.Unit, &log.MealsProvided, &log.CO2SavedKg, &log.Notes, &log.CreatedAt); err != nil {
+		if err := rows.Scan(&log.ID, &log.UserID, &log.Date, &log.RecipientType, &log.RecipientName, &log.Items, &log.Quantity, &log.Unit, &log.Category, &log.MealsProvided, &log.CO2SavedKg, &log.Notes, &log.CreatedAt); err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
 		logs = append(logs, log)
@@ -43,8 +44,8 @@ func (r *Repository) Create(log *DonationLog) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `INSERT INTO restaurant_donation_logs (id, user_id, date, recipient_type, recipient_name, items, quantity, unit, meals_provided, co2_saved_kg, notes, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, user_id, date, recipient_type, recipient_name, items, quantity, unit, meals_provided, co2_saved_kg, notes, created_at`
-	return r.db.QueryRow(query, log.ID, log.UserID, log.Date, log.RecipientType, log.RecipientName, log.Items, log.Quantity, log.Unit, log.MealsProvided, log.CO2SavedKg, log.Notes, time.Now()).Scan(&log.ID, &log.UserID, &log.Date, &log.RecipientType, &log.RecipientName, &log.Items, &log.Quantity, &log.Unit, &log.MealsProvided, &log.CO2SavedKg, &log.Notes, &log.CreatedAt)
+	query := `INSERT INTO restaurant_donation_logs (id, user_id, date, recipient_type, recipient_name, items, quantity, unit, category, meals_provided, co2_saved_kg, notes, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, user_id, date, recipient_type, recipient_name, items, quantity, unit, category, meals_provided, co2_saved_kg, notes, created_at`
+	return r.db.QueryRow(query, log.ID, log.UserID, log.Date, log.RecipientType, log.RecipientName, log.Items, log.Quantity, log.Unit, log.Category, log.MealsProvided, log.CO2SavedKg, log.Notes, time.Now()).Scan(&log.ID, &log.UserID, &log.Date, &log.RecipientType, &log.RecipientName, &log.Items, &log.Quantity, &log.Unit, &log.Category, &log.MealsProvided, &log.CO2SavedKg, &log.Notes, &log.CreatedAt)
 }
 
 func (r *Repository) GetImpactByUserID(userID uuid.UUID) (*ImpactMetrics, error) {
@@ -72,3 +73,85 @@ func (r *Repository) GetImpactByUserID(userID uuid.UUID) (*ImpactMetrics, error)
 	}
 	return metrics, nil
 }
+
+// SaveImpact creates or replaces the restaurant's impact snapshot
+func (r *Repository) SaveImpact(metrics *ImpactMetrics) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	weeklyTrendJSON, _ := json.Marshal(metrics.WeeklyTrend)
+	monthlyTrendJSON, _ := json.Marshal(metrics.MonthlyTrend)
+	categoryBreakdownJSON, _ := json.Marshal(metrics.CategoryBreakdown)
+	query := `
+		INSERT INTO restaurant_impact_metrics (id, user_id, waste_prevented_kg, surplus_donation_rate, water_saved_liters, co2_prevented_kg, sustainability_score, weekly_trend, monthly_trend, category_breakdown, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET
+			waste_prevented_kg = EXCLUDED.waste_prevented_kg,
+			surplus_donation_rate = EXCLUDED.surplus_donation_rate,
+			water_saved_liters = EXCLUDED.water_saved_liters,
+			co2_prevented_kg = EXCLUDED.co2_prevented_kg,
+			sustainability_score = EXCLUDED.sustainability_score,
+			weekly_trend = EXCLUDED.weekly_trend,
+			monthly_trend = EXCLUDED.monthly_trend,
+			category_breakdown = EXCLUDED.category_breakdown,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id, updated_at
+	`
+	err := r.db.QueryRow(query, uuid.New(), metrics.UserID, metrics.WastePreventedKg, metrics.SurplusDonationRate, metrics.WaterSavedLiters, metrics.CO2PreventedKg, metrics.SustainabilityScore, weeklyTrendJSON, monthlyTrendJSON, categoryBreakdownJSON).Scan(&metrics.ID, &metrics.UpdatedAt)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// ListContributions returns the restaurant's donations as rescued food and its
+// expired surplus items as waste
+func (r *Repository) ListContributions(userID uuid.UUID) ([]impact.Contribution, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT category, quantity, unit, COALESCE(meals_provided, 0), FALSE, date::timestamptz
+		FROM restaurant_donation_logs
+		WHERE user_id = $1
+		UNION ALL
+		SELECT category, quantity, unit, 0, TRUE, updated_at
+		FROM restaurant_surplus_items
+		WHERE user_id = $1 AND status = 'expired'
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	var contributions []impact.Contribution
+	for rows.Next() {
+		var c impact.Contribution
+		if err := rows.Scan(&c.Category, &c.Quantity, &c.Unit, &c.Meals, &c.Wasted, &c.OccurredAt); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		contributions = append(contributions, c)
+	}
+	return contributions, rows.Err()
+}
+
+// ListRestaurantIDs returns the IDs of every restaurant user
+func (r *Repository) ListRestaurantIDs() ([]uuid.UUID, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT id FROM users WHERE role = 'restaurant' ORDER BY id`)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -1,18 +1,32 @@
 package donations
 
 import (
+	"context"
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/impact"
 	"foodlink_backend/utils"
+	"log"
+	"math"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// Sustainability score weights: how much of the restaurant's surplus is donated
+// rather than wasted, and how many recent weeks had a donation
+const (
+	donationRateWeight = 0.7
+	consistencyWeight  = 0.3
+)
+
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	impact *impact.Service
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{repo: NewRepository(), impact: impact.NewService()}
 }
 
 func (s *Service) GetAllByUserID(userID uuid.UUID) ([]*DonationLog, error) {
@@ -23,6 +37,15 @@ func (s *Service) Create(userID uuid.UUID, req *CreateDonationLogRequest) (*Dona
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
 	}
+	category := impact.NormalizeCategory(req.Category)
+	if category == "" {
+		category = impact.DefaultCategory
+	}
+	measure := s.impact.Measure(category, req.Quantity, req.Unit)
+	mealsProvided := req.MealsProvided
+	if mealsProvided == 0 {
+		mealsProvided = measure.Meals
+	}
 	log := &DonationLog{
 		ID:            uuid.New(),
 		UserID:        userID,
@@ -32,16 +55,91 @@ func (s *Service) Create(userID uuid.UUID, req *CreateDonationLogRequest) (*Dona
 		Items:         req.Items,
 		Quantity:      req.Quantity,
 		Unit:          req.Unit,
-		MealsProvided: req.MealsProvided,
-		CO2SavedKg:    req.CO2SavedKg,
+		Category:      category,
+		MealsProvided: mealsProvided,
+		CO2SavedKg:    measure.CO2Kg,
 		Notes:         req.Notes,
 	}
 	if err := s.repo.Create(log); err != nil {
 		return nil, err
 	}
+	events.Publish(events.DonationLogged{
+		Meta:          events.NewMeta(),
+		UserID:        log.UserID,
+		DonationID:    log.ID,
+		RecipientType: log.RecipientType,
+		Quantity:      log.Quantity,
+		Unit:          log.Unit,
+		MealsProvided: log.MealsProvided,
+		CO2SavedKg:    log.CO2SavedKg,
+	})
 	return log, nil
 }
 
+// GetImpact returns the restaurant's impact snapshot, computing it if none exists yet
 func (s *Service) GetImpact(userID uuid.UUID) (*ImpactMetrics, error) {
-	return s.repo.GetImpactByUserID(userID)
+	metrics, err := s.repo.GetImpactByUserID(userID)
+	if err == errors.ErrNotFound {
+		return s.RecomputeImpact(userID)
+	}
+	return metrics, err
+}
+
+// RecomputeImpact rebuilds the restaurant's impact snapshot from its donations
+// and expired surplus. The sustainability score blends the share of surplus
+// donated with the share of recent weeks that had a donation.
+func (s *Service) RecomputeImpact(userID uuid.UUID) (*ImpactMetrics, error) {
+	contributions, err := s.repo.ListContributions(userID)
+	if err != nil {
+		return nil, err
+	}
+	summary := s.impact.Summarize(contributions, time.Now())
+
+	donationRate := 0.0
+	if total := summary.RescuedKg + summary.WasteKg; total > 0 {
+		donationRate = summary.RescuedKg / total * 100
+	}
+	activeWeeks := 0
+	for _, week := range summary.WeeklyTrend {
+		if week.Value > 0 {
+			activeWeeks++
+		}
+	}
+	consistency := 0.0
+	if len(summary.WeeklyTrend) > 0 {
+		consistency = float64(activeWeeks) / float64(len(summary.WeeklyTrend)) * 100
+	}
+
+	metrics := &ImpactMetrics{
+		UserID:              userID,
+		WastePreventedKg:    summary.RescuedKg,
+		SurplusDonationRate: math.Round(donationRate*100) / 100,
+		WaterSavedLiters:    summary.WaterLiters,
+		CO2PreventedKg:      summary.CO2Kg,
+		SustainabilityScore: int(math.Round(donationRateWeight*donationRate + consistencyWeight*consistency)),
+		WeeklyTrend:         summary.WeeklyTrend,
+		MonthlyTrend:        summary.MonthlyTrend,
+		CategoryBreakdown:   summary.Categories,
+	}
+	if err := s.repo.SaveImpact(metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// RecomputeAllImpact rebuilds every restaurant's impact snapshot, continuing past failures
+func (s *Service) RecomputeAllImpact(ctx context.Context) error {
+	userIDs, err := s.repo.ListRestaurantIDs()
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := s.RecomputeImpact(userID); err != nil {
+			log.Printf("Warning: failed to recompute impact for restaurant %s: %v", userID, err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,70 @@
+package forecast
+
+import (
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// Get handles GET /api/v1/restaurant/forecast
+// @Summary      Get demand forecast
+// @Description  Forecast each menu item's demand on a day, tomorrow by default, from the last eight weeks of POS sales using exponential smoothing with day-of-week seasonality. Returns recommended portions to prep, the ingredients their recipes need and any shortfall against inventory still in date on the day.
+// @Tags         restaurant-forecast
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        date  query     string  false  "Day to forecast (YYYY-MM-DD, UTC), up to 14 days ahead"
+// @Success      200   {object}  Forecast
+// @Failure      400   {object}  errors.AppError
+// @Failure      401   {object}  errors.AppError
+// @Router       /restaurant/forecast [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var date *time.Time
+	if value := r.URL.Query().Get("date"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid date; expected YYYY-MM-DD", nil)
+			return
+		}
+		date = &day
+	}
+	forecast, err := h.service.Get(userID, date)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to forecast demand", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Forecast retrieved successfully", forecast)
+}
@@ -0,0 +1,65 @@
+package forecast
+
+import (
+	"foodlink_backend/features/restaurant/menu"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How an item's expected demand was worked out
+const (
+	BasisSeasonal  = "seasonal"   // smoothed level scaled by the weekday's factor
+	BasisLevel     = "level"      // smoothed level only, too little history for weekday factors
+	BasisNoHistory = "no-history" // no sales in the history window
+)
+
+// Forecast is what a restaurant is expected to sell on a day, what to prep for
+// it and what the prep needs from inventory
+type Forecast struct {
+	Date        string                   `json:"date"`
+	HistoryFrom string                   `json:"history_from"`
+	HistoryTo   string                   `json:"history_to"`
+	Items       []*ItemForecast          `json:"items"`
+	Ingredients []*IngredientRequirement `json:"ingredients"`
+	Shortfalls  int                      `json:"shortfalls"`
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+// ItemForecast is the expected demand for one menu item and the portions
+// recommended to prep
+type ItemForecast struct {
+	MenuItemID          uuid.UUID `json:"menu_item_id"`
+	Name                string    `json:"name"`
+	Expected            float64   `json:"expected"`
+	RecommendedPortions int       `json:"recommended_portions"`
+	// Seasonality is the weekday's demand relative to an average day
+	Seasonality   float64 `json:"seasonality"`
+	DaysWithSales int     `json:"days_with_sales"`
+	Basis         string  `json:"basis"`
+}
+
+// IngredientRequirement is what the recommended prep needs of one ingredient,
+// against the inventory still in date on the forecast day
+type IngredientRequirement struct {
+	Name      string   `json:"name"`
+	Unit      string   `json:"unit"`
+	Required  float64  `json:"required"`
+	Available float64  `json:"available"`
+	Shortfall float64  `json:"shortfall"`
+	MenuItems []string `json:"menu_items"`
+}
+
+// menuItem is a menu item with its recipe
+type menuItem struct {
+	id     uuid.UUID
+	name   string
+	recipe []menu.RecipeLine
+}
+
+// stockBatch is an inventory batch of an ingredient
+type stockBatch struct {
+	name     string
+	quantity float64
+	unit     string
+}
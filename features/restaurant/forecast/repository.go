@@ -0,0 +1,112 @@
+package forecast
+
+import (
+	"database/sql"
+	"encoding/json"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/restaurant/menu"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+// ListMenuItems returns a restaurant's menu items with their recipes
+func (r *Repository) ListMenuItems(userID uuid.UUID) ([]*menuItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT id, name, ingredients FROM restaurant_menu_items WHERE user_id = $1 ORDER BY name ASC`, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	items := []*menuItem{}
+	for rows.Next() {
+		item := &menuItem{}
+		var ingredientsJSON []byte
+		if err := rows.Scan(&item.id, &item.name, &ingredientsJSON); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		var ingredients menu.JSONB
+		if len(ingredientsJSON) > 0 {
+			json.Unmarshal(ingredientsJSON, &ingredients)
+		}
+		item.recipe = menu.ParseRecipe(ingredients)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return items, nil
+}
+
+// GetDailySales returns units sold per menu item per UTC day in [from, to),
+// keyed by item then by day as "2006-01-02"
+func (r *Repository) GetDailySales(userID uuid.UUID, from, to time.Time) (map[uuid.UUID]map[string]float64, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT item_id, TO_CHAR(day, 'YYYY-MM-DD'), quantity FROM sales_daily
+		WHERE user_id = $1 AND item_type = 'menu-item' AND day >= $2::date AND day < $3::date`
+	rows, err := r.db.Query(query, userID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	sales := map[uuid.UUID]map[string]float64{}
+	for rows.Next() {
+		var itemID uuid.UUID
+		var day string
+		var quantity float64
+		if err := rows.Scan(&itemID, &day, &quantity); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		if sales[itemID] == nil {
+			sales[itemID] = map[string]float64{}
+		}
+		sales[itemID][day] = quantity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return sales, nil
+}
+
+// ListStock returns the restaurant's batches of the named ingredients that are
+// in stock and not expired before usableOn. Names match case-insensitively.
+func (r *Repository) ListStock(userID uuid.UUID, names []string, usableOn time.Time) ([]*stockBatch, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT LOWER(name), quantity, unit FROM restaurant_inventory_items
+		WHERE user_id = $1 AND LOWER(name) = ANY($2) AND quantity > 0 AND expiry_date >= $3`
+	rows, err := r.db.Query(query, userID, pq.Array(names), usableOn)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	batches := []*stockBatch{}
+	for rows.Next() {
+		batch := &stockBatch{}
+		if err := rows.Scan(&batch.name, &batch.quantity, &batch.unit); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		batches = append(batches, batch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return batches, nil
+}
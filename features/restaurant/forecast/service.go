@@ -0,0 +1,154 @@
+package forecast
+
+import (
+	"fmt"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/impact"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// historyDays is how far back sales are smoothed, eight weeks
+	historyDays = 56
+	// maxLeadDays is how far ahead a forecast may be asked for
+	maxLeadDays = 14
+)
+
+var (
+	errDateInPast = errors.NewAppError(http.StatusBadRequest, "Validation failed: date must be today or later")
+	errDateTooFar = errors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Validation failed: date must be within %d days", maxLeadDays))
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository()}
+}
+
+// Get forecasts each menu item's demand on a UTC day, tomorrow when date is
+// nil, from the eight weeks of sales before today. Today is left out as its
+// sales are still coming in. Recommended portions are the expected demand
+// rounded up; their recipes give the ingredients to prep, which are compared
+// with the inventory still in date on the day.
+func (s *Service) Get(userID uuid.UUID, date *time.Time) (*Forecast, error) {
+	now := time.Now()
+	today := now.UTC().Truncate(24 * time.Hour)
+	day := today.AddDate(0, 0, 1)
+	if date != nil {
+		day = date.UTC().Truncate(24 * time.Hour)
+	}
+	if day.Before(today) {
+		return nil, errDateInPast
+	}
+	if day.After(today.AddDate(0, 0, maxLeadDays)) {
+		return nil, errDateTooFar
+	}
+
+	items, err := s.repo.ListMenuItems(userID)
+	if err != nil {
+		return nil, err
+	}
+	historyFrom := today.AddDate(0, 0, -historyDays)
+	sales, err := s.repo.GetDailySales(userID, historyFrom, today)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := &Forecast{
+		Date:        day.Format("2006-01-02"),
+		HistoryFrom: historyFrom.Format("2006-01-02"),
+		HistoryTo:   today.AddDate(0, 0, -1).Format("2006-01-02"),
+		Items:       []*ItemForecast{},
+		Ingredients: []*IngredientRequirement{},
+		GeneratedAt: now,
+	}
+	requirements := map[string][]*IngredientRequirement{}
+	for _, item := range items {
+		series := make([]float64, historyDays)
+		daysWithSales := 0
+		for i := range series {
+			series[i] = sales[item.id][historyFrom.AddDate(0, 0, i).Format("2006-01-02")]
+			if series[i] > 0 {
+				daysWithSales++
+			}
+		}
+		expected, factor, basis := smooth(series, int(historyFrom.Weekday()), int(day.Weekday()))
+		itemForecast := &ItemForecast{
+			MenuItemID:          item.id,
+			Name:                item.name,
+			Expected:            round2(expected),
+			RecommendedPortions: portions(expected),
+			Seasonality:         round2(factor),
+			DaysWithSales:       daysWithSales,
+			Basis:               basis,
+		}
+		forecast.Items = append(forecast.Items, itemForecast)
+		if itemForecast.RecommendedPortions == 0 {
+			continue
+		}
+		for _, line := range item.recipe {
+			quantity := line.Quantity * float64(itemForecast.RecommendedPortions)
+			key := strings.ToLower(line.Name)
+			requirement := addRequirement(requirements[key], quantity, line.Unit)
+			if requirement == nil {
+				requirement = &IngredientRequirement{Name: line.Name, Unit: line.Unit, Required: quantity, MenuItems: []string{}}
+				requirements[key] = append(requirements[key], requirement)
+				forecast.Ingredients = append(forecast.Ingredients, requirement)
+			}
+			if n := len(requirement.MenuItems); n == 0 || requirement.MenuItems[n-1] != item.name {
+				requirement.MenuItems = append(requirement.MenuItems, item.name)
+			}
+		}
+	}
+	if len(requirements) == 0 {
+		return forecast, nil
+	}
+
+	names := make([]string, 0, len(requirements))
+	for name := range requirements {
+		names = append(names, name)
+	}
+	batches, err := s.repo.ListStock(userID, names, day)
+	if err != nil {
+		return nil, err
+	}
+	for _, batch := range batches {
+		for _, requirement := range requirements[batch.name] {
+			// An ingredient without a unit is counted in its stock's unit
+			if requirement.Unit == "" {
+				requirement.Unit = batch.unit
+			}
+			if available, ok := impact.ConvertUnit(batch.quantity, batch.unit, requirement.Unit); ok {
+				requirement.Available += available
+				break
+			}
+		}
+	}
+	for _, requirement := range forecast.Ingredients {
+		requirement.Required = round2(requirement.Required)
+		requirement.Available = round2(requirement.Available)
+		if requirement.Available < requirement.Required {
+			requirement.Shortfall = round2(requirement.Required - requirement.Available)
+			forecast.Shortfalls++
+		}
+	}
+	return forecast, nil
+}
+
+// addRequirement adds quantity to the first requirement whose unit it converts
+// to, and returns that requirement, or nil when none does
+func addRequirement(requirements []*IngredientRequirement, quantity float64, unit string) *IngredientRequirement {
+	for _, requirement := range requirements {
+		if converted, ok := impact.ConvertUnit(quantity, unit, requirement.Unit); ok {
+			requirement.Required += converted
+			return requirement
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package forecast
+
+import "math"
+
+const (
+	// levelSmoothing weighs each day's deseasonalised sales against the level so far
+	levelSmoothing = 0.3
+	// seasonSmoothing weighs each day's sales against its weekday's factor so far
+	seasonSmoothing = 0.2
+	// minSeasonalDays is how much history an item needs before weekday factors are used
+	minSeasonalDays = 14
+)
+
+// smooth forecasts the next day's demand from a daily series of units sold,
+// oldest first, whose first day falls on weekday firstWeekday. It smooths a
+// demand level and one multiplicative factor per weekday (Holt-Winters without
+// trend), seeding both from the first week after the item's first sale. The
+// forecast is for weekday target. Items with under minSeasonalDays of history
+// are forecast from the level alone.
+func smooth(series []float64, firstWeekday, target int) (expected, factor float64, basis string) {
+	start := 0
+	for start < len(series) && series[start] <= 0 {
+		start++
+	}
+	if start == len(series) {
+		return 0, 1, BasisNoHistory
+	}
+	series = series[start:]
+	firstWeekday = (firstWeekday + start) % 7
+
+	if len(series) < minSeasonalDays {
+		level := series[0]
+		for _, y := range series[1:] {
+			level = levelSmoothing*y + (1-levelSmoothing)*level
+		}
+		return level, 1, BasisLevel
+	}
+
+	level := 0.0
+	for _, y := range series[:7] {
+		level += y / 7
+	}
+	var season [7]float64
+	for i, y := range series[:7] {
+		season[(firstWeekday+i)%7] = y / level
+	}
+	for i := 7; i < len(series); i++ {
+		y := series[i]
+		weekday := (firstWeekday + i) % 7
+		s := season[weekday]
+		// A weekday that has never sold says nothing about the level
+		if s > 0 {
+			level = levelSmoothing*(y/s) + (1-levelSmoothing)*level
+		}
+		if level > 0 {
+			season[weekday] = seasonSmoothing*(y/level) + (1-seasonSmoothing)*s
+		}
+	}
+
+	// Keep the factors averaging 1 so the level stays an average day
+	sum := 0.0
+	for _, s := range season {
+		sum += s
+	}
+	if sum <= 0 {
+		return 0, 1, BasisSeasonal
+	}
+	factor = season[target] * 7 / sum
+	return level * sum / 7 * factor, factor, BasisSeasonal
+}
+
+// portions rounds expected demand up to whole portions, ignoring the
+// rounding noise of smoothing
+func portions(expected float64) int {
+	return int(math.Ceil(round2(expected)))
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,69 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+// weekly repeats a sales pattern indexed by weekday for days days, starting
+// on firstWeekday
+func weekly(pattern [7]float64, firstWeekday, days int) []float64 {
+	series := make([]float64, days)
+	for i := range series {
+		series[i] = pattern[(firstWeekday+i)%7]
+	}
+	return series
+}
+
+func TestSmooth(t *testing.T) {
+	pattern := [7]float64{2, 4, 6, 8, 10, 12, 14}
+	closedSundays := [7]float64{0, 5, 5, 5, 5, 10, 10}
+	tests := []struct {
+		name         string
+		series       []float64
+		firstWeekday int
+		target       int
+		wantExpected float64
+		wantFactor   float64
+		wantBasis    string
+	}{
+		{"no history", nil, 0, 3, 0, 1, BasisNoHistory},
+		{"never sold", make([]float64, 28), 2, 3, 0, 1, BasisNoHistory},
+		{"short series uses the level", []float64{4, 6, 5}, 0, 3, 4.72, 1, BasisLevel},
+		{"leading zeros do not count as history", append(make([]float64, 20), 4, 6, 5), 0, 3, 4.72, 1, BasisLevel},
+		{"flat series forecasts its mean", weekly([7]float64{5, 5, 5, 5, 5, 5, 5}, 0, 21), 0, 4, 5, 1, BasisSeasonal},
+		{"weekly pattern is forecast by weekday", weekly(pattern, 1, 28), 1, 5, 12, 12.0 / 8, BasisSeasonal},
+		// Sales start on day 3, a Wednesday, so the first week is seeded from there
+		{"leading zeros shift the weekdays", append(make([]float64, 3), weekly(pattern, 3, 21)...), 0, 3, 8, 1, BasisSeasonal},
+		{"weekday that never sells forecasts zero", weekly(closedSundays, 1, 28), 1, 0, 0, 0, BasisSeasonal},
+		{"other weekdays ignore the closed one", weekly(closedSundays, 1, 28), 1, 5, 10, 10.0 / (40.0 / 7), BasisSeasonal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, factor, basis := smooth(tt.series, tt.firstWeekday, tt.target)
+			if basis != tt.wantBasis {
+				t.Fatalf("basis = %q, want %q", basis, tt.wantBasis)
+			}
+			if math.Abs(expected-tt.wantExpected) > 1e-9 || math.Abs(factor-tt.wantFactor) > 1e-9 {
+				t.Fatalf("smooth() = %v, %v; want %v, %v", expected, factor, tt.wantExpected, tt.wantFactor)
+			}
+		})
+	}
+}
+
+func TestSmoothLearnsWeekdayMissingFromFirstWeek(t *testing.T) {
+	// Tuesdays sell nothing in the first week, then sell like any other day
+	series := weekly([7]float64{6, 6, 6, 6, 6, 6, 6}, 0, 28)
+	series[2] = 0
+
+	expected, factor, basis := smooth(series, 0, 2)
+	if basis != BasisSeasonal {
+		t.Fatalf("basis = %q, want %q", basis, BasisSeasonal)
+	}
+	if factor <= 0 || expected <= 0 {
+		t.Fatalf("smooth() = %v, %v; want Tuesday demand to be learnt after the first week", expected, factor)
+	}
+	if other, _, _ := smooth(series, 0, 3); expected >= other {
+		t.Fatalf("Tuesday forecast %v should still trail Wednesday's %v while it catches up", expected, other)
+	}
+}
@@ -19,6 +19,8 @@ type RestaurantInventoryItem struct {
 	AlertTags    []string   `json:"alert_tags,omitempty" db:"alert_tags"`
 	Status       string     `json:"status" db:"status"`
 	InvoiceImage string     `json:"invoice_image,omitempty" db:"invoice_image"`
+	// UnitCost is what one unit of the batch cost, e.g. per kg
+	UnitCost     *float64   `json:"unit_cost,omitempty" db:"unit_cost"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -32,7 +34,9 @@ type CreateRestaurantInventoryItemRequest struct {
 	StorageType  string    `json:"storage_type" validate:"required,oneof=fresh chilled frozen dry"`
 	BatchCode    string    `json:"batch_code,omitempty" validate:"omitempty,max=100"`
 	AlertTags    []string  `json:"alert_tags,omitempty"`
+	// An image URL, or the ID of an upload from POST /uploads
 	InvoiceImage string    `json:"invoice_image,omitempty"`
+	UnitCost     *float64  `json:"unit_cost,omitempty" validate:"omitempty,gte=0"`
 }
 
 type UpdateRestaurantInventoryItemRequest struct {
@@ -46,4 +50,5 @@ type UpdateRestaurantInventoryItemRequest struct {
 	AlertTags    []string  `json:"alert_tags,omitempty"`
 	Status       string    `json:"status,omitempty"`
 	InvoiceImage string    `json:"invoice_image,omitempty"`
+	UnitCost     *float64  `json:"unit_cost,omitempty" validate:"omitempty,gte=0"`
 }
@@ -22,7 +22,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*RestaurantInventoryIte
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	query := `SELECT id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, created_at, updated_at FROM restaurant_inventory_items WHERE user_id = $1 ORDER BY expiry_date ASC, created_at DESC`
+	query := `SELECT id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, unit_cost, created_at, updated_at FROM restaurant_inventory_items WHERE user_id = $1 ORDER BY expiry_date ASC, created_at DESC`
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
@@ -31,7 +31,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*RestaurantInventoryIte
 	var items []*RestaurantInventoryItem
 	for rows.Next() {
 		item := &RestaurantInventoryItem{}
-		if err := rows.Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.UnitCost, &item.CreatedAt, &item.UpdatedAt); err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
 		items = append(items, item)
@@ -44,8 +44,8 @@ func (r *Repository) GetByID(id uuid.UUID) (*RestaurantInventoryItem, error) {
 		return nil, errors.ErrDatabase
 	}
 	item := &RestaurantInventoryItem{}
-	query := `SELECT id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, created_at, updated_at FROM restaurant_inventory_items WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.CreatedAt, &item.UpdatedAt)
+	query := `SELECT id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, unit_cost, created_at, updated_at FROM restaurant_inventory_items WHERE id = $1`
+	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.UnitCost, &item.CreatedAt, &item.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
@@ -59,17 +59,17 @@ func (r *Repository) Create(item *RestaurantInventoryItem) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `INSERT INTO restaurant_inventory_items (id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, created_at, updated_at`
+	query := `INSERT INTO restaurant_inventory_items (id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, unit_cost, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, unit_cost, created_at, updated_at`
 	now := time.Now()
-	return r.db.QueryRow(query, item.ID, item.UserID, item.Name, item.Quantity, item.Unit, item.Category, item.ExpiryDate, item.StorageType, item.BatchCode, pq.Array(item.AlertTags), item.Status, item.InvoiceImage, now, now).Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.CreatedAt, &item.UpdatedAt)
+	return r.db.QueryRow(query, item.ID, item.UserID, item.Name, item.Quantity, item.Unit, item.Category, item.ExpiryDate, item.StorageType, item.BatchCode, pq.Array(item.AlertTags), item.Status, item.InvoiceImage, item.UnitCost, now, now).Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.UnitCost, &item.CreatedAt, &item.UpdatedAt)
 }
 
 func (r *Repository) Update(item *RestaurantInventoryItem) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `UPDATE restaurant_inventory_items SET name=$1, quantity=$2, unit=$3, category=$4, expiry_date=$5, storage_type=$6, batch_code=$7, alert_tags=$8, status=$9, invoice_image=$10, updated_at=$11 WHERE id=$12 RETURNING id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, created_at, updated_at`
-	return r.db.QueryRow(query, item.Name, item.Quantity, item.Unit, item.Category, item.ExpiryDate, item.StorageType, item.BatchCode, pq.Array(item.AlertTags), item.Status, item.InvoiceImage, time.Now(), item.ID).Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.CreatedAt, &item.UpdatedAt)
+	query := `UPDATE restaurant_inventory_items SET name=$1, quantity=$2, unit=$3, category=$4, expiry_date=$5, storage_type=$6, batch_code=$7, alert_tags=$8, status=$9, invoice_image=$10, unit_cost=$11, updated_at=$12 WHERE id=$13 RETURNING id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, unit_cost, created_at, updated_at`
+	return r.db.QueryRow(query, item.Name, item.Quantity, item.Unit, item.Category, item.ExpiryDate, item.StorageType, item.BatchCode, pq.Array(item.AlertTags), item.Status, item.InvoiceImage, item.UnitCost, time.Now(), item.ID).Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.UnitCost, &item.CreatedAt, &item.UpdatedAt)
 }
 
 func (r *Repository) Delete(id uuid.UUID) error {
@@ -92,7 +92,7 @@ func (r *Repository) GetExpiring(userID uuid.UUID, days int) ([]*RestaurantInven
 		return nil, errors.ErrDatabase
 	}
 	cutoffDate := time.Now().AddDate(0, 0, days)
-	query := `SELECT id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, created_at, updated_at FROM restaurant_inventory_items WHERE user_id = $1 AND expiry_date <= $2 AND expiry_date >= CURRENT_TIMESTAMP ORDER BY expiry_date ASC`
+	query := `SELECT id, user_id, name, quantity, unit, category, expiry_date, storage_type, batch_code, alert_tags, status, invoice_image, unit_cost, created_at, updated_at FROM restaurant_inventory_items WHERE user_id = $1 AND expiry_date <= $2 AND expiry_date >= CURRENT_TIMESTAMP ORDER BY expiry_date ASC`
 	rows, err := r.db.Query(query, userID, cutoffDate)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
@@ -101,7 +101,7 @@ func (r *Repository) GetExpiring(userID uuid.UUID, days int) ([]*RestaurantInven
 	var items []*RestaurantInventoryItem
 	for rows.Next() {
 		item := &RestaurantInventoryItem{}
-		if err := rows.Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Name, &item.Quantity, &item.Unit, &item.Category, &item.ExpiryDate, &item.StorageType, &item.BatchCode, pq.Array(&item.AlertTags), &item.Status, &item.InvoiceImage, &item.UnitCost, &item.CreatedAt, &item.UpdatedAt); err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
 		items = append(items, item)
@@ -2,17 +2,20 @@ package inventory
 
 import (
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/uploads"
 	"foodlink_backend/utils"
 
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	images *uploads.Linker
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{repo: NewRepository(), images: uploads.NewLinker()}
 }
 
 func (s *Service) GetAllByUserID(userID uuid.UUID) ([]*RestaurantInventoryItem, error) {
@@ -40,10 +43,22 @@ func (s *Service) Create(userID uuid.UUID, req *CreateRestaurantInventoryItemReq
 		AlertTags:   req.AlertTags,
 		Status:      "normal",
 		InvoiceImage: req.InvoiceImage,
+		UnitCost:    req.UnitCost,
 	}
+	image, err := s.images.Resolve(userID, item.InvoiceImage, uploads.AssociatedRestaurantInventory, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	item.InvoiceImage = image
 	if err := s.repo.Create(item); err != nil {
 		return nil, err
 	}
+	if err := s.images.Sync(item.InvoiceImage, uploads.AssociatedRestaurantInventory, item.ID); err != nil {
+		return nil, err
+	}
+	if item.UnitCost != nil {
+		publishCostChanged(userID, item.Name)
+	}
 	return item, nil
 }
 
@@ -58,6 +73,7 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateRestaurantIn
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
 	}
+	previous := *item
 	if req.Name != "" {
 		item.Name = req.Name
 	}
@@ -86,11 +102,28 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateRestaurantIn
 		item.Status = req.Status
 	}
 	if req.InvoiceImage != "" {
-		item.InvoiceImage = req.InvoiceImage
+		if item.InvoiceImage, err = s.images.Resolve(userID, req.InvoiceImage, uploads.AssociatedRestaurantInventory, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	if req.UnitCost != nil {
+		item.UnitCost = req.UnitCost
 	}
 	if err := s.repo.Update(item); err != nil {
 		return nil, err
 	}
+	if req.InvoiceImage != "" {
+		if err := s.images.Sync(item.InvoiceImage, uploads.AssociatedRestaurantInventory, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	if costChanged(&previous, item) {
+		names := []string{item.Name}
+		if previous.Name != item.Name {
+			names = append(names, previous.Name)
+		}
+		publishCostChanged(userID, names...)
+	}
 	return item, nil
 }
 
@@ -102,7 +135,16 @@ func (s *Service) Delete(id uuid.UUID, userID uuid.UUID) error {
 	if item.UserID != userID {
 		return errors.ErrForbidden
 	}
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if err := s.images.Release(uploads.AssociatedRestaurantInventory, id); err != nil {
+		return err
+	}
+	if item.UnitCost != nil {
+		publishCostChanged(userID, item.Name)
+	}
+	return nil
 }
 
 func (s *Service) GetExpiring(userID uuid.UUID, days int) ([]*RestaurantInventoryItem, error) {
@@ -111,3 +153,21 @@ func (s *Service) GetExpiring(userID uuid.UUID, days int) ([]*RestaurantInventor
 	}
 	return s.repo.GetExpiring(userID, days)
 }
+
+// costChanged reports whether an update may have changed what an ingredient
+// costs: a costed batch repriced, renamed or measured in another unit
+func costChanged(before, after *RestaurantInventoryItem) bool {
+	if before.UnitCost == nil && after.UnitCost == nil {
+		return false
+	}
+	if before.UnitCost == nil || after.UnitCost == nil || *before.UnitCost != *after.UnitCost {
+		return true
+	}
+	return before.Name != after.Name || before.Unit != after.Unit
+}
+
+// publishCostChanged announces that the latest cost of the named ingredients
+// may have changed, so menu items using them are costed again
+func publishCostChanged(userID uuid.UUID, names ...string) {
+	events.Publish(events.IngredientCostChanged{Meta: events.NewMeta(), UserID: userID, Ingredients: names})
+}
@@ -0,0 +1,16 @@
+package menu
+
+import (
+	"context"
+	"foodlink_backend/events"
+)
+
+const eventSubscriber = "menu-costing"
+
+// RegisterEventHandlers costs menu items again when the price of an ingredient
+// they use changes. Costing is idempotent, so redelivered events are harmless.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.IngredientCostChanged) error {
+		return s.RecostIngredients(e.UserID, e.Ingredients)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"foodlink_backend/features/auth"
 	"foodlink_backend/utils"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -27,15 +28,22 @@ func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
 	return user.ID, nil
 }
 
+// pathID parses the menu item ID leading the path below /api/v1/restaurant/menu
+func pathID(r *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(strings.Split(strings.Trim(r.URL.Path, "/"), "/")[0])
+}
+
 // GetAll handles GET /api/v1/restaurant/menu
 // @Summary      List menu items
-// @Description  Get all menu items for the authenticated restaurant
+// @Description  Get all menu items for the authenticated restaurant. Items whose margin is below the restaurant's min_margin preference are flagged low_margin.
 // @Tags         restaurant-menu
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {array}   RestaurantMenuItem
-// @Failure      401  {object}  errors.AppError
+// @Param        low_margin  query     bool  false  "Only items flagged low_margin"
+// @Success      200         {array}   RestaurantMenuItem
+// @Failure      400         {object}  errors.AppError
+// @Failure      401         {object}  errors.AppError
 // @Router       /restaurant/menu [get]
 func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -47,7 +55,14 @@ func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	items, err := h.service.GetAllByUserID(userID)
+	lowMarginOnly := false
+	if value := r.URL.Query().Get("low_margin"); value != "" {
+		if lowMarginOnly, err = strconv.ParseBool(value); err != nil {
+			utils.BadRequestResponse(w, "Invalid low_margin; expected true or false", nil)
+			return
+		}
+	}
+	items, err := h.service.GetAllByUserID(userID, lowMarginOnly)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
@@ -76,8 +91,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/restaurant/menu/")
-	id, err := uuid.Parse(idStr)
+	id, err := pathID(r)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
 		return
@@ -96,7 +110,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 // Create handles POST /api/v1/restaurant/menu
 // @Summary      Add menu item
-// @Description  Add a new item to restaurant menu
+// @Description  Add a new item to restaurant menu. Send a recipe of ingredients, each by name or by inventory_item_id, with quantity and unit per portion, to have the plate cost and margin computed from inventory unit costs; otherwise send free-form ingredients and a margin.
 // @Tags         restaurant-menu
 // @Accept       json
 // @Produce      json
@@ -135,7 +149,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 // Update handles PUT /api/v1/restaurant/menu/:id
 // @Summary      Update menu item
-// @Description  Update an existing menu item
+// @Description  Update an existing menu item. A recipe replaces the item's recipe and is costed again; an empty recipe removes it.
 // @Tags         restaurant-menu
 // @Accept       json
 // @Produce      json
@@ -158,8 +172,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/restaurant/menu/")
-	id, err := uuid.Parse(idStr)
+	id, err := pathID(r)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
 		return
@@ -204,8 +217,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
 	}
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/restaurant/menu/")
-	id, err := uuid.Parse(idStr)
+	id, err := pathID(r)
 	if err != nil {
 		utils.BadRequestResponse(w, "Invalid ID format", nil)
 		return
@@ -220,3 +232,44 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	utils.OKResponse(w, "Menu item deleted successfully", map[string]string{"message": "Deleted"})
 }
+
+// GetCostBreakdown handles GET /api/v1/restaurant/menu/:id/cost
+// @Summary      Get plate cost breakdown
+// @Description  Get a menu item's plate cost, margin and each recipe ingredient's cost and share of the plate, priced at the latest unit cost received for that ingredient. Costs are recomputed whenever the recipe, the price or an ingredient's cost changes.
+// @Tags         restaurant-menu
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Menu Item ID"
+// @Success      200  {object}  CostBreakdown
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /restaurant/menu/{id}/cost [get]
+func (h *Handler) GetCostBreakdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	breakdown, err := h.service.GetCostBreakdown(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve cost breakdown", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Cost breakdown retrieved successfully", breakdown)
+}
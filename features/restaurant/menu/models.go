@@ -39,17 +39,76 @@ type RestaurantMenuItem struct {
 	Price              float64   `json:"price" db:"price"`
 	Margin             float64   `json:"margin" db:"margin"`
 	Suggestions        []string  `json:"suggestions,omitempty" db:"suggestions"`
+	// Recipe, when set, prices the plate: PlateCost and Margin are computed
+	// from it and Ingredients mirrors it
+	Recipe             []*RecipeIngredient `json:"recipe,omitempty"`
+	PlateCost          *float64   `json:"plate_cost,omitempty" db:"plate_cost"`
+	CostComplete       bool       `json:"cost_complete" db:"cost_complete"`
+	LowMargin          bool       `json:"low_margin"`
+	CostedAt           *time.Time `json:"costed_at,omitempty" db:"costed_at"`
 	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// RecipeIngredient is how much of an ingredient one portion uses. It is priced
+// at the latest unit cost received for an inventory batch of that name;
+// UnitCost is per recipe unit, and both costs are nil until a batch is costed.
+type RecipeIngredient struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	InventoryItemID *uuid.UUID `json:"inventory_item_id,omitempty" db:"inventory_item_id"`
+	Name            string     `json:"name" db:"ingredient_name"`
+	Quantity        float64    `json:"quantity" db:"quantity"`
+	Unit            string     `json:"unit" db:"unit"`
+	UnitCost        *float64   `json:"unit_cost,omitempty" db:"unit_cost"`
+	Cost            *float64   `json:"cost,omitempty" db:"cost"`
+}
+
+// RecipeIngredientRequest is a recipe line naming an ingredient, or an
+// inventory batch whose ingredient it is
+type RecipeIngredientRequest struct {
+	InventoryItemID *uuid.UUID `json:"inventory_item_id,omitempty"`
+	Name            string     `json:"name,omitempty" validate:"required_without=InventoryItemID,max=255"`
+	Quantity        float64    `json:"quantity" validate:"gt=0"`
+	Unit            string     `json:"unit" validate:"required,max=50"`
+}
+
+// CostBreakdown is what a menu item's plate costs, ingredient by ingredient
+type CostBreakdown struct {
+	MenuItemID   uuid.UUID         `json:"menu_item_id"`
+	Name         string            `json:"name"`
+	Price        float64           `json:"price"`
+	PlateCost    *float64          `json:"plate_cost,omitempty"`
+	Margin       float64           `json:"margin"`
+	MinMargin    float64           `json:"min_margin"`
+	LowMargin    bool              `json:"low_margin"`
+	CostComplete bool              `json:"cost_complete"`
+	CostedAt     *time.Time        `json:"costed_at,omitempty"`
+	Ingredients  []*IngredientCost `json:"ingredients"`
+}
+
+// IngredientCost is a recipe line's cost and its share of the plate cost, in
+// percent
+type IngredientCost struct {
+	*RecipeIngredient
+	Share *float64 `json:"share,omitempty"`
+}
+
+// ingredientPrice is the unit cost of a costed inventory batch
+type ingredientPrice struct {
+	unitCost float64
+	unit     string
+}
+
 type CreateRestaurantMenuItemRequest struct {
 	Name               string                 `json:"name" validate:"required,min=1,max=255"`
 	Category           string                 `json:"category" validate:"required,min=1,max=100"`
-	Ingredients        []map[string]interface{} `json:"ingredients" validate:"required"`
+	// Ingredients is free-form; send Recipe instead to have the plate costed
+	Ingredients        []map[string]interface{} `json:"ingredients,omitempty" validate:"required_without=Recipe"`
+	Recipe             []*RecipeIngredientRequest `json:"recipe,omitempty" validate:"omitempty,dive"`
 	PredictedWasteScore string               `json:"predicted_waste_score,omitempty" validate:"omitempty,oneof=low medium high"`
 	Price              float64                `json:"price" validate:"required,gt=0"`
-	Margin             float64                `json:"margin" validate:"required"`
+	// Margin is typed in only for items without a recipe
+	Margin             *float64               `json:"margin,omitempty" validate:"required_without=Recipe"`
 	Suggestions        []string               `json:"suggestions,omitempty"`
 }
 
@@ -57,6 +116,8 @@ type UpdateRestaurantMenuItemRequest struct {
 	Name               string                 `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
 	Category           string                 `json:"category,omitempty" validate:"omitempty,min=1,max=100"`
 	Ingredients        []map[string]interface{} `json:"ingredients,omitempty"`
+	// Recipe replaces the item's recipe; an empty list removes it
+	Recipe             []*RecipeIngredientRequest `json:"recipe,omitempty" validate:"omitempty,dive"`
 	PredictedWasteScore string               `json:"predicted_waste_score,omitempty" validate:"omitempty,oneof=low medium high"`
 	Price              *float64               `json:"price,omitempty" validate:"omitempty,gt=0"`
 	Margin             *float64               `json:"margin,omitempty"`
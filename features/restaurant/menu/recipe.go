@@ -0,0 +1,64 @@
+package menu
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RecipeLine is one ingredient of a menu item, per portion
+type RecipeLine struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+// ParseRecipe reads a menu item's ingredients, stored as
+// {"ingredients": [{"name", "quantity", "unit"}]} with the quantity per
+// portion. A quantity may carry its unit, as in "200g" or "2 pcs". Lines
+// without a name or a positive quantity are skipped.
+func ParseRecipe(ingredients JSONB) []RecipeLine {
+	list, _ := ingredients["ingredients"].([]interface{})
+	recipe := []RecipeLine{}
+	for _, entry := range list {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := fields["name"].(string)
+		name = strings.TrimSpace(name)
+		unit, _ := fields["unit"].(string)
+		var quantity float64
+		switch q := fields["quantity"].(type) {
+		case float64:
+			quantity = q
+		case string:
+			var quantityUnit string
+			quantity, quantityUnit = splitQuantity(q)
+			if unit == "" {
+				unit = quantityUnit
+			}
+		}
+		if name == "" || quantity <= 0 {
+			continue
+		}
+		recipe = append(recipe, RecipeLine{Name: name, Quantity: quantity, Unit: strings.TrimSpace(unit)})
+	}
+	return recipe
+}
+
+// splitQuantity splits a quantity such as "0.5 kg" into its number and unit
+func splitQuantity(value string) (float64, string) {
+	value = strings.TrimSpace(value)
+	end := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if end == -1 {
+		end = len(value)
+	}
+	quantity, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil {
+		return 0, ""
+	}
+	return quantity, strings.TrimSpace(value[end:])
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"foodlink_backend/database"
 	"foodlink_backend/errors"
+	"foodlink_backend/features/restaurant/preferences"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,7 +24,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*RestaurantMenuItem, er
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	query := `SELECT id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, created_at, updated_at FROM restaurant_menu_items WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, plate_cost, cost_complete, costed_at, created_at, updated_at FROM restaurant_menu_items WHERE user_id = $1 ORDER BY created_at DESC`
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
@@ -33,7 +34,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*RestaurantMenuItem, er
 	for rows.Next() {
 		item := &RestaurantMenuItem{}
 		var ingredientsJSON []byte
-		if err := rows.Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &ingredientsJSON, &item.PredictedWasteScore, &item.Price, &item.Margin, pq.Array(&item.Suggestions), &item.CreatedAt, &item.UpdatedAt); err != nil {
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &ingredientsJSON, &item.PredictedWasteScore, &item.Price, &item.Margin, pq.Array(&item.Suggestions), &item.PlateCost, &item.CostComplete, &item.CostedAt, &item.CreatedAt, &item.UpdatedAt); err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
 		if len(ingredientsJSON) > 0 {
@@ -50,8 +51,8 @@ func (r *Repository) GetByID(id uuid.UUID) (*RestaurantMenuItem, error) {
 	}
 	item := &RestaurantMenuItem{}
 	var ingredientsJSON []byte
-	query := `SELECT id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, created_at, updated_at FROM restaurant_menu_items WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &ingredientsJSON, &item.PredictedWasteScore, &item.Price, &item.Margin, pq.Array(&item.Suggestions), &item.CreatedAt, &item.UpdatedAt)
+	query := `SELECT id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, plate_cost, cost_complete, costed_at, created_at, updated_at FROM restaurant_menu_items WHERE id = $1`
+	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &ingredientsJSON, &item.PredictedWasteScore, &item.Price, &item.Margin, pq.Array(&item.Suggestions), &item.PlateCost, &item.CostComplete, &item.CostedAt, &item.CreatedAt, &item.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
@@ -69,10 +70,10 @@ func (r *Repository) Create(item *RestaurantMenuItem) error {
 		return errors.ErrDatabase
 	}
 	ingredientsJSON, _ := json.Marshal(item.Ingredients)
-	query := `INSERT INTO restaurant_menu_items (id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, created_at, updated_at`
+	query := `INSERT INTO restaurant_menu_items (id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, plate_cost, cost_complete, costed_at, created_at, updated_at`
 	now := time.Now()
 	var ingredientsJSONOut []byte
-	err := r.db.QueryRow(query, item.ID, item.UserID, item.Name, item.Category, ingredientsJSON, item.PredictedWasteScore, item.Price, item.Margin, pq.Array(item.Suggestions), now, now).Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &ingredientsJSONOut, &item.PredictedWasteScore, &item.Price, &item.Margin, pq.Array(&item.Suggestions), &item.CreatedAt, &item.UpdatedAt)
+	err := r.db.QueryRow(query, item.ID, item.UserID, item.Name, item.Category, ingredientsJSON, item.PredictedWasteScore, item.Price, item.Margin, pq.Array(item.Suggestions), now, now).Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &ingredientsJSONOut, &item.PredictedWasteScore, &item.Price, &item.Margin, pq.Array(&item.Suggestions), &item.PlateCost, &item.CostComplete, &item.CostedAt, &item.CreatedAt, &item.UpdatedAt)
 	if err != nil {
 		return errors.WrapError(err, errors.ErrDatabase)
 	}
@@ -87,9 +88,9 @@ func (r *Repository) Update(item *RestaurantMenuItem) error {
 		return errors.ErrDatabase
 	}
 	ingredientsJSON, _ := json.Marshal(item.Ingredients)
-	query := `UPDATE restaurant_menu_items SET name=$1, category=$2, ingredients=$3, predicted_waste_score=$4, price=$5, margin=$6, suggestions=$7, updated_at=$8 WHERE id=$9 RETURNING id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, created_at, updated_at`
+	query := `UPDATE restaurant_menu_items SET name=$1, category=$2, ingredients=$3, predicted_waste_score=$4, price=$5, margin=$6, suggestions=$7, updated_at=$8 WHERE id=$9 RETURNING id, user_id, name, category, ingredients, predicted_waste_score, price, margin, suggestions, plate_cost, cost_complete, costed_at, created_at, updated_at`
 	var ingredientsJSONOut []byte
-	err := r.db.QueryRow(query, item.Name, item.Category, ingredientsJSON, item.PredictedWasteScore, item.Price, item.Margin, pq.Array(item.Suggestions), time.Now(), item.ID).Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &ingredientsJSONOut, &item.PredictedWasteScore, &item.Price, &item.Margin, pq.Array(&item.Suggestions), &item.CreatedAt, &item.UpdatedAt)
+	err := r.db.QueryRow(query, item.Name, item.Category, ingredientsJSON, item.PredictedWasteScore, item.Price, item.Margin, pq.Array(item.Suggestions), time.Now(), item.ID).Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &ingredientsJSONOut, &item.PredictedWasteScore, &item.Price, &item.Margin, pq.Array(&item.Suggestions), &item.PlateCost, &item.CostComplete, &item.CostedAt, &item.CreatedAt, &item.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return errors.ErrNotFound
@@ -116,3 +117,179 @@ func (r *Repository) Delete(id uuid.UUID) error {
 	}
 	return nil
 }
+
+// GetRecipes returns the recipe lines of the given menu items, keyed by item
+func (r *Repository) GetRecipes(menuItemIDs []uuid.UUID) (map[uuid.UUID][]*RecipeIngredient, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	recipes := map[uuid.UUID][]*RecipeIngredient{}
+	if len(menuItemIDs) == 0 {
+		return recipes, nil
+	}
+	ids := make([]string, len(menuItemIDs))
+	for i, id := range menuItemIDs {
+		ids[i] = id.String()
+	}
+	query := `SELECT id, menu_item_id, inventory_item_id, ingredient_name, quantity, unit, unit_cost, cost FROM restaurant_menu_recipe_lines WHERE menu_item_id = ANY($1::uuid[]) ORDER BY menu_item_id, position ASC`
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		line := &RecipeIngredient{}
+		var menuItemID uuid.UUID
+		if err := rows.Scan(&line.ID, &menuItemID, &line.InventoryItemID, &line.Name, &line.Quantity, &line.Unit, &line.UnitCost, &line.Cost); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		recipes[menuItemID] = append(recipes[menuItemID], line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return recipes, nil
+}
+
+// SaveRecipe replaces a menu item's recipe lines
+func (r *Repository) SaveRecipe(menuItemID uuid.UUID, lines []*RecipeIngredient) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM restaurant_menu_recipe_lines WHERE menu_item_id = $1`, menuItemID); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	for i, line := range lines {
+		if _, err := tx.Exec(`INSERT INTO restaurant_menu_recipe_lines (id, menu_item_id, inventory_item_id, ingredient_name, quantity, unit, position) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			line.ID, menuItemID, line.InventoryItemID, line.Name, line.Quantity, line.Unit, i); err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// SaveCosts stores a menu item's plate cost and margin and its recipe lines'
+// costs
+func (r *Repository) SaveCosts(item *RestaurantMenuItem) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE restaurant_menu_items SET plate_cost = $1, margin = $2, cost_complete = $3, costed_at = $4 WHERE id = $5`,
+		item.PlateCost, item.Margin, item.CostComplete, item.CostedAt, item.ID); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	for _, line := range item.Recipe {
+		if _, err := tx.Exec(`UPDATE restaurant_menu_recipe_lines SET unit_cost = $1, cost = $2 WHERE id = $3`, line.UnitCost, line.Cost, line.ID); err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// GetInventoryItemName returns the name of one of the restaurant's inventory
+// batches
+func (r *Repository) GetInventoryItemName(userID, inventoryItemID uuid.UUID) (string, error) {
+	if r.db == nil {
+		return "", errors.ErrDatabase
+	}
+	var name string
+	err := r.db.QueryRow(`SELECT name FROM restaurant_inventory_items WHERE id = $1 AND user_id = $2`, inventoryItemID, userID).Scan(&name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.ErrNotFound
+		}
+		return "", errors.WrapError(err, errors.ErrDatabase)
+	}
+	return name, nil
+}
+
+// ListIngredientPrices returns the unit costs of the restaurant's costed
+// batches of the named ingredients, newest first, keyed by lower-case name
+func (r *Repository) ListIngredientPrices(userID uuid.UUID, names []string) (map[string][]*ingredientPrice, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT LOWER(name), unit_cost, unit FROM restaurant_inventory_items
+		WHERE user_id = $1 AND LOWER(name) = ANY($2) AND unit_cost IS NOT NULL
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(query, userID, pq.Array(names))
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	prices := map[string][]*ingredientPrice{}
+	for rows.Next() {
+		var name string
+		price := &ingredientPrice{}
+		if err := rows.Scan(&name, &price.unitCost, &price.unit); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		prices[name] = append(prices[name], price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return prices, nil
+}
+
+// ListItemIDsUsing returns the restaurant's menu items whose recipe uses any of
+// the named ingredients
+func (r *Repository) ListItemIDsUsing(userID uuid.UUID, names []string) ([]uuid.UUID, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT DISTINCT l.menu_item_id FROM restaurant_menu_recipe_lines l
+		JOIN restaurant_menu_items m ON m.id = l.menu_item_id
+		WHERE m.user_id = $1 AND LOWER(l.ingredient_name) = ANY($2)`
+	rows, err := r.db.Query(query, userID, pq.Array(names))
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return ids, nil
+}
+
+// GetMinMargin returns the restaurant's margin threshold, in percent
+func (r *Repository) GetMinMargin(userID uuid.UUID) (float64, error) {
+	if r.db == nil {
+		return 0, errors.ErrDatabase
+	}
+	var minMargin float64
+	err := r.db.QueryRow(`SELECT min_margin FROM restaurant_preferences WHERE user_id = $1`, userID).Scan(&minMargin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return preferences.DefaultMinMargin, nil
+		}
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return minMargin, nil
+}
@@ -9,18 +9,22 @@ import (
 func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.TrimPrefix(r.URL.Path, "/api/v1/restaurant/menu/")
+		path := strings.Trim(r.URL.Path, "/")
+		pathParts := strings.Split(path, "/")
+
 		switch {
 		case path == "" && r.Method == http.MethodGet:
 			handler.GetAll(w, r)
-		case len(path) == 36 && r.Method == http.MethodGet:
+		case path == "" && r.Method == http.MethodPost:
+			handler.Create(w, r)
+		case len(pathParts) == 2 && pathParts[1] == "cost" && r.Method == http.MethodGet:
+			handler.GetCostBreakdown(w, r)
+		case len(pathParts) == 1 && r.Method == http.MethodGet:
 			handler.GetByID(w, r)
-		case len(path) == 36 && r.Method == http.MethodPut:
+		case len(pathParts) == 1 && r.Method == http.MethodPut:
 			handler.Update(w, r)
-		case len(path) == 36 && r.Method == http.MethodDelete:
+		case len(pathParts) == 1 && r.Method == http.MethodDelete:
 			handler.Delete(w, r)
-		case r.Method == http.MethodPost:
-			handler.Create(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
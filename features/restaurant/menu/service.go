@@ -2,11 +2,22 @@ package menu
 
 import (
 	"foodlink_backend/errors"
+	"foodlink_backend/features/impact"
 	"foodlink_backend/utils"
+	"math"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	errUnknownInventoryItem  = errors.NewAppError(http.StatusBadRequest, "Validation failed: recipe references an inventory item that does not exist")
+	errIngredientsFromRecipe = errors.NewAppError(http.StatusBadRequest, "Validation failed: ingredients come from the recipe; update the recipe instead")
+	errMarginFromRecipe      = errors.NewAppError(http.StatusBadRequest, "Validation failed: margin is computed from the recipe")
+)
+
 type Service struct {
 	repo *Repository
 }
@@ -15,19 +26,57 @@ func NewService() *Service {
 	return &Service{repo: NewRepository()}
 }
 
-func (s *Service) GetAllByUserID(userID uuid.UUID) ([]*RestaurantMenuItem, error) {
-	return s.repo.GetAllByUserID(userID)
+// GetAllByUserID returns the restaurant's menu items, only those below its
+// margin threshold when lowMarginOnly is set
+func (s *Service) GetAllByUserID(userID uuid.UUID, lowMarginOnly bool) ([]*RestaurantMenuItem, error) {
+	items, err := s.repo.GetAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decorate(userID, items); err != nil {
+		return nil, err
+	}
+	if !lowMarginOnly {
+		return items, nil
+	}
+	flagged := []*RestaurantMenuItem{}
+	for _, item := range items {
+		if item.LowMargin {
+			flagged = append(flagged, item)
+		}
+	}
+	return flagged, nil
 }
 
 func (s *Service) GetByID(id uuid.UUID) (*RestaurantMenuItem, error) {
-	return s.repo.GetByID(id)
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decorate(item.UserID, []*RestaurantMenuItem{item}); err != nil {
+		return nil, err
+	}
+	return item, nil
 }
 
 func (s *Service) Create(userID uuid.UUID, req *CreateRestaurantMenuItemRequest) (*RestaurantMenuItem, error) {
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
 	}
+	if len(req.Recipe) > 0 && req.Ingredients != nil {
+		return nil, errIngredientsFromRecipe
+	}
+	if len(req.Recipe) > 0 && req.Margin != nil {
+		return nil, errMarginFromRecipe
+	}
+	recipe, err := s.resolveRecipe(userID, req.Recipe)
+	if err != nil {
+		return nil, err
+	}
 	ingredientsJSON := JSONB{"ingredients": req.Ingredients}
+	if len(recipe) > 0 {
+		ingredientsJSON = recipeIngredients(recipe)
+	}
 	item := &RestaurantMenuItem{
 		ID:                 uuid.New(),
 		UserID:             userID,
@@ -36,12 +85,25 @@ func (s *Service) Create(userID uuid.UUID, req *CreateRestaurantMenuItemRequest)
 		Ingredients:        ingredientsJSON,
 		PredictedWasteScore: req.PredictedWasteScore,
 		Price:              req.Price,
-		Margin:             req.Margin,
 		Suggestions:        req.Suggestions,
 	}
+	if req.Margin != nil {
+		item.Margin = *req.Margin
+	}
 	if err := s.repo.Create(item); err != nil {
 		return nil, err
 	}
+	if len(recipe) > 0 {
+		if err := s.repo.SaveRecipe(item.ID, recipe); err != nil {
+			return nil, err
+		}
+		if err := s.recost(item); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.decorate(userID, []*RestaurantMenuItem{item}); err != nil {
+		return nil, err
+	}
 	return item, nil
 }
 
@@ -56,6 +118,24 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateRestaurantMe
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
 	}
+	recipes, err := s.repo.GetRecipes([]uuid.UUID{item.ID})
+	if err != nil {
+		return nil, err
+	}
+	hasRecipe := len(recipes[item.ID]) > 0
+	var recipe []*RecipeIngredient
+	if req.Recipe != nil {
+		if recipe, err = s.resolveRecipe(userID, req.Recipe); err != nil {
+			return nil, err
+		}
+		hasRecipe = len(recipe) > 0
+	}
+	if hasRecipe && req.Ingredients != nil {
+		return nil, errIngredientsFromRecipe
+	}
+	if hasRecipe && req.Margin != nil {
+		return nil, errMarginFromRecipe
+	}
 	if req.Name != "" {
 		item.Name = req.Name
 	}
@@ -65,6 +145,9 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateRestaurantMe
 	if req.Ingredients != nil {
 		item.Ingredients = JSONB{"ingredients": req.Ingredients}
 	}
+	if len(recipe) > 0 {
+		item.Ingredients = recipeIngredients(recipe)
+	}
 	if req.PredictedWasteScore != "" {
 		item.PredictedWasteScore = req.PredictedWasteScore
 	}
@@ -80,6 +163,20 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateRestaurantMe
 	if err := s.repo.Update(item); err != nil {
 		return nil, err
 	}
+	if req.Recipe != nil {
+		if err := s.repo.SaveRecipe(item.ID, recipe); err != nil {
+			return nil, err
+		}
+	}
+	// The price or the recipe may have changed
+	if hasRecipe || req.Recipe != nil {
+		if err := s.recost(item); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.decorate(userID, []*RestaurantMenuItem{item}); err != nil {
+		return nil, err
+	}
 	return item, nil
 }
 
@@ -93,3 +190,188 @@ func (s *Service) Delete(id uuid.UUID, userID uuid.UUID) error {
 	}
 	return s.repo.Delete(id)
 }
+
+// GetCostBreakdown returns what a menu item's plate costs, ingredient by
+// ingredient, as of its last costing
+func (s *Service) GetCostBreakdown(id uuid.UUID, userID uuid.UUID) (*CostBreakdown, error) {
+	item, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	minMargin, err := s.repo.GetMinMargin(userID)
+	if err != nil {
+		return nil, err
+	}
+	breakdown := &CostBreakdown{
+		MenuItemID:   item.ID,
+		Name:         item.Name,
+		Price:        item.Price,
+		PlateCost:    item.PlateCost,
+		Margin:       item.Margin,
+		MinMargin:    minMargin,
+		LowMargin:    item.LowMargin,
+		CostComplete: item.CostComplete,
+		CostedAt:     item.CostedAt,
+		Ingredients:  []*IngredientCost{},
+	}
+	for _, line := range item.Recipe {
+		cost := &IngredientCost{RecipeIngredient: line}
+		if line.Cost != nil && item.PlateCost != nil && *item.PlateCost > 0 {
+			share := round2(*line.Cost / *item.PlateCost * 100)
+			cost.Share = &share
+		}
+		breakdown.Ingredients = append(breakdown.Ingredients, cost)
+	}
+	return breakdown, nil
+}
+
+// RecostIngredients costs again the restaurant's menu items whose recipes use
+// any of the named ingredients
+func (s *Service) RecostIngredients(userID uuid.UUID, names []string) error {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+	ids, err := s.repo.ListItemIDsUsing(userID, lowered)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		item, err := s.repo.GetByID(id)
+		if err == errors.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.recost(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recost prices a menu item's recipe and stores its plate cost and margin. An
+// item without a recipe keeps its typed-in margin and has no plate cost.
+func (s *Service) recost(item *RestaurantMenuItem) error {
+	recipes, err := s.repo.GetRecipes([]uuid.UUID{item.ID})
+	if err != nil {
+		return err
+	}
+	item.Recipe = recipes[item.ID]
+	item.PlateCost, item.CostComplete, item.CostedAt = nil, false, nil
+	if len(item.Recipe) == 0 {
+		return s.repo.SaveCosts(item)
+	}
+	names := make([]string, 0, len(item.Recipe))
+	for _, line := range item.Recipe {
+		names = append(names, strings.ToLower(line.Name))
+	}
+	prices, err := s.repo.ListIngredientPrices(item.UserID, names)
+	if err != nil {
+		return err
+	}
+	plateCost, complete := priceRecipe(item.Recipe, prices)
+	plateCost = round2(plateCost)
+	now := time.Now()
+	item.PlateCost, item.CostComplete, item.CostedAt = &plateCost, complete, &now
+	if item.Price > 0 {
+		item.Margin = round2((item.Price - plateCost) / item.Price * 100)
+	}
+	return s.repo.SaveCosts(item)
+}
+
+// priceRecipe prices each recipe line at the newest costed batch of its
+// ingredient whose unit the line's converts to. Lines with no such batch stay
+// unpriced and make the plate cost incomplete.
+func priceRecipe(recipe []*RecipeIngredient, prices map[string][]*ingredientPrice) (plateCost float64, complete bool) {
+	complete = true
+	for _, line := range recipe {
+		line.UnitCost, line.Cost = nil, nil
+		for _, price := range prices[strings.ToLower(line.Name)] {
+			batchUnits, ok := impact.ConvertUnit(1, line.Unit, price.unit)
+			if !ok {
+				continue
+			}
+			unitCost := price.unitCost * batchUnits
+			roundedUnitCost := math.Round(unitCost*10000) / 10000
+			cost := round2(unitCost * line.Quantity)
+			line.UnitCost, line.Cost = &roundedUnitCost, &cost
+			plateCost += unitCost * line.Quantity
+			break
+		}
+		if line.Cost == nil {
+			complete = false
+		}
+	}
+	return plateCost, complete
+}
+
+// decorate attaches the items' recipes and flags those whose margin is below
+// the restaurant's threshold
+func (s *Service) decorate(userID uuid.UUID, items []*RestaurantMenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	recipes, err := s.repo.GetRecipes(ids)
+	if err != nil {
+		return err
+	}
+	minMargin, err := s.repo.GetMinMargin(userID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		item.Recipe = recipes[item.ID]
+		item.LowMargin = item.Margin < minMargin
+	}
+	return nil
+}
+
+// resolveRecipe turns requested recipe lines into recipe ingredients. A line
+// referencing an inventory batch takes the batch's ingredient name.
+func (s *Service) resolveRecipe(userID uuid.UUID, lines []*RecipeIngredientRequest) ([]*RecipeIngredient, error) {
+	recipe := make([]*RecipeIngredient, 0, len(lines))
+	for _, line := range lines {
+		ingredient := &RecipeIngredient{
+			ID:              uuid.New(),
+			InventoryItemID: line.InventoryItemID,
+			Name:            strings.TrimSpace(line.Name),
+			Quantity:        line.Quantity,
+			Unit:            strings.TrimSpace(line.Unit),
+		}
+		if line.InventoryItemID != nil {
+			name, err := s.repo.GetInventoryItemName(userID, *line.InventoryItemID)
+			if err == errors.ErrNotFound {
+				return nil, errUnknownInventoryItem
+			}
+			if err != nil {
+				return nil, err
+			}
+			ingredient.Name = name
+		}
+		recipe = append(recipe, ingredient)
+	}
+	return recipe, nil
+}
+
+// recipeIngredients mirrors a recipe in the free-form ingredients, which sales
+// depletion and forecasting read
+func recipeIngredients(recipe []*RecipeIngredient) JSONB {
+	ingredients := make([]interface{}, 0, len(recipe))
+	for _, line := range recipe {
+		ingredients = append(ingredients, map[string]interface{}{"name": line.Name, "quantity": line.Quantity, "unit": line.Unit})
+	}
+	return JSONB{"ingredients": ingredients}
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -6,12 +6,17 @@ import (
 	"github.com/google/uuid"
 )
 
+// DefaultMinMargin is the margin threshold of a restaurant that has not set one
+const DefaultMinMargin = 30.0
+
 type RestaurantPreferences struct {
 	ID                 uuid.UUID `json:"id" db:"id"`
 	UserID             uuid.UUID `json:"user_id" db:"user_id"`
 	CuisineType        string    `json:"cuisine_type,omitempty" db:"cuisine_type"`
 	OperatingHours     string    `json:"operating_hours,omitempty" db:"operating_hours"`
 	DonationPreferences []string `json:"donation_preferences,omitempty" db:"donation_preferences"`
+	// MinMargin is the margin, in percent, below which menu items are flagged
+	MinMargin          float64   `json:"min_margin" db:"min_margin"`
 	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -20,4 +25,5 @@ type CreateRestaurantPreferencesRequest struct {
 	CuisineType        string   `json:"cuisine_type,omitempty" validate:"omitempty,max=100"`
 	OperatingHours     string   `json:"operating_hours,omitempty"`
 	DonationPreferences []string `json:"donation_preferences,omitempty"`
+	MinMargin          *float64 `json:"min_margin,omitempty" validate:"omitempty,gte=0,lt=100"`
 }
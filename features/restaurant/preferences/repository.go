@@ -23,8 +23,8 @@ func (r *Repository) GetByUserID(userID uuid.UUID) (*RestaurantPreferences, erro
 		return nil, errors.ErrDatabase
 	}
 	prefs := &RestaurantPreferences{}
-	query := `SELECT id, user_id, cuisine_type, operating_hours, donation_preferences, created_at, updated_at FROM restaurant_preferences WHERE user_id = $1`
-	err := r.db.QueryRow(query, userID).Scan(&prefs.ID, &prefs.UserID, &prefs.CuisineType, &prefs.OperatingHours, pq.Array(&prefs.DonationPreferences), &prefs.CreatedAt, &prefs.UpdatedAt)
+	query := `SELECT id, user_id, cuisine_type, operating_hours, donation_preferences, min_margin, created_at, updated_at FROM restaurant_preferences WHERE user_id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&prefs.ID, &prefs.UserID, &prefs.CuisineType, &prefs.OperatingHours, pq.Array(&prefs.DonationPreferences), &prefs.MinMargin, &prefs.CreatedAt, &prefs.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
@@ -38,7 +38,7 @@ func (r *Repository) CreateOrUpdate(prefs *RestaurantPreferences) error {
 	if r.db == nil {
 		return errors.ErrDatabase
 	}
-	query := `INSERT INTO restaurant_preferences (id, user_id, cuisine_type, operating_hours, donation_preferences, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (user_id) DO UPDATE SET cuisine_type=EXCLUDED.cuisine_type, operating_hours=EXCLUDED.operating_hours, donation_preferences=EXCLUDED.donation_preferences, updated_at=EXCLUDED.updated_at RETURNING id, user_id, cuisine_type, operating_hours, donation_preferences, created_at, updated_at`
+	query := `INSERT INTO restaurant_preferences (id, user_id, cuisine_type, operating_hours, donation_preferences, min_margin, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (user_id) DO UPDATE SET cuisine_type=EXCLUDED.cuisine_type, operating_hours=EXCLUDED.operating_hours, donation_preferences=EXCLUDED.donation_preferences, min_margin=EXCLUDED.min_margin, updated_at=EXCLUDED.updated_at RETURNING id, user_id, cuisine_type, operating_hours, donation_preferences, min_margin, created_at, updated_at`
 	now := time.Now()
-	return r.db.QueryRow(query, prefs.ID, prefs.UserID, prefs.CuisineType, prefs.OperatingHours, pq.Array(prefs.DonationPreferences), now, now).Scan(&prefs.ID, &prefs.UserID, &prefs.CuisineType, &prefs.OperatingHours, pq.Array(&prefs.DonationPreferences), &prefs.CreatedAt, &prefs.UpdatedAt)
+	return r.db.QueryRow(query, prefs.ID, prefs.UserID, prefs.CuisineType, prefs.OperatingHours, pq.Array(prefs.DonationPreferences), prefs.MinMargin, now, now).Scan(&prefs.ID, &prefs.UserID, &prefs.CuisineType, &prefs.OperatingHours, pq.Array(&prefs.DonationPreferences), &prefs.MinMargin, &prefs.CreatedAt, &prefs.UpdatedAt)
 }
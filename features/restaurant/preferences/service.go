@@ -30,6 +30,15 @@ func (s *Service) CreateOrUpdate(userID uuid.UUID, req *CreateRestaurantPreferen
 		OperatingHours:     req.OperatingHours,
 		DonationPreferences: req.DonationPreferences,
 	}
+	// Keep the margin threshold unless the request sets one
+	prefs.MinMargin = DefaultMinMargin
+	if req.MinMargin != nil {
+		prefs.MinMargin = *req.MinMargin
+	} else if existing, err := s.repo.GetByUserID(userID); err == nil {
+		prefs.MinMargin = existing.MinMargin
+	} else if err != errors.ErrNotFound {
+		return nil, err
+	}
 	if err := s.repo.CreateOrUpdate(prefs); err != nil {
 		return nil, err
 	}
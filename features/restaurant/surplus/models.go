@@ -39,6 +39,8 @@ type RestaurantSurplusItem struct {
 	Category     string    `json:"category" db:"category"`
 	StorageType  string    `json:"storage_type" db:"storage_type"`
 	PickupWindow JSONB     `json:"pickup_window" db:"pickup_window"`
+	Location     string    `json:"location,omitempty" db:"location"`
+	GeoPoint     JSONB     `json:"geo_point,omitempty" db:"geo_point"`
 	Tags         []string  `json:"tags,omitempty" db:"tags"`
 	Image        string    `json:"image,omitempty" db:"image"`
 	AssignedTo   string    `json:"assigned_to,omitempty" db:"assigned_to"`
@@ -56,7 +58,10 @@ type CreateRestaurantSurplusItemRequest struct {
 	Category     string                 `json:"category" validate:"required,min=1,max=100"`
 	StorageType  string                 `json:"storage_type" validate:"required,oneof=fresh chilled frozen"`
 	PickupWindow map[string]interface{} `json:"pickup_window" validate:"required"`
+	Location     string                 `json:"location,omitempty"`
+	GeoPoint     map[string]interface{} `json:"geo_point,omitempty"`
 	Tags         []string               `json:"tags,omitempty"`
+	// An image URL, or the ID of an upload from POST /uploads
 	Image        string                 `json:"image,omitempty"`
 }
 
@@ -68,6 +73,8 @@ type UpdateRestaurantSurplusItemRequest struct {
 	Category     string                 `json:"category,omitempty" validate:"omitempty,min=1,max=100"`
 	StorageType  string                 `json:"storage_type,omitempty" validate:"omitempty,oneof=fresh chilled frozen"`
 	PickupWindow map[string]interface{} `json:"pickup_window,omitempty"`
+	Location     string                 `json:"location,omitempty"`
+	GeoPoint     map[string]interface{} `json:"geo_point,omitempty"`
 	Tags         []string               `json:"tags,omitempty"`
 	Image        string                 `json:"image,omitempty"`
 	Status       string                 `json:"status,omitempty"`
@@ -77,3 +84,11 @@ type AssignSurplusItemRequest struct {
 	AssignedTo   string `json:"assigned_to" validate:"required,oneof=ngo kitchen"`
 	RecipientName string `json:"recipient_name" validate:"required,min=1"`
 }
+
+// Surplus item statuses. An item is claimed once an NGO accepts an offer for it.
+const (
+	StatusPending  = "pending"
+	StatusClaimed  = "claimed"
+	StatusPickedUp = "picked-up"
+	StatusExpired  = "expired"
+)
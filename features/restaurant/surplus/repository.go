@@ -11,6 +11,10 @@ import (
 	"github.com/lib/pq"
 )
 
+// itemColumns lists the columns scanned by scanItem. Optional text columns are
+// coalesced so unassigned items scan into plain strings.
+const itemColumns = `id, user_id, title, description, quantity, unit, category, storage_type, pickup_window, COALESCE(location, ''), geo_point, tags, COALESCE(image, ''), COALESCE(assigned_to, ''), COALESCE(recipient_name, ''), status, created_at, updated_at`
+
 type Repository struct {
 	db *sql.DB
 }
@@ -23,7 +27,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*RestaurantSurplusItem,
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	query := `SELECT id, user_id, title, description, quantity, unit, category, storage_type, pickup_window, tags, image, assigned_to, recipient_name, status, created_at, updated_at FROM restaurant_surplus_items WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + itemColumns + ` FROM restaurant_surplus_items WHERE user_id = $1 ORDER BY created_at DESC`
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
@@ -31,14 +35,10 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*RestaurantSurplusItem,
 	defer rows.Close()
 	var items []*RestaurantSurplusItem
 	for rows.Next() {
-		item := &RestaurantSurplusItem{}
-		var pickupWindowJSON []byte
-		if err := rows.Scan(&item.ID, &item.UserID, &item.Title, &item.Description, &item.Quantity, &item.Unit, &item.Category, &item.StorageType, &pickupWindowJSON, pq.Array(&item.Tags), &item.Image, &item.AssignedTo, &item.RecipientName, &item.Status, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		item, err := scanItem(rows)
+		if err != nil {
 			return nil, errors.WrapError(err, errors.ErrDatabase)
 		}
-		if len(pickupWindowJSON) > 0 {
-			json.Unmarshal(pickupWindowJSON, &item.PickupWindow)
-		}
 		items = append(items, item)
 	}
 	return items, nil
@@ -48,19 +48,14 @@ func (r *Repository) GetByID(id uuid.UUID) (*RestaurantSurplusItem, error) {
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	item := &RestaurantSurplusItem{}
-	var pickupWindowJSON []byte
-	query := `SELECT id, user_id, title, description, quantity, unit, category, storage_type, pickup_window, tags, image, assigned_to, recipient_name, status, created_at, updated_at FROM restaurant_surplus_items WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.UserID, &item.Title, &item.Description, &item.Quantity, &item.Unit, &item.Category, &item.StorageType, &pickupWindowJSON, pq.Array(&item.Tags), &item.Image, &item.AssignedTo, &item.RecipientName, &item.Status, &item.CreatedAt, &item.UpdatedAt)
+	query := `SELECT ` + itemColumns + ` FROM restaurant_surplus_items WHERE id = $1`
+	item, err := scanItem(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.ErrNotFound
 		}
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(pickupWindowJSON) > 0 {
-		json.Unmarshal(pickupWindowJSON, &item.PickupWindow)
-	}
 	return item, nil
 }
 
@@ -69,16 +64,13 @@ func (r *Repository) Create(item *RestaurantSurplusItem) error {
 		return errors.ErrDatabase
 	}
 	pickupWindowJSON, _ := json.Marshal(item.PickupWindow)
-	query := `INSERT INTO restaurant_surplus_items (id, user_id, title, description, quantity, unit, category, storage_type, pickup_window, tags, image, assigned_to, recipient_name, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id, user_id, title, description, quantity, unit, category, storage_type, pickup_window, tags, image, assigned_to, recipient_name, status, created_at, updated_at`
+	query := `INSERT INTO restaurant_surplus_items (id, user_id, title, description, quantity, unit, category, storage_type, pickup_window, location, geo_point, tags, image, assigned_to, recipient_name, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $12, $13, NULLIF($14, ''), NULLIF($15, ''), $16, $17, $18) RETURNING ` + itemColumns
 	now := time.Now()
-	var pickupWindowJSONOut []byte
-	err := r.db.QueryRow(query, item.ID, item.UserID, item.Title, item.Description, item.Quantity, item.Unit, item.Category, item.StorageType, pickupWindowJSON, pq.Array(item.Tags), item.Image, item.AssignedTo, item.RecipientName, item.Status, now, now).Scan(&item.ID, &item.UserID, &item.Title, &item.Description, &item.Quantity, &item.Unit, &item.Category, &item.StorageType, &pickupWindowJSONOut, pq.Array(&item.Tags), &item.Image, &item.AssignedTo, &item.RecipientName, &item.Status, &item.CreatedAt, &item.UpdatedAt)
+	created, err := scanItem(r.db.QueryRow(query, item.ID, item.UserID, item.Title, item.Description, item.Quantity, item.Unit, item.Category, item.StorageType, pickupWindowJSON, item.Location, item.GeoPoint, pq.Array(item.Tags), item.Image, item.AssignedTo, item.RecipientName, item.Status, now, now))
 	if err != nil {
 		return errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(pickupWindowJSONOut) > 0 {
-		json.Unmarshal(pickupWindowJSONOut, &item.PickupWindow)
-	}
+	*item = *created
 	return nil
 }
 
@@ -87,17 +79,33 @@ func (r *Repository) Update(item *RestaurantSurplusItem) error {
 		return errors.ErrDatabase
 	}
 	pickupWindowJSON, _ := json.Marshal(item.PickupWindow)
-	query := `UPDATE restaurant_surplus_items SET title=$1, description=$2, quantity=$3, unit=$4, category=$5, storage_type=$6, pickup_window=$7, tags=$8, image=$9, assigned_to=$10, recipient_name=$11, status=$12, updated_at=$13 WHERE id=$14 RETURNING id, user_id, title, description, quantity, unit, category, storage_type, pickup_window, tags, image, assigned_to, recipient_name, status, created_at, updated_at`
-	var pickupWindowJSONOut []byte
-	err := r.db.QueryRow(query, item.Title, item.Description, item.Quantity, item.Unit, item.Category, item.StorageType, pickupWindowJSON, pq.Array(item.Tags), item.Image, item.AssignedTo, item.RecipientName, item.Status, time.Now(), item.ID).Scan(&item.ID, &item.UserID, &item.Title, &item.Description, &item.Quantity, &item.Unit, &item.Category, &item.StorageType, &pickupWindowJSONOut, pq.Array(&item.Tags), &item.Image, &item.AssignedTo, &item.RecipientName, &item.Status, &item.CreatedAt, &item.UpdatedAt)
+	query := `UPDATE restaurant_surplus_items SET title=$1, description=$2, quantity=$3, unit=$4, category=$5, storage_type=$6, pickup_window=$7, location=NULLIF($8, ''), geo_point=$9, tags=$10, image=$11, assigned_to=NULLIF($12, ''), recipient_name=NULLIF($13, ''), status=$14, updated_at=$15 WHERE id=$16 RETURNING ` + itemColumns
+	updated, err := scanItem(r.db.QueryRow(query, item.Title, item.Description, item.Quantity, item.Unit, item.Category, item.StorageType, pickupWindowJSON, item.Location, item.GeoPoint, pq.Array(item.Tags), item.Image, item.AssignedTo, item.RecipientName, item.Status, time.Now(), item.ID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return errors.ErrNotFound
 		}
 		return errors.WrapError(err, errors.ErrDatabase)
 	}
-	if len(pickupWindowJSONOut) > 0 {
-		json.Unmarshal(pickupWindowJSONOut, &item.PickupWindow)
-	}
+	*item = *updated
 	return nil
 }
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanItem(row rowScanner) (*RestaurantSurplusItem, error) {
+	item := &RestaurantSurplusItem{}
+	var pickupWindowJSON, geoPointJSON []byte
+	if err := row.Scan(&item.ID, &item.UserID, &item.Title, &item.Description, &item.Quantity, &item.Unit, &item.Category, &item.StorageType, &pickupWindowJSON, &item.Location, &geoPointJSON, pq.Array(&item.Tags), &item.Image, &item.AssignedTo, &item.RecipientName, &item.Status, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(pickupWindowJSON) > 0 {
+		json.Unmarshal(pickupWindowJSON, &item.PickupWindow)
+	}
+	if len(geoPointJSON) > 0 {
+		json.Unmarshal(geoPointJSON, &item.GeoPoint)
+	}
+	return item, nil
+}
@@ -2,17 +2,20 @@ package surplus
 
 import (
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/uploads"
 	"foodlink_backend/utils"
 
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	repo *Repository
+	repo   *Repository
+	images *uploads.Linker
 }
 
 func NewService() *Service {
-	return &Service{repo: NewRepository()}
+	return &Service{repo: NewRepository(), images: uploads.NewLinker()}
 }
 
 func (s *Service) GetAllByUserID(userID uuid.UUID) ([]*RestaurantSurplusItem, error) {
@@ -37,13 +40,32 @@ func (s *Service) Create(userID uuid.UUID, req *CreateRestaurantSurplusItemReque
 		Category:     req.Category,
 		StorageType:  req.StorageType,
 		PickupWindow: JSONB(req.PickupWindow),
+		Location:     req.Location,
+		GeoPoint:     JSONB(req.GeoPoint),
 		Tags:         req.Tags,
 		Image:        req.Image,
-		Status:       "pending",
+		Status:       StatusPending,
 	}
+	image, err := s.images.Resolve(userID, item.Image, uploads.AssociatedRestaurantSurplus, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	item.Image = image
 	if err := s.repo.Create(item); err != nil {
 		return nil, err
 	}
+	if err := s.images.Sync(item.Image, uploads.AssociatedRestaurantSurplus, item.ID); err != nil {
+		return nil, err
+	}
+	events.Publish(events.RestaurantSurplusAdded{
+		Meta:        events.NewMeta(),
+		UserID:      item.UserID,
+		ItemID:      item.ID,
+		Category:    item.Category,
+		Quantity:    item.Quantity,
+		Unit:        item.Unit,
+		StorageType: item.StorageType,
+	})
 	return item, nil
 }
 
@@ -79,11 +101,19 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateRestaurantSu
 	if req.PickupWindow != nil {
 		item.PickupWindow = JSONB(req.PickupWindow)
 	}
+	if req.Location != "" {
+		item.Location = req.Location
+	}
+	if req.GeoPoint != nil {
+		item.GeoPoint = JSONB(req.GeoPoint)
+	}
 	if req.Tags != nil {
 		item.Tags = req.Tags
 	}
 	if req.Image != "" {
-		item.Image = req.Image
+		if item.Image, err = s.images.Resolve(userID, req.Image, uploads.AssociatedRestaurantSurplus, item.ID); err != nil {
+			return nil, err
+		}
 	}
 	if req.Status != "" {
 		item.Status = req.Status
@@ -91,6 +121,11 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateRestaurantSu
 	if err := s.repo.Update(item); err != nil {
 		return nil, err
 	}
+	if req.Image != "" {
+		if err := s.images.Sync(item.Image, uploads.AssociatedRestaurantSurplus, item.ID); err != nil {
+			return nil, err
+		}
+	}
 	return item, nil
 }
 
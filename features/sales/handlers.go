@@ -0,0 +1,178 @@
+package sales
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxBatchBytes bounds the size of a batch body
+const maxBatchBytes = 5 << 20
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUser(r *http.Request) (*auth.User, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return user, nil
+}
+
+// Ingest handles POST /api/v1/sales
+// @Summary      Ingest point-of-sale lines
+// @Description  Record a batch of sold line items, as JSON or as CSV (Content-Type text/csv) with a header row of receipt_id, sold_at, quantity, unit_price and menu_item_id/menu_item (restaurants) or sku_id/barcode (shops). Lines are grouped by receipt_id and a receipt already sent is skipped. Shop sales decrement SKU stock; restaurant sales deplete ingredient stock through each menu item's recipe, earliest expiry first. Daily totals are kept for forecasting and reporting.
+// @Tags         sales
+// @Accept       json
+// @Accept       text/csv
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      IngestSalesRequest  true  "Sale lines"
+// @Success      201      {object}  IngestResult
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Router       /sales [post]
+func (h *Handler) Ingest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBytes)
+	source := SourceJSON
+	var req IngestSalesRequest
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		source = SourceCSV
+		if req.Lines, err = parseCSV(r.Body); err != nil {
+			if appErr, ok := err.(*errors.AppError); ok {
+				utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+				return
+			}
+			utils.BadRequestResponse(w, "Invalid request body", err.Error())
+			return
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	result, err := h.service.Ingest(user.ID, user.Role, source, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to ingest sales", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Sales ingested successfully", result)
+}
+
+// ListReceipts handles GET /api/v1/sales/receipts
+// @Summary      List receipts
+// @Description  List the authenticated restaurant's or shop's most recently sold receipts
+// @Tags         sales
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit  query     int  false  "Maximum number of receipts (default: 50, max: 100)"
+// @Success      200    {array}   Receipt
+// @Failure      401    {object}  errors.AppError
+// @Router       /sales/receipts [get]
+func (h *Handler) ListReceipts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	receipts, err := h.service.ListReceipts(user.ID, limit)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve receipts", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Receipts retrieved successfully", receipts)
+}
+
+// GetDailySeries handles GET /api/v1/sales/series
+// @Summary      Get daily sales
+// @Description  Get units sold and revenue per menu item or SKU per UTC day, over the last four weeks when no window is given
+// @Tags         sales
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from     query     string  false  "Window start (RFC 3339)"
+// @Param        to       query     string  false  "Window end (RFC 3339)"
+// @Param        item_id  query     string  false  "Only this menu item or SKU"
+// @Success      200      {array}   DailySales
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Router       /sales/series [get]
+func (h *Handler) GetDailySeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	query := r.URL.Query()
+	var filter SeriesFilter
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			utils.BadRequestResponse(w, "Invalid from; expected an RFC 3339 time", nil)
+			return
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			utils.BadRequestResponse(w, "Invalid to; expected an RFC 3339 time", nil)
+			return
+		}
+	}
+	if value := query.Get("item_id"); value != "" {
+		itemID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid item_id", nil)
+			return
+		}
+		filter.ItemID = &itemID
+	}
+	series, err := h.service.GetDailySeries(user.ID, filter)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve sales series", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Sales series retrieved successfully", series)
+}
@@ -0,0 +1,120 @@
+package sales
+
+import (
+	"foodlink_backend/features/restaurant/menu"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Item types a sale is recorded against
+const (
+	ItemMenuItem = "menu-item"
+	ItemSKU      = "sku"
+)
+
+// Sources a batch can arrive in
+const (
+	SourceJSON = "json"
+	SourceCSV  = "csv"
+)
+
+// maxBatchLines bounds how many lines one batch may carry
+const maxBatchLines = 5000
+
+// SaleLine is one line of a point-of-sale receipt. Restaurants identify the
+// menu item sold by menu_item_id or menu_item; shops identify the SKU by
+// sku_id or barcode.
+type SaleLine struct {
+	ReceiptID  string     `json:"receipt_id" validate:"required,min=1,max=255"`
+	SoldAt     time.Time  `json:"sold_at" validate:"required"`
+	MenuItemID *uuid.UUID `json:"menu_item_id,omitempty"`
+	MenuItem   string     `json:"menu_item,omitempty" validate:"omitempty,max=255"`
+	SKUID      *uuid.UUID `json:"sku_id,omitempty"`
+	Barcode    string     `json:"barcode,omitempty" validate:"omitempty,max=64"`
+	Quantity   float64    `json:"quantity" validate:"gt=0"`
+	UnitPrice  float64    `json:"unit_price" validate:"gte=0"`
+}
+
+// IngestSalesRequest is a batch of receipt lines. The same lines can be sent
+// as CSV with a header row naming these fields.
+type IngestSalesRequest struct {
+	Lines []*SaleLine `json:"lines" validate:"required,min=1,dive"`
+}
+
+// IngestResult reports what a batch did. Receipts already ingested are
+// skipped whole; lines naming an unknown item are stored but deplete nothing.
+type IngestResult struct {
+	Receipts          int                    `json:"receipts"`
+	DuplicateReceipts []string               `json:"duplicate_receipts"`
+	Lines             int                    `json:"lines"`
+	Unmatched         []*UnmatchedLine       `json:"unmatched"`
+	Shortfalls        []*IngredientShortfall `json:"shortfalls,omitempty"`
+}
+
+// UnmatchedLine is a line whose item could not be found. Line numbers start at
+// 1 and count data rows only.
+type UnmatchedLine struct {
+	Line   int    `json:"line"`
+	Item   string `json:"item"`
+	Reason string `json:"reason"`
+}
+
+// IngredientShortfall is recipe stock the restaurant's inventory did not have
+// when the sales were depleted from it
+type IngredientShortfall struct {
+	Ingredient string  `json:"ingredient"`
+	Missing    float64 `json:"missing"`
+	Unit       string  `json:"unit"`
+}
+
+// Receipt is an ingested point-of-sale receipt
+type Receipt struct {
+	ID                uuid.UUID `json:"id" db:"id"`
+	UserID            uuid.UUID `json:"user_id" db:"user_id"`
+	ExternalReceiptID string    `json:"external_receipt_id" db:"external_receipt_id"`
+	SoldAt            time.Time `json:"sold_at" db:"sold_at"`
+	Total             float64   `json:"total" db:"total"`
+	LineCount         int       `json:"line_count" db:"line_count"`
+	Source            string    `json:"source" db:"source"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+// DailySales is what one item sold on one UTC day
+type DailySales struct {
+	ItemType string    `json:"item_type" db:"item_type"`
+	ItemID   uuid.UUID `json:"item_id" db:"item_id"`
+	Day      time.Time `json:"day" db:"day"`
+	Quantity float64   `json:"quantity" db:"quantity"`
+	Revenue  float64   `json:"revenue" db:"revenue"`
+}
+
+// SeriesFilter selects daily sales between From and To, optionally of one item
+type SeriesFilter struct {
+	ItemID *uuid.UUID
+	From   time.Time
+	To     time.Time
+}
+
+// resolvedLine is a line matched, or not, to the seller's item
+type resolvedLine struct {
+	*SaleLine
+	itemType string
+	itemID   *uuid.UUID
+	name     string
+}
+
+// receiptBatch is the lines of one receipt within a batch
+type receiptBatch struct {
+	externalID string
+	soldAt     time.Time
+	lines      []*resolvedLine
+}
+
+// catalogItem is a menu item or SKU that lines are matched against
+type catalogItem struct {
+	id      uuid.UUID
+	name    string
+	barcode string
+	recipe  []menu.RecipeLine
+}
@@ -0,0 +1,99 @@
+package sales
+
+import (
+	"encoding/csv"
+	"fmt"
+	"foodlink_backend/errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// csvColumns are the header names a CSV batch may use
+var csvColumns = map[string]bool{
+	"receipt_id": true, "sold_at": true, "menu_item_id": true, "menu_item": true,
+	"sku_id": true, "barcode": true, "quantity": true, "unit_price": true,
+}
+
+// parseCSV reads a CSV batch. The first row names the columns, in any order;
+// receipt_id, sold_at and quantity are required. sold_at is an RFC 3339 time.
+func parseCSV(r io.Reader) ([]*SaleLine, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, csvError("missing header row")
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !csvColumns[name] {
+			return nil, csvError(fmt.Sprintf("unknown column %q", name))
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"receipt_id", "sold_at", "quantity"} {
+		if _, ok := columns[required]; !ok {
+			return nil, csvError("missing column " + required)
+		}
+	}
+
+	lines := []*SaleLine{}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err.Error())
+		}
+		if len(lines) == maxBatchLines {
+			return nil, errTooManyLines
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		line := &SaleLine{ReceiptID: field("receipt_id"), MenuItem: field("menu_item"), Barcode: field("barcode")}
+		if line.SoldAt, err = time.Parse(time.RFC3339, field("sold_at")); err != nil {
+			return nil, csvError(fmt.Sprintf("line %d: invalid sold_at; expected an RFC 3339 time", row))
+		}
+		if line.Quantity, err = strconv.ParseFloat(field("quantity"), 64); err != nil {
+			return nil, csvError(fmt.Sprintf("line %d: invalid quantity", row))
+		}
+		if value := field("unit_price"); value != "" {
+			if line.UnitPrice, err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, csvError(fmt.Sprintf("line %d: invalid unit_price", row))
+			}
+		}
+		if line.MenuItemID, err = parseOptionalID(field("menu_item_id")); err != nil {
+			return nil, csvError(fmt.Sprintf("line %d: invalid menu_item_id", row))
+		}
+		if line.SKUID, err = parseOptionalID(field("sku_id")); err != nil {
+			return nil, csvError(fmt.Sprintf("line %d: invalid sku_id", row))
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func parseOptionalID(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func csvError(message string) error {
+	return errors.NewAppError(http.StatusBadRequest, "Invalid CSV: "+message)
+}
@@ -0,0 +1,294 @@
+package sales
+
+import (
+	"database/sql"
+	"encoding/json"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/impact"
+	"foodlink_backend/features/restaurant/menu"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+// MenuCatalog returns a restaurant's menu items with their recipes
+func (r *Repository) MenuCatalog(userID uuid.UUID) ([]*catalogItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT id, name, ingredients FROM restaurant_menu_items WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	items := []*catalogItem{}
+	for rows.Next() {
+		item := &catalogItem{}
+		var ingredientsJSON []byte
+		if err := rows.Scan(&item.id, &item.name, &ingredientsJSON); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		var ingredients menu.JSONB
+		if len(ingredientsJSON) > 0 {
+			json.Unmarshal(ingredientsJSON, &ingredients)
+		}
+		item.recipe = menu.ParseRecipe(ingredients)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return items, nil
+}
+
+// SKUCatalog returns a shop's SKUs
+func (r *Repository) SKUCatalog(userID uuid.UUID) ([]*catalogItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT id, name, barcode FROM shop_inventory_items WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	items := []*catalogItem{}
+	for rows.Next() {
+		item := &catalogItem{}
+		if err := rows.Scan(&item.id, &item.name, &item.barcode); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return items, nil
+}
+
+// Ingest stores a batch's receipts in one transaction. A receipt whose
+// external ID the seller already sent is skipped and reported as a duplicate.
+// Matched lines add to the daily series and deplete stock: a SKU's stock
+// directly, a menu item's ingredients through its recipe, earliest expiry
+// first. Ingredient stock that ran out is reported as a shortfall.
+func (r *Repository) Ingest(userID uuid.UUID, source string, batches []*receiptBatch, recipes map[uuid.UUID][]menu.RecipeLine) (ingested int, duplicates []string, shortfalls []*IngredientShortfall, err error) {
+	if r.db == nil {
+		return 0, nil, nil, errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, nil, nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	duplicates = []string{}
+	needs := map[string]*IngredientShortfall{}
+	now := time.Now()
+	for _, batch := range batches {
+		total := 0.0
+		for _, line := range batch.lines {
+			total += line.Quantity * line.UnitPrice
+		}
+		var receiptID uuid.UUID
+		err := tx.QueryRow(`
+			INSERT INTO sales_receipts (id, user_id, external_receipt_id, sold_at, total, line_count, source, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (user_id, external_receipt_id) DO NOTHING
+			RETURNING id`, uuid.New(), userID, batch.externalID, batch.soldAt, total, len(batch.lines), source, now).Scan(&receiptID)
+		if err == sql.ErrNoRows {
+			duplicates = append(duplicates, batch.externalID)
+			continue
+		}
+		if err != nil {
+			return 0, nil, nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		ingested++
+
+		for _, line := range batch.lines {
+			var menuItemID, skuID *uuid.UUID
+			switch line.itemType {
+			case ItemMenuItem:
+				menuItemID = line.itemID
+			case ItemSKU:
+				skuID = line.itemID
+			}
+			if _, err := tx.Exec(`INSERT INTO sales_lines (id, receipt_id, user_id, menu_item_id, sku_id, item_name, quantity, unit_price, sold_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+				uuid.New(), receiptID, userID, menuItemID, skuID, line.name, line.Quantity, line.UnitPrice, line.SoldAt, now); err != nil {
+				return 0, nil, nil, errors.WrapError(err, errors.ErrDatabase)
+			}
+			if line.itemID == nil {
+				continue
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO sales_daily (user_id, item_type, item_id, day, quantity, revenue, updated_at)
+				VALUES ($1, $2, $3, $4::date, $5, $6, $7)
+				ON CONFLICT (user_id, item_type, item_id, day) DO UPDATE SET
+					quantity = sales_daily.quantity + EXCLUDED.quantity,
+					revenue = sales_daily.revenue + EXCLUDED.revenue,
+					updated_at = EXCLUDED.updated_at`,
+				userID, line.itemType, *line.itemID, line.SoldAt.UTC().Format("2006-01-02"), line.Quantity, line.Quantity*line.UnitPrice, now); err != nil {
+				return 0, nil, nil, errors.WrapError(err, errors.ErrDatabase)
+			}
+			switch line.itemType {
+			case ItemSKU:
+				if _, err := tx.Exec(`UPDATE shop_inventory_items SET stock_quantity = GREATEST(stock_quantity - $1, 0), updated_at = $2 WHERE id = $3`, line.Quantity, now, *line.itemID); err != nil {
+					return 0, nil, nil, errors.WrapError(err, errors.ErrDatabase)
+				}
+			case ItemMenuItem:
+				for _, ingredient := range recipes[*line.itemID] {
+					key := strings.ToLower(ingredient.Name) + "|" + strings.ToLower(ingredient.Unit)
+					need, ok := needs[key]
+					if !ok {
+						need = &IngredientShortfall{Ingredient: ingredient.Name, Unit: ingredient.Unit}
+						needs[key] = need
+					}
+					need.Missing += ingredient.Quantity * line.Quantity
+				}
+			}
+		}
+	}
+
+	// Deplete in a fixed order so concurrent batches lock rows alike
+	keys := make([]string, 0, len(needs))
+	for key := range needs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	shortfalls = []*IngredientShortfall{}
+	for _, key := range keys {
+		need := needs[key]
+		missing, err := depleteIngredient(tx, userID, need.Ingredient, need.Missing, need.Unit, now)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		if missing > 0 {
+			need.Missing = math.Round(missing*100) / 100
+			shortfalls = append(shortfalls, need)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return ingested, duplicates, shortfalls, nil
+}
+
+// depleteIngredient takes quantity of an ingredient, in unit, from the
+// restaurant's batches of that name, earliest expiry first, and returns how
+// much could not be taken. Batches in units that cannot be converted are left
+// alone.
+func depleteIngredient(tx *sql.Tx, userID uuid.UUID, name string, quantity float64, unit string, now time.Time) (float64, error) {
+	rows, err := tx.Query(`
+		SELECT id, quantity, unit FROM restaurant_inventory_items
+		WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND quantity > 0
+		ORDER BY expiry_date ASC
+		FOR UPDATE`, userID, name)
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	type batch struct {
+		id       uuid.UUID
+		quantity float64
+		unit     string
+	}
+	var batches []batch
+	for rows.Next() {
+		var b batch
+		if err := rows.Scan(&b.id, &b.quantity, &b.unit); err != nil {
+			rows.Close()
+			return 0, errors.WrapError(err, errors.ErrDatabase)
+		}
+		batches = append(batches, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	remaining := quantity
+	for _, b := range batches {
+		if remaining <= 0 {
+			break
+		}
+		// An ingredient without a unit is counted in the batch's own unit
+		recipeUnit := unit
+		if recipeUnit == "" {
+			recipeUnit = b.unit
+		}
+		available, ok := impact.ConvertUnit(b.quantity, b.unit, recipeUnit)
+		if !ok {
+			continue
+		}
+		taken := math.Min(available, remaining)
+		takenInBatchUnit, _ := impact.ConvertUnit(taken, recipeUnit, b.unit)
+		if _, err := tx.Exec(`UPDATE restaurant_inventory_items SET quantity = GREATEST(quantity - $1, 0), updated_at = $2 WHERE id = $3`, takenInBatchUnit, now, b.id); err != nil {
+			return 0, errors.WrapError(err, errors.ErrDatabase)
+		}
+		remaining -= taken
+	}
+	return math.Max(remaining, 0), nil
+}
+
+// ListReceipts returns the seller's most recent receipts
+func (r *Repository) ListReceipts(userID uuid.UUID, limit int) ([]*Receipt, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `SELECT id, user_id, external_receipt_id, sold_at, total, line_count, source, created_at FROM sales_receipts WHERE user_id = $1 ORDER BY sold_at DESC LIMIT $2`
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	receipts := []*Receipt{}
+	for rows.Next() {
+		receipt := &Receipt{}
+		if err := rows.Scan(&receipt.ID, &receipt.UserID, &receipt.ExternalReceiptID, &receipt.SoldAt, &receipt.Total, &receipt.LineCount, &receipt.Source, &receipt.CreatedAt); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		receipts = append(receipts, receipt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return receipts, nil
+}
+
+// GetDailySeries returns the seller's daily sales in a window, by day then item
+func (r *Repository) GetDailySeries(userID uuid.UUID, filter SeriesFilter) ([]*DailySales, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT item_type, item_id, day, quantity, revenue FROM sales_daily
+		WHERE user_id = $1 AND ($2::uuid IS NULL OR item_id = $2) AND day >= $3::date AND day < $4::date
+		ORDER BY day ASC, item_id ASC`
+	rows, err := r.db.Query(query, userID, filter.ItemID, filter.From.UTC().Format("2006-01-02"), filter.To.UTC().Format("2006-01-02"))
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	series := []*DailySales{}
+	for rows.Next() {
+		d := &DailySales{}
+		if err := rows.Scan(&d.ItemType, &d.ItemID, &d.Day, &d.Quantity, &d.Revenue); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		series = append(series, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return series, nil
+}
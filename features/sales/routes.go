@@ -0,0 +1,26 @@
+package sales
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+
+		switch {
+		case path == "" && r.Method == http.MethodPost:
+			handler.Ingest(w, r)
+		case path == "receipts" && r.Method == http.MethodGet:
+			handler.ListReceipts(w, r)
+		case path == "series" && r.Method == http.MethodGet:
+			handler.GetDailySeries(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
@@ -0,0 +1,170 @@
+package sales
+
+import (
+	"fmt"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/features/restaurant/menu"
+	"foodlink_backend/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errNotSeller     = errors.NewAppError(http.StatusForbidden, "Sales can only be recorded by restaurant and shop accounts")
+	errTooManyLines  = errors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Validation failed: a batch can carry at most %d lines", maxBatchLines))
+	errInvalidWindow = errors.NewAppError(http.StatusBadRequest, "Validation failed: to must be after from")
+	errWindowTooLong = errors.NewAppError(http.StatusBadRequest, "Validation failed: a series covers at most 366 days")
+)
+
+const (
+	// defaultSeriesDays is how many days back the series goes when no window is given
+	defaultSeriesDays = 28
+	maxSeriesDays     = 366
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository()}
+}
+
+// Ingest records a batch of receipt lines for a restaurant or shop. Lines are
+// grouped into receipts by receipt_id; a receipt sent before is skipped. See
+// Repository.Ingest for how stock is depleted.
+func (s *Service) Ingest(userID uuid.UUID, role, source string, req *IngestSalesRequest) (*IngestResult, error) {
+	if len(req.Lines) > maxBatchLines {
+		return nil, errTooManyLines
+	}
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+
+	var catalog []*catalogItem
+	var err error
+	switch role {
+	case auth.RoleRestaurant:
+		catalog, err = s.repo.MenuCatalog(userID)
+	case auth.RoleShop:
+		catalog, err = s.repo.SKUCatalog(userID)
+	default:
+		return nil, errNotSeller
+	}
+	if err != nil {
+		return nil, err
+	}
+	byID := map[uuid.UUID]*catalogItem{}
+	byKey := map[string]*catalogItem{}
+	recipes := map[uuid.UUID][]menu.RecipeLine{}
+	for _, item := range catalog {
+		byID[item.id] = item
+		if role == auth.RoleShop {
+			byKey[item.barcode] = item
+		} else {
+			byKey[strings.ToLower(item.name)] = item
+			recipes[item.id] = item.recipe
+		}
+	}
+
+	var batches []*receiptBatch
+	byReceipt := map[string]*receiptBatch{}
+	unmatched := map[string][]*UnmatchedLine{}
+	for i, line := range req.Lines {
+		resolved, problem := resolve(line, role, byID, byKey)
+		if problem != nil {
+			problem.Line = i + 1
+			unmatched[line.ReceiptID] = append(unmatched[line.ReceiptID], problem)
+		}
+		batch, ok := byReceipt[line.ReceiptID]
+		if !ok {
+			batch = &receiptBatch{externalID: line.ReceiptID, soldAt: line.SoldAt}
+			byReceipt[line.ReceiptID] = batch
+			batches = append(batches, batch)
+		}
+		if line.SoldAt.Before(batch.soldAt) {
+			batch.soldAt = line.SoldAt
+		}
+		batch.lines = append(batch.lines, resolved)
+	}
+
+	ingested, duplicates, shortfalls, err := s.repo.Ingest(userID, source, batches, recipes)
+	if err != nil {
+		return nil, err
+	}
+	result := &IngestResult{Receipts: ingested, DuplicateReceipts: duplicates, Unmatched: []*UnmatchedLine{}, Shortfalls: shortfalls}
+	skipped := map[string]bool{}
+	for _, id := range duplicates {
+		skipped[id] = true
+	}
+	for _, batch := range batches {
+		if skipped[batch.externalID] {
+			continue
+		}
+		result.Lines += len(batch.lines)
+		result.Unmatched = append(result.Unmatched, unmatched[batch.externalID]...)
+	}
+	return result, nil
+}
+
+// resolve matches a line to the seller's menu item or SKU, by ID first, then
+// by menu item name or barcode. A line that matches nothing comes back with
+// the reason, and no item.
+func resolve(line *SaleLine, role string, byID map[uuid.UUID]*catalogItem, byKey map[string]*catalogItem) (*resolvedLine, *UnmatchedLine) {
+	resolved := &resolvedLine{SaleLine: line, itemType: ItemSKU}
+	id, name, label := line.SKUID, strings.TrimSpace(line.Barcode), "sku_id or barcode"
+	key := name
+	if role == auth.RoleRestaurant {
+		resolved.itemType = ItemMenuItem
+		id, name, label = line.MenuItemID, strings.TrimSpace(line.MenuItem), "menu_item_id or menu_item"
+		key = strings.ToLower(name)
+	}
+
+	var item *catalogItem
+	switch {
+	case id != nil:
+		item = byID[*id]
+		resolved.name = id.String()
+	case key != "":
+		item = byKey[key]
+		resolved.name = name
+	default:
+		resolved.name = "unknown"
+		return resolved, &UnmatchedLine{Item: resolved.name, Reason: "Line has no " + label}
+	}
+	if item == nil {
+		return resolved, &UnmatchedLine{Item: resolved.name, Reason: "No item matches " + label}
+	}
+	resolved.itemID = &item.id
+	resolved.name = item.name
+	return resolved, nil
+}
+
+func (s *Service) ListReceipts(userID uuid.UUID, limit int) ([]*Receipt, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.repo.ListReceipts(userID, limit)
+}
+
+// GetDailySeries returns daily units sold and revenue per item, over the last
+// four weeks when the filter has no window
+func (s *Service) GetDailySeries(userID uuid.UUID, filter SeriesFilter) ([]*DailySales, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -defaultSeriesDays)
+	}
+	if !filter.To.After(filter.From) {
+		return nil, errInvalidWindow
+	}
+	if filter.To.Sub(filter.From) > maxSeriesDays*24*time.Hour {
+		return nil, errWindowTooLong
+	}
+	return s.repo.GetDailySeries(userID, filter)
+}
@@ -0,0 +1,104 @@
+package analytics
+
+import (
+	"foodlink_backend/features/impact"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const dayLabel = "2006-01-02"
+
+// uncategorised labels write-offs of surplus not linked to an inventory item
+const uncategorised = "other"
+
+// aggregate turns a period's stock movements into analytics. Stock sold at a
+// markdown or donated counts as rescued, written-off stock as waste; the waste
+// reduction is the rescued share of both, by weight. Quantities in units that
+// cannot be weighed are left out of the kg figures.
+func (s *Service) aggregate(userID uuid.UUID, from, to time.Time, movements []*movement) *ShopAnalytics {
+	days := dayLabels(from, to)
+	rescuedKg := make(map[string]float64, len(days))
+	wastedKg := make(map[string]float64, len(days))
+	recovered := make(map[string]float64, len(days))
+	expired := make(map[string]float64, len(days))
+	byOutcome := map[string]float64{}
+	byCategory := map[string]float64{}
+
+	a := &ShopAnalytics{UserID: userID, PeriodStart: from, PeriodEnd: to}
+	for _, m := range movements {
+		day := m.OccurredAt.UTC().Format(dayLabel)
+		kg, _ := s.impact.ToKg(m.Category, m.Quantity, m.Unit)
+		byOutcome[m.Outcome] += kg
+		switch m.Outcome {
+		case outcomeSold:
+			rescuedKg[day] += kg
+			recovered[day] += m.Revenue
+			a.MarkdownRevenueRecovered += m.Revenue
+			a.TotalCO2Prevented += s.impact.Measure(m.Category, m.Quantity, m.Unit).CO2Kg
+		case outcomeDonated:
+			rescuedKg[day] += kg
+			measure := s.impact.Measure(m.Category, m.Quantity, m.Unit)
+			a.TotalCO2Prevented += measure.CO2Kg
+			a.MealsDonated += measure.Meals
+		case outcomeExpired:
+			wastedKg[day] += kg
+			expired[day]++
+			category := m.Category
+			if category == "" {
+				category = uncategorised
+			}
+			byCategory[category] += kg
+			a.WrittenOffValue += m.Value
+		}
+	}
+
+	for _, day := range days {
+		a.WasteReductionTrend = append(a.WasteReductionTrend, impact.TrendPoint{Label: day, Value: percent(rescuedKg[day], wastedKg[day])})
+		a.MarkdownRecoveryTrend = append(a.MarkdownRecoveryTrend, impact.TrendPoint{Label: day, Value: round2(recovered[day])})
+		a.ExpiredPerDay = append(a.ExpiredPerDay, impact.TrendPoint{Label: day, Value: expired[day]})
+	}
+	a.WasteByCategory = []CategoryValue{}
+	for category, kg := range byCategory {
+		a.WasteByCategory = append(a.WasteByCategory, CategoryValue{Category: category, Value: round2(kg)})
+	}
+	sort.Slice(a.WasteByCategory, func(i, j int) bool {
+		if a.WasteByCategory[i].Value != a.WasteByCategory[j].Value {
+			return a.WasteByCategory[i].Value > a.WasteByCategory[j].Value
+		}
+		return a.WasteByCategory[i].Category < a.WasteByCategory[j].Category
+	})
+	a.SurplusVsSold = []impact.TrendPoint{
+		{Label: outcomeSold, Value: round2(byOutcome[outcomeSold])},
+		{Label: outcomeDonated, Value: round2(byOutcome[outcomeDonated])},
+		{Label: outcomeExpired, Value: round2(byOutcome[outcomeExpired])},
+	}
+	a.WasteReductionPercent = percent(byOutcome[outcomeSold]+byOutcome[outcomeDonated], byOutcome[outcomeExpired])
+	a.TotalCO2Prevented = round2(a.TotalCO2Prevented)
+	a.MarkdownRevenueRecovered = round2(a.MarkdownRevenueRecovered)
+	a.WrittenOffValue = round2(a.WrittenOffValue)
+	return a
+}
+
+// dayLabels lists the UTC days from from up to, but not including, to
+func dayLabels(from, to time.Time) []string {
+	labels := []string{}
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.AddDate(0, 0, 1) {
+		labels = append(labels, day.Format(dayLabel))
+	}
+	return labels
+}
+
+// percent is rescued's share of rescued and wasted, or zero when there is neither
+func percent(rescued, wasted float64) float64 {
+	if rescued+wasted <= 0 {
+		return 0
+	}
+	return round2(rescued / (rescued + wasted) * 100)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
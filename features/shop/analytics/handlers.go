@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// Get handles GET /api/v1/shop/analytics
+// @Summary      Get shop analytics
+// @Description  Get the authenticated shop's waste and markdown analytics for a period, the last 30 days by default: revenue recovered by markdowns, stock written off, surplus donated and the resulting waste reduction, CO2 prevented and meals donated. Snapshots are cached for up to an hour; set refresh to recompute.
+// @Tags         shop-analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from     query     string  false  "Period start (RFC 3339)"
+// @Param        to       query     string  false  "Period end (RFC 3339)"
+// @Param        refresh  query     bool    false  "Recompute instead of serving the cached snapshot"
+// @Success      200      {object}  ShopAnalytics
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Router       /shop/analytics [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	query := r.URL.Query()
+	var period *Period
+	if fromStr, toStr := query.Get("from"), query.Get("to"); fromStr != "" || toStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid from; expected an RFC 3339 time", nil)
+			return
+		}
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid to; expected an RFC 3339 time", nil)
+			return
+		}
+		period = &Period{From: from, To: to}
+	}
+	refresh := false
+	if value := query.Get("refresh"); value != "" {
+		if refresh, err = strconv.ParseBool(value); err != nil {
+			utils.BadRequestResponse(w, "Invalid refresh; expected true or false", nil)
+			return
+		}
+	}
+	analytics, err := h.service.Get(userID, period, refresh)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve shop analytics", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Shop analytics retrieved successfully", analytics)
+}
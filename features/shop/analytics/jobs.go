@@ -0,0 +1,16 @@
+package analytics
+
+import (
+	"foodlink_backend/jobs"
+	"time"
+)
+
+// RegisterJobs schedules a periodic recompute of every shop's analytics, so
+// the default period's snapshot is usually fresh when asked for
+func (s *Service) RegisterJobs(scheduler *jobs.Scheduler, interval time.Duration) {
+	scheduler.Register(jobs.Job{
+		Name:     "shop.analytics",
+		Interval: interval,
+		Run:      s.RecomputeAll,
+	})
+}
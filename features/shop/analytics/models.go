@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"foodlink_backend/features/impact"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShopAnalytics is a shop's waste and markdown analytics over a date range.
+// Trends have one point per day, labelled YYYY-MM-DD in UTC. Weights are in
+// kg; revenue and write-offs are in the shop's currency.
+type ShopAnalytics struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	PeriodStart time.Time `json:"period_start" db:"period_start"`
+	PeriodEnd   time.Time `json:"period_end" db:"period_end"`
+	// Share of at-risk stock sold at a markdown or donated rather than
+	// written off, per day
+	WasteReductionTrend []impact.TrendPoint `json:"waste_reduction_trend" db:"waste_reduction_trend"`
+	// Revenue from stock sold at a markdown price, per day
+	MarkdownRecoveryTrend []impact.TrendPoint `json:"markdown_recovery_trend" db:"markdown_recovery_trend"`
+	// Kg written off per category, largest first
+	WasteByCategory []CategoryValue `json:"waste_by_category" db:"waste_by_category"`
+	// Kg sold at a markdown, donated and written off
+	SurplusVsSold []impact.TrendPoint `json:"surplus_vs_sold" db:"surplus_vs_sold"`
+	// Number of items written off, per day
+	ExpiredPerDay            []impact.TrendPoint `json:"expired_per_day" db:"expired_per_day"`
+	TotalCO2Prevented        float64             `json:"total_co2_prevented" db:"total_co2_prevented"`
+	MealsDonated             int                 `json:"meals_donated" db:"meals_donated"`
+	WasteReductionPercent    float64             `json:"waste_reduction_percent" db:"waste_reduction_percent"`
+	MarkdownRevenueRecovered float64             `json:"markdown_revenue_recovered" db:"markdown_revenue_recovered"`
+	WrittenOffValue          float64             `json:"written_off_value" db:"written_off_value"`
+	UpdatedAt                time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// CategoryValue is one category's share of a total
+type CategoryValue struct {
+	Category string  `json:"category"`
+	Value    float64 `json:"value"`
+}
+
+// Outcomes of at-risk stock
+const (
+	outcomeSold    = "sold"
+	outcomeDonated = "donated"
+	outcomeExpired = "expired"
+)
+
+// movement is stock that left the shop one way or another: sold after a
+// markdown, donated as surplus, or written off when it expired. Value is the
+// stock at cost; Revenue is what a markdown sale brought in.
+type movement struct {
+	Outcome    string
+	Category   string
+	Quantity   float64
+	Unit       string
+	Value      float64
+	Revenue    float64
+	OccurredAt time.Time
+}
+
+// Period is the date range analytics cover, from From up to To
+type Period struct {
+	From time.Time
+	To   time.Time
+}
@@ -0,0 +1,187 @@
+package analytics
+
+import (
+	"database/sql"
+	"encoding/json"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+func (r *Repository) GetByUserID(userID uuid.UUID) (*ShopAnalytics, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	a := &ShopAnalytics{}
+	var periodStart, periodEnd sql.NullTime
+	var wasteReductionJSON, markdownRecoveryJSON, wasteByCategoryJSON, surplusVsSoldJSON, expiredPerDayJSON []byte
+	query := `SELECT id, user_id, period_start, period_end, waste_reduction_trend, markdown_recovery_trend, waste_by_category, surplus_vs_sold, expired_per_day, COALESCE(total_co2_prevented, 0), COALESCE(meals_donated, 0), COALESCE(waste_reduction_percent, 0), COALESCE(markdown_revenue_recovered, 0), COALESCE(written_off_value, 0), updated_at FROM shop_analytics_records WHERE user_id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&a.ID, &a.UserID, &periodStart, &periodEnd, &wasteReductionJSON, &markdownRecoveryJSON, &wasteByCategoryJSON, &surplusVsSoldJSON, &expiredPerDayJSON, &a.TotalCO2Prevented, &a.MealsDonated, &a.WasteReductionPercent, &a.MarkdownRevenueRecovered, &a.WrittenOffValue, &a.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	a.PeriodStart, a.PeriodEnd = periodStart.Time, periodEnd.Time
+	if len(wasteReductionJSON) > 0 {
+		json.Unmarshal(wasteReductionJSON, &a.WasteReductionTrend)
+	}
+	if len(markdownRecoveryJSON) > 0 {
+		json.Unmarshal(markdownRecoveryJSON, &a.MarkdownRecoveryTrend)
+	}
+	if len(wasteByCategoryJSON) > 0 {
+		json.Unmarshal(wasteByCategoryJSON, &a.WasteByCategory)
+	}
+	if len(surplusVsSoldJSON) > 0 {
+		json.Unmarshal(surplusVsSoldJSON, &a.SurplusVsSold)
+	}
+	if len(expiredPerDayJSON) > 0 {
+		json.Unmarshal(expiredPerDayJSON, &a.ExpiredPerDay)
+	}
+	return a, nil
+}
+
+// Save creates or replaces the shop's analytics snapshot
+func (r *Repository) Save(a *ShopAnalytics) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	wasteReductionJSON, _ := json.Marshal(a.WasteReductionTrend)
+	markdownRecoveryJSON, _ := json.Marshal(a.MarkdownRecoveryTrend)
+	wasteByCategoryJSON, _ := json.Marshal(a.WasteByCategory)
+	surplusVsSoldJSON, _ := json.Marshal(a.SurplusVsSold)
+	expiredPerDayJSON, _ := json.Marshal(a.ExpiredPerDay)
+	query := `
+		INSERT INTO shop_analytics_records (id, user_id, period_start, period_end, waste_reduction_trend, markdown_recovery_trend, waste_by_category, surplus_vs_sold, expired_per_day, total_co2_prevented, meals_donated, waste_reduction_percent, markdown_revenue_recovered, written_off_value, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET
+			period_start = EXCLUDED.period_start,
+			period_end = EXCLUDED.period_end,
+			waste_reduction_trend = EXCLUDED.waste_reduction_trend,
+			markdown_recovery_trend = EXCLUDED.markdown_recovery_trend,
+			waste_by_category = EXCLUDED.waste_by_category,
+			surplus_vs_sold = EXCLUDED.surplus_vs_sold,
+			expired_per_day = EXCLUDED.expired_per_day,
+			total_co2_prevented = EXCLUDED.total_co2_prevented,
+			meals_donated = EXCLUDED.meals_donated,
+			waste_reduction_percent = EXCLUDED.waste_reduction_percent,
+			markdown_revenue_recovered = EXCLUDED.markdown_revenue_recovered,
+			written_off_value = EXCLUDED.written_off_value,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id, updated_at
+	`
+	err := r.db.QueryRow(query, uuid.New(), a.UserID, a.PeriodStart, a.PeriodEnd, wasteReductionJSON, markdownRecoveryJSON, wasteByCategoryJSON, surplusVsSoldJSON, expiredPerDayJSON, a.TotalCO2Prevented, a.MealsDonated, a.WasteReductionPercent, a.MarkdownRevenueRecovered, a.WrittenOffValue).Scan(&a.ID, &a.UpdatedAt)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// ListMovements returns how the shop's at-risk stock left it between from and
+// to: units sold after each applied markdown, until the next markdown or now;
+// surplus picked up; and expired stock, both shelf stock never turned into
+// surplus and surplus that was not collected. Nothing counts as expired before
+// now.
+//
+// Markdown sales come from the point-of-sale lines recorded for the SKU. A SKU
+// that has never been through the till falls back to an estimate from the
+// stock on hand when each markdown was applied and when the next one came,
+// less any stock converted to surplus in between.
+func (r *Repository) ListMovements(userID uuid.UUID, from, to, now time.Time) ([]*movement, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		WITH applied AS (
+			SELECT sku_id, applied_at, new_price, stock_at_apply,
+				LEAD(stock_at_apply) OVER w AS next_stock,
+				COALESCE(LEAD(applied_at) OVER w, $4) AS ends_at
+			FROM shop_price_map_entries
+			WHERE user_id = $1 AND status = 'applied' AND stock_at_apply IS NOT NULL
+			WINDOW w AS (PARTITION BY sku_id ORDER BY applied_at)
+		)
+		SELECT 'sold', i.category, i.unit,
+			CASE WHEN tracked.at_till THEN pos.quantity ELSE est.quantity END,
+			i.cost,
+			CASE WHEN tracked.at_till THEN pos.revenue ELSE est.quantity * a.new_price END,
+			a.applied_at
+		FROM applied a
+		JOIN shop_inventory_items i ON i.id = a.sku_id
+		CROSS JOIN LATERAL (
+			SELECT EXISTS (SELECT 1 FROM sales_lines l WHERE l.sku_id = a.sku_id AND l.user_id = $1) AS at_till
+		) tracked
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(SUM(l.quantity), 0) AS quantity, COALESCE(SUM(l.quantity * l.unit_price), 0) AS revenue
+			FROM sales_lines l
+			WHERE l.sku_id = a.sku_id AND l.user_id = $1 AND l.sold_at >= a.applied_at AND l.sold_at < a.ends_at
+		) pos
+		CROSS JOIN LATERAL (
+			SELECT GREATEST(a.stock_at_apply - COALESCE(a.next_stock, i.stock_quantity) - COALESCE(SUM(s.quantity), 0), 0) AS quantity
+			FROM shop_surplus_items s
+			WHERE s.sku_id = a.sku_id AND s.created_at >= a.applied_at AND s.created_at < a.ends_at
+		) est
+		WHERE a.applied_at >= $2 AND a.applied_at < $3
+		UNION ALL
+		SELECT 'expired', i.category, i.unit, i.stock_quantity, i.cost, 0, i.expiry_date
+		FROM shop_inventory_items i
+		WHERE i.user_id = $1 AND i.stock_quantity > 0 AND i.expiry_date >= $2 AND i.expiry_date < LEAST($3, $4)
+			AND NOT EXISTS (SELECT 1 FROM shop_surplus_items s WHERE s.sku_id = i.id)
+		UNION ALL
+		SELECT CASE WHEN s.status = 'picked' THEN 'donated' ELSE 'expired' END, COALESCE(i.category, ''), s.unit, s.quantity, COALESCE(i.cost, 0), 0, COALESCE(s.picked_at, s.expiry_window_end)
+		FROM shop_surplus_items s
+		LEFT JOIN shop_inventory_items i ON i.id = s.sku_id
+		WHERE s.user_id = $1 AND (
+			(s.status = 'picked' AND s.picked_at >= $2 AND s.picked_at < $3)
+			OR (s.status = 'expired' AND s.expiry_window_end >= $2 AND s.expiry_window_end < LEAST($3, $4)))`
+	rows, err := r.db.Query(query, userID, from, to, now)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	movements := []*movement{}
+	for rows.Next() {
+		m := &movement{}
+		var cost float64
+		if err := rows.Scan(&m.Outcome, &m.Category, &m.Unit, &m.Quantity, &cost, &m.Revenue, &m.OccurredAt); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		m.Value = m.Quantity * cost
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return movements, nil
+}
+
+// ListShopIDs returns the IDs of every shop user
+func (r *Repository) ListShopIDs() ([]uuid.UUID, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT id FROM users WHERE role = 'shop' ORDER BY id`)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
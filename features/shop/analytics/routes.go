@@ -0,0 +1,22 @@
+package analytics
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+
+		switch {
+		case path == "" && r.Method == http.MethodGet:
+			handler.Get(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
@@ -0,0 +1,101 @@
+package analytics
+
+import (
+	"context"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/impact"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errInvalidPeriod = errors.NewAppError(http.StatusBadRequest, "Validation failed: to must be after from")
+	errPeriodTooLong = errors.NewAppError(http.StatusBadRequest, "Validation failed: analytics cover at most 366 days")
+)
+
+const (
+	// defaultPeriodDays is how many days, up to and including today, analytics
+	// cover when no period is given
+	defaultPeriodDays = 30
+	maxPeriodDays     = 366
+	// cacheTTL is how long a stored snapshot is served before it is recomputed
+	cacheTTL = time.Hour
+)
+
+type Service struct {
+	repo   *Repository
+	impact *impact.Service
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository(), impact: impact.NewService()}
+}
+
+// Get returns the shop's analytics for a period, the last 30 days by default.
+// The stored snapshot is served while it covers the same period and is under
+// an hour old; otherwise, or when refresh is set, it is recomputed.
+func (s *Service) Get(userID uuid.UUID, period *Period, refresh bool) (*ShopAnalytics, error) {
+	if period == nil {
+		p := defaultPeriod(time.Now())
+		period = &p
+	}
+	if !period.To.After(period.From) {
+		return nil, errInvalidPeriod
+	}
+	if period.To.Sub(period.From) > maxPeriodDays*24*time.Hour {
+		return nil, errPeriodTooLong
+	}
+	if !refresh {
+		cached, err := s.repo.GetByUserID(userID)
+		if err != nil && err != errors.ErrNotFound {
+			return nil, err
+		}
+		if err == nil && cached.PeriodStart.Equal(period.From) && cached.PeriodEnd.Equal(period.To) && time.Since(cached.UpdatedAt) < cacheTTL {
+			return cached, nil
+		}
+	}
+	return s.Recompute(userID, *period)
+}
+
+// Recompute derives the shop's analytics for a period from its markdowns,
+// surplus and expired stock, and stores them as its snapshot
+func (s *Service) Recompute(userID uuid.UUID, period Period) (*ShopAnalytics, error) {
+	movements, err := s.repo.ListMovements(userID, period.From, period.To, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	analytics := s.aggregate(userID, period.From, period.To, movements)
+	if err := s.repo.Save(analytics); err != nil {
+		return nil, err
+	}
+	return analytics, nil
+}
+
+// RecomputeAll refreshes every shop's snapshot for the default period,
+// continuing past failures
+func (s *Service) RecomputeAll(ctx context.Context) error {
+	userIDs, err := s.repo.ListShopIDs()
+	if err != nil {
+		return err
+	}
+	period := defaultPeriod(time.Now())
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := s.Recompute(userID, period); err != nil {
+			log.Printf("Warning: failed to recompute analytics for shop %s: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+// defaultPeriod covers the last 30 UTC days up to and including today, so
+// that it stays the same, and its snapshot reusable, all day
+func defaultPeriod(now time.Time) Period {
+	to := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	return Period{From: to.AddDate(0, 0, -defaultPeriodDays), To: to}
+}
@@ -0,0 +1,322 @@
+package inventory
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// GetAll handles GET /api/v1/shop/inventory
+// @Summary      List shop inventory
+// @Description  Get the authenticated shop's inventory, soonest expiry first
+// @Tags         shop-inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        category         query     string  false  "Filter by category"
+// @Param        storage_type     query     string  false  "Filter by storage type (frozen, chilled, ambient)"
+// @Param        markdown_status  query     string  false  "Filter by markdown status (none, scheduled, active)"
+// @Success      200              {array}   ShopInventoryItem
+// @Failure      401              {object}  errors.AppError
+// @Router       /shop/inventory [get]
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	query := r.URL.Query()
+	items, err := h.service.GetAllByUserID(userID, query.Get("category"), query.Get("storage_type"), query.Get("markdown_status"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve inventory", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Inventory retrieved successfully", items)
+}
+
+// GetByID handles GET /api/v1/shop/inventory/:id
+// @Summary      Get shop inventory item
+// @Description  Get one of the authenticated shop's inventory items
+// @Tags         shop-inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Inventory Item ID"
+// @Success      200  {object}  ShopInventoryItem
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/inventory/{id} [get]
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	item, err := h.service.GetByID(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve inventory item", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Inventory item retrieved successfully", item)
+}
+
+// GetByBarcode handles GET /api/v1/shop/inventory/barcode/:code
+// @Summary      Look up item by barcode
+// @Description  Find the authenticated shop's item with a scanned barcode
+// @Tags         shop-inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        code  path      string  true  "Barcode"
+// @Success      200   {object}  ShopInventoryItem
+// @Failure      401   {object}  errors.AppError
+// @Failure      404   {object}  errors.AppError
+// @Router       /shop/inventory/barcode/{code} [get]
+func (h *Handler) GetByBarcode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	code := strings.TrimPrefix(strings.Trim(r.URL.Path, "/"), "barcode/")
+	item, err := h.service.GetByBarcode(userID, code)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve inventory item", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Inventory item retrieved successfully", item)
+}
+
+// GetExpiring handles GET /api/v1/shop/inventory/expiring
+// @Summary      Get expiring items
+// @Description  Get in-stock items expiring between from and to, or over the next few days when no window is given
+// @Tags         shop-inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from  query     string  false  "Window start (RFC 3339)"
+// @Param        to    query     string  false  "Window end (RFC 3339)"
+// @Param        days  query     int     false  "Number of days ahead when no window is given (default: 3)"
+// @Success      200   {array}   ShopInventoryItem
+// @Failure      400   {object}  errors.AppError
+// @Failure      401   {object}  errors.AppError
+// @Router       /shop/inventory/expiring [get]
+func (h *Handler) GetExpiring(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	query := r.URL.Query()
+	var window *ExpiryWindow
+	if fromStr, toStr := query.Get("from"), query.Get("to"); fromStr != "" || toStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid from; expected an RFC 3339 time", nil)
+			return
+		}
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid to; expected an RFC 3339 time", nil)
+			return
+		}
+		window = &ExpiryWindow{From: from, To: to}
+	}
+	days, _ := strconv.Atoi(query.Get("days"))
+	items, err := h.service.GetExpiring(userID, window, days)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve expiring items", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Expiring items retrieved successfully", items)
+}
+
+// Create handles POST /api/v1/shop/inventory
+// @Summary      Add shop inventory item
+// @Description  Add an item to the authenticated shop's inventory. Barcodes are unique within a shop.
+// @Tags         shop-inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreateShopInventoryItemRequest  true  "Inventory item data"
+// @Success      201      {object}  ShopInventoryItem
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /shop/inventory [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var req CreateShopInventoryItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	item, err := h.service.Create(userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to create inventory item", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Inventory item created successfully", item)
+}
+
+// Update handles PUT /api/v1/shop/inventory/:id
+// @Summary      Update shop inventory item
+// @Description  Update one of the authenticated shop's inventory items
+// @Tags         shop-inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                          true  "Inventory Item ID"
+// @Param        request  body      UpdateShopInventoryItemRequest  true  "Inventory item data"
+// @Success      200      {object}  ShopInventoryItem
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /shop/inventory/{id} [put]
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	var req UpdateShopInventoryItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	item, err := h.service.Update(id, userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update inventory item", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Inventory item updated successfully", item)
+}
+
+// Delete handles DELETE /api/v1/shop/inventory/:id
+// @Summary      Delete shop inventory item
+// @Description  Delete one of the authenticated shop's inventory items
+// @Tags         shop-inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Inventory Item ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/inventory/{id} [delete]
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.Delete(id, userID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to delete inventory item", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Inventory item deleted successfully", map[string]string{"message": "Deleted"})
+}
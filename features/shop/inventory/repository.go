@@ -0,0 +1,160 @@
+package inventory
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+const itemColumns = `id, user_id, name, category, barcode, stock_quantity, unit, price, cost, expiry_date, storage_type, COALESCE(shelf_location, ''), COALESCE(image_data, ''), COALESCE(markdown_status, 'none'), COALESCE(surplus_eligible, FALSE), created_at, updated_at`
+
+// GetAllByUserID lists a shop's items, soonest expiry first, optionally
+// filtered by category, storage type and markdown status
+func (r *Repository) GetAllByUserID(userID uuid.UUID, category, storageType, markdownStatus string) ([]*ShopInventoryItem, error) {
+	query := `
+		SELECT ` + itemColumns + ` FROM shop_inventory_items
+		WHERE user_id = $1 AND ($2 = '' OR category = $2) AND ($3 = '' OR storage_type = $3) AND ($4 = '' OR markdown_status = $4)
+		ORDER BY expiry_date ASC, name ASC`
+	return r.queryItems(query, userID, category, storageType, markdownStatus)
+}
+
+// GetExpiring lists a shop's in-stock items expiring within a window, soonest
+// first
+func (r *Repository) GetExpiring(userID uuid.UUID, window ExpiryWindow) ([]*ShopInventoryItem, error) {
+	query := `
+		SELECT ` + itemColumns + ` FROM shop_inventory_items
+		WHERE user_id = $1 AND expiry_date >= $2 AND expiry_date < $3 AND stock_quantity > 0
+		ORDER BY expiry_date ASC, name ASC`
+	return r.queryItems(query, userID, window.From, window.To)
+}
+
+func (r *Repository) GetByID(id uuid.UUID) (*ShopInventoryItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	item, err := scanItem(r.db.QueryRow(`SELECT `+itemColumns+` FROM shop_inventory_items WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return item, nil
+}
+
+func (r *Repository) GetByBarcode(userID uuid.UUID, barcode string) (*ShopInventoryItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	item, err := scanItem(r.db.QueryRow(`SELECT `+itemColumns+` FROM shop_inventory_items WHERE user_id = $1 AND barcode = $2`, userID, barcode))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return item, nil
+}
+
+func (r *Repository) Create(item *ShopInventoryItem) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `INSERT INTO shop_inventory_items (id, user_id, name, category, barcode, stock_quantity, unit, price, cost, expiry_date, storage_type, shelf_location, image_data, markdown_status, surplus_eligible, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''), $14, $15, $16, $17) RETURNING ` + itemColumns
+	now := time.Now()
+	created, err := scanItem(r.db.QueryRow(query, item.ID, item.UserID, item.Name, item.Category, item.Barcode, item.StockQuantity, item.Unit, item.Price, item.Cost, item.ExpiryDate, item.StorageType, item.ShelfLocation, item.Image, item.MarkdownStatus, item.SurplusEligible, now, now))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errDuplicateBarcode
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*item = *created
+	return nil
+}
+
+func (r *Repository) Update(item *ShopInventoryItem) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `UPDATE shop_inventory_items SET name = $1, category = $2, barcode = $3, stock_quantity = $4, unit = $5, price = $6, cost = $7, expiry_date = $8, storage_type = $9, shelf_location = NULLIF($10, ''), image_data = NULLIF($11, ''), markdown_status = $12, surplus_eligible = $13, updated_at = $14 WHERE id = $15 RETURNING ` + itemColumns
+	updated, err := scanItem(r.db.QueryRow(query, item.Name, item.Category, item.Barcode, item.StockQuantity, item.Unit, item.Price, item.Cost, item.ExpiryDate, item.StorageType, item.ShelfLocation, item.Image, item.MarkdownStatus, item.SurplusEligible, time.Now(), item.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return errDuplicateBarcode
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*item = *updated
+	return nil
+}
+
+func (r *Repository) Delete(id uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	result, err := r.db.Exec(`DELETE FROM shop_inventory_items WHERE id = $1`, id)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *Repository) queryItems(query string, args ...interface{}) ([]*ShopInventoryItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	items := []*ShopInventoryItem{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return items, nil
+}
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate key
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanItem(row rowScanner) (*ShopInventoryItem, error) {
+	item := &ShopInventoryItem{}
+	if err := row.Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &item.Barcode, &item.StockQuantity, &item.Unit, &item.Price, &item.Cost, &item.ExpiryDate, &item.StorageType, &item.ShelfLocation, &item.Image, &item.MarkdownStatus, &item.SurplusEligible, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,183 @@
+package inventory
+
+import (
+	"foodlink_backend/errors"
+	"foodlink_backend/features/uploads"
+	"foodlink_backend/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errDuplicateBarcode = errors.NewAppError(http.StatusConflict, "Another item in this shop already has that barcode")
+	errInvalidWindow    = errors.NewAppError(http.StatusBadRequest, "Validation failed: to must be after from")
+)
+
+// defaultExpiryDays is how far ahead GetExpiring looks when no window is given
+const defaultExpiryDays = 3
+
+type Service struct {
+	repo   *Repository
+	images *uploads.Linker
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository(), images: uploads.NewLinker()}
+}
+
+func (s *Service) GetAllByUserID(userID uuid.UUID, category, storageType, markdownStatus string) ([]*ShopInventoryItem, error) {
+	return s.repo.GetAllByUserID(userID, category, storageType, markdownStatus)
+}
+
+func (s *Service) GetByID(id, userID uuid.UUID) (*ShopInventoryItem, error) {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	return item, nil
+}
+
+// GetByBarcode looks up one of a shop's items by the code scanned at the shelf
+// or till
+func (s *Service) GetByBarcode(userID uuid.UUID, barcode string) (*ShopInventoryItem, error) {
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil, errors.ErrNotFound
+	}
+	return s.repo.GetByBarcode(userID, barcode)
+}
+
+// GetExpiring lists in-stock items expiring inside a window. Without one it
+// looks the given number of days ahead from now, three by default.
+func (s *Service) GetExpiring(userID uuid.UUID, window *ExpiryWindow, days int) ([]*ShopInventoryItem, error) {
+	if window == nil {
+		if days <= 0 {
+			days = defaultExpiryDays
+		}
+		now := time.Now()
+		window = &ExpiryWindow{From: now, To: now.AddDate(0, 0, days)}
+	}
+	if !window.To.After(window.From) {
+		return nil, errInvalidWindow
+	}
+	return s.repo.GetExpiring(userID, *window)
+}
+
+func (s *Service) Create(userID uuid.UUID, req *CreateShopInventoryItemRequest) (*ShopInventoryItem, error) {
+	req.Barcode = strings.TrimSpace(req.Barcode)
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	item := &ShopInventoryItem{
+		ID:              uuid.New(),
+		UserID:          userID,
+		Name:            req.Name,
+		Category:        req.Category,
+		Barcode:         req.Barcode,
+		StockQuantity:   req.StockQuantity,
+		Unit:            req.Unit,
+		Price:           req.Price,
+		Cost:            req.Cost,
+		ExpiryDate:      req.ExpiryDate,
+		StorageType:     req.StorageType,
+		ShelfLocation:   req.ShelfLocation,
+		MarkdownStatus:  MarkdownNone,
+		SurplusEligible: req.SurplusEligible,
+	}
+	image, err := s.images.Resolve(userID, req.Image, uploads.AssociatedShopInventory, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	item.Image = image
+	if err := s.repo.Create(item); err != nil {
+		return nil, err
+	}
+	if err := s.images.Sync(item.Image, uploads.AssociatedShopInventory, item.ID); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *Service) Update(id, userID uuid.UUID, req *UpdateShopInventoryItemRequest) (*ShopInventoryItem, error) {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	req.Barcode = strings.TrimSpace(req.Barcode)
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if req.Name != "" {
+		item.Name = req.Name
+	}
+	if req.Category != "" {
+		item.Category = req.Category
+	}
+	if req.Barcode != "" {
+		item.Barcode = req.Barcode
+	}
+	if req.StockQuantity != nil {
+		item.StockQuantity = *req.StockQuantity
+	}
+	if req.Unit != "" {
+		item.Unit = req.Unit
+	}
+	if req.Price != nil {
+		item.Price = *req.Price
+	}
+	if req.Cost != nil {
+		item.Cost = *req.Cost
+	}
+	if req.ExpiryDate != nil {
+		item.ExpiryDate = *req.ExpiryDate
+	}
+	if req.StorageType != "" {
+		item.StorageType = req.StorageType
+	}
+	if req.ShelfLocation != nil {
+		item.ShelfLocation = *req.ShelfLocation
+	}
+	if req.MarkdownStatus != "" {
+		item.MarkdownStatus = req.MarkdownStatus
+	}
+	if req.SurplusEligible != nil {
+		item.SurplusEligible = *req.SurplusEligible
+	}
+	if req.Image != "" {
+		if item.Image, err = s.images.Resolve(userID, req.Image, uploads.AssociatedShopInventory, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Update(item); err != nil {
+		return nil, err
+	}
+	if req.Image != "" {
+		if err := s.images.Sync(item.Image, uploads.AssociatedShopInventory, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+func (s *Service) Delete(id, userID uuid.UUID) error {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if item.UserID != userID {
+		return errors.ErrForbidden
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	return s.images.Release(uploads.AssociatedShopInventory, id)
+}
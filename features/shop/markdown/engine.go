@@ -0,0 +1,157 @@
+package markdown
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// horizonDays is how close to expiry an item must be to be marked down
+	horizonDays = 7
+	// maxDiscountPct caps any suggested discount
+	maxDiscountPct = 70
+	// minDiscountPct is the smallest discount worth suggesting; items whose
+	// cost floor leaves less room are skipped
+	minDiscountPct = 5
+	// maxPriorityBonusPct is added for the shop's first priority category,
+	// shrinking to nothing past the last
+	maxPriorityBonusPct = 10
+	// suggestionTTL is how long a suggestion stays open, at most until the
+	// item expires
+	suggestionTTL = 24 * time.Hour
+)
+
+// daysToExpiryDiscounts is the base discount by whole days left before
+// expiry, checked in order
+var daysToExpiryDiscounts = []struct {
+	days int
+	pct  float64
+}{
+	{1, 50},
+	{2, 35},
+	{3, 25},
+	{5, 15},
+	{horizonDays, 10},
+}
+
+// suggest works out a discount for an item, or returns nil when it should not
+// be marked down. categoryPriority is the shop's category ranking, most
+// important first.
+//
+// The discount starts from how many days the item has left and grows with
+// stock on hand and the category's priority. It never takes the price below
+// cost.
+func suggest(item *candidate, categoryPriority []string, now time.Time) *DiscountSuggestion {
+	if item.StockQuantity <= 0 || item.Price <= 0 || !item.ExpiryDate.After(now) {
+		return nil
+	}
+	days := int(math.Ceil(item.ExpiryDate.Sub(now).Hours() / 24))
+	pct := 0.0
+	for _, step := range daysToExpiryDiscounts {
+		if days <= step.days {
+			pct = step.pct
+			break
+		}
+	}
+	if pct == 0 {
+		return nil
+	}
+
+	pct += stockBonus(item.StockQuantity)
+	pct += priorityBonus(item.Category, categoryPriority)
+	if pct > maxDiscountPct {
+		pct = maxDiscountPct
+	}
+	if item.Cost > 0 {
+		if floor := (item.Price - item.Cost) / item.Price * 100; pct > floor {
+			pct = floor
+		}
+	}
+	pct = math.Floor(pct)
+	if pct < minDiscountPct {
+		return nil
+	}
+
+	expiresAt := now.Add(suggestionTTL)
+	if item.ExpiryDate.Before(expiresAt) {
+		expiresAt = item.ExpiryDate
+	}
+	return &DiscountSuggestion{
+		UserID:               item.UserID,
+		SKUID:                item.ID,
+		SKUName:              item.Name,
+		Reason:               reason(days, item),
+		SuggestedDiscountPct: pct,
+		PredictedSellThrough: predictSellThrough(days, pct, item.StockQuantity),
+		Urgency:              urgency(days),
+		ExpiresAt:            expiresAt,
+		Status:               SuggestionPending,
+	}
+}
+
+// stockBonus deepens the discount when there is a lot left to sell
+func stockBonus(stock float64) float64 {
+	switch {
+	case stock >= 50:
+		return 10
+	case stock >= 20:
+		return 5
+	default:
+		return 0
+	}
+}
+
+// priorityBonus deepens the discount for categories the shop ranks highly
+func priorityBonus(category string, categoryPriority []string) float64 {
+	for rank, c := range categoryPriority {
+		if c == category {
+			n := float64(len(categoryPriority))
+			return math.Round(maxPriorityBonusPct * (n - float64(rank)) / n)
+		}
+	}
+	return 0
+}
+
+// predictSellThrough estimates the percentage of stock sold before expiry at
+// the discounted price. Deeper discounts sell more; little time left and
+// large stock sell less.
+func predictSellThrough(days int, pct, stock float64) float64 {
+	sellThrough := 35 + pct*1.1 + float64(days)*3
+	if stock >= 50 {
+		sellThrough -= 15
+	} else if stock >= 20 {
+		sellThrough -= 5
+	}
+	return math.Max(5, math.Min(95, math.Round(sellThrough)))
+}
+
+func urgency(days int) string {
+	switch {
+	case days <= 1:
+		return "high"
+	case days <= 3:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+func reason(days int, item *candidate) string {
+	when := fmt.Sprintf("in %d days", days)
+	if days <= 1 {
+		when = "within a day"
+	}
+	return fmt.Sprintf("Expires %s with %g %s in stock", when, item.StockQuantity, item.Unit)
+}
+
+// newPrice applies a price change to a price, rounded to cents and never
+// below zero
+func newPrice(price float64, method string, changeValue float64) float64 {
+	if method == MethodPercentage {
+		price = price * (1 - changeValue/100)
+	} else {
+		price -= changeValue
+	}
+	return math.Max(0, math.Round(price*100)/100)
+}
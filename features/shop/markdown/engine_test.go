@@ -0,0 +1,117 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSuggest(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	// expiresIn puts expiry partway through the given day from now
+	expiresIn := func(days int) time.Time {
+		return now.Add(time.Duration(days)*24*time.Hour - time.Hour)
+	}
+	priority := []string{"dairy", "bakery"}
+	tests := []struct {
+		name    string
+		item    candidate
+		wantPct float64 // 0 means no suggestion
+	}{
+		{"a day left", candidate{Category: "produce", StockQuantity: 10, Price: 10, ExpiryDate: expiresIn(1)}, 50},
+		{"three days left", candidate{Category: "produce", StockQuantity: 10, Price: 10, ExpiryDate: expiresIn(3)}, 25},
+		{"last day of the horizon", candidate{Category: "produce", StockQuantity: 10, Price: 10, ExpiryDate: expiresIn(horizonDays)}, 10},
+		{"beyond the horizon", candidate{Category: "produce", StockQuantity: 10, Price: 10, ExpiryDate: expiresIn(horizonDays + 1)}, 0},
+		{"already expired", candidate{Category: "produce", StockQuantity: 10, Price: 10, ExpiryDate: now.Add(-time.Hour)}, 0},
+		{"out of stock", candidate{Category: "produce", StockQuantity: 0, Price: 10, ExpiryDate: expiresIn(1)}, 0},
+		{"large stock", candidate{Category: "produce", StockQuantity: 60, Price: 10, ExpiryDate: expiresIn(3)}, 35},
+		{"moderate stock", candidate{Category: "produce", StockQuantity: 20, Price: 10, ExpiryDate: expiresIn(3)}, 30},
+		{"first priority category", candidate{Category: "dairy", StockQuantity: 10, Price: 10, ExpiryDate: expiresIn(3)}, 35},
+		{"second priority category", candidate{Category: "bakery", StockQuantity: 10, Price: 10, ExpiryDate: expiresIn(3)}, 30},
+		{"every bonus stops at the cap", candidate{Category: "dairy", StockQuantity: 60, Price: 10, ExpiryDate: expiresIn(1)}, maxDiscountPct},
+		{"cost floor limits the discount", candidate{Category: "produce", StockQuantity: 10, Price: 10, Cost: 6, ExpiryDate: expiresIn(1)}, 40},
+		{"cost floor rounds down", candidate{Category: "produce", StockQuantity: 10, Price: 3, Cost: 2, ExpiryDate: expiresIn(1)}, 33},
+		{"cost floor below the minimum discount", candidate{Category: "dairy", StockQuantity: 60, Price: 10, Cost: 9.7, ExpiryDate: expiresIn(1)}, 0},
+		{"sold at cost", candidate{Category: "produce", StockQuantity: 10, Price: 10, Cost: 10, ExpiryDate: expiresIn(1)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggest(&tt.item, priority, now)
+			if tt.wantPct == 0 {
+				if got != nil {
+					t.Fatalf("suggest() = %v%%, want no suggestion", got.SuggestedDiscountPct)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("suggest() = nil, want %v%%", tt.wantPct)
+			}
+			if got.SuggestedDiscountPct != tt.wantPct {
+				t.Fatalf("discount = %v%%, want %v%%", got.SuggestedDiscountPct, tt.wantPct)
+			}
+			if got.SuggestedDiscountPct < minDiscountPct || got.SuggestedDiscountPct > maxDiscountPct {
+				t.Fatalf("discount %v%% outside [%d, %d]", got.SuggestedDiscountPct, minDiscountPct, maxDiscountPct)
+			}
+			if tt.item.Cost > 0 && tt.item.Price*(1-got.SuggestedDiscountPct/100) < tt.item.Cost {
+				t.Fatalf("discount %v%% takes the price below cost", got.SuggestedDiscountPct)
+			}
+		})
+	}
+}
+
+func TestSuggestExpiresWithItem(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	soon := candidate{StockQuantity: 10, Price: 10, ExpiryDate: now.Add(6 * time.Hour)}
+	if got := suggest(&soon, nil, now); got == nil || !got.ExpiresAt.Equal(soon.ExpiryDate) {
+		t.Fatalf("suggestion for an item expiring in 6h = %+v, want it to expire with the item", got)
+	}
+	later := candidate{StockQuantity: 10, Price: 10, ExpiryDate: now.Add(72 * time.Hour)}
+	if got := suggest(&later, nil, now); got == nil || !got.ExpiresAt.Equal(now.Add(suggestionTTL)) {
+		t.Fatalf("suggestion for an item expiring in 3 days = %+v, want it to expire after %v", got, suggestionTTL)
+	}
+}
+
+func TestPriorityBonus(t *testing.T) {
+	priority := []string{"dairy", "bakery", "produce", "frozen"}
+	tests := []struct {
+		category string
+		want     float64
+	}{
+		{"dairy", maxPriorityBonusPct},
+		{"bakery", 8},
+		{"produce", 5},
+		{"frozen", 3},
+		{"household", 0},
+	}
+	for _, tt := range tests {
+		if got := priorityBonus(tt.category, priority); got != tt.want {
+			t.Errorf("priorityBonus(%q) = %v, want %v", tt.category, got, tt.want)
+		}
+	}
+	if got := priorityBonus("dairy", nil); got != 0 {
+		t.Errorf("priorityBonus with no ranking = %v, want 0", got)
+	}
+}
+
+func TestNewPrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		price  float64
+		method string
+		change float64
+		want   float64
+	}{
+		{"percentage", 10, MethodPercentage, 25, 7.5},
+		{"percentage rounds to cents", 9.99, MethodPercentage, 33, 6.69},
+		{"fixed", 10, MethodFixed, 2.5, 7.5},
+		{"fixed to exactly zero", 10, MethodFixed, 10, 0},
+		{"fixed below zero stops at zero", 10, MethodFixed, 12, 0},
+		{"full percentage", 10, MethodPercentage, 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newPrice(tt.price, tt.method, tt.change); got != tt.want {
+				t.Fatalf("newPrice(%v, %s, %v) = %v, want %v", tt.price, tt.method, tt.change, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package markdown
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUser(r *http.Request) (*auth.User, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return nil, errors.ErrUnauthorized
+	}
+	return user, nil
+}
+
+// scheduledBy names the user on the price changes they schedule
+func scheduledBy(user *auth.User) string {
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.Email
+}
+
+// pathID parses the ID in the second path segment, as in suggestions/{id}/accept
+func pathID(r *http.Request) (uuid.UUID, error) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) < 2 {
+		return uuid.Nil, errors.ErrBadRequest
+	}
+	return uuid.Parse(pathParts[1])
+}
+
+// ListSuggestions handles GET /api/v1/shop/markdown/suggestions
+// @Summary      List discount suggestions
+// @Description  List the markdown engine's discount suggestions for the authenticated shop, most urgent first. Suggestions are refreshed hourly from days to expiry, stock on hand, the cost floor and the category priority in the shop profile.
+// @Tags         shop-markdown
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query     string  false  "Filter by status (pending, accepted, dismissed, expired; default: pending)"
+// @Success      200     {array}   DiscountSuggestion
+// @Failure      401     {object}  errors.AppError
+// @Router       /shop/markdown/suggestions [get]
+func (h *Handler) ListSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	suggestions, err := h.service.ListSuggestions(user.ID, r.URL.Query().Get("status"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve discount suggestions", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Discount suggestions retrieved successfully", suggestions)
+}
+
+// GenerateSuggestions handles POST /api/v1/shop/markdown/suggestions/generate
+// @Summary      Refresh discount suggestions
+// @Description  Run the markdown engine for the authenticated shop now and return its pending suggestions
+// @Tags         shop-markdown
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   DiscountSuggestion
+// @Failure      401  {object}  errors.AppError
+// @Router       /shop/markdown/suggestions/generate [post]
+func (h *Handler) GenerateSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	suggestions, err := h.service.Generate(user.ID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to generate discount suggestions", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Discount suggestions generated successfully", suggestions)
+}
+
+// AcceptSuggestion handles POST /api/v1/shop/markdown/suggestions/:id/accept
+// @Summary      Accept discount suggestion
+// @Description  Schedule a suggestion's discount as a price change, effective now or at effective_at. The item's markdown status becomes scheduled, then active once the new price is applied.
+// @Tags         shop-markdown
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                   true   "Suggestion ID"
+// @Param        request  body      AcceptSuggestionRequest  false  "When to apply the discount"
+// @Success      201      {object}  PriceMapEntry
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /shop/markdown/suggestions/{id}/accept [post]
+func (h *Handler) AcceptSuggestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	var req AcceptSuggestionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.BadRequestResponse(w, "Invalid request body", err.Error())
+			return
+		}
+	}
+	entry, err := h.service.AcceptSuggestion(id, user.ID, scheduledBy(user), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to accept discount suggestion", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Price change scheduled successfully", entry)
+}
+
+// DismissSuggestion handles POST /api/v1/shop/markdown/suggestions/:id/dismiss
+// @Summary      Dismiss discount suggestion
+// @Description  Dismiss a pending suggestion. The engine may suggest a new discount for the item on its next run.
+// @Tags         shop-markdown
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Suggestion ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Failure      409  {object}  errors.AppError
+// @Router       /shop/markdown/suggestions/{id}/dismiss [post]
+func (h *Handler) DismissSuggestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.DismissSuggestion(id, user.ID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to dismiss discount suggestion", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Discount suggestion dismissed", map[string]string{"message": "Dismissed"})
+}
+
+// ListPriceMap handles GET /api/v1/shop/markdown/price-map
+// @Summary      List price changes
+// @Description  List the authenticated shop's scheduled and past price changes, latest effective first
+// @Tags         shop-markdown
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query     string  false  "Filter by status (scheduled, applied, superseded, cancelled)"
+// @Param        limit   query     int     false  "Number of entries (default: 50, max: 100)"
+// @Success      200     {array}   PriceMapEntry
+// @Failure      401     {object}  errors.AppError
+// @Router       /shop/markdown/price-map [get]
+func (h *Handler) ListPriceMap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	entries, err := h.service.ListEntries(user.ID, r.URL.Query().Get("status"), limit)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve price changes", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Price changes retrieved successfully", entries)
+}
+
+// CreatePriceMapEntry handles POST /api/v1/shop/markdown/price-map
+// @Summary      Schedule price change
+// @Description  Schedule a price change for an item at effective_at: change_value percent off with the percentage method, or change_value off the price with the fixed method. An item can have one scheduled change at a time.
+// @Tags         shop-markdown
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreatePriceMapEntryRequest  true  "Price change"
+// @Success      201      {object}  PriceMapEntry
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /shop/markdown/price-map [post]
+func (h *Handler) CreatePriceMapEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var req CreatePriceMapEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	entry, err := h.service.CreateEntry(user.ID, scheduledBy(user), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to schedule price change", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Price change scheduled successfully", entry)
+}
+
+// CancelPriceMapEntry handles DELETE /api/v1/shop/markdown/price-map/:id
+// @Summary      Cancel price change
+// @Description  Cancel a price change that has not been applied yet
+// @Tags         shop-markdown
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Price Change ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Failure      409  {object}  errors.AppError
+// @Router       /shop/markdown/price-map/{id} [delete]
+func (h *Handler) CancelPriceMapEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	user, err := h.getUser(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.CancelEntry(id, user.ID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to cancel price change", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Price change cancelled successfully", map[string]string{"message": "Cancelled"})
+}
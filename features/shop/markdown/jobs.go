@@ -0,0 +1,21 @@
+package markdown
+
+import (
+	"foodlink_backend/jobs"
+	"time"
+)
+
+// RegisterJobs schedules refreshing discount suggestions and applying
+// scheduled price changes when they fall due
+func (s *Service) RegisterJobs(scheduler *jobs.Scheduler, suggestInterval, applyInterval time.Duration) {
+	scheduler.Register(jobs.Job{
+		Name:     "shop.markdown.suggest",
+		Interval: suggestInterval,
+		Run:      s.GenerateAll,
+	})
+	scheduler.Register(jobs.Job{
+		Name:     "shop.markdown.apply",
+		Interval: applyInterval,
+		Run:      s.ApplyDue,
+	})
+}
@@ -0,0 +1,90 @@
+package markdown
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a discount suggestion
+const (
+	SuggestionPending   = "pending"
+	SuggestionAccepted  = "accepted"
+	SuggestionDismissed = "dismissed"
+	SuggestionExpired   = "expired"
+)
+
+// Statuses of a price-map entry
+const (
+	EntryScheduled  = "scheduled"
+	EntryApplied    = "applied"
+	EntrySuperseded = "superseded"
+	EntryCancelled  = "cancelled"
+)
+
+// How a price-map entry's change_value is applied to the old price
+const (
+	MethodPercentage = "percentage" // change_value percent off
+	MethodFixed      = "fixed"      // change_value off the price
+)
+
+type DiscountSuggestion struct {
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	UserID               uuid.UUID  `json:"user_id" db:"user_id"`
+	SKUID                uuid.UUID  `json:"sku_id" db:"sku_id"`
+	SKUName              string     `json:"sku_name" db:"sku_name"`
+	Reason               string     `json:"reason" db:"reason"`
+	SuggestedDiscountPct float64    `json:"suggested_discount_pct" db:"suggested_discount_pct"`
+	PredictedSellThrough float64    `json:"predicted_sell_through" db:"predicted_sell_through"`
+	Urgency              string     `json:"urgency" db:"urgency"`
+	ExpiresAt            time.Time  `json:"expires_at" db:"expires_at"`
+	Status               string     `json:"status" db:"status"`
+	PriceMapEntryID      *uuid.UUID `json:"price_map_entry_id,omitempty" db:"price_map_entry_id"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type PriceMapEntry struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	SKUID       uuid.UUID  `json:"sku_id" db:"sku_id"`
+	SKUName     string     `json:"sku_name" db:"sku_name"`
+	OldPrice    float64    `json:"old_price" db:"old_price"`
+	NewPrice    float64    `json:"new_price" db:"new_price"`
+	Method      string     `json:"method" db:"method"`
+	ChangeValue float64    `json:"change_value" db:"change_value"`
+	EffectiveAt time.Time  `json:"effective_at" db:"effective_at"`
+	ScheduledBy string     `json:"scheduled_by" db:"scheduled_by"`
+	Notes       string     `json:"notes,omitempty" db:"notes"`
+	Status      string     `json:"status" db:"status"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty" db:"applied_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type AcceptSuggestionRequest struct {
+	// When the new price takes effect; now when omitted
+	EffectiveAt *time.Time `json:"effective_at,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+}
+
+type CreatePriceMapEntryRequest struct {
+	SKUID       uuid.UUID `json:"sku_id" validate:"required"`
+	Method      string    `json:"method" validate:"required,oneof=percentage fixed"`
+	ChangeValue float64   `json:"change_value" validate:"gt=0"`
+	EffectiveAt time.Time `json:"effective_at" validate:"required"`
+	Notes       string    `json:"notes,omitempty"`
+}
+
+// candidate is an inventory item the engine considers for a markdown
+type candidate struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Name          string
+	Category      string
+	StockQuantity float64
+	Unit          string
+	Price         float64
+	Cost          float64
+	ExpiryDate    time.Time
+}
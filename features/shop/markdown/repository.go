@@ -0,0 +1,404 @@
+package markdown
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+const (
+	suggestionColumns = `id, user_id, sku_id, sku_name, reason, suggested_discount_pct, COALESCE(predicted_sell_through, 0), COALESCE(urgency, 'medium'), expires_at, status, price_map_entry_id, created_at, COALESCE(updated_at, created_at)`
+	entryColumns      = `id, user_id, sku_id, sku_name, old_price, new_price, method, change_value, effective_at, scheduled_by, COALESCE(notes, ''), status, applied_at, created_at, COALESCE(updated_at, created_at)`
+)
+
+// Candidates lists in-stock items expiring before horizon that have no
+// markdown yet, with their shop's category priority. With a userID only that
+// shop's items are listed.
+func (r *Repository) Candidates(userID *uuid.UUID, now, horizon time.Time) ([]*candidate, map[uuid.UUID][]string, error) {
+	if r.db == nil {
+		return nil, nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT i.id, i.user_id, i.name, i.category, i.stock_quantity, i.unit, i.price, i.cost, i.expiry_date, p.category_priority
+		FROM shop_inventory_items i
+		LEFT JOIN shop_profiles p ON p.user_id = i.user_id
+		WHERE ($1::uuid IS NULL OR i.user_id = $1)
+			AND COALESCE(i.markdown_status, 'none') = 'none'
+			AND i.stock_quantity > 0 AND i.expiry_date > $2 AND i.expiry_date <= $3
+		ORDER BY i.user_id, i.expiry_date`
+	rows, err := r.db.Query(query, userID, now, horizon)
+	if err != nil {
+		return nil, nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	items := []*candidate{}
+	priorities := map[uuid.UUID][]string{}
+	for rows.Next() {
+		item := &candidate{}
+		var priority []string
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Name, &item.Category, &item.StockQuantity, &item.Unit, &item.Price, &item.Cost, &item.ExpiryDate, pq.Array(&priority)); err != nil {
+			return nil, nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		items = append(items, item)
+		priorities[item.UserID] = priority
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return items, priorities, nil
+}
+
+// ExpireSuggestions closes pending suggestions whose window has passed
+func (r *Repository) ExpireSuggestions(now time.Time) (int64, error) {
+	if r.db == nil {
+		return 0, errors.ErrDatabase
+	}
+	result, err := r.db.Exec(`UPDATE shop_discount_suggestions SET status = $1 WHERE status = $2 AND expires_at <= $3`, SuggestionExpired, SuggestionPending, now)
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	expired, _ := result.RowsAffected()
+	return expired, nil
+}
+
+// UpsertSuggestion stores a suggestion, replacing the figures of the item's
+// pending suggestion if it has one
+func (r *Repository) UpsertSuggestion(s *DiscountSuggestion) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `
+		INSERT INTO shop_discount_suggestions (id, user_id, sku_id, sku_name, reason, suggested_discount_pct, predicted_sell_through, urgency, expires_at, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		ON CONFLICT (sku_id) WHERE status = 'pending' DO UPDATE SET
+			sku_name = EXCLUDED.sku_name, reason = EXCLUDED.reason, suggested_discount_pct = EXCLUDED.suggested_discount_pct,
+			predicted_sell_through = EXCLUDED.predicted_sell_through, urgency = EXCLUDED.urgency, expires_at = EXCLUDED.expires_at
+		RETURNING ` + suggestionColumns
+	saved, err := scanSuggestion(r.db.QueryRow(query, uuid.New(), s.UserID, s.SKUID, s.SKUName, s.Reason, s.SuggestedDiscountPct, s.PredictedSellThrough, s.Urgency, s.ExpiresAt, s.Status, time.Now()))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*s = *saved
+	return nil
+}
+
+func (r *Repository) ListSuggestions(userID uuid.UUID, status string) ([]*DiscountSuggestion, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT ` + suggestionColumns + ` FROM shop_discount_suggestions
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY CASE urgency WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END, expires_at ASC
+		LIMIT 200`
+	rows, err := r.db.Query(query, userID, status)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	suggestions := []*DiscountSuggestion{}
+	for rows.Next() {
+		s, err := scanSuggestion(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return suggestions, nil
+}
+
+func (r *Repository) GetSuggestion(id uuid.UUID) (*DiscountSuggestion, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	s, err := scanSuggestion(r.db.QueryRow(`SELECT `+suggestionColumns+` FROM shop_discount_suggestions WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return s, nil
+}
+
+// SetSuggestionStatus moves a pending suggestion to status. It fails with
+// errSuggestionClosed when the suggestion is no longer pending.
+func (r *Repository) SetSuggestionStatus(id uuid.UUID, status string) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	result, err := r.db.Exec(`UPDATE shop_discount_suggestions SET status = $1 WHERE id = $2 AND status = $3`, status, id, SuggestionPending)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return errSuggestionClosed
+	}
+	return nil
+}
+
+// AcceptSuggestion schedules a suggestion's discount as a price-map entry and
+// marks the suggestion accepted, in one transaction. The entry's prices are
+// taken from the item as it is now.
+func (r *Repository) AcceptSuggestion(id uuid.UUID, entry *PriceMapEntry) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM shop_discount_suggestions WHERE id = $1 FOR UPDATE`, id).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if status != SuggestionPending {
+		return errSuggestionClosed
+	}
+	if err := schedule(tx, entry); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE shop_discount_suggestions SET status = $1, price_map_entry_id = $2 WHERE id = $3`, SuggestionAccepted, entry.ID, id); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// CreateEntry schedules a price change entered by hand
+func (r *Repository) CreateEntry(entry *PriceMapEntry) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+	if err := schedule(tx, entry); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// schedule inserts a price-map entry for an item, locking the item to read its
+// current price, and marks the item's markdown scheduled. The entry's
+// OldPrice, NewPrice and SKUName are filled in from the item.
+func schedule(tx *sql.Tx, entry *PriceMapEntry) error {
+	var owner uuid.UUID
+	err := tx.QueryRow(`SELECT user_id, name, price FROM shop_inventory_items WHERE id = $1 FOR UPDATE`, entry.SKUID).Scan(&owner, &entry.SKUName, &entry.OldPrice)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if owner != entry.UserID {
+		return errors.ErrForbidden
+	}
+	entry.NewPrice = newPrice(entry.OldPrice, entry.Method, entry.ChangeValue)
+	if entry.NewPrice <= 0 {
+		return errPriceNotPositive
+	}
+	query := `INSERT INTO shop_price_map_entries (id, user_id, sku_id, sku_name, old_price, new_price, method, change_value, effective_at, scheduled_by, notes, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12, $13, $13) RETURNING ` + entryColumns
+	saved, err := scanEntry(tx.QueryRow(query, entry.ID, entry.UserID, entry.SKUID, entry.SKUName, entry.OldPrice, entry.NewPrice, entry.Method, entry.ChangeValue, entry.EffectiveAt, entry.ScheduledBy, entry.Notes, EntryScheduled, time.Now()))
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errAlreadyScheduled
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*entry = *saved
+	if _, err := tx.Exec(`UPDATE shop_inventory_items SET markdown_status = 'scheduled', updated_at = CURRENT_TIMESTAMP WHERE id = $1`, entry.SKUID); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+func (r *Repository) ListEntries(userID uuid.UUID, status string, limit int) ([]*PriceMapEntry, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT ` + entryColumns + ` FROM shop_price_map_entries
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY effective_at DESC
+		LIMIT $3`
+	rows, err := r.db.Query(query, userID, status, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	entries := []*PriceMapEntry{}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return entries, nil
+}
+
+func (r *Repository) GetEntry(id uuid.UUID) (*PriceMapEntry, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	entry, err := scanEntry(r.db.QueryRow(`SELECT `+entryColumns+` FROM shop_price_map_entries WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return entry, nil
+}
+
+// CancelEntry cancels a scheduled entry and clears its item's scheduled
+// markdown. It fails with errEntryNotScheduled once the entry has been applied
+// or closed.
+func (r *Repository) CancelEntry(id uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	var skuID uuid.UUID
+	err = tx.QueryRow(`UPDATE shop_price_map_entries SET status = $1 WHERE id = $2 AND status = $3 RETURNING sku_id`, EntryCancelled, id, EntryScheduled).Scan(&skuID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errEntryNotScheduled
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if _, err := tx.Exec(`UPDATE shop_inventory_items SET markdown_status = 'none', updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND markdown_status = 'scheduled'`, skuID); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// ApplyDue applies up to limit scheduled entries whose effective_at has
+// passed. Each item takes its new price and an active markdown. An entry whose
+// item was repriced after it was scheduled is superseded instead, and the
+// item's markdown cleared. Entries being applied by another run are skipped.
+func (r *Repository) ApplyDue(now time.Time, limit int) (applied, superseded int, err error) {
+	if r.db == nil {
+		return 0, 0, errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT e.id, e.sku_id, e.new_price, e.old_price = i.price, i.stock_quantity
+		FROM shop_price_map_entries e
+		JOIN shop_inventory_items i ON i.id = e.sku_id
+		WHERE e.status = $1 AND e.effective_at <= $2
+		ORDER BY e.effective_at
+		LIMIT $3
+		FOR UPDATE OF e, i SKIP LOCKED`
+	rows, err := tx.Query(query, EntryScheduled, now, limit)
+	if err != nil {
+		return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	type due struct {
+		id, skuID    uuid.UUID
+		newPrice     float64
+		priceCurrent bool
+		stock        float64
+	}
+	var dueEntries []due
+	for rows.Next() {
+		var d due
+		if err := rows.Scan(&d.id, &d.skuID, &d.newPrice, &d.priceCurrent, &d.stock); err != nil {
+			rows.Close()
+			return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+		}
+		dueEntries = append(dueEntries, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	for _, d := range dueEntries {
+		if d.priceCurrent {
+			if _, err := tx.Exec(`UPDATE shop_inventory_items SET price = $1, markdown_status = 'active', updated_at = $2 WHERE id = $3`, d.newPrice, now, d.skuID); err != nil {
+				return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+			}
+			if _, err := tx.Exec(`UPDATE shop_price_map_entries SET status = $1, applied_at = $2, stock_at_apply = $3 WHERE id = $4`, EntryApplied, now, d.stock, d.id); err != nil {
+				return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+			}
+			applied++
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE shop_inventory_items SET markdown_status = 'none', updated_at = $1 WHERE id = $2 AND markdown_status = 'scheduled'`, now, d.skuID); err != nil {
+			return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+		}
+		if _, err := tx.Exec(`UPDATE shop_price_map_entries SET status = $1 WHERE id = $2`, EntrySuperseded, d.id); err != nil {
+			return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+		}
+		superseded++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return applied, superseded, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSuggestion(row rowScanner) (*DiscountSuggestion, error) {
+	s := &DiscountSuggestion{}
+	if err := row.Scan(&s.ID, &s.UserID, &s.SKUID, &s.SKUName, &s.Reason, &s.SuggestedDiscountPct, &s.PredictedSellThrough, &s.Urgency, &s.ExpiresAt, &s.Status, &s.PriceMapEntryID, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func scanEntry(row rowScanner) (*PriceMapEntry, error) {
+	e := &PriceMapEntry{}
+	if err := row.Scan(&e.ID, &e.UserID, &e.SKUID, &e.SKUName, &e.OldPrice, &e.NewPrice, &e.Method, &e.ChangeValue, &e.EffectiveAt, &e.ScheduledBy, &e.Notes, &e.Status, &e.AppliedAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,35 @@
+package markdown
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		pathParts := strings.Split(path, "/")
+
+		switch {
+		case path == "suggestions" && r.Method == http.MethodGet:
+			handler.ListSuggestions(w, r)
+		case path == "suggestions/generate" && r.Method == http.MethodPost:
+			handler.GenerateSuggestions(w, r)
+		case len(pathParts) == 3 && pathParts[0] == "suggestions" && pathParts[2] == "accept" && r.Method == http.MethodPost:
+			handler.AcceptSuggestion(w, r)
+		case len(pathParts) == 3 && pathParts[0] == "suggestions" && pathParts[2] == "dismiss" && r.Method == http.MethodPost:
+			handler.DismissSuggestion(w, r)
+		case path == "price-map" && r.Method == http.MethodGet:
+			handler.ListPriceMap(w, r)
+		case path == "price-map" && r.Method == http.MethodPost:
+			handler.CreatePriceMapEntry(w, r)
+		case len(pathParts) == 2 && pathParts[0] == "price-map" && r.Method == http.MethodDelete:
+			handler.CancelPriceMapEntry(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
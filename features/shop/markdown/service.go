@@ -0,0 +1,191 @@
+package markdown
+
+import (
+	"context"
+	"foodlink_backend/errors"
+	"foodlink_backend/utils"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errSuggestionClosed  = errors.NewAppError(http.StatusConflict, "Suggestion has already been accepted, dismissed or has expired")
+	errAlreadyScheduled  = errors.NewAppError(http.StatusConflict, "Item already has a scheduled price change")
+	errEntryNotScheduled = errors.NewAppError(http.StatusConflict, "Only scheduled price changes can be cancelled")
+	errPriceNotPositive  = errors.NewAppError(http.StatusBadRequest, "Validation failed: the change would take the price to zero or below")
+)
+
+// applyBatch is how many due price changes one run applies
+const applyBatch = 100
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository()}
+}
+
+// GenerateAll refreshes the discount suggestions of every shop
+func (s *Service) GenerateAll(ctx context.Context) error {
+	count, err := s.generate(nil, time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("Markdown engine suggested discounts for %d items", count)
+	}
+	return nil
+}
+
+// Generate refreshes one shop's suggestions now and returns its pending ones
+func (s *Service) Generate(userID uuid.UUID) ([]*DiscountSuggestion, error) {
+	if _, err := s.generate(&userID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSuggestions(userID, SuggestionPending)
+}
+
+// generate expires stale suggestions, then suggests a discount for each item
+// close to expiry that has no markdown yet. An item's pending suggestion is
+// updated rather than duplicated.
+func (s *Service) generate(userID *uuid.UUID, now time.Time) (int, error) {
+	if _, err := s.repo.ExpireSuggestions(now); err != nil {
+		return 0, err
+	}
+	items, priorities, err := s.repo.Candidates(userID, now, now.AddDate(0, 0, horizonDays))
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, item := range items {
+		suggestion := suggest(item, priorities[item.UserID], now)
+		if suggestion == nil {
+			continue
+		}
+		if err := s.repo.UpsertSuggestion(suggestion); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (s *Service) ListSuggestions(userID uuid.UUID, status string) ([]*DiscountSuggestion, error) {
+	if status == "" {
+		status = SuggestionPending
+	}
+	return s.repo.ListSuggestions(userID, status)
+}
+
+// AcceptSuggestion schedules a suggestion's discount, by default straight
+// away. The scheduler applies it once effective_at passes.
+func (s *Service) AcceptSuggestion(id, userID uuid.UUID, scheduledBy string, req *AcceptSuggestionRequest) (*PriceMapEntry, error) {
+	suggestion, err := s.ownSuggestion(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if suggestion.Status != SuggestionPending || !suggestion.ExpiresAt.After(now) {
+		return nil, errSuggestionClosed
+	}
+	effectiveAt := now
+	if req.EffectiveAt != nil && req.EffectiveAt.After(now) {
+		effectiveAt = *req.EffectiveAt
+	}
+	entry := &PriceMapEntry{
+		ID:          uuid.New(),
+		UserID:      userID,
+		SKUID:       suggestion.SKUID,
+		Method:      MethodPercentage,
+		ChangeValue: suggestion.SuggestedDiscountPct,
+		EffectiveAt: effectiveAt,
+		ScheduledBy: scheduledBy,
+		Notes:       req.Notes,
+	}
+	if err := s.repo.AcceptSuggestion(id, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *Service) DismissSuggestion(id, userID uuid.UUID) error {
+	if _, err := s.ownSuggestion(id, userID); err != nil {
+		return err
+	}
+	return s.repo.SetSuggestionStatus(id, SuggestionDismissed)
+}
+
+func (s *Service) ListEntries(userID uuid.UUID, status string, limit int) ([]*PriceMapEntry, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.repo.ListEntries(userID, status, limit)
+}
+
+// CreateEntry schedules a price change for one of the shop's items
+func (s *Service) CreateEntry(userID uuid.UUID, scheduledBy string, req *CreatePriceMapEntryRequest) (*PriceMapEntry, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if req.Method == MethodPercentage && req.ChangeValue >= 100 {
+		return nil, errPriceNotPositive
+	}
+	entry := &PriceMapEntry{
+		ID:          uuid.New(),
+		UserID:      userID,
+		SKUID:       req.SKUID,
+		Method:      req.Method,
+		ChangeValue: req.ChangeValue,
+		EffectiveAt: req.EffectiveAt,
+		ScheduledBy: scheduledBy,
+		Notes:       req.Notes,
+	}
+	if err := s.repo.CreateEntry(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *Service) CancelEntry(id, userID uuid.UUID) error {
+	entry, err := s.repo.GetEntry(id)
+	if err != nil {
+		return err
+	}
+	if entry.UserID != userID {
+		return errors.ErrForbidden
+	}
+	return s.repo.CancelEntry(id)
+}
+
+// ApplyDue applies scheduled price changes whose time has come, a batch at a
+// time until none are left
+func (s *Service) ApplyDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		applied, superseded, err := s.repo.ApplyDue(time.Now(), applyBatch)
+		if err != nil {
+			return err
+		}
+		if applied+superseded > 0 {
+			log.Printf("Applied %d scheduled price changes; %d were superseded by later repricing", applied, superseded)
+		}
+		if applied+superseded < applyBatch {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+func (s *Service) ownSuggestion(id, userID uuid.UUID) (*DiscountSuggestion, error) {
+	suggestion, err := s.repo.GetSuggestion(id)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	return suggestion, nil
+}
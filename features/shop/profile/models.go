@@ -0,0 +1,43 @@
+package profile
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPreferences chooses which shop alerts are sent. It is stored as
+// JSON in shop_profiles.notification_preferences.
+type NotificationPreferences struct {
+	ExpiryAlerts     bool `json:"expiryAlerts"`
+	SurplusReminders bool `json:"surplusReminders"`
+	PriceUpdates     bool `json:"priceUpdates"`
+}
+
+type ShopProfile struct {
+	ID                      uuid.UUID               `json:"id" db:"id"`
+	UserID                  uuid.UUID               `json:"user_id" db:"user_id"`
+	StoreName               string                  `json:"store_name" db:"store_name"`
+	Address                 string                  `json:"address" db:"address"`
+	ContactNumber           string                  `json:"contact_number" db:"contact_number"`
+	ManagerName             string                  `json:"manager_name" db:"manager_name"`
+	OperatingHours          string                  `json:"operating_hours" db:"operating_hours"`
+	NotificationPreferences NotificationPreferences `json:"notification_preferences" db:"notification_preferences"`
+	DonationPreferences     []string                `json:"donation_preferences,omitempty" db:"donation_preferences"`
+	CategoryPriority        []string                `json:"category_priority,omitempty" db:"category_priority"`
+	BarcodePrefix           string                  `json:"barcode_prefix,omitempty" db:"barcode_prefix"`
+	UpdatedAt               time.Time               `json:"updated_at" db:"updated_at"`
+}
+
+type CreateShopProfileRequest struct {
+	StoreName               string                  `json:"store_name" validate:"required,min=1,max=255"`
+	Address                 string                  `json:"address" validate:"required,min=1"`
+	ContactNumber           string                  `json:"contact_number" validate:"required,min=1,max=50"`
+	ManagerName             string                  `json:"manager_name" validate:"required,min=1,max=255"`
+	OperatingHours          string                  `json:"operating_hours" validate:"required,min=1"`
+	NotificationPreferences NotificationPreferences `json:"notification_preferences"`
+	DonationPreferences     []string                `json:"donation_preferences,omitempty"`
+	// Categories in the order they should be handled, most important first
+	CategoryPriority []string `json:"category_priority,omitempty"`
+	BarcodePrefix    string   `json:"barcode_prefix,omitempty" validate:"omitempty,max=50"`
+}
@@ -0,0 +1,66 @@
+package profile
+
+import (
+	"database/sql"
+	"encoding/json"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+const profileColumns = `id, user_id, store_name, address, contact_number, manager_name, operating_hours, notification_preferences, donation_preferences, category_priority, COALESCE(barcode_prefix, ''), updated_at`
+
+func (r *Repository) GetByUserID(userID uuid.UUID) (*ShopProfile, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	profile, err := scanProfile(r.db.QueryRow(`SELECT `+profileColumns+` FROM shop_profiles WHERE user_id = $1`, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return profile, nil
+}
+
+func (r *Repository) CreateOrUpdate(profile *ShopProfile) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `INSERT INTO shop_profiles (id, user_id, store_name, address, contact_number, manager_name, operating_hours, notification_preferences, donation_preferences, category_priority, barcode_prefix, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12) ON CONFLICT (user_id) DO UPDATE SET store_name=EXCLUDED.store_name, address=EXCLUDED.address, contact_number=EXCLUDED.contact_number, manager_name=EXCLUDED.manager_name, operating_hours=EXCLUDED.operating_hours, notification_preferences=EXCLUDED.notification_preferences, donation_preferences=EXCLUDED.donation_preferences, category_priority=EXCLUDED.category_priority, barcode_prefix=EXCLUDED.barcode_prefix, updated_at=EXCLUDED.updated_at RETURNING ` + profileColumns
+	notificationsJSON, _ := json.Marshal(profile.NotificationPreferences)
+	saved, err := scanProfile(r.db.QueryRow(query, profile.ID, profile.UserID, profile.StoreName, profile.Address, profile.ContactNumber, profile.ManagerName, profile.OperatingHours, notificationsJSON, pq.Array(profile.DonationPreferences), pq.Array(profile.CategoryPriority), profile.BarcodePrefix, time.Now()))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*profile = *saved
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProfile(row rowScanner) (*ShopProfile, error) {
+	profile := &ShopProfile{}
+	var notificationsJSON []byte
+	if err := row.Scan(&profile.ID, &profile.UserID, &profile.StoreName, &profile.Address, &profile.ContactNumber, &profile.ManagerName, &profile.OperatingHours, &notificationsJSON, pq.Array(&profile.DonationPreferences), pq.Array(&profile.CategoryPriority), &profile.BarcodePrefix, &profile.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(notificationsJSON) > 0 {
+		json.Unmarshal(notificationsJSON, &profile.NotificationPreferences)
+	}
+	return profile, nil
+}
@@ -0,0 +1,21 @@
+package profile
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet:
+			handler.Get(w, r)
+		case r.Method == http.MethodPost || r.Method == http.MethodPut:
+			handler.CreateOrUpdate(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
@@ -0,0 +1,43 @@
+package profile
+
+import (
+	"foodlink_backend/errors"
+	"foodlink_backend/utils"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository()}
+}
+
+func (s *Service) GetByUserID(userID uuid.UUID) (*ShopProfile, error) {
+	return s.repo.GetByUserID(userID)
+}
+
+func (s *Service) CreateOrUpdate(userID uuid.UUID, req *CreateShopProfileRequest) (*ShopProfile, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	profile := &ShopProfile{
+		ID:                      uuid.New(),
+		UserID:                  userID,
+		StoreName:               req.StoreName,
+		Address:                 req.Address,
+		ContactNumber:           req.ContactNumber,
+		ManagerName:             req.ManagerName,
+		OperatingHours:          req.OperatingHours,
+		NotificationPreferences: req.NotificationPreferences,
+		DonationPreferences:     req.DonationPreferences,
+		CategoryPriority:        req.CategoryPriority,
+		BarcodePrefix:           req.BarcodePrefix,
+	}
+	if err := s.repo.CreateOrUpdate(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
@@ -0,0 +1,650 @@
+package staff
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// pathID parses the ID that follows the collection name, as in members/{id}
+func pathID(r *http.Request) (uuid.UUID, error) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	return uuid.Parse(pathParts[len(pathParts)-1])
+}
+
+// GetAllMembers handles GET /api/v1/shop/staff/members
+// @Summary      List staff members
+// @Description  Get the authenticated shop's staff members, by name
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   StaffMember
+// @Failure      401  {object}  errors.AppError
+// @Router       /shop/staff/members [get]
+func (h *Handler) GetAllMembers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	members, err := h.service.GetAllMembers(userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve staff members", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Staff members retrieved successfully", members)
+}
+
+// GetMember handles GET /api/v1/shop/staff/members/:id
+// @Summary      Get staff member
+// @Description  Get one of the authenticated shop's staff members
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Staff Member ID"
+// @Success      200  {object}  StaffMember
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/staff/members/{id} [get]
+func (h *Handler) GetMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	member, err := h.service.GetMemberByID(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve staff member", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Staff member retrieved successfully", member)
+}
+
+// CreateMember handles POST /api/v1/shop/staff/members
+// @Summary      Add staff member
+// @Description  Add a member to the authenticated shop's staff
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreateStaffMemberRequest  true  "Staff member data"
+// @Success      201      {object}  StaffMember
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Router       /shop/staff/members [post]
+func (h *Handler) CreateMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var req CreateStaffMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	member, err := h.service.CreateMember(userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to create staff member", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Staff member created successfully", member)
+}
+
+// UpdateMember handles PUT /api/v1/shop/staff/members/:id
+// @Summary      Update staff member
+// @Description  Update one of the authenticated shop's staff members
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                    true  "Staff Member ID"
+// @Param        request  body      UpdateStaffMemberRequest  true  "Staff member data"
+// @Success      200      {object}  StaffMember
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
+// @Router       /shop/staff/members/{id} [put]
+func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	var req UpdateStaffMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	member, err := h.service.UpdateMember(id, userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update staff member", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Staff member updated successfully", member)
+}
+
+// DeleteMember handles DELETE /api/v1/shop/staff/members/:id
+// @Summary      Remove staff member
+// @Description  Remove a staff member and their shifts. Their tasks are kept but left unassigned.
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Staff Member ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/staff/members/{id} [delete]
+func (h *Handler) DeleteMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.DeleteMember(id, userID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to delete staff member", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Staff member deleted successfully", map[string]string{"message": "Deleted"})
+}
+
+// GetAllTasks handles GET /api/v1/shop/staff/tasks
+// @Summary      List staff tasks
+// @Description  Get the authenticated shop's tasks, soonest due first. Each task says whether it is overdue.
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        assignee_id  query     string  false  "Only tasks assigned to this staff member"
+// @Param        completed    query     bool    false  "Only completed (true) or open (false) tasks"
+// @Param        due_before   query     string  false  "Only tasks due before this time (RFC 3339)"
+// @Success      200          {array}   StaffTask
+// @Failure      400          {object}  errors.AppError
+// @Failure      401          {object}  errors.AppError
+// @Router       /shop/staff/tasks [get]
+func (h *Handler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	query := r.URL.Query()
+	var filter TaskFilter
+	if value := query.Get("assignee_id"); value != "" {
+		assigneeID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid assignee_id", nil)
+			return
+		}
+		filter.AssigneeID = &assigneeID
+	}
+	if value := query.Get("completed"); value != "" {
+		completed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid completed; expected true or false", nil)
+			return
+		}
+		filter.Completed = &completed
+	}
+	if value := query.Get("due_before"); value != "" {
+		dueBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid due_before; expected an RFC 3339 time", nil)
+			return
+		}
+		filter.DueBefore = &dueBefore
+	}
+	tasks, err := h.service.GetAllTasks(userID, filter)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve tasks", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Tasks retrieved successfully", tasks)
+}
+
+// GetTask handles GET /api/v1/shop/staff/tasks/:id
+// @Summary      Get staff task
+// @Description  Get one of the authenticated shop's tasks
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Task ID"
+// @Success      200  {object}  StaffTask
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/staff/tasks/{id} [get]
+func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	task, err := h.service.GetTaskByID(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve task", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Task retrieved successfully", task)
+}
+
+// CreateTask handles POST /api/v1/shop/staff/tasks
+// @Summary      Create staff task
+// @Description  Create a task with a due time, optionally assigned to one of the shop's staff members
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreateStaffTaskRequest  true  "Task data"
+// @Success      201      {object}  StaffTask
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Router       /shop/staff/tasks [post]
+func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var req CreateStaffTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	task, err := h.service.CreateTask(userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to create task", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Task created successfully", task)
+}
+
+// UpdateTask handles PUT /api/v1/shop/staff/tasks/:id
+// @Summary      Update staff task
+// @Description  Update a task, reassign it or mark it completed
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true  "Task ID"
+// @Param        request  body      UpdateStaffTaskRequest  true  "Task data"
+// @Success      200      {object}  StaffTask
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
+// @Router       /shop/staff/tasks/{id} [put]
+func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	var req UpdateStaffTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	task, err := h.service.UpdateTask(id, userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update task", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Task updated successfully", task)
+}
+
+// DeleteTask handles DELETE /api/v1/shop/staff/tasks/:id
+// @Summary      Delete staff task
+// @Description  Delete one of the authenticated shop's tasks
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Task ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/staff/tasks/{id} [delete]
+func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.DeleteTask(id, userID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to delete task", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Task deleted successfully", map[string]string{"message": "Deleted"})
+}
+
+// GetAllShifts handles GET /api/v1/shop/staff/shifts
+// @Summary      List shifts
+// @Description  Get the authenticated shop's shifts overlapping a window, earliest first. The window defaults to the next seven days.
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from      query     string  false  "Window start (RFC 3339, default: now)"
+// @Param        to        query     string  false  "Window end (RFC 3339, default: seven days after from)"
+// @Param        staff_id  query     string  false  "Only shifts of this staff member"
+// @Success      200       {array}   Shift
+// @Failure      400       {object}  errors.AppError
+// @Failure      401       {object}  errors.AppError
+// @Router       /shop/staff/shifts [get]
+func (h *Handler) GetAllShifts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	query := r.URL.Query()
+	var filter ShiftFilter
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			utils.BadRequestResponse(w, "Invalid from; expected an RFC 3339 time", nil)
+			return
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			utils.BadRequestResponse(w, "Invalid to; expected an RFC 3339 time", nil)
+			return
+		}
+	}
+	if value := query.Get("staff_id"); value != "" {
+		staffID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequestResponse(w, "Invalid staff_id", nil)
+			return
+		}
+		filter.StaffID = &staffID
+	}
+	shifts, err := h.service.GetAllShifts(userID, filter)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve shifts", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Shifts retrieved successfully", shifts)
+}
+
+// CreateShift handles POST /api/v1/shop/staff/shifts
+// @Summary      Schedule shift
+// @Description  Schedule a staff member from start_time to end_time (at most 16 hours). A staff member cannot have overlapping shifts.
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreateShiftRequest  true  "Shift data"
+// @Success      201      {object}  Shift
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /shop/staff/shifts [post]
+func (h *Handler) CreateShift(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var req CreateShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	shift, err := h.service.CreateShift(userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to create shift", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Shift created successfully", shift)
+}
+
+// UpdateShift handles PUT /api/v1/shop/staff/shifts/:id
+// @Summary      Update shift
+// @Description  Move, reassign or edit a shift
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string              true  "Shift ID"
+// @Param        request  body      UpdateShiftRequest  true  "Shift data"
+// @Success      200      {object}  Shift
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /shop/staff/shifts/{id} [put]
+func (h *Handler) UpdateShift(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	var req UpdateShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	shift, err := h.service.UpdateShift(id, userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update shift", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Shift updated successfully", shift)
+}
+
+// DeleteShift handles DELETE /api/v1/shop/staff/shifts/:id
+// @Summary      Delete shift
+// @Description  Delete one of the authenticated shop's shifts
+// @Tags         shop-staff
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Shift ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/staff/shifts/{id} [delete]
+func (h *Handler) DeleteShift(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.DeleteShift(id, userID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to delete shift", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Shift deleted successfully", map[string]string{"message": "Deleted"})
+}
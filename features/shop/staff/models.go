@@ -0,0 +1,122 @@
+package staff
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxShiftLength is the longest shift that can be scheduled
+const maxShiftLength = 16 * time.Hour
+
+type StaffMember struct {
+	ID               uuid.UUID `json:"id" db:"id"`
+	UserID           uuid.UUID `json:"user_id" db:"user_id"`
+	Name             string    `json:"name" db:"name"`
+	Role             string    `json:"role" db:"role"`
+	Shift            string    `json:"shift" db:"shift"`
+	Contact          string    `json:"contact" db:"contact"`
+	Avatar           string    `json:"avatar,omitempty" db:"avatar"`
+	Responsibilities []string  `json:"responsibilities,omitempty" db:"responsibilities"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type StaffTask struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Title       string     `json:"title" db:"title"`
+	Description string     `json:"description,omitempty" db:"description"`
+	AssigneeID  *uuid.UUID `json:"assignee_id,omitempty" db:"assignee_id"`
+	Due         time.Time  `json:"due" db:"due"`
+	Completed   bool       `json:"completed" db:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	Category    string     `json:"category,omitempty" db:"category"`
+	Priority    string     `json:"priority" db:"priority"`
+	// Overdue is set when the task is not completed and its due time has passed
+	Overdue   bool      `json:"overdue"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type Shift struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	StaffID   uuid.UUID `json:"staff_id" db:"staff_id"`
+	StartTime time.Time `json:"start_time" db:"start_time"`
+	EndTime   time.Time `json:"end_time" db:"end_time"`
+	Station   string    `json:"station" db:"station"`
+	Notes     string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateStaffMemberRequest struct {
+	Name             string   `json:"name" validate:"required,min=1,max=255"`
+	Role             string   `json:"role" validate:"required,min=1,max=100"`
+	Shift            string   `json:"shift" validate:"required,min=1,max=100"`
+	Contact          string   `json:"contact" validate:"required,min=1,max=255"`
+	Avatar           string   `json:"avatar,omitempty"`
+	Responsibilities []string `json:"responsibilities,omitempty"`
+}
+
+type UpdateStaffMemberRequest struct {
+	Name             string   `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Role             string   `json:"role,omitempty" validate:"omitempty,min=1,max=100"`
+	Shift            string   `json:"shift,omitempty" validate:"omitempty,min=1,max=100"`
+	Contact          string   `json:"contact,omitempty" validate:"omitempty,min=1,max=255"`
+	Avatar           *string  `json:"avatar,omitempty"`
+	Responsibilities []string `json:"responsibilities,omitempty"`
+}
+
+type CreateStaffTaskRequest struct {
+	Title       string     `json:"title" validate:"required,min=1,max=255"`
+	Description string     `json:"description,omitempty"`
+	AssigneeID  *uuid.UUID `json:"assignee_id,omitempty"`
+	Due         time.Time  `json:"due" validate:"required"`
+	Category    string     `json:"category,omitempty" validate:"omitempty,oneof=expiry pricing surplus cleaning"`
+	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+}
+
+type UpdateStaffTaskRequest struct {
+	Title       string     `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Description *string    `json:"description,omitempty"`
+	AssigneeID  *uuid.UUID `json:"assignee_id,omitempty"`
+	// Unassign clears the assignee; assignee_id is ignored when it is set
+	Unassign  bool       `json:"unassign,omitempty"`
+	Due       *time.Time `json:"due,omitempty"`
+	Completed *bool      `json:"completed,omitempty"`
+	Category  string     `json:"category,omitempty" validate:"omitempty,oneof=expiry pricing surplus cleaning"`
+	Priority  string     `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+}
+
+// TaskFilter narrows a task listing. Zero values match every task.
+type TaskFilter struct {
+	AssigneeID *uuid.UUID
+	Completed  *bool
+	DueBefore  *time.Time
+}
+
+type CreateShiftRequest struct {
+	StaffID   uuid.UUID `json:"staff_id" validate:"required"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required"`
+	Station   string    `json:"station" validate:"required,min=1,max=100"`
+	Notes     string    `json:"notes,omitempty"`
+}
+
+type UpdateShiftRequest struct {
+	StaffID   *uuid.UUID `json:"staff_id,omitempty"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	Station   string     `json:"station,omitempty" validate:"omitempty,min=1,max=100"`
+	Notes     *string    `json:"notes,omitempty"`
+}
+
+// ShiftFilter narrows a shift listing to shifts overlapping From and To and,
+// optionally, one staff member
+type ShiftFilter struct {
+	From    time.Time
+	To      time.Time
+	StaffID *uuid.UUID
+}
@@ -0,0 +1,310 @@
+package staff
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+const (
+	memberColumns = `id, user_id, name, role, shift, contact, COALESCE(avatar, ''), responsibilities, created_at, COALESCE(updated_at, created_at)`
+	taskColumns   = `id, user_id, title, COALESCE(description, ''), assignee_id, due, COALESCE(completed, FALSE), completed_at, COALESCE(category, ''), COALESCE(priority, 'medium'), created_at, COALESCE(updated_at, created_at)`
+	shiftColumns  = `id, user_id, staff_id, start_time, end_time, station, COALESCE(notes, ''), created_at, COALESCE(updated_at, created_at)`
+)
+
+func (r *Repository) GetAllMembersByUserID(userID uuid.UUID) ([]*StaffMember, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT `+memberColumns+` FROM shop_staff_members WHERE user_id = $1 ORDER BY name ASC`, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	members := []*StaffMember{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return members, nil
+}
+
+func (r *Repository) GetMemberByID(id uuid.UUID) (*StaffMember, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	member, err := scanMember(r.db.QueryRow(`SELECT `+memberColumns+` FROM shop_staff_members WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return member, nil
+}
+
+func (r *Repository) CreateMember(member *StaffMember) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `INSERT INTO shop_staff_members (id, user_id, name, role, shift, contact, avatar, responsibilities, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10) RETURNING ` + memberColumns
+	now := time.Now()
+	created, err := scanMember(r.db.QueryRow(query, member.ID, member.UserID, member.Name, member.Role, member.Shift, member.Contact, member.Avatar, pq.Array(member.Responsibilities), now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*member = *created
+	return nil
+}
+
+func (r *Repository) UpdateMember(member *StaffMember) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `UPDATE shop_staff_members SET name = $1, role = $2, shift = $3, contact = $4, avatar = NULLIF($5, ''), responsibilities = $6, updated_at = $7 WHERE id = $8 RETURNING ` + memberColumns
+	updated, err := scanMember(r.db.QueryRow(query, member.Name, member.Role, member.Shift, member.Contact, member.Avatar, pq.Array(member.Responsibilities), time.Now(), member.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*member = *updated
+	return nil
+}
+
+// DeleteMember removes a staff member along with their shifts. Tasks assigned
+// to them are kept and become unassigned.
+func (r *Repository) DeleteMember(id uuid.UUID) error {
+	return r.delete(`DELETE FROM shop_staff_members WHERE id = $1`, id)
+}
+
+// GetAllTasksByUserID lists a shop's tasks, soonest due first
+func (r *Repository) GetAllTasksByUserID(userID uuid.UUID, filter TaskFilter) ([]*StaffTask, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT ` + taskColumns + ` FROM shop_staff_tasks
+		WHERE user_id = $1
+			AND ($2::uuid IS NULL OR assignee_id = $2)
+			AND ($3::boolean IS NULL OR COALESCE(completed, FALSE) = $3)
+			AND ($4::timestamptz IS NULL OR due < $4)
+		ORDER BY due ASC, created_at ASC`
+	rows, err := r.db.Query(query, userID, filter.AssigneeID, filter.Completed, filter.DueBefore)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	tasks := []*StaffTask{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return tasks, nil
+}
+
+func (r *Repository) GetTaskByID(id uuid.UUID) (*StaffTask, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	task, err := scanTask(r.db.QueryRow(`SELECT `+taskColumns+` FROM shop_staff_tasks WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return task, nil
+}
+
+func (r *Repository) CreateTask(task *StaffTask) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `INSERT INTO shop_staff_tasks (id, user_id, title, description, assignee_id, due, completed, completed_at, category, priority, created_at, updated_at) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12) RETURNING ` + taskColumns
+	now := time.Now()
+	created, err := scanTask(r.db.QueryRow(query, task.ID, task.UserID, task.Title, task.Description, task.AssigneeID, task.Due, task.Completed, task.CompletedAt, task.Category, task.Priority, now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*task = *created
+	return nil
+}
+
+func (r *Repository) UpdateTask(task *StaffTask) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `UPDATE shop_staff_tasks SET title = $1, description = NULLIF($2, ''), assignee_id = $3, due = $4, completed = $5, completed_at = $6, category = NULLIF($7, ''), priority = $8, updated_at = $9 WHERE id = $10 RETURNING ` + taskColumns
+	updated, err := scanTask(r.db.QueryRow(query, task.Title, task.Description, task.AssigneeID, task.Due, task.Completed, task.CompletedAt, task.Category, task.Priority, time.Now(), task.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*task = *updated
+	return nil
+}
+
+func (r *Repository) DeleteTask(id uuid.UUID) error {
+	return r.delete(`DELETE FROM shop_staff_tasks WHERE id = $1`, id)
+}
+
+// GetAllShiftsByUserID lists a shop's shifts that overlap the filter's window,
+// earliest first
+func (r *Repository) GetAllShiftsByUserID(userID uuid.UUID, filter ShiftFilter) ([]*Shift, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT ` + shiftColumns + ` FROM shop_shifts
+		WHERE user_id = $1 AND start_time < $3 AND end_time > $2 AND ($4::uuid IS NULL OR staff_id = $4)
+		ORDER BY start_time ASC`
+	rows, err := r.db.Query(query, userID, filter.From, filter.To, filter.StaffID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	shifts := []*Shift{}
+	for rows.Next() {
+		shift, err := scanShift(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		shifts = append(shifts, shift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return shifts, nil
+}
+
+func (r *Repository) GetShiftByID(id uuid.UUID) (*Shift, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	shift, err := scanShift(r.db.QueryRow(`SELECT `+shiftColumns+` FROM shop_shifts WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return shift, nil
+}
+
+// HasOverlappingShift reports whether a staff member already works at any time
+// between start and end, ignoring the shift being edited
+func (r *Repository) HasOverlappingShift(staffID uuid.UUID, start, end time.Time, exceptID uuid.UUID) (bool, error) {
+	if r.db == nil {
+		return false, errors.ErrDatabase
+	}
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM shop_shifts WHERE staff_id = $1 AND start_time < $3 AND end_time > $2 AND id <> $4)`
+	if err := r.db.QueryRow(query, staffID, start, end, exceptID).Scan(&exists); err != nil {
+		return false, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return exists, nil
+}
+
+func (r *Repository) CreateShift(shift *Shift) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `INSERT INTO shop_shifts (id, user_id, staff_id, start_time, end_time, station, notes, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9) RETURNING ` + shiftColumns
+	now := time.Now()
+	created, err := scanShift(r.db.QueryRow(query, shift.ID, shift.UserID, shift.StaffID, shift.StartTime, shift.EndTime, shift.Station, shift.Notes, now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*shift = *created
+	return nil
+}
+
+func (r *Repository) UpdateShift(shift *Shift) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `UPDATE shop_shifts SET staff_id = $1, start_time = $2, end_time = $3, station = $4, notes = NULLIF($5, ''), updated_at = $6 WHERE id = $7 RETURNING ` + shiftColumns
+	updated, err := scanShift(r.db.QueryRow(query, shift.StaffID, shift.StartTime, shift.EndTime, shift.Station, shift.Notes, time.Now(), shift.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*shift = *updated
+	return nil
+}
+
+func (r *Repository) DeleteShift(id uuid.UUID) error {
+	return r.delete(`DELETE FROM shop_shifts WHERE id = $1`, id)
+}
+
+func (r *Repository) delete(query string, id uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMember(row rowScanner) (*StaffMember, error) {
+	m := &StaffMember{}
+	if err := row.Scan(&m.ID, &m.UserID, &m.Name, &m.Role, &m.Shift, &m.Contact, &m.Avatar, pq.Array(&m.Responsibilities), &m.CreatedAt, &m.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func scanTask(row rowScanner) (*StaffTask, error) {
+	t := &StaffTask{}
+	if err := row.Scan(&t.ID, &t.UserID, &t.Title, &t.Description, &t.AssigneeID, &t.Due, &t.Completed, &t.CompletedAt, &t.Category, &t.Priority, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func scanShift(row rowScanner) (*Shift, error) {
+	s := &Shift{}
+	if err := row.Scan(&s.ID, &s.UserID, &s.StaffID, &s.StartTime, &s.EndTime, &s.Station, &s.Notes, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,49 @@
+package staff
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		collection := pathParts[0]
+
+		switch {
+		case len(pathParts) == 1 && collection == "members" && r.Method == http.MethodGet:
+			handler.GetAllMembers(w, r)
+		case len(pathParts) == 1 && collection == "members" && r.Method == http.MethodPost:
+			handler.CreateMember(w, r)
+		case len(pathParts) == 2 && collection == "members" && r.Method == http.MethodGet:
+			handler.GetMember(w, r)
+		case len(pathParts) == 2 && collection == "members" && r.Method == http.MethodPut:
+			handler.UpdateMember(w, r)
+		case len(pathParts) == 2 && collection == "members" && r.Method == http.MethodDelete:
+			handler.DeleteMember(w, r)
+		case len(pathParts) == 1 && collection == "tasks" && r.Method == http.MethodGet:
+			handler.GetAllTasks(w, r)
+		case len(pathParts) == 1 && collection == "tasks" && r.Method == http.MethodPost:
+			handler.CreateTask(w, r)
+		case len(pathParts) == 2 && collection == "tasks" && r.Method == http.MethodGet:
+			handler.GetTask(w, r)
+		case len(pathParts) == 2 && collection == "tasks" && r.Method == http.MethodPut:
+			handler.UpdateTask(w, r)
+		case len(pathParts) == 2 && collection == "tasks" && r.Method == http.MethodDelete:
+			handler.DeleteTask(w, r)
+		case len(pathParts) == 1 && collection == "shifts" && r.Method == http.MethodGet:
+			handler.GetAllShifts(w, r)
+		case len(pathParts) == 1 && collection == "shifts" && r.Method == http.MethodPost:
+			handler.CreateShift(w, r)
+		case len(pathParts) == 2 && collection == "shifts" && r.Method == http.MethodPut:
+			handler.UpdateShift(w, r)
+		case len(pathParts) == 2 && collection == "shifts" && r.Method == http.MethodDelete:
+			handler.DeleteShift(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
@@ -0,0 +1,331 @@
+package staff
+
+import (
+	"foodlink_backend/errors"
+	"foodlink_backend/utils"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errUnknownAssignee = errors.NewAppError(http.StatusBadRequest, "Validation failed: assignee_id is not a member of this shop's staff")
+	errUnknownStaff    = errors.NewAppError(http.StatusBadRequest, "Validation failed: staff_id is not a member of this shop's staff")
+	errShiftTimes      = errors.NewAppError(http.StatusBadRequest, "Validation failed: end_time must be after start_time")
+	errShiftTooLong    = errors.NewAppError(http.StatusBadRequest, "Validation failed: shifts cannot be longer than 16 hours")
+	errShiftOverlap    = errors.NewAppError(http.StatusConflict, "Staff member already has a shift during that time")
+	errInvalidWindow   = errors.NewAppError(http.StatusBadRequest, "Validation failed: to must be after from")
+)
+
+// defaultShiftWindow is how far ahead shifts are listed when no window is given
+const defaultShiftWindow = 7 * 24 * time.Hour
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository()}
+}
+
+func (s *Service) GetAllMembers(userID uuid.UUID) ([]*StaffMember, error) {
+	return s.repo.GetAllMembersByUserID(userID)
+}
+
+func (s *Service) GetMemberByID(id, userID uuid.UUID) (*StaffMember, error) {
+	member, err := s.repo.GetMemberByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if member.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	return member, nil
+}
+
+func (s *Service) CreateMember(userID uuid.UUID, req *CreateStaffMemberRequest) (*StaffMember, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	member := &StaffMember{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Name:             req.Name,
+		Role:             req.Role,
+		Shift:            req.Shift,
+		Contact:          req.Contact,
+		Avatar:           req.Avatar,
+		Responsibilities: req.Responsibilities,
+	}
+	if err := s.repo.CreateMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *Service) UpdateMember(id, userID uuid.UUID, req *UpdateStaffMemberRequest) (*StaffMember, error) {
+	member, err := s.GetMemberByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if req.Name != "" {
+		member.Name = req.Name
+	}
+	if req.Role != "" {
+		member.Role = req.Role
+	}
+	if req.Shift != "" {
+		member.Shift = req.Shift
+	}
+	if req.Contact != "" {
+		member.Contact = req.Contact
+	}
+	if req.Avatar != nil {
+		member.Avatar = *req.Avatar
+	}
+	if req.Responsibilities != nil {
+		member.Responsibilities = req.Responsibilities
+	}
+	if err := s.repo.UpdateMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *Service) DeleteMember(id, userID uuid.UUID) error {
+	if _, err := s.GetMemberByID(id, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteMember(id)
+}
+
+func (s *Service) GetAllTasks(userID uuid.UUID, filter TaskFilter) ([]*StaffTask, error) {
+	tasks, err := s.repo.GetAllTasksByUserID(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, task := range tasks {
+		task.setOverdue(now)
+	}
+	return tasks, nil
+}
+
+func (s *Service) GetTaskByID(id, userID uuid.UUID) (*StaffTask, error) {
+	task, err := s.repo.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	task.setOverdue(time.Now())
+	return task, nil
+}
+
+func (s *Service) CreateTask(userID uuid.UUID, req *CreateStaffTaskRequest) (*StaffTask, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if req.AssigneeID != nil {
+		if err := s.checkStaff(*req.AssigneeID, userID, errUnknownAssignee); err != nil {
+			return nil, err
+		}
+	}
+	priority := req.Priority
+	if priority == "" {
+		priority = "medium"
+	}
+	task := &StaffTask{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Title:       req.Title,
+		Description: req.Description,
+		AssigneeID:  req.AssigneeID,
+		Due:         req.Due,
+		Category:    req.Category,
+		Priority:    priority,
+	}
+	if err := s.repo.CreateTask(task); err != nil {
+		return nil, err
+	}
+	task.setOverdue(time.Now())
+	return task, nil
+}
+
+// UpdateTask edits a task. Completing it records when; reopening it clears
+// that again.
+func (s *Service) UpdateTask(id, userID uuid.UUID, req *UpdateStaffTaskRequest) (*StaffTask, error) {
+	task, err := s.GetTaskByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if req.Title != "" {
+		task.Title = req.Title
+	}
+	if req.Description != nil {
+		task.Description = *req.Description
+	}
+	if req.Unassign {
+		task.AssigneeID = nil
+	} else if req.AssigneeID != nil {
+		if err := s.checkStaff(*req.AssigneeID, userID, errUnknownAssignee); err != nil {
+			return nil, err
+		}
+		task.AssigneeID = req.AssigneeID
+	}
+	if req.Due != nil {
+		task.Due = *req.Due
+	}
+	if req.Completed != nil && *req.Completed != task.Completed {
+		task.Completed = *req.Completed
+		task.CompletedAt = nil
+		if task.Completed {
+			now := time.Now()
+			task.CompletedAt = &now
+		}
+	}
+	if req.Category != "" {
+		task.Category = req.Category
+	}
+	if req.Priority != "" {
+		task.Priority = req.Priority
+	}
+	if err := s.repo.UpdateTask(task); err != nil {
+		return nil, err
+	}
+	task.setOverdue(time.Now())
+	return task, nil
+}
+
+func (s *Service) DeleteTask(id, userID uuid.UUID) error {
+	if _, err := s.GetTaskByID(id, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteTask(id)
+}
+
+// GetAllShifts lists shifts overlapping a window, the coming week when the
+// filter has none
+func (s *Service) GetAllShifts(userID uuid.UUID, filter ShiftFilter) ([]*Shift, error) {
+	if filter.From.IsZero() {
+		filter.From = time.Now()
+	}
+	if filter.To.IsZero() {
+		filter.To = filter.From.Add(defaultShiftWindow)
+	}
+	if !filter.To.After(filter.From) {
+		return nil, errInvalidWindow
+	}
+	return s.repo.GetAllShiftsByUserID(userID, filter)
+}
+
+func (s *Service) CreateShift(userID uuid.UUID, req *CreateShiftRequest) (*Shift, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	shift := &Shift{
+		ID:        uuid.New(),
+		UserID:    userID,
+		StaffID:   req.StaffID,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Station:   req.Station,
+		Notes:     req.Notes,
+	}
+	if err := s.checkShift(shift); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateShift(shift); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+func (s *Service) UpdateShift(id, userID uuid.UUID, req *UpdateShiftRequest) (*Shift, error) {
+	shift, err := s.repo.GetShiftByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if shift.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if req.StaffID != nil {
+		shift.StaffID = *req.StaffID
+	}
+	if req.StartTime != nil {
+		shift.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		shift.EndTime = *req.EndTime
+	}
+	if req.Station != "" {
+		shift.Station = req.Station
+	}
+	if req.Notes != nil {
+		shift.Notes = *req.Notes
+	}
+	if err := s.checkShift(shift); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateShift(shift); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+func (s *Service) DeleteShift(id, userID uuid.UUID) error {
+	shift, err := s.repo.GetShiftByID(id)
+	if err != nil {
+		return err
+	}
+	if shift.UserID != userID {
+		return errors.ErrForbidden
+	}
+	return s.repo.DeleteShift(id)
+}
+
+// checkShift validates a shift's times and that its staff member belongs to
+// the shop and is not already working then
+func (s *Service) checkShift(shift *Shift) error {
+	if !shift.EndTime.After(shift.StartTime) {
+		return errShiftTimes
+	}
+	if shift.EndTime.Sub(shift.StartTime) > maxShiftLength {
+		return errShiftTooLong
+	}
+	if err := s.checkStaff(shift.StaffID, shift.UserID, errUnknownStaff); err != nil {
+		return err
+	}
+	overlaps, err := s.repo.HasOverlappingShift(shift.StaffID, shift.StartTime, shift.EndTime, shift.ID)
+	if err != nil {
+		return err
+	}
+	if overlaps {
+		return errShiftOverlap
+	}
+	return nil
+}
+
+// checkStaff returns notMember unless staffID is one of the shop's staff
+func (s *Service) checkStaff(staffID, userID uuid.UUID, notMember error) error {
+	member, err := s.repo.GetMemberByID(staffID)
+	if err == errors.ErrNotFound || (err == nil && member.UserID != userID) {
+		return notMember
+	}
+	return err
+}
+
+func (t *StaffTask) setOverdue(now time.Time) {
+	t.Overdue = !t.Completed && t.Due.Before(now)
+}
@@ -0,0 +1,311 @@
+package surplus
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// pathID parses the ID in the first path segment, as in {id}/assign
+func pathID(r *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(strings.Split(strings.Trim(r.URL.Path, "/"), "/")[0])
+}
+
+// GetAll handles GET /api/v1/shop/surplus
+// @Summary      List shop surplus
+// @Description  Get the authenticated shop's surplus items, soonest expiry first
+// @Tags         shop-surplus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query     string  false  "Filter by status (pending, picked, expired)"
+// @Success      200     {array}   ShopSurplusItem
+// @Failure      401     {object}  errors.AppError
+// @Router       /shop/surplus [get]
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	items, err := h.service.GetAllByUserID(userID, r.URL.Query().Get("status"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve surplus items", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Surplus items retrieved successfully", items)
+}
+
+// GetByID handles GET /api/v1/shop/surplus/:id
+// @Summary      Get shop surplus item
+// @Description  Get one of the authenticated shop's surplus items
+// @Tags         shop-surplus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Surplus Item ID"
+// @Success      200  {object}  ShopSurplusItem
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/surplus/{id} [get]
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	item, err := h.service.GetByID(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve surplus item", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Surplus item retrieved successfully", item)
+}
+
+// Create handles POST /api/v1/shop/surplus
+// @Summary      Add shop surplus item
+// @Description  Record surplus stock to hand to an NGO or community kitchen
+// @Tags         shop-surplus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreateShopSurplusItemRequest  true  "Surplus item data"
+// @Success      201      {object}  ShopSurplusItem
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Router       /shop/surplus [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var req CreateShopSurplusItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	item, err := h.service.Create(userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to create surplus item", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Surplus item created successfully", item)
+}
+
+// Convert handles POST /api/v1/shop/surplus/convert
+// @Summary      Convert expiring inventory to surplus
+// @Description  Create a near-expiry surplus entry for each surplus-eligible, in-stock inventory item expiring in the next few days. Items that already have a pending entry are skipped.
+// @Tags         shop-surplus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days  query     int  false  "Number of days ahead to look for expiring stock (default: 2)"
+// @Success      201   {object}  ConvertResult
+// @Failure      401   {object}  errors.AppError
+// @Router       /shop/surplus/convert [post]
+func (h *Handler) Convert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	result, err := h.service.ConvertFromInventory(userID, days)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to convert inventory to surplus", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Inventory converted to surplus successfully", result)
+}
+
+// Assign handles PUT /api/v1/shop/surplus/:id/assign
+// @Summary      Assign surplus to a destination
+// @Description  Hand a pending surplus item to an NGO or community kitchen for pickup. A registered destination is reminded shortly before pickup_time.
+// @Tags         shop-surplus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                        true  "Surplus Item ID"
+// @Param        request  body      AssignShopSurplusItemRequest  true  "Destination and pickup time"
+// @Success      200      {object}  ShopSurplusItem
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
+// @Failure      409      {object}  errors.AppError
+// @Router       /shop/surplus/{id}/assign [put]
+func (h *Handler) Assign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	var req AssignShopSurplusItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	item, err := h.service.Assign(id, userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to assign surplus item", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Surplus item assigned successfully", item)
+}
+
+// MarkPicked handles POST /api/v1/shop/surplus/:id/picked
+// @Summary      Mark surplus picked up
+// @Description  Record that a pending surplus item was collected
+// @Tags         shop-surplus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Surplus Item ID"
+// @Success      200  {object}  ShopSurplusItem
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Failure      409  {object}  errors.AppError
+// @Router       /shop/surplus/{id}/picked [post]
+func (h *Handler) MarkPicked(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	item, err := h.service.MarkPicked(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to mark surplus item picked up", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Surplus item marked picked up", item)
+}
+
+// Delete handles DELETE /api/v1/shop/surplus/:id
+// @Summary      Delete shop surplus item
+// @Description  Delete one of the authenticated shop's surplus items
+// @Tags         shop-surplus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Surplus Item ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /shop/surplus/{id} [delete]
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := pathID(r)
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.Delete(id, userID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to delete surplus item", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Surplus item deleted successfully", map[string]string{"message": "Deleted"})
+}
@@ -0,0 +1,24 @@
+package surplus
+
+import (
+	"context"
+	"foodlink_backend/jobs"
+	"time"
+)
+
+// RegisterJobs schedules pickup reminders, sent once a pickup is within
+// reminderLead, and expiring surplus that was never collected
+func (s *Service) RegisterJobs(scheduler *jobs.Scheduler, interval, reminderLead time.Duration) {
+	scheduler.Register(jobs.Job{
+		Name:     "shop.surplus.remind",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			return s.SendReminders(ctx, reminderLead)
+		},
+	})
+	scheduler.Register(jobs.Job{
+		Name:     "shop.surplus.expire",
+		Interval: interval,
+		Run:      s.ExpireOverdue,
+	})
+}
@@ -0,0 +1,85 @@
+package surplus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a surplus item. A pending item waits for collection until it is
+// picked up, or expires once its expiry window has passed.
+const (
+	StatusPending = "pending"
+	StatusPicked  = "picked"
+	StatusExpired = "expired"
+)
+
+// Conditions of a surplus item
+const (
+	ConditionFresh      = "fresh"
+	ConditionNearExpiry = "near-expiry"
+)
+
+// Destinations surplus can be handed to
+const (
+	DestinationNGO              = "ngo"
+	DestinationCommunityKitchen = "community-kitchen"
+)
+
+type ShopSurplusItem struct {
+	ID                uuid.UUID  `json:"id" db:"id"`
+	UserID            uuid.UUID  `json:"user_id" db:"user_id"`
+	SKUID             *uuid.UUID `json:"sku_id,omitempty" db:"sku_id"`
+	SKUName           string     `json:"sku_name" db:"sku_name"`
+	Quantity          float64    `json:"quantity" db:"quantity"`
+	Unit              string     `json:"unit" db:"unit"`
+	ExpiryWindowStart time.Time  `json:"expiry_window_start" db:"expiry_window_start"`
+	ExpiryWindowEnd   time.Time  `json:"expiry_window_end" db:"expiry_window_end"`
+	Condition         string     `json:"condition" db:"condition"`
+	DestinationType   string     `json:"destination_type,omitempty" db:"destination_type"`
+	DestinationName   string     `json:"destination_name,omitempty" db:"destination_name"`
+	DestinationUserID *uuid.UUID `json:"destination_user_id,omitempty" db:"destination_user_id"`
+	Status            string     `json:"status" db:"status"`
+	PickupTime        *time.Time `json:"pickup_time,omitempty" db:"pickup_time"`
+	ReminderSent      bool       `json:"reminder_sent" db:"reminder_sent"`
+	PickedAt          *time.Time `json:"picked_at,omitempty" db:"picked_at"`
+	Image             string     `json:"image,omitempty" db:"image_data"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type CreateShopSurplusItemRequest struct {
+	SKUName           string    `json:"sku_name" validate:"required,min=1,max=255"`
+	Quantity          float64   `json:"quantity" validate:"gt=0"`
+	Unit              string    `json:"unit" validate:"required,min=1,max=50"`
+	ExpiryWindowStart time.Time `json:"expiry_window_start" validate:"required"`
+	ExpiryWindowEnd   time.Time `json:"expiry_window_end" validate:"required"`
+	Condition         string    `json:"condition" validate:"required,oneof=fresh near-expiry"`
+	// An image URL, or the ID of an upload from POST /uploads
+	Image string `json:"image,omitempty"`
+}
+
+// AssignShopSurplusItemRequest hands a pending item to a destination. When
+// destination_user_id names a registered NGO or community kitchen it is
+// reminded before pickup_time; destination_name defaults to the NGO's
+// organisation name.
+type AssignShopSurplusItemRequest struct {
+	DestinationType   string     `json:"destination_type" validate:"required,oneof=ngo community-kitchen"`
+	DestinationName   string     `json:"destination_name,omitempty" validate:"omitempty,max=255"`
+	DestinationUserID *uuid.UUID `json:"destination_user_id,omitempty"`
+	PickupTime        time.Time  `json:"pickup_time" validate:"required"`
+}
+
+// ConvertResult reports the surplus entries created from inventory and how
+// many eligible items already had one
+type ConvertResult struct {
+	Created []*ShopSurplusItem `json:"created"`
+	Skipped int                `json:"skipped"`
+}
+
+// dueReminder is a pending item whose pickup is close, with what the reminder
+// needs to say
+type dueReminder struct {
+	item     ShopSurplusItem
+	shopName string
+}
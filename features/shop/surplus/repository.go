@@ -0,0 +1,286 @@
+package surplus
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+const surplusColumns = `id, user_id, sku_id, sku_name, quantity, unit, expiry_window_start, expiry_window_end, condition, COALESCE(destination_type, ''), COALESCE(destination_name, ''), destination_user_id, COALESCE(status, 'pending'), pickup_time, COALESCE(reminder_sent, FALSE), picked_at, COALESCE(image_data, ''), created_at, updated_at`
+
+// GetAllByUserID lists a shop's surplus items, soonest expiry first,
+// optionally filtered by status
+func (r *Repository) GetAllByUserID(userID uuid.UUID, status string) ([]*ShopSurplusItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT ` + surplusColumns + ` FROM shop_surplus_items
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY expiry_window_end ASC, sku_name ASC`
+	rows, err := r.db.Query(query, userID, status)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return scanItems(rows)
+}
+
+func (r *Repository) GetByID(id uuid.UUID) (*ShopSurplusItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	item, err := scanItem(r.db.QueryRow(`SELECT `+surplusColumns+` FROM shop_surplus_items WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return item, nil
+}
+
+func (r *Repository) Create(item *ShopSurplusItem) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `INSERT INTO shop_surplus_items (id, user_id, sku_name, quantity, unit, expiry_window_start, expiry_window_end, condition, status, reminder_sent, image_data, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, FALSE, NULLIF($10, ''), $11, $12) RETURNING ` + surplusColumns
+	now := time.Now()
+	created, err := scanItem(r.db.QueryRow(query, item.ID, item.UserID, item.SKUName, item.Quantity, item.Unit, item.ExpiryWindowStart, item.ExpiryWindowEnd, item.Condition, StatusPending, item.Image, now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*item = *created
+	return nil
+}
+
+// ConvertFromInventory creates a near-expiry surplus entry for each of a
+// shop's surplus-eligible, in-stock items expiring between now and until,
+// covering its whole stock. The converted quantity leaves the item's stock in
+// the same transaction, so it can neither be sold nor converted again. Items
+// that already have a pending entry are skipped and counted.
+func (r *Repository) ConvertFromInventory(userID uuid.UUID, now, until time.Time) ([]*ShopSurplusItem, int, error) {
+	if r.db == nil {
+		return nil, 0, errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	eligible := `
+		FROM shop_inventory_items
+		WHERE user_id = $1 AND COALESCE(surplus_eligible, FALSE)
+			AND stock_quantity > 0 AND expiry_date > $2 AND expiry_date <= $3`
+	var total int
+	if err := tx.QueryRow(`SELECT COUNT(*) `+eligible, userID, now, until).Scan(&total); err != nil {
+		return nil, 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	query := `
+		WITH converted AS (
+			INSERT INTO shop_surplus_items (id, user_id, sku_id, sku_name, quantity, unit, expiry_window_start, expiry_window_end, condition, status, reminder_sent, image_data, created_at, updated_at)
+			SELECT uuid_generate_v4(), user_id, id, name, stock_quantity, unit, $2, expiry_date, $4, $5, FALSE, image_data, $2, $2
+			` + eligible + `
+			ON CONFLICT (sku_id) WHERE status = 'pending' DO NOTHING
+			RETURNING *
+		), drained AS (
+			UPDATE shop_inventory_items i
+			SET stock_quantity = GREATEST(i.stock_quantity - c.quantity, 0), updated_at = $2
+			FROM converted c WHERE i.id = c.sku_id
+		)
+		SELECT ` + surplusColumns + ` FROM converted`
+	rows, err := tx.Query(query, userID, now, until, ConditionNearExpiry, StatusPending)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	created, err := scanItems(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return created, total - len(created), nil
+}
+
+// Assign sets a pending item's destination and pickup time. A new pickup
+// time gets a new reminder.
+func (r *Repository) Assign(item *ShopSurplusItem) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `UPDATE shop_surplus_items SET destination_type = $1, destination_name = NULLIF($2, ''), destination_user_id = $3, pickup_time = $4, reminder_sent = FALSE, updated_at = $5 WHERE id = $6 AND status = $7 RETURNING ` + surplusColumns
+	updated, err := scanItem(r.db.QueryRow(query, item.DestinationType, item.DestinationName, item.DestinationUserID, item.PickupTime, time.Now(), item.ID, StatusPending))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errNotPending
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*item = *updated
+	return nil
+}
+
+// MarkPicked records that a pending item was collected. Converted stock
+// already left inventory when the item was created.
+func (r *Repository) MarkPicked(id uuid.UUID, now time.Time) (*ShopSurplusItem, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `UPDATE shop_surplus_items SET status = $1, picked_at = $2, updated_at = $2 WHERE id = $3 AND status = $4 RETURNING ` + surplusColumns
+	item, err := scanItem(r.db.QueryRow(query, StatusPicked, now, id, StatusPending))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errNotPending
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return item, nil
+}
+
+// Delete removes a surplus item. A pending item converted from inventory
+// hands its quantity back to the item's stock.
+func (r *Repository) Delete(id uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	var skuID *uuid.UUID
+	var quantity float64
+	var status string
+	err = tx.QueryRow(`DELETE FROM shop_surplus_items WHERE id = $1 RETURNING sku_id, quantity, COALESCE(status, 'pending')`, id).Scan(&skuID, &quantity, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if skuID != nil && status == StatusPending {
+		_, err = tx.Exec(`UPDATE shop_inventory_items SET stock_quantity = stock_quantity + $1, updated_at = $2 WHERE id = $3`, quantity, time.Now(), *skuID)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// GetDestination returns a user's role and the name surplus handed to them
+// goes by: an NGO's organisation name, otherwise the user's own
+func (r *Repository) GetDestination(userID uuid.UUID) (role, name string, err error) {
+	if r.db == nil {
+		return "", "", errors.ErrDatabase
+	}
+	query := `
+		SELECT COALESCE(u.role, ''), COALESCE(n.org_name, u.name)
+		FROM users u
+		LEFT JOIN ngo_capacity_settings n ON n.user_id = u.id
+		WHERE u.id = $1`
+	if err := r.db.QueryRow(query, userID).Scan(&role, &name); err != nil {
+		if err == sql.ErrNoRows {
+			return "", "", errors.ErrNotFound
+		}
+		return "", "", errors.WrapError(err, errors.ErrDatabase)
+	}
+	return role, name, nil
+}
+
+// ClaimDueReminders marks up to limit pending items whose pickup falls between
+// now and until as reminded and returns them, so each reminder goes out once
+// even with several instances running
+func (r *Repository) ClaimDueReminders(now, until time.Time, limit int) ([]*dueReminder, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		WITH due AS (
+			SELECT id FROM shop_surplus_items
+			WHERE status = $1 AND NOT reminder_sent AND destination_user_id IS NOT NULL
+				AND pickup_time > $2 AND pickup_time <= $3
+			ORDER BY pickup_time
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE shop_surplus_items s SET reminder_sent = TRUE
+			FROM due WHERE s.id = due.id
+			RETURNING s.*
+		)
+		SELECT ` + surplusColumns + `, COALESCE((SELECT store_name FROM shop_profiles p WHERE p.user_id = claimed.user_id), '')
+		FROM claimed`
+	rows, err := r.db.Query(query, StatusPending, now, until, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	reminders := []*dueReminder{}
+	for rows.Next() {
+		d := &dueReminder{}
+		i := &d.item
+		if err := rows.Scan(&i.ID, &i.UserID, &i.SKUID, &i.SKUName, &i.Quantity, &i.Unit, &i.ExpiryWindowStart, &i.ExpiryWindowEnd, &i.Condition, &i.DestinationType, &i.DestinationName, &i.DestinationUserID, &i.Status, &i.PickupTime, &i.ReminderSent, &i.PickedAt, &i.Image, &i.CreatedAt, &i.UpdatedAt, &d.shopName); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		reminders = append(reminders, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return reminders, nil
+}
+
+// ExpireOverdue expires pending items whose expiry window ended before now
+func (r *Repository) ExpireOverdue(now time.Time) (int64, error) {
+	if r.db == nil {
+		return 0, errors.ErrDatabase
+	}
+	result, err := r.db.Exec(`UPDATE shop_surplus_items SET status = $1, updated_at = $2 WHERE status = $3 AND expiry_window_end < $2`, StatusExpired, now, StatusPending)
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return result.RowsAffected()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanItem(row rowScanner) (*ShopSurplusItem, error) {
+	i := &ShopSurplusItem{}
+	if err := row.Scan(&i.ID, &i.UserID, &i.SKUID, &i.SKUName, &i.Quantity, &i.Unit, &i.ExpiryWindowStart, &i.ExpiryWindowEnd, &i.Condition, &i.DestinationType, &i.DestinationName, &i.DestinationUserID, &i.Status, &i.PickupTime, &i.ReminderSent, &i.PickedAt, &i.Image, &i.CreatedAt, &i.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func scanItems(rows *sql.Rows) ([]*ShopSurplusItem, error) {
+	defer rows.Close()
+	items := []*ShopSurplusItem{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return items, nil
+}
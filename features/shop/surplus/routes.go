@@ -0,0 +1,35 @@
+package surplus
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		pathParts := strings.Split(path, "/")
+
+		switch {
+		case path == "" && r.Method == http.MethodGet:
+			handler.GetAll(w, r)
+		case path == "" && r.Method == http.MethodPost:
+			handler.Create(w, r)
+		case path == "convert" && r.Method == http.MethodPost:
+			handler.Convert(w, r)
+		case len(pathParts) == 2 && pathParts[1] == "assign" && r.Method == http.MethodPut:
+			handler.Assign(w, r)
+		case len(pathParts) == 2 && pathParts[1] == "picked" && r.Method == http.MethodPost:
+			handler.MarkPicked(w, r)
+		case len(pathParts) == 1 && r.Method == http.MethodGet:
+			handler.GetByID(w, r)
+		case len(pathParts) == 1 && r.Method == http.MethodDelete:
+			handler.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
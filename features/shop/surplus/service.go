@@ -0,0 +1,230 @@
+package surplus
+
+import (
+	"context"
+	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/features/uploads"
+	"foodlink_backend/utils"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errNotPending          = errors.NewAppError(http.StatusConflict, "Only pending surplus items can be assigned or picked up")
+	errInvalidWindow       = errors.NewAppError(http.StatusBadRequest, "Validation failed: expiry_window_end must be after expiry_window_start")
+	errPickupTime          = errors.NewAppError(http.StatusBadRequest, "Validation failed: pickup_time must be in the future and before the expiry window ends")
+	errDestinationName     = errors.NewAppError(http.StatusBadRequest, "Validation failed: destination_name is required without destination_user_id")
+	errUnknownDestination  = errors.NewAppError(http.StatusBadRequest, "Validation failed: destination_user_id is not a registered user")
+	errDestinationMismatch = errors.NewAppError(http.StatusBadRequest, "Validation failed: destination_user_id does not match destination_type")
+)
+
+// destinationRoles are the accounts each destination type can be handed to.
+// Community kitchens are run from household or restaurant accounts.
+var destinationRoles = map[string][]string{
+	DestinationNGO:              {auth.RoleNGO},
+	DestinationCommunityKitchen: {auth.RoleFamily, auth.RoleRestaurant},
+}
+
+const (
+	// defaultConvertDays is how far ahead ConvertFromInventory looks for
+	// expiring stock when no number of days is given
+	defaultConvertDays = 2
+	// reminderBatch is how many reminders one run claims at a time
+	reminderBatch = 100
+)
+
+type Service struct {
+	repo   *Repository
+	images *uploads.Linker
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository(), images: uploads.NewLinker()}
+}
+
+func (s *Service) GetAllByUserID(userID uuid.UUID, status string) ([]*ShopSurplusItem, error) {
+	return s.repo.GetAllByUserID(userID, status)
+}
+
+func (s *Service) GetByID(id, userID uuid.UUID) (*ShopSurplusItem, error) {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	return item, nil
+}
+
+func (s *Service) Create(userID uuid.UUID, req *CreateShopSurplusItemRequest) (*ShopSurplusItem, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if !req.ExpiryWindowEnd.After(req.ExpiryWindowStart) {
+		return nil, errInvalidWindow
+	}
+	item := &ShopSurplusItem{
+		ID:                uuid.New(),
+		UserID:            userID,
+		SKUName:           req.SKUName,
+		Quantity:          req.Quantity,
+		Unit:              req.Unit,
+		ExpiryWindowStart: req.ExpiryWindowStart,
+		ExpiryWindowEnd:   req.ExpiryWindowEnd,
+		Condition:         req.Condition,
+	}
+	image, err := s.images.Resolve(userID, req.Image, uploads.AssociatedShopSurplus, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	item.Image = image
+	if err := s.repo.Create(item); err != nil {
+		return nil, err
+	}
+	if err := s.images.Sync(item.Image, uploads.AssociatedShopSurplus, item.ID); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ConvertFromInventory turns the shop's surplus-eligible stock expiring in
+// the next few days, two by default, into pending surplus entries
+func (s *Service) ConvertFromInventory(userID uuid.UUID, days int) (*ConvertResult, error) {
+	if days <= 0 {
+		days = defaultConvertDays
+	}
+	now := time.Now()
+	created, skipped, err := s.repo.ConvertFromInventory(userID, now, now.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+	return &ConvertResult{Created: created, Skipped: skipped}, nil
+}
+
+// Assign hands a pending item to an NGO or community kitchen for pickup.
+// Naming a registered destination user means they are reminded before
+// pickup_time.
+func (s *Service) Assign(id, userID uuid.UUID, req *AssignShopSurplusItemRequest) (*ShopSurplusItem, error) {
+	item, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != StatusPending {
+		return nil, errNotPending
+	}
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if !req.PickupTime.After(time.Now()) || req.PickupTime.After(item.ExpiryWindowEnd) {
+		return nil, errPickupTime
+	}
+	name := strings.TrimSpace(req.DestinationName)
+	if req.DestinationUserID != nil {
+		role, destinationName, err := s.repo.GetDestination(*req.DestinationUserID)
+		if err == errors.ErrNotFound {
+			return nil, errUnknownDestination
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !hasRole(destinationRoles[req.DestinationType], role) {
+			return nil, errDestinationMismatch
+		}
+		if name == "" {
+			name = destinationName
+		}
+	}
+	if name == "" {
+		return nil, errDestinationName
+	}
+	pickupTime := req.PickupTime
+	item.DestinationType = req.DestinationType
+	item.DestinationName = name
+	item.DestinationUserID = req.DestinationUserID
+	item.PickupTime = &pickupTime
+	if err := s.repo.Assign(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// MarkPicked records that a pending item was collected by its destination
+func (s *Service) MarkPicked(id, userID uuid.UUID) (*ShopSurplusItem, error) {
+	if _, err := s.GetByID(id, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.MarkPicked(id, time.Now())
+}
+
+func (s *Service) Delete(id, userID uuid.UUID) error {
+	if _, err := s.GetByID(id, userID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	return s.images.Release(uploads.AssociatedShopSurplus, id)
+}
+
+// SendReminders announces each pending pickup due within lead whose
+// destination has not been reminded yet, a batch at a time until none are
+// left. Notifications turn the announcements into reminders.
+func (s *Service) SendReminders(ctx context.Context, lead time.Duration) error {
+	for ctx.Err() == nil {
+		now := time.Now()
+		due, err := s.repo.ClaimDueReminders(now, now.Add(lead), reminderBatch)
+		if err != nil {
+			return err
+		}
+		for _, d := range due {
+			events.Publish(events.ShopSurplusPickupDue{
+				Meta:              events.NewMeta(),
+				ShopUserID:        d.item.UserID,
+				SurplusItemID:     d.item.ID,
+				DestinationUserID: *d.item.DestinationUserID,
+				DestinationType:   d.item.DestinationType,
+				ShopName:          d.shopName,
+				SKUName:           d.item.SKUName,
+				Quantity:          d.item.Quantity,
+				Unit:              d.item.Unit,
+				PickupTime:        *d.item.PickupTime,
+			})
+		}
+		if len(due) > 0 {
+			log.Printf("Sent %d shop surplus pickup reminders", len(due))
+		}
+		if len(due) < reminderBatch {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// ExpireOverdue expires pending items whose expiry window has passed without
+// a pickup
+func (s *Service) ExpireOverdue(ctx context.Context) error {
+	count, err := s.repo.ExpireOverdue(time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("Expired %d shop surplus items that were not picked up", count)
+	}
+	return nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
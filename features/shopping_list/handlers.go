@@ -24,13 +24,8 @@ func (h *Handler) getUserAndHousehold(r *http.Request) (uuid.UUID, *uuid.UUID, e
 	if !ok || user == nil {
 		return uuid.Nil, nil, errors.ErrUnauthorized
 	}
-	userID := user.ID
-	if user.HouseholdID != nil {
-		return userID, user.HouseholdID, nil
-	}
-	// fall back to user id as household
-	hh := userID
-	return userID, &hh, nil
+	// Items created outside a household are moved into one when the user joins
+	return user.ID, user.HouseholdID, nil
 }
 
 // GetAll handles GET /api/v1/shopping-list
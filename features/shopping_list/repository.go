@@ -26,7 +26,7 @@ func (r *Repository) GetAllByUserID(userID uuid.UUID, includePurchased bool) ([]
 	query := `
 		SELECT id, user_id, household_id, name, quantity, unit, category, priority, purchased, purchased_at, estimated_price, created_at, updated_at
 		FROM shopping_list_items
-		WHERE user_id = $1
+		WHERE ` + database.HouseholdScope("user_id", 1) + `
 	`
 	args := []interface{}{userID}
 	if !includePurchased {
@@ -219,7 +219,7 @@ func (r *Repository) ListUnpurchasedNamesLower(userID uuid.UUID) (map[string]str
 	if r.db == nil {
 		return nil, errors.ErrDatabase
 	}
-	rows, err := r.db.Query(`SELECT name FROM shopping_list_items WHERE user_id=$1 AND purchased=FALSE`, userID)
+	rows, err := r.db.Query(`SELECT name FROM shopping_list_items WHERE `+database.HouseholdScope("user_id", 1)+` AND purchased=FALSE`, userID)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
@@ -245,7 +245,7 @@ func (r *Repository) GetLowStockInventoryNames(userID uuid.UUID) ([]struct {
 		return nil, errors.ErrDatabase
 	}
 
-	rows, err := r.db.Query(`SELECT name, unit, category FROM inventory_items WHERE user_id=$1 AND quantity < 1`, userID)
+	rows, err := r.db.Query(`SELECT name, unit, category FROM inventory_items WHERE `+database.HouseholdScope("user_id", 1)+` AND quantity < 1`, userID)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrDatabase)
 	}
@@ -269,4 +269,3 @@ func (r *Repository) GetLowStockInventoryNames(userID uuid.UUID) ([]struct {
 	}
 	return out, nil
 }
-
@@ -1,6 +1,7 @@
 package shopping_list
 
 import (
+	"foodlink_backend/database"
 	"foodlink_backend/errors"
 	"foodlink_backend/utils"
 	"strings"
@@ -57,8 +58,8 @@ func (s *Service) Update(id uuid.UUID, userID uuid.UUID, req *UpdateShoppingList
 	if err != nil {
 		return nil, err
 	}
-	if item.UserID != userID {
-		return nil, errors.ErrForbidden
+	if err := database.CheckHouseholdAccess(s.repo.db, item.UserID, userID); err != nil {
+		return nil, err
 	}
 	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
 		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
@@ -107,8 +108,8 @@ func (s *Service) Delete(id uuid.UUID, userID uuid.UUID) error {
 	if err != nil {
 		return err
 	}
-	if item.UserID != userID {
-		return errors.ErrForbidden
+	if err := database.CheckHouseholdAccess(s.repo.db, item.UserID, userID); err != nil {
+		return err
 	}
 	return s.repo.Delete(id)
 }
@@ -118,8 +119,8 @@ func (s *Service) TogglePurchased(id uuid.UUID, userID uuid.UUID) (*ShoppingList
 	if err != nil {
 		return nil, err
 	}
-	if item.UserID != userID {
-		return nil, errors.ErrForbidden
+	if err := database.CheckHouseholdAccess(s.repo.db, item.UserID, userID); err != nil {
+		return nil, err
 	}
 	item.Purchased = !item.Purchased
 	if item.Purchased {
@@ -180,4 +181,3 @@ func (s *Service) ComputeMissingFromInventory(userID uuid.UUID, householdID *uui
 	// Return updated list (unpurchased)
 	return s.repo.GetAllByUserID(userID, false)
 }
-
@@ -0,0 +1,29 @@
+package stream
+
+import (
+	"context"
+	"foodlink_backend/events"
+
+	"github.com/google/uuid"
+)
+
+const eventSubscriber = "stream"
+
+// RegisterEventHandlers pushes the events a user cares about to their open
+// streams, each named after its domain event. Users who are not connected
+// receive them from the replay buffer when they reconnect.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	forward(s, bus, func(e events.NotificationCreated) uuid.UUID { return e.UserID })
+	forward(s, bus, func(e events.SurplusRequestCreated) uuid.UUID { return e.OwnerID })
+	forward(s, bus, func(e events.SurplusRequestApproved) uuid.UUID { return e.RequesterID })
+	forward(s, bus, func(e events.LeftoverClaimed) uuid.UUID { return e.OwnerID })
+	forward(s, bus, func(e events.OfferCreated) uuid.UUID { return e.NGOUserID })
+	forward(s, bus, func(e events.PickupStatusChanged) uuid.UUID { return e.NGOUserID })
+}
+
+// forward sends every event of type T to the user recipient picks
+func forward[T events.Event](s *Service, bus *events.Bus, recipient func(T) uuid.UUID) {
+	events.On(bus, eventSubscriber, func(ctx context.Context, e T) error {
+		return s.hub.Publish(recipient(e), e.EventName(), e)
+	})
+}
@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"fmt"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// heartbeatInterval keeps idle connections open through proxies
+const heartbeatInterval = 25 * time.Second
+
+// reconnectDelayMS is how long clients wait before reconnecting
+const reconnectDelayMS = 3000
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// Stream handles GET /api/v1/stream
+// @Summary      Live event stream
+// @Description  Server-Sent Events for the authenticated user: new notifications, surplus requests and claims, NGO offers and pickup status changes. Events carry IDs; reconnect with Last-Event-ID to receive missed events. A resync event means some were lost and the client should reload.
+// @Tags         stream
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        Last-Event-ID  header    string  false  "ID of the last event received"
+// @Success      200            {string}  string  "Event stream"
+// @Failure      401            {object}  errors.AppError
+// @Failure      503            {object}  errors.AppError
+// @Router       /stream [get]
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.InternalServerErrorResponse(w, "Streaming is not supported", nil)
+		return
+	}
+
+	var lastEventID *uint64
+	if id, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64); err == nil {
+		lastEventID = &id
+	}
+	sub, replay, resync, err := h.service.Subscribe(userID, lastEventID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusServiceUnavailable, "Server is shutting down", nil)
+		return
+	}
+	defer h.service.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", reconnectDelayMS)
+	if resync {
+		fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+	}
+	for _, msg := range replay {
+		if err := writeMessage(w, msg); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := writeMessage(w, msg); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeMessage(w http.ResponseWriter, msg Message) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, msg.Data)
+	return err
+}
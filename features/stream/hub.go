@@ -0,0 +1,219 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is one server-sent event for a user
+type Message struct {
+	ID    uint64
+	Event string
+	Data  json.RawMessage
+}
+
+var errHubClosed = fmt.Errorf("stream hub is closed")
+
+// Hub fans messages out to each user's open streams. It keeps the latest
+// messages of every user so a client that reconnects with Last-Event-ID
+// receives what it missed.
+type Hub struct {
+	mu     sync.Mutex
+	users  map[uuid.UUID]*userStream
+	closed bool
+	done   chan struct{}
+
+	replaySize int
+	idleTTL    time.Duration
+}
+
+// userStream holds one user's subscribers and replay buffer. Message IDs start
+// from the stream's creation time in milliseconds, so they keep increasing
+// when the stream is recreated or the server restarts.
+type userStream struct {
+	nextID   uint64
+	replay   []Message
+	subs     map[*Subscription]struct{}
+	lastUsed time.Time
+}
+
+// Subscription is one open stream. C is closed when the hub shuts down or the
+// subscriber falls too far behind; the client is expected to reconnect.
+type Subscription struct {
+	C      <-chan Message
+	c      chan Message
+	userID uuid.UUID
+}
+
+// subscriberBuffer is how many messages a subscriber may fall behind before
+// it is dropped
+const subscriberBuffer = 32
+
+// NewHub creates a hub keeping up to replaySize messages per user. Users
+// without open streams are forgotten after idleTTL.
+func NewHub(replaySize int, idleTTL time.Duration) *Hub {
+	if replaySize <= 0 {
+		replaySize = 100
+	}
+	if idleTTL <= 0 {
+		idleTTL = 10 * time.Minute
+	}
+	h := &Hub{
+		users:      make(map[uuid.UUID]*userStream),
+		done:       make(chan struct{}),
+		replaySize: replaySize,
+		idleTTL:    idleTTL,
+	}
+	go h.prune()
+	return h
+}
+
+// Publish sends a message to every open stream of a user and keeps it for
+// replay. Subscribers whose buffer is full are dropped rather than blocking
+// the publisher.
+func (h *Hub) Publish(userID uuid.UUID, event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	us := h.stream(userID)
+	msg := Message{ID: us.nextID, Event: event, Data: data}
+	us.nextID++
+	us.replay = append(us.replay, msg)
+	if len(us.replay) > h.replaySize {
+		us.replay = append([]Message(nil), us.replay[len(us.replay)-h.replaySize:]...)
+	}
+	us.lastUsed = time.Now()
+
+	for sub := range us.subs {
+		select {
+		case sub.c <- msg:
+		default:
+			delete(us.subs, sub)
+			close(sub.c)
+		}
+	}
+	return nil
+}
+
+// Subscribe opens a stream for a user. With lastEventID it also returns the
+// buffered messages after that ID; resync is true when some of the messages
+// since then are no longer buffered and the client should reload its state.
+func (h *Hub) Subscribe(userID uuid.UUID, lastEventID *uint64) (sub *Subscription, replay []Message, resync bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, nil, false, errHubClosed
+	}
+
+	us := h.stream(userID)
+	if lastEventID != nil {
+		oldest := us.nextID
+		if len(us.replay) > 0 {
+			oldest = us.replay[0].ID
+		}
+		resync = *lastEventID+1 < oldest || *lastEventID >= us.nextID
+		for _, msg := range us.replay {
+			if msg.ID > *lastEventID {
+				replay = append(replay, msg)
+			}
+		}
+	}
+
+	c := make(chan Message, subscriberBuffer)
+	sub = &Subscription{C: c, c: c, userID: userID}
+	us.subs[sub] = struct{}{}
+	us.lastUsed = time.Now()
+	return sub, replay, resync, nil
+}
+
+// Unsubscribe closes a stream. It is safe to call after the hub has dropped
+// the subscriber or shut down.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	us, ok := h.users[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := us.subs[sub]; ok {
+		delete(us.subs, sub)
+		close(sub.c)
+	}
+	us.lastUsed = time.Now()
+}
+
+// Close ends every open stream and stops accepting new ones. Call it when the
+// server begins shutting down so streaming handlers return.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	close(h.done)
+	for _, us := range h.users {
+		for sub := range us.subs {
+			close(sub.c)
+		}
+		us.subs = nil
+	}
+	h.users = map[uuid.UUID]*userStream{}
+}
+
+// stream returns a user's stream, creating it if needed. h.mu must be held.
+func (h *Hub) stream(userID uuid.UUID) *userStream {
+	us, ok := h.users[userID]
+	if !ok {
+		us = &userStream{
+			nextID:   uint64(time.Now().UnixMilli()),
+			subs:     make(map[*Subscription]struct{}),
+			lastUsed: time.Now(),
+		}
+		h.users[userID] = us
+	}
+	return us
+}
+
+// prune forgets users that have had no open stream for idleTTL
+func (h *Hub) prune() {
+	ticker := time.NewTicker(h.idleTTL / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.done:
+			return
+		case now := <-ticker.C:
+			h.mu.Lock()
+			for userID, us := range h.users {
+				if len(us.subs) == 0 && now.Sub(us.lastUsed) > h.idleTTL {
+					delete(h.users, userID)
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+var defaultHub = NewHub(0, 0)
+
+// Default returns the process-wide hub
+func Default() *Hub {
+	return defaultHub
+}
+
+// Shutdown closes the default hub
+func Shutdown() {
+	defaultHub.Close()
+}
@@ -0,0 +1,168 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func publishN(t *testing.T, h *Hub, userID uuid.UUID, n int) []uint64 {
+	t.Helper()
+	sub, _, _, err := h.Subscribe(userID, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer h.Unsubscribe(sub)
+	ids := make([]uint64, n)
+	for i := range ids {
+		if err := h.Publish(userID, "test", i); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		ids[i] = (<-sub.C).ID
+	}
+	return ids
+}
+
+func TestSubscribeReplaysAfterLastEventID(t *testing.T) {
+	h := NewHub(10, time.Minute)
+	defer h.Close()
+	userID := uuid.New()
+	ids := publishN(t, h, userID, 3)
+	for i := 1; i < len(ids); i++ {
+		if ids[i] != ids[i-1]+1 {
+			t.Fatalf("IDs %v are not consecutive", ids)
+		}
+	}
+
+	sub, replay, resync, err := h.Subscribe(userID, &ids[0])
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer h.Unsubscribe(sub)
+	if resync {
+		t.Fatal("resync = true with every missed message buffered")
+	}
+	if len(replay) != 2 || replay[0].ID != ids[1] || replay[1].ID != ids[2] {
+		t.Fatalf("replay = %v, want messages %v", replay, ids[1:])
+	}
+
+	_, replay, resync, _ = h.Subscribe(userID, &ids[2])
+	if resync || len(replay) != 0 {
+		t.Fatalf("up-to-date client got replay %v, resync %v", replay, resync)
+	}
+}
+
+func TestSubscribeResyncsWhenBufferTrimmed(t *testing.T) {
+	h := NewHub(2, time.Minute)
+	defer h.Close()
+	userID := uuid.New()
+	ids := publishN(t, h, userID, 5)
+
+	sub, replay, resync, err := h.Subscribe(userID, &ids[0])
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer h.Unsubscribe(sub)
+	if !resync {
+		t.Fatal("resync = false after messages were trimmed")
+	}
+	if len(replay) != 2 || replay[0].ID != ids[3] || replay[1].ID != ids[4] {
+		t.Fatalf("replay = %v, want the buffered messages %v", replay, ids[3:])
+	}
+
+	// The oldest buffered message directly follows ids[2], so nothing was lost
+	_, _, resync, _ = h.Subscribe(userID, &ids[2])
+	if resync {
+		t.Fatal("resync = true though nothing was trimmed since the last event")
+	}
+}
+
+func TestSubscribeResyncsWhenStreamRecreated(t *testing.T) {
+	userID := uuid.New()
+	before := NewHub(10, time.Minute)
+	ids := publishN(t, before, userID, 3)
+	before.Close()
+	time.Sleep(5 * time.Millisecond)
+
+	// A restarted server seeds IDs from the clock, past anything sent before
+	after := NewHub(10, time.Minute)
+	defer after.Close()
+	later := publishN(t, after, userID, 1)
+	if later[0] <= ids[2] {
+		t.Fatalf("ID after restart = %d, want more than %d", later[0], ids[2])
+	}
+	_, replay, resync, _ := after.Subscribe(userID, &ids[0])
+	if !resync {
+		t.Fatal("resync = false for an ID from before the restart")
+	}
+	if len(replay) != 1 || replay[0].ID != later[0] {
+		t.Fatalf("replay = %v, want the message sent since the restart", replay)
+	}
+
+	// An ID the stream has not issued yet cannot be trusted either
+	future := later[0] + 100
+	_, replay, resync, _ = after.Subscribe(userID, &future)
+	if !resync || len(replay) != 0 {
+		t.Fatalf("future ID got replay %v, resync %v; want resync", replay, resync)
+	}
+}
+
+func TestPublishDropsFullSubscriber(t *testing.T) {
+	h := NewHub(10, time.Minute)
+	defer h.Close()
+	userID := uuid.New()
+	slow, _, _, err := h.Subscribe(userID, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	fast, _, _, _ := h.Subscribe(userID, nil)
+	defer h.Unsubscribe(fast)
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		if err := h.Publish(userID, "test", i); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		<-fast.C
+	}
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Fatalf("slow subscriber received %d messages before being dropped, want %d", received, subscriberBuffer)
+	}
+	// Unsubscribing a dropped subscriber must not close its channel again
+	h.Unsubscribe(slow)
+
+	if err := h.Publish(userID, "test", "after"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if msg, ok := <-fast.C; !ok || msg.Event != "test" {
+		t.Fatalf("fast subscriber got %v, %v after the slow one was dropped", msg, ok)
+	}
+}
+
+func TestUnsubscribeAfterClose(t *testing.T) {
+	h := NewHub(10, time.Minute)
+	userID := uuid.New()
+	sub, _, _, err := h.Subscribe(userID, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	h.Close()
+	if _, ok := <-sub.C; ok {
+		t.Fatal("subscription channel still open after Close")
+	}
+	h.Unsubscribe(sub)
+	h.Close()
+
+	if err := h.Publish(userID, "test", 1); err != nil {
+		t.Fatalf("Publish() after Close error = %v", err)
+	}
+	if _, _, _, err := h.Subscribe(userID, nil); err != errHubClosed {
+		t.Fatalf("Subscribe() after Close error = %v, want errHubClosed", err)
+	}
+}
@@ -0,0 +1,23 @@
+package stream
+
+import (
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	hub *Hub
+}
+
+func NewService() *Service {
+	return &Service{hub: Default()}
+}
+
+// Subscribe opens a stream for a user, replaying what it missed since
+// lastEventID
+func (s *Service) Subscribe(userID uuid.UUID, lastEventID *uint64) (*Subscription, []Message, bool, error) {
+	return s.hub.Subscribe(userID, lastEventID)
+}
+
+func (s *Service) Unsubscribe(sub *Subscription) {
+	s.hub.Unsubscribe(sub)
+}
@@ -0,0 +1,254 @@
+package uploads
+
+import (
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// multipartOverhead is room for the multipart boundaries and headers around
+// the file itself
+const multipartOverhead = 1 << 20
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// Create handles POST /api/v1/uploads
+// @Summary      Upload a file
+// @Description  Upload an image (JPEG, PNG, GIF, WebP) or PDF as the multipart field "file". The type is detected from the contents. The returned ID can be sent in place of an image URL when creating leftovers, surplus posts, restaurant and shop inventory items, restaurant surplus items, and NGO feedback or stories.
+// @Tags         uploads
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file  formData  file  true  "File to upload"
+// @Success      201   {object}  Upload
+// @Failure      400   {object}  errors.AppError
+// @Failure      401   {object}  errors.AppError
+// @Failure      413   {object}  errors.AppError
+// @Failure      415   {object}  errors.AppError
+// @Router       /uploads [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	maxBytes := h.service.MaxBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		if _, ok := err.(*http.MaxBytesError); ok {
+			tooLarge := h.service.errTooLarge()
+			utils.ErrorResponse(w, tooLarge.Code, tooLarge.Message, nil)
+			return
+		}
+		utils.BadRequestResponse(w, "Invalid request body", "expected a multipart form with a file field")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	upload, err := h.service.Create(userID, header.Filename, data)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to store upload", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "File uploaded successfully", upload)
+}
+
+// GetAll handles GET /api/v1/uploads
+// @Summary      List uploads
+// @Description  List the authenticated user's uploads, newest first, each with a signed download URL
+// @Tags         uploads
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   Upload
+// @Failure      401  {object}  errors.AppError
+// @Router       /uploads [get]
+func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	uploads, err := h.service.GetAllByUserID(userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve uploads", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Uploads retrieved successfully", uploads)
+}
+
+// GetByID handles GET /api/v1/uploads/:id
+// @Summary      Get upload
+// @Description  Get an upload with a fresh signed download URL. Image fields that reference an upload hold this path. Other users can only see uploads shown on a leftover, surplus post or restaurant surplus listing.
+// @Tags         uploads
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Upload ID"
+// @Success      200  {object}  Upload
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /uploads/{id} [get]
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	upload, err := h.service.GetByID(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve upload", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Upload retrieved successfully", upload)
+}
+
+// Delete handles DELETE /api/v1/uploads/:id
+// @Summary      Delete upload
+// @Description  Delete one of the authenticated user's uploads. Uploads still shown on an item cannot be deleted; replacing the image or deleting the item frees them.
+// @Tags         uploads
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Upload ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Failure      409  {object}  errors.AppError
+// @Router       /uploads/{id} [delete]
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.Delete(id, userID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to delete upload", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Upload deleted successfully", map[string]string{"message": "Deleted"})
+}
+
+// Content handles GET /api/v1/files/:id
+// @Summary      Download upload
+// @Description  Download an upload's contents through a signed URL from the uploads API. No other authentication is needed; the URL stops working when it expires.
+// @Tags         uploads
+// @Produce      application/octet-stream
+// @Param        id         path      string  true  "Upload ID"
+// @Param        expires    query     int     true  "Expiry as a Unix timestamp"
+// @Param        signature  query     string  true  "URL signature"
+// @Success      200        {file}    file
+// @Failure      400        {object}  errors.AppError
+// @Failure      403        {object}  errors.AppError
+// @Failure      404        {object}  errors.AppError
+// @Router       /files/{id} [get]
+func (h *Handler) Content(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	query := r.URL.Query()
+	upload, contents, err := h.service.Open(id, query.Get("expires"), query.Get("signature"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to read upload", err.Error())
+		return
+	}
+	defer contents.Close()
+
+	w.Header().Set("Content-Type", upload.FileType)
+	w.Header().Set("Content-Length", strconv.FormatInt(upload.FileSize, 10))
+	w.Header().Set("Content-Disposition", `inline; filename="`+upload.FileName+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if upload.SHA256 != "" {
+		w.Header().Set("ETag", `"`+upload.SHA256+`"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, contents); err != nil {
+		log.Printf("Warning: failed to send upload %s: %v", upload.ID, err)
+	}
+}
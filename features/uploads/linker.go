@@ -0,0 +1,74 @@
+package uploads
+
+import (
+	"foodlink_backend/errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// referencePrefix starts the value stored in an image field that points at an
+// upload. Clients fetch it, authenticated, for a signed download URL.
+const referencePrefix = "/api/v1/uploads/"
+
+// Reference returns the value image fields store for an upload
+func Reference(id uuid.UUID) string {
+	return referencePrefix + id.String()
+}
+
+// Linker lets other features take upload IDs in their image fields
+type Linker struct {
+	repo *Repository
+}
+
+func NewLinker() *Linker {
+	return &Linker{repo: NewRepository()}
+}
+
+// Resolve checks an image field before its item is saved. A value that is an
+// upload ID, or a reference to one, must be the user's own upload and free to
+// show on the item; it is replaced by the upload's reference. Any other value,
+// such as an external image URL, is returned unchanged. Nothing is attached
+// until Sync runs once the item is saved.
+func (l *Linker) Resolve(userID uuid.UUID, image, associatedType string, associatedID uuid.UUID) (string, error) {
+	id, ok := referencedID(image)
+	if !ok {
+		return image, nil
+	}
+	upload, err := l.repo.GetByID(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return "", errUploadNotFound
+		}
+		return "", err
+	}
+	if upload.UserID != userID {
+		return "", errors.ErrForbidden
+	}
+	if upload.AssociatedID != nil && (upload.AssociatedType != associatedType || *upload.AssociatedID != associatedID) {
+		return "", errUploadInUse
+	}
+	return Reference(id), nil
+}
+
+// Sync makes the upload a saved item's image field references, if any, the
+// only one attached to the item. A replaced image is detached so its owner
+// can delete it.
+func (l *Linker) Sync(image, associatedType string, associatedID uuid.UUID) error {
+	var uploadID *uuid.UUID
+	if id, ok := referencedID(image); ok {
+		uploadID = &id
+	}
+	return l.repo.Sync(uploadID, associatedType, associatedID)
+}
+
+// Release detaches every upload from an item that has been deleted
+func (l *Linker) Release(associatedType string, associatedID uuid.UUID) error {
+	return l.repo.Detach(associatedType, associatedID)
+}
+
+// referencedID returns the upload an image field names, by ID or reference
+func referencedID(image string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(strings.TrimPrefix(image, referencePrefix))
+	return id, err == nil
+}
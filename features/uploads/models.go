@@ -0,0 +1,61 @@
+package uploads
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Storage backends, as recorded in uploads.storage_backend
+const (
+	BackendDatabase   = "database"
+	BackendFilesystem = "filesystem"
+)
+
+// What an upload can be attached to, as recorded in uploads.associated_type
+const (
+	AssociatedLeftover            = "leftover"
+	AssociatedSurplusPost         = "surplus-post"
+	AssociatedRestaurantInventory = "restaurant-inventory"
+	AssociatedRestaurantSurplus   = "restaurant-surplus"
+	AssociatedNGOFeedback         = "ngo-feedback"
+	AssociatedNGOStory            = "ngo-story"
+	AssociatedShopInventory       = "shop-inventory"
+	AssociatedShopSurplus         = "shop-surplus"
+)
+
+// publicAssociations are the listings whose attachments any signed-in user may
+// fetch. Uploads attached to anything else stay visible to their owner only.
+var publicAssociations = map[string]bool{
+	AssociatedLeftover:          true,
+	AssociatedSurplusPost:       true,
+	AssociatedRestaurantSurplus: true,
+}
+
+// allowedTypes are the content types accepted, as sniffed from the file itself
+var allowedTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+type Upload struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	FileName       string     `json:"file_name" db:"file_name"`
+	FileType       string     `json:"file_type" db:"file_type"`
+	FileSize       int64      `json:"file_size" db:"file_size"`
+	SHA256         string     `json:"sha256,omitempty" db:"sha256"`
+	StorageBackend string     `json:"-" db:"storage_backend"`
+	StorageKey     string     `json:"-" db:"storage_key"`
+	AssociatedType string     `json:"associated_type,omitempty" db:"associated_type"`
+	AssociatedID   *uuid.UUID `json:"associated_id,omitempty" db:"associated_id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+
+	// A signed download URL and when it stops working
+	URL          string     `json:"url,omitempty"`
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+}
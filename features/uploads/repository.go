@@ -0,0 +1,157 @@
+package uploads
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+const uploadColumns = `id, user_id, file_name, file_type, file_size, COALESCE(sha256, ''), storage_backend, COALESCE(storage_key, ''), COALESCE(associated_type, ''), associated_id, created_at, updated_at`
+
+func (r *Repository) GetByID(id uuid.UUID) (*Upload, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	upload, err := scanUpload(r.db.QueryRow(`SELECT `+uploadColumns+` FROM uploads WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return upload, nil
+}
+
+func (r *Repository) GetAllByUserID(userID uuid.UUID) ([]*Upload, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT `+uploadColumns+` FROM uploads WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	list := []*Upload{}
+	for rows.Next() {
+		upload, err := scanUpload(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		list = append(list, upload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return list, nil
+}
+
+// Create inserts an upload's metadata. Its contents are stored afterwards
+// through the backend it names.
+func (r *Repository) Create(upload *Upload) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `INSERT INTO uploads (id, user_id, file_name, file_type, file_size, sha256, storage_backend, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING ` + uploadColumns
+	now := time.Now()
+	created, err := scanUpload(r.db.QueryRow(query, upload.ID, upload.UserID, upload.FileName, upload.FileType, upload.FileSize, upload.SHA256, upload.StorageBackend, now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*upload = *created
+	return nil
+}
+
+func (r *Repository) SetStorageKey(id uuid.UUID, key string) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	if _, err := r.db.Exec(`UPDATE uploads SET storage_key = NULLIF($1, '') WHERE id = $2`, key, id); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// Sync attaches uploadID, when set, to an item and detaches any other upload
+// from it, in one transaction. It fails with errUploadInUse when the upload is
+// already attached to something else.
+func (r *Repository) Sync(uploadID *uuid.UUID, associatedType string, associatedID uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE uploads SET associated_type = NULL, associated_id = NULL
+		WHERE associated_type = $1 AND associated_id = $2 AND ($3::uuid IS NULL OR id <> $3)`, associatedType, associatedID, uploadID)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if uploadID != nil {
+		result, err := tx.Exec(`
+			UPDATE uploads SET associated_type = $2, associated_id = $3
+			WHERE id = $1 AND (associated_id IS NULL OR (associated_type = $2 AND associated_id = $3))`, *uploadID, associatedType, associatedID)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrDatabase)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return errUploadInUse
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// Detach frees every upload attached to an item
+func (r *Repository) Detach(associatedType string, associatedID uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	_, err := r.db.Exec(`UPDATE uploads SET associated_type = NULL, associated_id = NULL WHERE associated_type = $1 AND associated_id = $2`, associatedType, associatedID)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+func (r *Repository) Delete(id uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	result, err := r.db.Exec(`DELETE FROM uploads WHERE id = $1`, id)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUpload(row rowScanner) (*Upload, error) {
+	u := &Upload{}
+	if err := row.Scan(&u.ID, &u.UserID, &u.FileName, &u.FileType, &u.FileSize, &u.SHA256, &u.StorageBackend, &u.StorageKey, &u.AssociatedType, &u.AssociatedID, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,43 @@
+package uploads
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		switch {
+		case path == "" && r.Method == http.MethodGet:
+			handler.GetAll(w, r)
+		case path == "" && r.Method == http.MethodPost:
+			handler.Create(w, r)
+		case len(path) == 36 && r.Method == http.MethodGet:
+			handler.GetByID(w, r)
+		case len(path) == 36 && r.Method == http.MethodDelete:
+			handler.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
+
+// SetupContentRoutes serves signed downloads. The signature in the URL is the
+// only credential, so these routes are not authenticated.
+func SetupContentRoutes(service *Service, handler *Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		switch {
+		case len(path) == 36 && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+			handler.Content(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return mux
+}
@@ -0,0 +1,245 @@
+package uploads
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"foodlink_backend/config"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errEmptyFile        = errors.NewAppError(http.StatusBadRequest, "Validation failed: file is empty")
+	errUnsupportedType  = errors.NewAppError(http.StatusUnsupportedMediaType, "Only JPEG, PNG, GIF, WebP images and PDF documents can be uploaded")
+	errUploadInUse      = errors.NewAppError(http.StatusConflict, "Upload is already attached to another item")
+	errUploadNotFound   = errors.NewAppError(http.StatusBadRequest, "Validation failed: image upload does not exist")
+	errInvalidSignature = errors.NewAppError(http.StatusForbidden, "Download link is invalid or has expired")
+)
+
+// contentPath is where signed downloads are served
+const contentPath = "/api/v1/files/"
+
+// signingKeyLabel separates the key derived for download URLs from any other
+// use of the JWT secret
+const signingKeyLabel = "foodlink/uploads/url-signing/v1"
+
+type Service struct {
+	repo     *Repository
+	storage  string
+	backends map[string]Storage
+	maxBytes int64
+	urlTTL   time.Duration
+	secret   []byte
+}
+
+func NewService(cfg *config.Config) *Service {
+	storage := cfg.UploadStorage
+	if storage != BackendFilesystem {
+		if storage != BackendDatabase {
+			log.Printf("Warning: UPLOAD_STORAGE %q is not database or filesystem; using database", storage)
+		}
+		storage = BackendDatabase
+	}
+	maxBytes := int64(cfg.UploadMaxBytes)
+	if maxBytes <= 0 {
+		maxBytes = 5 * 1024 * 1024
+	}
+	urlTTL := cfg.UploadURLTTL
+	if urlTTL <= 0 {
+		urlTTL = 15 * time.Minute
+	}
+	return &Service{
+		repo:    NewRepository(),
+		storage: storage,
+		backends: map[string]Storage{
+			BackendDatabase:   NewDatabaseStorage(database.GetDB()),
+			BackendFilesystem: NewFilesystemStorage(cfg.UploadDir),
+		},
+		maxBytes: maxBytes,
+		urlTTL:   urlTTL,
+		secret:   signingKey(cfg),
+	}
+}
+
+// MaxBytes is the largest file accepted
+func (s *Service) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Create stores a file for a user. Its type is sniffed from the contents;
+// the name and type the client sent are not trusted.
+func (s *Service) Create(userID uuid.UUID, fileName string, data []byte) (*Upload, error) {
+	if len(data) == 0 {
+		return nil, errEmptyFile
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, s.errTooLarge()
+	}
+	fileType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	if !allowedTypes[fileType] {
+		return nil, errUnsupportedType
+	}
+	sum := sha256.Sum256(data)
+	upload := &Upload{
+		ID:             uuid.New(),
+		UserID:         userID,
+		FileName:       cleanFileName(fileName),
+		FileType:       fileType,
+		FileSize:       int64(len(data)),
+		SHA256:         hex.EncodeToString(sum[:]),
+		StorageBackend: s.storage,
+	}
+	if err := s.repo.Create(upload); err != nil {
+		return nil, err
+	}
+	key, err := s.backends[s.storage].Put(upload, data)
+	if err == nil {
+		err = s.repo.SetStorageKey(upload.ID, key)
+	}
+	if err != nil {
+		if delErr := s.repo.Delete(upload.ID); delErr != nil {
+			log.Printf("Warning: failed to remove upload %s after its contents could not be stored: %v", upload.ID, delErr)
+		}
+		return nil, errors.WrapError(err, errors.ErrInternalServer)
+	}
+	upload.StorageKey = key
+	s.sign(upload, time.Now())
+	return upload, nil
+}
+
+func (s *Service) GetAllByUserID(userID uuid.UUID) ([]*Upload, error) {
+	uploads, err := s.repo.GetAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, upload := range uploads {
+		s.sign(upload, now)
+	}
+	return uploads, nil
+}
+
+// GetByID returns an upload with a fresh signed URL. Owners can always see
+// their uploads; other users only once it is attached to a public listing,
+// such as a leftover or a surplus post.
+func (s *Service) GetByID(id, userID uuid.UUID) (*Upload, error) {
+	upload, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UserID != userID && (upload.AssociatedID == nil || !publicAssociations[upload.AssociatedType]) {
+		return nil, errors.ErrForbidden
+	}
+	s.sign(upload, time.Now())
+	return upload, nil
+}
+
+// Delete removes an upload that is not attached to anything
+func (s *Service) Delete(id, userID uuid.UUID) error {
+	upload, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if upload.UserID != userID {
+		return errors.ErrForbidden
+	}
+	if upload.AssociatedID != nil {
+		return errUploadInUse
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if backend, ok := s.backends[upload.StorageBackend]; ok {
+		if err := backend.Delete(upload); err != nil {
+			log.Printf("Warning: failed to delete contents of upload %s: %v", upload.ID, err)
+		}
+	}
+	return nil
+}
+
+// Open checks a signed download URL and opens the upload's contents
+func (s *Service) Open(id uuid.UUID, expires, signature string) (*Upload, io.ReadCloser, error) {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix || !hmac.Equal([]byte(signature), []byte(s.signature(id, unix))) {
+		return nil, nil, errInvalidSignature
+	}
+	upload, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	backend, ok := s.backends[upload.StorageBackend]
+	if !ok {
+		return nil, nil, fmt.Errorf("upload %s uses unknown storage %q", upload.ID, upload.StorageBackend)
+	}
+	contents, err := backend.Open(upload)
+	if err != nil {
+		return nil, nil, errors.WrapError(err, errors.ErrInternalServer)
+	}
+	return upload, contents, nil
+}
+
+// sign sets an upload's download URL, valid for the configured TTL
+func (s *Service) sign(upload *Upload, now time.Time) {
+	expiresAt := now.Add(s.urlTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	upload.URL = fmt.Sprintf("%s%s?expires=%d&signature=%s", contentPath, upload.ID, expires, s.signature(upload.ID, expires))
+	upload.URLExpiresAt = &expiresAt
+}
+
+// signingKey is UPLOAD_SIGNING_SECRET when set. Otherwise a key is derived from
+// the JWT secret with HKDF, so a download signature can never double as a
+// token signature.
+func signingKey(cfg *config.Config) []byte {
+	if cfg.UploadSigningSecret != "" {
+		return []byte(cfg.UploadSigningSecret)
+	}
+	key, err := hkdf.Key(sha256.New, []byte(cfg.JWTSecret), nil, signingKeyLabel, sha256.Size)
+	if err != nil {
+		log.Fatalf("Failed to derive upload signing key: %v", err)
+	}
+	return key
+}
+
+// signature is the hex HMAC-SHA256 of "<id>.<expires>"
+func (s *Service) signature(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id.String() + "." + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) errTooLarge() *errors.AppError {
+	return errors.NewAppError(http.StatusRequestEntityTooLarge, fmt.Sprintf("File is larger than the %d byte limit", s.maxBytes))
+}
+
+// cleanFileName keeps the base name of a client-supplied file name without
+// control characters or quotes, so it is safe in a Content-Disposition header
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "upload"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
@@ -0,0 +1,111 @@
+package uploads
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Storage keeps the contents of uploads. The uploads row holds the metadata
+// and names the backend, so uploads stay readable after the configured
+// backend changes.
+type Storage interface {
+	// Put stores an upload's contents and returns the key they are kept under
+	Put(upload *Upload, data []byte) (string, error)
+	Open(upload *Upload) (io.ReadCloser, error)
+	Delete(upload *Upload) error
+}
+
+// DatabaseStorage keeps contents base64 encoded in uploads.data
+type DatabaseStorage struct {
+	db *sql.DB
+}
+
+func NewDatabaseStorage(db *sql.DB) *DatabaseStorage {
+	return &DatabaseStorage{db: db}
+}
+
+func (s *DatabaseStorage) Put(upload *Upload, data []byte) (string, error) {
+	if s.db == nil {
+		return "", fmt.Errorf("database connection is not initialized")
+	}
+	_, err := s.db.Exec(`UPDATE uploads SET data = $1 WHERE id = $2`, base64.StdEncoding.EncodeToString(data), upload.ID)
+	return "", err
+}
+
+func (s *DatabaseStorage) Open(upload *Upload) (io.ReadCloser, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+	var encoded sql.NullString
+	if err := s.db.QueryRow(`SELECT data FROM uploads WHERE id = $1`, upload.ID).Scan(&encoded); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded.String)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete is a no-op: the contents go with the uploads row
+func (s *DatabaseStorage) Delete(upload *Upload) error {
+	return nil
+}
+
+// FilesystemStorage keeps contents in files under a directory, spread over
+// subdirectories named after the first characters of the upload ID
+type FilesystemStorage struct {
+	dir string
+}
+
+func NewFilesystemStorage(dir string) *FilesystemStorage {
+	return &FilesystemStorage{dir: dir}
+}
+
+func (s *FilesystemStorage) Put(upload *Upload, data []byte) (string, error) {
+	path := s.path(upload)
+	id := upload.ID.String()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", err
+	}
+	// Write to a temporary file first so a reader never sees a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), id+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return id[:2] + "/" + id, nil
+}
+
+func (s *FilesystemStorage) Open(upload *Upload) (io.ReadCloser, error) {
+	return os.Open(s.path(upload))
+}
+
+func (s *FilesystemStorage) Delete(upload *Upload) error {
+	if err := os.Remove(s.path(upload)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path returns where an upload's file is. It is derived from the upload ID
+// alone rather than the stored key, so no row can point outside the directory.
+func (s *FilesystemStorage) path(upload *Upload) string {
+	id := upload.ID.String()
+	return filepath.Join(s.dir, id[:2], id)
+}
@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"foodlink_backend/events"
+
+	"github.com/google/uuid"
+)
+
+const eventSubscriber = "webhooks"
+
+// RegisterEventHandlers queues the subscribable events for the endpoints of
+// the users they concern: the NGO and the donating restaurant for offers and
+// pickups, and the restaurant for its donation log. The retry job sends them.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.OfferAccepted) error {
+		users := []uuid.UUID{e.NGOUserID}
+		if e.SurplusItemID != nil {
+			donorID, err := s.repo.GetSurplusOwner(*e.SurplusItemID)
+			if err != nil {
+				return err
+			}
+			users = appendUser(users, donorID)
+		}
+		return s.enqueue(e, e.Meta, users)
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.PickupStatusChanged) error {
+		donorID, err := s.repo.GetOfferDonor(e.OfferID)
+		if err != nil {
+			return err
+		}
+		return s.enqueue(e, e.Meta, appendUser([]uuid.UUID{e.NGOUserID}, donorID))
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.DonationLogged) error {
+		return s.enqueue(e, e.Meta, []uuid.UUID{e.UserID})
+	})
+}
+
+func (s *Service) enqueue(e events.Event, meta events.Meta, users []uuid.UUID) error {
+	payload, err := json.Marshal(Envelope{
+		ID:        e.EventID(),
+		Type:      e.EventName(),
+		CreatedAt: meta.OccurredAt,
+		Data:      e,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.Enqueue(e.EventID(), e.EventName(), payload, users)
+	return err
+}
+
+func appendUser(users []uuid.UUID, userID *uuid.UUID) []uuid.UUID {
+	if userID == nil {
+		return users
+	}
+	for _, u := range users {
+		if u == *userID {
+			return users
+		}
+	}
+	return append(users, *userID)
+}
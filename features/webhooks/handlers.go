@@ -0,0 +1,315 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"foodlink_backend/errors"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
+	user, ok := r.Context().Value("user").(*auth.User)
+	if !ok || user == nil {
+		return uuid.Nil, errors.ErrUnauthorized
+	}
+	return user.ID, nil
+}
+
+// ListEndpoints handles GET /api/v1/webhooks
+// @Summary      List webhook endpoints
+// @Description  List the authenticated user's webhook endpoints. Secrets are not included.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   Endpoint
+// @Failure      401  {object}  errors.AppError
+// @Router       /webhooks [get]
+func (h *Handler) ListEndpoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	endpoints, err := h.service.ListEndpoints(userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve webhook endpoints", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Webhook endpoints retrieved successfully", endpoints)
+}
+
+// GetEndpoint handles GET /api/v1/webhooks/:id
+// @Summary      Get webhook endpoint
+// @Description  Get one of the authenticated user's webhook endpoints
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Endpoint ID"
+// @Success      200  {object}  Endpoint
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /webhooks/{id} [get]
+func (h *Handler) GetEndpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	endpoint, err := h.service.GetEndpoint(id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve webhook endpoint", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Webhook endpoint retrieved successfully", endpoint)
+}
+
+// CreateEndpoint handles POST /api/v1/webhooks
+// @Summary      Register webhook endpoint
+// @Description  Register a URL to receive the chosen events (ngo.offer.accepted, ngo.pickup.status_changed, restaurant.donation.logged). Each delivery is signed in the X-Foodlink-Signature header as t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>">. The signing secret is only returned in this response.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      CreateEndpointRequest  true  "Endpoint data"
+// @Success      201      {object}  Endpoint
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Router       /webhooks [post]
+func (h *Handler) CreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	var req CreateEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	endpoint, err := h.service.CreateEndpoint(userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to create webhook endpoint", err.Error())
+		return
+	}
+	utils.CreatedResponse(w, "Webhook endpoint created successfully", endpoint)
+}
+
+// UpdateEndpoint handles PUT /api/v1/webhooks/:id
+// @Summary      Update webhook endpoint
+// @Description  Change an endpoint's URL, events or description, or disable it. Deliveries for a disabled endpoint wait until it is enabled again.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                 true  "Endpoint ID"
+// @Param        request  body      UpdateEndpointRequest  true  "Endpoint changes"
+// @Success      200      {object}  Endpoint
+// @Failure      400      {object}  errors.AppError
+// @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
+// @Failure      404      {object}  errors.AppError
+// @Router       /webhooks/{id} [put]
+func (h *Handler) UpdateEndpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	var req UpdateEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	endpoint, err := h.service.UpdateEndpoint(id, userID, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update webhook endpoint", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Webhook endpoint updated successfully", endpoint)
+}
+
+// DeleteEndpoint handles DELETE /api/v1/webhooks/:id
+// @Summary      Delete webhook endpoint
+// @Description  Delete an endpoint along with its deliveries
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Endpoint ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Router       /webhooks/{id} [delete]
+func (h *Handler) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Trim(r.URL.Path, "/"))
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	if err := h.service.DeleteEndpoint(id, userID); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to delete webhook endpoint", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Webhook endpoint deleted successfully", map[string]string{"message": "Deleted"})
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/:id/deliveries
+// @Summary      List webhook deliveries
+// @Description  List an endpoint's deliveries, newest first, with the attempts made, the status code of the last response and when the next retry is due
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      string  true   "Endpoint ID"
+// @Param        status  query     string  false  "Filter by status (pending, retrying, succeeded, dead)"
+// @Param        limit   query     int     false  "Number of deliveries (default: 50, max: 100)"
+// @Success      200     {array}   Delivery
+// @Failure      400     {object}  errors.AppError
+// @Failure      401     {object}  errors.AppError
+// @Failure      403     {object}  errors.AppError
+// @Failure      404     {object}  errors.AppError
+// @Router       /webhooks/{id}/deliveries [get]
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(strings.Split(strings.Trim(r.URL.Path, "/"), "/")[0])
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	deliveries, err := h.service.ListDeliveries(id, userID, r.URL.Query().Get("status"), limit)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve webhook deliveries", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Webhook deliveries retrieved successfully", deliveries)
+}
+
+// Redeliver handles POST /api/v1/webhooks/deliveries/:id/redeliver
+// @Summary      Redeliver webhook
+// @Description  Send a delivery again now, including dead-lettered ones, and return the outcome. A failed redelivery is retried with backoff like a new delivery. Deliveries of disabled endpoints, and ones being sent or waiting to retry, are refused with 409.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Delivery ID"
+// @Success      200  {object}  Delivery
+// @Failure      400  {object}  errors.AppError
+// @Failure      401  {object}  errors.AppError
+// @Failure      403  {object}  errors.AppError
+// @Failure      404  {object}  errors.AppError
+// @Failure      409  {object}  errors.AppError
+// @Router       /webhooks/deliveries/{id}/redeliver [post]
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	id, err := uuid.Parse(pathParts[len(pathParts)-2])
+	if err != nil {
+		utils.BadRequestResponse(w, "Invalid ID format", nil)
+		return
+	}
+	delivery, err := h.service.Redeliver(r.Context(), id, userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to redeliver webhook", err.Error())
+		return
+	}
+	utils.OKResponse(w, "Webhook redelivered", delivery)
+}
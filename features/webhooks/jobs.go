@@ -0,0 +1,15 @@
+package webhooks
+
+import (
+	"foodlink_backend/jobs"
+	"time"
+)
+
+// RegisterJobs schedules sending queued and retrying deliveries
+func (s *Service) RegisterJobs(scheduler *jobs.Scheduler, interval time.Duration) {
+	scheduler.Register(jobs.Job{
+		Name:     "webhooks.deliver",
+		Interval: interval,
+		Run:      s.DeliverDue,
+	})
+}
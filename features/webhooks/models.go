@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"foodlink_backend/events"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delivery statuses. A pending delivery has not been attempted yet; a retrying
+// one failed and waits for its next attempt; a dead one used up its attempts
+// and is only sent again when redelivered by hand.
+const (
+	DeliveryPending   = "pending"
+	DeliveryRetrying  = "retrying"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// EventTypes are the events an endpoint can subscribe to
+var EventTypes = []string{
+	events.NameOfferAccepted,
+	events.NamePickupStatusChanged,
+	events.NameDonationLogged,
+}
+
+// Endpoint is a URL a user registered to receive events. Secret is only
+// returned when the endpoint is created.
+type Endpoint struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	URL         string    `json:"url" db:"url"`
+	Secret      string    `json:"secret,omitempty" db:"secret"`
+	EventTypes  []string  `json:"event_types" db:"event_types"`
+	Description string    `json:"description,omitempty" db:"description"`
+	Active      bool      `json:"active" db:"active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Delivery is one event sent, or to be sent, to one endpoint
+type Delivery struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	EndpointID     uuid.UUID       `json:"endpoint_id" db:"endpoint_id"`
+	EventID        uuid.UUID       `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Envelope is the JSON body of every delivery. ID is the event's ID, so a
+// receiver can drop events it has already handled.
+type Envelope struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type CreateEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,oneof=ngo.offer.accepted ngo.pickup.status_changed restaurant.donation.logged"`
+	Description string   `json:"description,omitempty" validate:"omitempty,max=500"`
+}
+
+type UpdateEndpointRequest struct {
+	URL         string   `json:"url,omitempty" validate:"omitempty,url,max=2048"`
+	EventTypes  []string `json:"event_types,omitempty" validate:"omitempty,min=1,dive,oneof=ngo.offer.accepted ngo.pickup.status_changed restaurant.donation.logged"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=500"`
+	Active      *bool    `json:"active,omitempty"`
+}
@@ -0,0 +1,297 @@
+package webhooks
+
+import (
+	"database/sql"
+	"foodlink_backend/database"
+	"foodlink_backend/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository() *Repository {
+	return &Repository{db: database.GetDB()}
+}
+
+const (
+	endpointColumns = `id, user_id, url, secret, event_types, COALESCE(description, ''), COALESCE(active, TRUE), created_at, updated_at`
+	deliveryColumns = `id, endpoint_id, event_id, event_type, payload, COALESCE(status, 'pending'), COALESCE(attempts, 0), next_attempt_at, last_attempt_at, last_status_code, COALESCE(last_error, ''), delivered_at, created_at, updated_at`
+)
+
+func (r *Repository) GetEndpoint(id uuid.UUID) (*Endpoint, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	endpoint, err := scanEndpoint(r.db.QueryRow(`SELECT `+endpointColumns+` FROM webhook_endpoints WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return endpoint, nil
+}
+
+func (r *Repository) ListEndpoints(userID uuid.UUID) ([]*Endpoint, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT `+endpointColumns+` FROM webhook_endpoints WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	list := []*Endpoint{}
+	for rows.Next() {
+		endpoint, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		list = append(list, endpoint)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return list, nil
+}
+
+func (r *Repository) CreateEndpoint(endpoint *Endpoint) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `INSERT INTO webhook_endpoints (id, user_id, url, secret, event_types, description, active, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9) RETURNING ` + endpointColumns
+	now := time.Now()
+	created, err := scanEndpoint(r.db.QueryRow(query, endpoint.ID, endpoint.UserID, endpoint.URL, endpoint.Secret, pq.Array(endpoint.EventTypes), endpoint.Description, endpoint.Active, now, now))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*endpoint = *created
+	return nil
+}
+
+func (r *Repository) UpdateEndpoint(endpoint *Endpoint) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `UPDATE webhook_endpoints SET url = $1, event_types = $2, description = NULLIF($3, ''), active = $4, updated_at = $5 WHERE id = $6 RETURNING ` + endpointColumns
+	updated, err := scanEndpoint(r.db.QueryRow(query, endpoint.URL, pq.Array(endpoint.EventTypes), endpoint.Description, endpoint.Active, time.Now(), endpoint.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*endpoint = *updated
+	return nil
+}
+
+func (r *Repository) DeleteEndpoint(id uuid.UUID) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	result, err := r.db.Exec(`DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// Enqueue queues an event for every active endpoint of the given users that
+// subscribes to its type. An endpoint gets each event once, however often the
+// event is handled.
+func (r *Repository) Enqueue(eventID uuid.UUID, eventType string, payload []byte, userIDs []uuid.UUID) (int64, error) {
+	if r.db == nil {
+		return 0, errors.ErrDatabase
+	}
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, next_attempt_at)
+		SELECT id, $1::uuid, $2::text, $3::jsonb, 'pending', CURRENT_TIMESTAMP FROM webhook_endpoints
+		WHERE user_id = ANY($4::uuid[]) AND COALESCE(active, TRUE) AND $2::text = ANY(event_types)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING`
+	result, err := r.db.Exec(query, eventID, eventType, string(payload), pq.Array(ids))
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	queued, _ := result.RowsAffected()
+	return queued, nil
+}
+
+func (r *Repository) GetDelivery(id uuid.UUID) (*Delivery, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	delivery, err := scanDelivery(r.db.QueryRow(`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return delivery, nil
+}
+
+// ListDeliveries returns an endpoint's deliveries, newest first, optionally
+// filtered by status
+func (r *Repository) ListDeliveries(endpointID uuid.UUID, status string, limit int) ([]*Delivery, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE endpoint_id = $1 AND ($2 = '' OR status = $2) ORDER BY created_at DESC LIMIT $3`
+	return r.queryDeliveries(query, endpointID, status, limit)
+}
+
+// ClaimDue picks up to limit deliveries whose next attempt is due and pushes
+// their next attempt back by lease, so no other run sends them while this one
+// does. Deliveries of disabled endpoints wait until the endpoint is enabled.
+func (r *Repository) ClaimDue(limit int, lease time.Duration) ([]*Delivery, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhook_endpoints e ON e.id = d.endpoint_id
+			WHERE d.status IN ('pending', 'retrying') AND d.next_attempt_at <= CURRENT_TIMESTAMP AND COALESCE(e.active, TRUE)
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	return r.queryDeliveries(query, limit, time.Now().Add(lease))
+}
+
+// ClaimForRedelivery queues a delivery to be sent again with a fresh set of
+// attempts, claiming it for lease so the retry job leaves it to the caller.
+// A delivery still held by a claim or waiting out its backoff fails with
+// errDeliveryInFlight, so it is never sent twice at once.
+func (r *Repository) ClaimForRedelivery(id uuid.UUID, lease time.Duration) (*Delivery, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = $2
+		WHERE id = $1 AND (status IN ('succeeded', 'dead') OR next_attempt_at IS NULL OR next_attempt_at <= CURRENT_TIMESTAMP)
+		RETURNING ` + deliveryColumns
+	delivery, err := scanDelivery(r.db.QueryRow(query, id, time.Now().Add(lease)))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetDelivery(id); err != nil {
+			return nil, err
+		}
+		return nil, errDeliveryInFlight
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return delivery, nil
+}
+
+// RecordAttempt saves the outcome of sending a delivery
+func (r *Repository) RecordAttempt(d *Delivery) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_attempt_at = $4, last_status_code = $5,
+			last_error = NULLIF($6, ''), delivered_at = $7
+		WHERE id = $8
+		RETURNING ` + deliveryColumns
+	updated, err := scanDelivery(r.db.QueryRow(query, d.Status, d.Attempts, d.NextAttemptAt, d.LastAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt, d.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.ErrNotFound
+		}
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	*d = *updated
+	return nil
+}
+
+// GetSurplusOwner returns the restaurant that listed a surplus item, or nil
+// when the item no longer exists
+func (r *Repository) GetSurplusOwner(itemID uuid.UUID) (*uuid.UUID, error) {
+	return r.lookupUser(`SELECT user_id FROM restaurant_surplus_items WHERE id = $1`, itemID)
+}
+
+// GetOfferDonor returns the restaurant whose surplus item an NGO offer was
+// made for, or nil when the offer did not come from a restaurant surplus item
+func (r *Repository) GetOfferDonor(offerID uuid.UUID) (*uuid.UUID, error) {
+	return r.lookupUser(`
+		SELECT s.user_id FROM ngo_donation_offers o
+		JOIN restaurant_surplus_items s ON s.id = o.surplus_item_id
+		WHERE o.id = $1`, offerID)
+}
+
+func (r *Repository) lookupUser(query string, id uuid.UUID) (*uuid.UUID, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	var userID uuid.UUID
+	if err := r.db.QueryRow(query, id).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return &userID, nil
+}
+
+func (r *Repository) queryDeliveries(query string, args ...interface{}) ([]*Delivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	list := []*Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		list = append(list, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return list, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEndpoint(row rowScanner) (*Endpoint, error) {
+	e := &Endpoint{}
+	if err := row.Scan(&e.ID, &e.UserID, &e.URL, &e.Secret, pq.Array(&e.EventTypes), &e.Description, &e.Active, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func scanDelivery(row rowScanner) (*Delivery, error) {
+	d := &Delivery{}
+	var payload []byte
+	var statusCode sql.NullInt64
+	if err := row.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastAttemptAt, &statusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if statusCode.Valid {
+		code := int(statusCode.Int64)
+		d.LastStatusCode = &code
+	}
+	return d, nil
+}
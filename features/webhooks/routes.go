@@ -0,0 +1,35 @@
+package webhooks
+
+import (
+	"foodlink_backend/middleware"
+	"net/http"
+	"strings"
+)
+
+func SetupRoutes(service *Service, handler *Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(r.URL.Path, "/")
+		pathParts := strings.Split(path, "/")
+
+		switch {
+		case path == "" && r.Method == http.MethodGet:
+			handler.ListEndpoints(w, r)
+		case path == "" && r.Method == http.MethodPost:
+			handler.CreateEndpoint(w, r)
+		case len(pathParts) == 3 && pathParts[0] == "deliveries" && pathParts[2] == "redeliver" && r.Method == http.MethodPost:
+			handler.Redeliver(w, r)
+		case len(pathParts) == 2 && pathParts[1] == "deliveries" && r.Method == http.MethodGet:
+			handler.ListDeliveries(w, r)
+		case len(pathParts) == 1 && r.Method == http.MethodGet:
+			handler.GetEndpoint(w, r)
+		case len(pathParts) == 1 && r.Method == http.MethodPut:
+			handler.UpdateEndpoint(w, r)
+		case len(pathParts) == 1 && r.Method == http.MethodDelete:
+			handler.DeleteEndpoint(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return middleware.Chain(authMiddleware)(mux)
+}
@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Retry policy: the first retry waits retryBaseDelay and each later one twice
+// as long, up to retryMaxDelay. A delivery that fails maxAttempts times is
+// dead-lettered.
+const (
+	maxAttempts    = 10
+	retryBaseDelay = time.Minute
+	retryMaxDelay  = 6 * time.Hour
+)
+
+// sendTimeout bounds each attempt, including reading the response
+const sendTimeout = 10 * time.Second
+
+// dialTimeout bounds connecting to an endpoint within an attempt
+const dialTimeout = 5 * time.Second
+
+// backoff returns how long to wait before retrying a delivery that has failed
+// attempts times
+func backoff(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// Sender posts signed deliveries to endpoints
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender returns a sender that refuses to connect to loopback, private,
+// link-local and unspecified addresses
+func NewSender() *Sender {
+	return newSender(refuseNonPublic)
+}
+
+// newSender builds a sender whose dialer runs control on every address it
+// connects to, after the endpoint's host has been resolved
+func newSender(control func(network, address string, c syscall.RawConn) error) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed in place of the endpoint and escape the address check
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second, Control: control}).DialContext
+	return &Sender{client: &http.Client{
+		Timeout:   sendTimeout,
+		Transport: transport,
+		// A redirect would resend the body somewhere the owner did not register
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// refuseNonPublic is a dialer control that rejects connections to addresses
+// publicIP does not allow. It runs on the resolved address, so a host that
+// passed the check at registration cannot be rebound to an internal one.
+func refuseNonPublic(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", host)
+	}
+	return nil
+}
+
+// publicIP reports whether ip is routable on the public internet, as opposed to
+// loopback, private, link-local, multicast or unspecified
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// Send posts a delivery's payload to an endpoint. It returns the response
+// status code, or 0 when no response arrived, and an error unless the endpoint
+// answered with a 2xx status.
+func (s *Sender) Send(ctx context.Context, endpoint *Endpoint, d *Delivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FoodLink-Webhooks/1.0")
+	req.Header.Set(EventHeader, d.EventType)
+	req.Header.Set(DeliveryHeader, d.ID.String())
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, now, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	// The body is never read: it is the endpoint's to keep, and echoing it back
+	// through last_error would turn deliveries into a way to read internal pages
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,137 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSendSignsPayload(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"1","type":"ngo.offer.accepted","data":{}}`)
+
+	var verifyErr error
+	var event string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		event = r.Header.Get(EventHeader)
+		verifyErr = VerifySignature(secret, r.Header.Get(SignatureHeader), body, 5*time.Minute, time.Now())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	endpoint := &Endpoint{URL: receiver.URL, Secret: secret}
+	d := &Delivery{ID: uuid.New(), EventType: "ngo.offer.accepted", Payload: payload}
+	status, err := newSender(nil).Send(context.Background(), endpoint, d, time.Now())
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send() = %d, %v; want 204, nil", status, err)
+	}
+	if verifyErr != nil {
+		t.Fatalf("receiver rejected signature: %v", verifyErr)
+	}
+	if event != d.EventType {
+		t.Fatalf("event header = %q, want %q", event, d.EventType)
+	}
+}
+
+func TestSendReportsFailedResponse(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "try later", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	endpoint := &Endpoint{URL: receiver.URL, Secret: "whsec_test"}
+	d := &Delivery{ID: uuid.New(), Payload: []byte(`{}`)}
+	status, err := newSender(nil).Send(context.Background(), endpoint, d, time.Now())
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", status)
+	}
+	if err == nil || strings.Contains(err.Error(), "try later") {
+		t.Fatalf("err = %v, want a failure without the response body", err)
+	}
+}
+
+func TestSendRefusesLoopback(t *testing.T) {
+	reached := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer receiver.Close()
+
+	endpoint := &Endpoint{URL: receiver.URL, Secret: "whsec_test"}
+	d := &Delivery{ID: uuid.New(), Payload: []byte(`{}`)}
+	status, err := NewSender().Send(context.Background(), endpoint, d, time.Now())
+	if err == nil || status != 0 || reached {
+		t.Fatalf("Send() = %d, %v; want the connection refused", status, err)
+	}
+}
+
+func TestCheckURLRejectsInternalAddresses(t *testing.T) {
+	for _, raw := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"https://10.1.2.3/hook",
+		"http://192.168.0.10/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://0.0.0.0/hook",
+		"ftp://93.184.216.34/hook",
+	} {
+		if err := checkURL(raw); err == nil {
+			t.Errorf("checkURL(%q) accepted", raw)
+		}
+	}
+	if err := checkURL("https://93.184.216.34/hook"); err != nil {
+		t.Errorf("checkURL(public address) = %v", err)
+	}
+}
+
+func TestVerifySignatureRejects(t *testing.T) {
+	body := []byte(`{"ok":true}`)
+	signedAt := time.Unix(1700000000, 0)
+	header := Sign("secret", signedAt, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+	}{
+		{"wrong secret", "other", header, body, signedAt},
+		{"tampered body", "secret", header, []byte(`{"ok":false}`), signedAt},
+		{"too old", "secret", header, body, signedAt.Add(10 * time.Minute)},
+		{"malformed", "secret", "v1=abc", body, signedAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifySignature(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now); err == nil {
+				t.Fatal("signature was accepted")
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{maxAttempts, retryMaxDelay},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
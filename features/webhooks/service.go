@@ -0,0 +1,288 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"foodlink_backend/errors"
+	"foodlink_backend/utils"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errURLScheme  = errors.NewAppError(http.StatusBadRequest, "Validation failed: url must use http or https")
+	errURLAddress = errors.NewAppError(http.StatusBadRequest, "Validation failed: url must resolve to a public address")
+
+	errEndpointInactive = errors.NewAppError(http.StatusConflict, "Endpoint is disabled; enable it before redelivering")
+	errDeliveryInFlight = errors.NewAppError(http.StatusConflict, "Delivery is being sent or waiting to retry; try again later")
+)
+
+// resolveTimeout bounds the DNS lookup that vets an endpoint's host
+const resolveTimeout = 5 * time.Second
+
+// Claimed deliveries are held this long before another run may pick them up
+// again; it covers a full batch of attempts that all time out
+const (
+	claimBatch = 20
+	claimLease = claimBatch*sendTimeout + time.Minute
+)
+
+type Service struct {
+	repo   *Repository
+	sender *Sender
+}
+
+func NewService() *Service {
+	return &Service{repo: NewRepository(), sender: NewSender()}
+}
+
+func (s *Service) ListEndpoints(userID uuid.UUID) ([]*Endpoint, error) {
+	endpoints, err := s.repo.ListEndpoints(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return endpoints, nil
+}
+
+func (s *Service) GetEndpoint(id, userID uuid.UUID) (*Endpoint, error) {
+	endpoint, err := s.ownEndpoint(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+// CreateEndpoint registers an endpoint with a new signing secret. The response
+// is the only time the secret is shown.
+func (s *Service) CreateEndpoint(userID uuid.UUID, req *CreateEndpointRequest) (*Endpoint, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if err := checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrInternalServer)
+	}
+	endpoint := &Endpoint{
+		ID:          uuid.New(),
+		UserID:      userID,
+		URL:         req.URL,
+		Secret:      secret,
+		EventTypes:  dedupe(req.EventTypes),
+		Description: req.Description,
+		Active:      true,
+	}
+	if err := s.repo.CreateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (s *Service) UpdateEndpoint(id, userID uuid.UUID, req *UpdateEndpointRequest) (*Endpoint, error) {
+	endpoint, err := s.ownEndpoint(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if req.URL != "" {
+		if err := checkURL(req.URL); err != nil {
+			return nil, err
+		}
+		endpoint.URL = req.URL
+	}
+	if len(req.EventTypes) > 0 {
+		endpoint.EventTypes = dedupe(req.EventTypes)
+	}
+	if req.Description != nil {
+		endpoint.Description = *req.Description
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+	if err := s.repo.UpdateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+func (s *Service) DeleteEndpoint(id, userID uuid.UUID) error {
+	if _, err := s.ownEndpoint(id, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteEndpoint(id)
+}
+
+// ListDeliveries returns an endpoint's deliveries, newest first, each with the
+// status code of its last attempt
+func (s *Service) ListDeliveries(endpointID, userID uuid.UUID, status string, limit int) ([]*Delivery, error) {
+	if _, err := s.ownEndpoint(endpointID, userID); err != nil {
+		return nil, err
+	}
+	switch status {
+	case "", DeliveryPending, DeliveryRetrying, DeliverySucceeded, DeliveryDead:
+	default:
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: status must be one of pending retrying succeeded dead", nil)
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.repo.ListDeliveries(endpointID, status, limit)
+}
+
+// Redeliver sends a delivery again now and returns the outcome. A failed
+// redelivery starts a fresh round of retries. Disabled endpoints are refused,
+// as are deliveries the retry job is sending or will retry later.
+func (s *Service) Redeliver(ctx context.Context, id, userID uuid.UUID) (*Delivery, error) {
+	delivery, err := s.repo.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := s.ownEndpoint(delivery.EndpointID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.Active {
+		return nil, errEndpointInactive
+	}
+	delivery, err = s.repo.ClaimForRedelivery(id, claimLease)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attempt(ctx, endpoint, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DeliverDue sends every delivery whose next attempt is due, a batch at a time
+func (s *Service) DeliverDue(ctx context.Context) error {
+	endpoints := map[uuid.UUID]*Endpoint{}
+	for ctx.Err() == nil {
+		due, err := s.repo.ClaimDue(claimBatch, claimLease)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range due {
+			endpoint, ok := endpoints[delivery.EndpointID]
+			if !ok {
+				if endpoint, err = s.repo.GetEndpoint(delivery.EndpointID); err != nil {
+					return err
+				}
+				endpoints[delivery.EndpointID] = endpoint
+			}
+			if err := s.attempt(ctx, endpoint, delivery); err != nil {
+				return err
+			}
+		}
+		if len(due) < claimBatch {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// attempt sends a delivery once and records the outcome: success, a retry
+// after the backoff delay, or the dead letter state once attempts run out
+func (s *Service) attempt(ctx context.Context, endpoint *Endpoint, d *Delivery) error {
+	now := time.Now()
+	statusCode, sendErr := s.sender.Send(ctx, endpoint, d, now)
+
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.LastStatusCode = nil
+	if statusCode != 0 {
+		d.LastStatusCode = &statusCode
+	}
+	d.LastError = ""
+	d.NextAttemptAt = nil
+	switch {
+	case sendErr == nil:
+		d.Status = DeliverySucceeded
+		d.DeliveredAt = &now
+	case d.Attempts >= maxAttempts:
+		d.Status = DeliveryDead
+		d.LastError = sendErr.Error()
+		log.Printf("Warning: webhook delivery %s to %s dead-lettered after %d attempts: %v", d.ID, endpoint.URL, d.Attempts, sendErr)
+	default:
+		d.Status = DeliveryRetrying
+		d.LastError = sendErr.Error()
+		next := now.Add(backoff(d.Attempts))
+		d.NextAttemptAt = &next
+	}
+	return s.repo.RecordAttempt(d)
+}
+
+// ownEndpoint returns an endpoint if it belongs to the user
+func (s *Service) ownEndpoint(id, userID uuid.UUID) (*Endpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(id)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.UserID != userID {
+		return nil, errors.ErrForbidden
+	}
+	return endpoint, nil
+}
+
+// checkURL accepts http and https URLs whose host resolves only to public
+// addresses. The sender checks again as it dials, since DNS can change after
+// registration.
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errURLScheme
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		if !publicIP(ip) {
+			return errURLAddress
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return errURLAddress
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return errURLAddress
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func dedupe(values []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
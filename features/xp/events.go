@@ -0,0 +1,41 @@
+package xp
+
+import (
+	"context"
+	"foodlink_backend/events"
+)
+
+const eventSubscriber = "xp"
+
+// RegisterEventHandlers subscribes the XP service to the activity events that
+// earn XP. Amounts and caps come from xp_rules; the ledger's idempotency keys
+// make redelivered events harmless.
+func (s *Service) RegisterEventHandlers(bus *events.Bus) {
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.ConsumptionLogged) error {
+		if e.WasWasted {
+			return nil
+		}
+		_, err := s.Award(e.UserID, SourceConsumptionLog, e.LogID, "Logged "+e.FoodName)
+		return err
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.SurplusPostCreated) error {
+		_, err := s.Award(e.UserID, SourceSurplusPost, e.PostID, "Shared a surplus post")
+		return err
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.SurplusRequestApproved) error {
+		_, err := s.Award(e.OwnerID, SourceSurplusRequestApproved, e.RequestID, "Surplus request approved")
+		return err
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.LeftoverClaimed) error {
+		_, err := s.Award(e.OwnerID, SourceLeftoverClaimed, e.ClaimID, "Leftover claimed")
+		return err
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.DonationLogged) error {
+		_, err := s.Award(e.UserID, SourceDonationLogged, e.DonationID, "Donation logged")
+		return err
+	})
+	events.On(bus, eventSubscriber, func(ctx context.Context, e events.PickupDelivered) error {
+		_, err := s.Award(e.NGOUserID, SourcePickupDelivered, e.PickupID, "Pickup delivered")
+		return err
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"foodlink_backend/utils"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -60,23 +61,24 @@ func (h *Handler) GetUserXP(w http.ResponseWriter, r *http.Request) {
 }
 
 // AddXP handles POST /api/v1/xp/add
-// @Summary      Add XP
-// @Description  Add XP to the authenticated user (system endpoint)
+// @Summary      Grant XP
+// @Description  Grant XP to a user as a ledger adjustment (admin only). A repeated idempotency_key does not grant twice.
 // @Tags         xp
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        request  body      AddXPRequest  true  "XP data"
+// @Param        request  body      AddXPRequest  true  "XP grant"
 // @Success      200      {object}  UserXP
 // @Failure      400      {object}  errors.AppError
 // @Failure      401      {object}  errors.AppError
+// @Failure      403      {object}  errors.AppError
 // @Router       /xp/add [post]
 func (h *Handler) AddXP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		utils.BadRequestResponse(w, "Method not allowed", nil)
 		return
 	}
-	userID, err := h.getUserID(r)
+	adminID, err := h.getUserID(r)
 	if err != nil {
 		utils.UnauthorizedResponse(w, "Authentication required")
 		return
@@ -86,11 +88,7 @@ func (h *Handler) AddXP(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequestResponse(w, "Invalid request body", err.Error())
 		return
 	}
-	if validationErrors := utils.ValidateStruct(&req); len(validationErrors) > 0 {
-		utils.BadRequestResponse(w, "Validation failed: "+validationErrors[0], nil)
-		return
-	}
-	xp, err := h.service.AddXP(userID, req.Amount)
+	xp, err := h.service.Grant(adminID, &req)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
@@ -102,6 +100,105 @@ func (h *Handler) AddXP(w http.ResponseWriter, r *http.Request) {
 	utils.OKResponse(w, "XP added successfully", xp)
 }
 
+// GetHistory handles GET /api/v1/xp/history
+// @Summary      Get XP history
+// @Description  Get the authenticated user's XP ledger, newest first
+// @Tags         xp
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit   query     int  false  "Number of entries (default: 20, max: 100)"
+// @Param        offset  query     int  false  "Entries to skip"
+// @Success      200     {array}   Transaction
+// @Failure      401     {object}  errors.AppError
+// @Router       /xp/history [get]
+func (h *Handler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		utils.UnauthorizedResponse(w, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	history, err := h.service.GetHistory(userID, limit, offset)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve XP history", err.Error())
+		return
+	}
+	utils.OKResponse(w, "XP history retrieved successfully", history)
+}
+
+// GetRules handles GET /api/v1/xp/rules
+// @Summary      List XP rules
+// @Description  List the XP awarded per activity source and its daily cap (admin only)
+// @Tags         xp
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   Rule
+// @Failure      403  {object}  errors.AppError
+// @Router       /xp/rules [get]
+func (h *Handler) GetRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	rules, err := h.service.GetRules()
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to retrieve XP rules", err.Error())
+		return
+	}
+	utils.OKResponse(w, "XP rules retrieved successfully", rules)
+}
+
+// UpdateRule handles PUT /api/v1/xp/rules/:sourceType
+// @Summary      Update XP rule
+// @Description  Create or replace the XP rule for an activity source (admin only)
+// @Tags         xp
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sourceType  path      string             true  "Source type"
+// @Param        request     body      UpdateRuleRequest  true  "Rule"
+// @Success      200         {object}  Rule
+// @Failure      400         {object}  errors.AppError
+// @Failure      403         {object}  errors.AppError
+// @Router       /xp/rules/{sourceType} [put]
+func (h *Handler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.BadRequestResponse(w, "Method not allowed", nil)
+		return
+	}
+	sourceType := strings.TrimPrefix(r.URL.Path, "/rules/")
+	var req UpdateRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.BadRequestResponse(w, "Invalid request body", err.Error())
+		return
+	}
+	rule, err := h.service.UpdateRule(sourceType, &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			utils.ErrorResponse(w, appErr.Code, appErr.Message, nil)
+			return
+		}
+		utils.InternalServerErrorResponse(w, "Failed to update XP rule", err.Error())
+		return
+	}
+	utils.OKResponse(w, "XP rule updated successfully", rule)
+}
+
 // GetLeaderboard handles GET /api/v1/xp/leaderboard
 // @Summary      Get leaderboard
 // @Description  Get XP leaderboard
@@ -17,10 +17,56 @@ type UserXP struct {
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// AddXPRequest represents a request to add XP
+// XP source types. Activity sources are priced by xp_rules; badge rewards and
+// admin adjustments are not.
+const (
+	SourceConsumptionLog         = "consumption_log"
+	SourceSurplusPost            = "surplus_post"
+	SourceSurplusRequestApproved = "surplus_request_approved"
+	SourceLeftoverClaimed        = "leftover_claimed"
+	SourceDonationLogged         = "donation_logged"
+	SourcePickupDelivered        = "pickup_delivered"
+	SourceBadge                  = "badge"
+	SourceAdminAdjustment        = "admin_adjustment"
+)
+
+// Transaction is an entry in the append-only XP ledger
+type Transaction struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Amount         int        `json:"amount" db:"amount"`
+	SourceType     string     `json:"source_type" db:"source_type"`
+	SourceID       *uuid.UUID `json:"source_id,omitempty" db:"source_id"`
+	Reason         string     `json:"reason,omitempty" db:"reason"`
+	IdempotencyKey string     `json:"-" db:"idempotency_key"`
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Rule prices an XP source. DailyCap limits the XP a user can earn from the source per day.
+type Rule struct {
+	SourceType  string    `json:"source_type" db:"source_type"`
+	Amount      int       `json:"amount" db:"amount"`
+	DailyCap    *int      `json:"daily_cap,omitempty" db:"daily_cap"`
+	Active      bool      `json:"active" db:"active"`
+	Description string    `json:"description,omitempty" db:"description"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// AddXPRequest represents an admin request to grant XP to a user
 type AddXPRequest struct {
-	Amount int    `json:"amount" validate:"required,gt=0"`
-	Reason string `json:"reason,omitempty"`
+	UserID         uuid.UUID `json:"user_id" validate:"required"`
+	Amount         int       `json:"amount" validate:"required,gt=0"`
+	Reason         string    `json:"reason" validate:"required,max=500"`
+	IdempotencyKey string    `json:"idempotency_key,omitempty" validate:"omitempty,max=200"`
+}
+
+// UpdateRuleRequest represents an admin request to reprice an XP source
+type UpdateRuleRequest struct {
+	Amount      int    `json:"amount" validate:"min=0"`
+	DailyCap    *int   `json:"daily_cap,omitempty" validate:"omitempty,min=0"`
+	Active      *bool  `json:"active,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 // LeaderboardEntry represents a leaderboard entry
@@ -85,3 +85,180 @@ func (r *Repository) GetLeaderboard(limit int) ([]*LeaderboardEntry, error) {
 	}
 	return entries, nil
 }
+
+// GetByUserIDForUpdate loads a user's XP row inside tx, creating the level 1 default
+// if missing, and locks it until the tx ends
+func (r *Repository) GetByUserIDForUpdate(tx *sql.Tx, userID uuid.UUID) (*UserXP, error) {
+	insert := `
+		INSERT INTO user_xp (id, user_id, total_xp, level, current_level_xp, next_level_xp, updated_at)
+		VALUES ($1, $2, 0, 1, 0, 100, $3)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+	if _, err := tx.Exec(insert, uuid.New(), userID, time.Now()); err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+
+	xp := &UserXP{}
+	query := `SELECT id, user_id, total_xp, level, current_level_xp, next_level_xp, updated_at FROM user_xp WHERE user_id = $1 FOR UPDATE`
+	err := tx.QueryRow(query, userID).Scan(&xp.ID, &xp.UserID, &xp.TotalXP, &xp.Level, &xp.CurrentLevelXP, &xp.NextLevelXP, &xp.UpdatedAt)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return xp, nil
+}
+
+// SaveTx writes a user's XP row locked by GetByUserIDForUpdate
+func (r *Repository) SaveTx(tx *sql.Tx, xp *UserXP) error {
+	query := `
+		UPDATE user_xp
+		SET total_xp = $1, level = $2, current_level_xp = $3, next_level_xp = $4, updated_at = $5
+		WHERE user_id = $6
+	`
+	if _, err := tx.Exec(query, xp.TotalXP, xp.Level, xp.CurrentLevelXP, xp.NextLevelXP, time.Now(), xp.UserID); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+// InTx runs fn in a transaction, committing if it returns nil
+func (r *Repository) InTx(fn func(tx *sql.Tx) error) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+func (r *Repository) GetRule(tx *sql.Tx, sourceType string) (*Rule, error) {
+	rule := &Rule{}
+	var description sql.NullString
+	query := `SELECT source_type, amount, daily_cap, active, description, updated_at FROM xp_rules WHERE source_type = $1`
+	err := tx.QueryRow(query, sourceType).Scan(&rule.SourceType, &rule.Amount, &rule.DailyCap, &rule.Active, &description, &rule.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	rule.Description = description.String
+	return rule, nil
+}
+
+func (r *Repository) ListRules() ([]*Rule, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	rows, err := r.db.Query(`SELECT source_type, amount, daily_cap, active, description, updated_at FROM xp_rules ORDER BY source_type`)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	var rules []*Rule
+	for rows.Next() {
+		rule := &Rule{}
+		var description sql.NullString
+		if err := rows.Scan(&rule.SourceType, &rule.Amount, &rule.DailyCap, &rule.Active, &description, &rule.UpdatedAt); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		rule.Description = description.String
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *Repository) UpsertRule(rule *Rule) error {
+	if r.db == nil {
+		return errors.ErrDatabase
+	}
+	query := `
+		INSERT INTO xp_rules (source_type, amount, daily_cap, active, description, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (source_type) DO UPDATE SET
+			amount = EXCLUDED.amount,
+			daily_cap = EXCLUDED.daily_cap,
+			active = EXCLUDED.active,
+			description = EXCLUDED.description,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(query, rule.SourceType, rule.Amount, rule.DailyCap, rule.Active, rule.Description, time.Now()).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+func (r *Repository) TransactionExists(tx *sql.Tx, idempotencyKey string) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM xp_transactions WHERE idempotency_key = $1)`, idempotencyKey).Scan(&exists)
+	if err != nil {
+		return false, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return exists, nil
+}
+
+// SumEarnedToday returns the XP a user has earned from a source since midnight
+func (r *Repository) SumEarnedToday(tx *sql.Tx, userID uuid.UUID, sourceType string) (int, error) {
+	var total int
+	query := `
+		SELECT COALESCE(SUM(amount), 0) FROM xp_transactions
+		WHERE user_id = $1 AND source_type = $2 AND created_at >= date_trunc('day', CURRENT_TIMESTAMP)
+	`
+	if err := tx.QueryRow(query, userID, sourceType).Scan(&total); err != nil {
+		return 0, errors.WrapError(err, errors.ErrDatabase)
+	}
+	return total, nil
+}
+
+func (r *Repository) CreateTransaction(tx *sql.Tx, t *Transaction) error {
+	query := `
+		INSERT INTO xp_transactions (id, user_id, amount, source_type, source_id, reason, idempotency_key, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`
+	err := tx.QueryRow(query, t.ID, t.UserID, t.Amount, t.SourceType, t.SourceID, t.Reason, t.IdempotencyKey, t.CreatedBy, time.Now()).Scan(&t.CreatedAt)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrDatabase)
+	}
+	return nil
+}
+
+func (r *Repository) ListTransactions(userID uuid.UUID, limit, offset int) ([]*Transaction, error) {
+	if r.db == nil {
+		return nil, errors.ErrDatabase
+	}
+	query := `
+		SELECT id, user_id, amount, source_type, source_id, reason, idempotency_key, created_by, created_at
+		FROM xp_transactions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrDatabase)
+	}
+	defer rows.Close()
+	var transactions []*Transaction
+	for rows.Next() {
+		t := &Transaction{}
+		var reason sql.NullString
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Amount, &t.SourceType, &t.SourceID, &reason, &t.IdempotencyKey, &t.CreatedBy, &t.CreatedAt); err != nil {
+			return nil, errors.WrapError(err, errors.ErrDatabase)
+		}
+		t.Reason = reason.String
+		transactions = append(transactions, t)
+	}
+	return transactions, nil
+}
@@ -1,7 +1,11 @@
 package xp
 
 import (
+	"database/sql"
+	"fmt"
 	"foodlink_backend/errors"
+	"foodlink_backend/events"
+	"foodlink_backend/utils"
 
 	"github.com/google/uuid"
 )
@@ -34,27 +38,227 @@ func (s *Service) GetByUserID(userID uuid.UUID) (*UserXP, error) {
 	return xp, err
 }
 
-func (s *Service) AddXP(userID uuid.UUID, amount int) (*UserXP, error) {
-	xp, err := s.GetByUserID(userID)
+// Award grants XP for an activity according to the source's rule. Each
+// (source, user) pair is rewarded at most once and the rule's daily cap is
+// enforced. It returns nil when nothing was awarded.
+func (s *Service) Award(userID uuid.UUID, sourceType string, sourceID uuid.UUID, reason string) (*Transaction, error) {
+	t := &Transaction{
+		ID:             uuid.New(),
+		UserID:         userID,
+		SourceType:     sourceType,
+		SourceID:       &sourceID,
+		Reason:         reason,
+		IdempotencyKey: fmt.Sprintf("%s:%s:%s", sourceType, sourceID, userID),
+	}
+
+	awarded := false
+	err := s.repo.InTx(func(tx *sql.Tx) error {
+		// Locking the XP row serialises awards per user so caps and idempotency checks cannot race
+		xp, err := s.repo.GetByUserIDForUpdate(tx, userID)
+		if err != nil {
+			return err
+		}
+		exists, err := s.repo.TransactionExists(tx, t.IdempotencyKey)
+		if err != nil || exists {
+			return err
+		}
+
+		rule, err := s.repo.GetRule(tx, sourceType)
+		if err == errors.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !rule.Active {
+			return nil
+		}
+
+		amount := rule.Amount
+		if rule.DailyCap != nil {
+			earned, err := s.repo.SumEarnedToday(tx, userID, sourceType)
+			if err != nil {
+				return err
+			}
+			if remaining := *rule.DailyCap - earned; remaining < amount {
+				amount = remaining
+			}
+		}
+		if amount <= 0 {
+			return nil
+		}
+
+		t.Amount = amount
+		if err := s.post(tx, xp, t); err != nil {
+			return err
+		}
+		awarded = true
+		return nil
+	})
+	if err != nil || !awarded {
+		return nil, err
+	}
+	s.publishAwarded(t)
+	return t, nil
+}
+
+// Reward grants a fixed amount of XP for an achievement that xp_rules does not
+// price, such as a badge. key identifies the achievement; each (source, key,
+// user) is rewarded at most once. It returns nil when nothing was awarded.
+func (s *Service) Reward(userID uuid.UUID, sourceType, key string, amount int, reason string) (*Transaction, error) {
+	if amount <= 0 {
+		return nil, nil
+	}
+	t := &Transaction{
+		ID:             uuid.New(),
+		UserID:         userID,
+		Amount:         amount,
+		SourceType:     sourceType,
+		Reason:         reason,
+		IdempotencyKey: fmt.Sprintf("%s:%s:%s", sourceType, key, userID),
+	}
+
+	awarded := false
+	err := s.repo.InTx(func(tx *sql.Tx) error {
+		xp, err := s.repo.GetByUserIDForUpdate(tx, userID)
+		if err != nil {
+			return err
+		}
+		exists, err := s.repo.TransactionExists(tx, t.IdempotencyKey)
+		if err != nil || exists {
+			return err
+		}
+		if err := s.post(tx, xp, t); err != nil {
+			return err
+		}
+		awarded = true
+		return nil
+	})
+	if err != nil || !awarded {
+		return nil, err
+	}
+	s.publishAwarded(t)
+	return t, nil
+}
+
+// Grant records an admin adjustment. Rules and caps do not apply; a repeated
+// idempotency key returns the user's XP without granting again.
+func (s *Service) Grant(adminID uuid.UUID, req *AddXPRequest) (*UserXP, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+
+	key := req.IdempotencyKey
+	if key == "" {
+		key = uuid.New().String()
+	}
+	t := &Transaction{
+		ID:             uuid.New(),
+		UserID:         req.UserID,
+		Amount:         req.Amount,
+		SourceType:     SourceAdminAdjustment,
+		Reason:         req.Reason,
+		IdempotencyKey: SourceAdminAdjustment + ":" + key,
+		CreatedBy:      &adminID,
+	}
+
+	var result *UserXP
+	granted := false
+	err := s.repo.InTx(func(tx *sql.Tx) error {
+		xp, err := s.repo.GetByUserIDForUpdate(tx, req.UserID)
+		if err != nil {
+			return err
+		}
+		result = xp
+		exists, err := s.repo.TransactionExists(tx, t.IdempotencyKey)
+		if err != nil || exists {
+			return err
+		}
+		if err := s.post(tx, xp, t); err != nil {
+			return err
+		}
+		granted = true
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	
+	if granted {
+		s.publishAwarded(t)
+	}
+	return result, nil
+}
+
+// GetHistory returns a page of the user's XP ledger, newest first
+func (s *Service) GetHistory(userID uuid.UUID, limit, offset int) ([]*Transaction, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.ListTransactions(userID, limit, offset)
+}
+
+func (s *Service) GetRules() ([]*Rule, error) {
+	return s.repo.ListRules()
+}
+
+func (s *Service) UpdateRule(sourceType string, req *UpdateRuleRequest) (*Rule, error) {
+	if validationErrors := utils.ValidateStruct(req); len(validationErrors) > 0 {
+		return nil, errors.NewAppErrorWithErr(errors.ErrValidationFailed.Code, "Validation failed: "+validationErrors[0], nil)
+	}
+	if sourceType == "" || sourceType == SourceAdminAdjustment || sourceType == SourceBadge {
+		return nil, errors.NewAppError(errors.ErrBadRequest.Code, "Invalid XP source type")
+	}
+
+	rule := &Rule{
+		SourceType:  sourceType,
+		Amount:      req.Amount,
+		DailyCap:    req.DailyCap,
+		Active:      true,
+		Description: req.Description,
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	if err := s.repo.UpsertRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// post appends t to the ledger and applies it to the locked XP row
+func (s *Service) post(tx *sql.Tx, xp *UserXP, t *Transaction) error {
+	if err := s.repo.CreateTransaction(tx, t); err != nil {
+		return err
+	}
+	s.applyXP(xp, t.Amount)
+	return s.repo.SaveTx(tx, xp)
+}
+
+// publishAwarded announces a committed ledger entry
+func (s *Service) publishAwarded(t *Transaction) {
+	events.Publish(events.XPAwarded{
+		Meta:          events.NewMeta(),
+		UserID:        t.UserID,
+		TransactionID: t.ID,
+		SourceType:    t.SourceType,
+		Amount:        t.Amount,
+	})
+}
+
+// applyXP adds amount to xp and levels it up as many times as the new total allows
+func (s *Service) applyXP(xp *UserXP, amount int) {
 	xp.TotalXP += amount
 	xp.CurrentLevelXP += amount
-	
+
 	// Level up logic
 	for xp.CurrentLevelXP >= xp.NextLevelXP {
 		xp.CurrentLevelXP -= xp.NextLevelXP
 		xp.Level++
 		xp.NextLevelXP = s.calculateNextLevelXP(xp.Level)
 	}
-	
-	if err := s.repo.CreateOrUpdate(xp); err != nil {
-		return nil, err
-	}
-	
-	return xp, nil
 }
 
 func (s *Service) calculateNextLevelXP(level int) int {
@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a task the scheduler runs every Interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered jobs on fixed intervals. When a database is
+// configured each run holds a Postgres advisory lock named after the job, so
+// only one replica runs a given job at a time.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []Job
+	db      *sql.DB
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewScheduler creates an empty scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job. Jobs registered after Start are not run.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+}
+
+// Start launches a goroutine per job. db may be nil, in which case runs are not
+// coordinated across replicas.
+func (s *Scheduler) Start(db *sql.DB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.db = db
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			log.Printf("Warning: job %s has no interval; not scheduled", job.Name)
+			continue
+		}
+		s.running.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop cancels the jobs and waits for in-flight runs to return or ctx to expire
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunNow runs a registered job once, outside its schedule
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	s.mu.Lock()
+	var found *Job
+	for i := range s.jobs {
+		if s.jobs[i].Name == name {
+			found = &s.jobs[i]
+			break
+		}
+	}
+	s.mu.Unlock()
+	if found == nil {
+		return fmt.Errorf("job %q is not registered", name)
+	}
+	return s.run(ctx, *found)
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.running.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.run(ctx, job); err != nil {
+				log.Printf("Error: job %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
+
+// run executes one run of job under its advisory lock. A run skipped because
+// another replica holds the lock is not an error.
+func (s *Scheduler) run(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	if s.db == nil {
+		return job.Run(ctx)
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, "job:"+job.Name).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+	if !locked {
+		return nil
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, "job:"+job.Name)
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		return err
+	}
+	log.Printf("Job %s completed in %v", job.Name, time.Since(start))
+	return nil
+}
+
+var defaultScheduler = NewScheduler()
+
+// Default returns the process-wide scheduler
+func Default() *Scheduler {
+	return defaultScheduler
+}
+
+// Start starts the default scheduler
+func Start(db *sql.DB) {
+	defaultScheduler.Start(db)
+}
+
+// Stop stops the default scheduler
+func Stop(ctx context.Context) error {
+	return defaultScheduler.Stop(ctx)
+}
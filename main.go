@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
 	_ "foodlink_backend/docs" // Import docs for Swagger
 	"foodlink_backend/config"
 	"foodlink_backend/database"
+	"foodlink_backend/database/migrations"
+	"foodlink_backend/events"
+	"foodlink_backend/features/stream"
+	"foodlink_backend/jobs"
 	"foodlink_backend/routes"
 	"foodlink_backend/utils"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 )
 
 // @title           Foodlink Backend API
@@ -39,6 +47,15 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Migration commands run and exit without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(cfg, os.Args[2:]); err != nil {
+			log.Print(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize JWT
 	utils.InitJWT(cfg)
 
@@ -47,8 +64,8 @@ func main() {
 		if err := database.Init(cfg); err != nil {
 			log.Printf("Warning: Failed to initialize database: %v", err)
 			log.Println("Server will start without database connection")
-		} else {
-			// Initialize schema if database is connected
+		} else if cfg.DBAutoMigrate {
+			// Apply pending migrations if database is connected
 			if err := database.InitSchema(); err != nil {
 				log.Printf("Warning: Failed to initialize schema: %v", err)
 			}
@@ -61,6 +78,11 @@ func main() {
 	// Setup routes with middleware
 	router := routes.SetupRoutes(cfg)
 
+	// Background jobs need the database
+	if db := database.GetDB(); db != nil {
+		jobs.Start(db)
+	}
+
 	// Start server
 	serverAddr := ":" + cfg.Port
 	log.Printf("Server starting on port %s", cfg.Port)
@@ -76,6 +98,8 @@ func main() {
 		Addr:    serverAddr,
 		Handler: router,
 	}
+	// Open event streams never go idle, so end them as shutdown begins
+	server.RegisterOnShutdown(stream.Shutdown)
 
 	// Start server in a goroutine
 	go func() {
@@ -88,6 +112,19 @@ func main() {
 	<-sigChan
 	log.Println("Shutting down server...")
 
+	// Stop taking requests and running jobs, then let queued event handlers finish before the database goes away
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+	if err := jobs.Stop(ctx); err != nil {
+		log.Printf("Error stopping background jobs: %v", err)
+	}
+	if err := events.Shutdown(ctx); err != nil {
+		log.Printf("Error draining event bus: %v", err)
+	}
+
 	// Close database connection
 	if err := database.Close(); err != nil {
 		log.Printf("Error closing database: %v", err)
@@ -95,3 +132,59 @@ func main() {
 
 	log.Println("Server stopped")
 }
+
+// runMigrateCommand handles `migrate up|down [steps]|status|redo`. It returns
+// errors rather than exiting so the database, and the migration lock held on
+// its connection, are always released.
+func runMigrateCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [steps]|status|redo")
+	}
+	steps := 1
+	switch args[0] {
+	case "up", "status", "redo":
+	case "down":
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+			steps = n
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q (expected up, down, status or redo)", args[0])
+	}
+
+	if err := database.Init(cfg); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer database.Close()
+
+	db := database.GetDB()
+	var err error
+	switch args[0] {
+	case "up":
+		err = migrations.Up(db)
+	case "down":
+		err = migrations.Down(db, steps)
+	case "redo":
+		err = migrations.Redo(db)
+	case "status":
+		var statuses []migrations.MigrationStatus
+		statuses, err = migrations.Status(db)
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			if status.Drifted {
+				state += " (drifted)"
+			}
+			fmt.Printf("%05d  %-40s %s\n", status.Version, status.Name, state)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("migration %s failed: %w", args[0], err)
+	}
+	return nil
+}
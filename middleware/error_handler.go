@@ -41,6 +41,19 @@ func (rw *errorResponseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Flush sends buffered data to the client, so streaming responses work
+// through the error handler
+func (rw *errorResponseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *errorResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // handleError processes and sends error responses
 func handleError(w http.ResponseWriter, r *http.Request, err error, statusCode int) {
 	requestID := GetRequestID(r)
@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"foodlink_backend/features/auth"
+	"net/http"
+)
+
+var (
+	public      = []string{auth.RolePublic}
+	anyUser     = []string{auth.RoleFamily, auth.RoleRestaurant, auth.RoleShop, auth.RoleNGO, auth.RoleAdmin}
+	household   = []string{auth.RoleFamily, auth.RoleAdmin}
+	restaurants = []string{auth.RoleRestaurant, auth.RoleAdmin}
+	ngos        = []string{auth.RoleNGO, auth.RoleAdmin}
+	shops       = []string{auth.RoleShop, auth.RoleAdmin}
+	admins      = []string{auth.RoleAdmin}
+)
+
+// routePolicies is the access policy for every route group mounted in SetupRoutes,
+// keyed by mount path. A mount without a policy rejects every request.
+var routePolicies = map[string]auth.MethodRoles{
+	"/health":  {"*": public},
+	"/api/v1":  {"*": public},
+	"/swagger": {"*": public},
+
+	// The auth router authenticates /me, /refresh and /logout itself
+	"/api/v1/auth": {"*": public},
+
+	"/api/v1/food-items":        {http.MethodGet: public, "*": admins},
+	"/api/v1/price-comparisons": {http.MethodGet: public, "*": {auth.RoleShop, auth.RoleAdmin}},
+
+	// Family household features
+	"/api/v1/households":    {"*": household},
+	"/api/v1/inventory":     {"*": household},
+	"/api/v1/shopping-list": {"*": household},
+	"/api/v1/consumption":   {"*": household},
+	"/api/v1/meal-plans":    {"*": household},
+	"/api/v1/preferences":   {"*": household},
+	"/api/v1/nutrition":     {"*": household},
+
+	// Gamification
+	"/api/v1/badges":   {"*": anyUser},
+	"/api/v1/xp":       {"*": anyUser},
+	"/api/v1/xp/add":   {"*": admins},
+	"/api/v1/xp/rules": {"*": admins},
+
+	// Notifications across the community and NGO channels, and the live stream
+	"/api/v1/notifications": {"*": anyUser},
+	"/api/v1/stream":        {"*": anyUser},
+
+	// Uploads; downloads are authorized by their signed URL
+	"/api/v1/uploads": {"*": anyUser},
+	"/api/v1/files":   {"*": public},
+
+	// Outbound webhooks for restaurant and NGO systems
+	"/api/v1/webhooks": {"*": {auth.RoleRestaurant, auth.RoleNGO, auth.RoleAdmin}},
+
+	// Point-of-sale ingestion for restaurant and shop tills
+	"/api/v1/sales": {"*": {auth.RoleRestaurant, auth.RoleShop, auth.RoleAdmin}},
+
+	// Community
+	"/api/v1/community/surplus":        {"*": anyUser},
+	"/api/v1/community/leftovers":      {"*": anyUser},
+	"/api/v1/community/kitchen-events": {"*": anyUser},
+	"/api/v1/community/leaderboard":    {"*": anyUser},
+	"/api/v1/community/impact":         {"*": anyUser},
+	"/api/v1/community/profile":        {"*": anyUser},
+	"/api/v1/impact":                   {http.MethodGet: anyUser, "*": admins},
+
+	// Restaurant module
+	"/api/v1/restaurant/inventory":   {"*": restaurants},
+	"/api/v1/restaurant/menu":        {"*": restaurants},
+	"/api/v1/restaurant/surplus":     {"*": restaurants},
+	"/api/v1/restaurant/donations":   {"*": restaurants},
+	"/api/v1/restaurant/impact":      {"*": restaurants},
+	"/api/v1/restaurant/tasks":       {"*": restaurants},
+	"/api/v1/restaurant/shifts":      {"*": restaurants},
+	"/api/v1/restaurant/preferences": {"*": restaurants},
+	"/api/v1/restaurant/forecast":    {"*": restaurants},
+
+	// NGO module
+	"/api/v1/ngos":         {"*": anyUser},
+	"/api/v1/ngo/capacity": {"*": ngos},
+	"/api/v1/ngo/offers":   {"*": ngos},
+	"/api/v1/ngo/pickups":  {"*": ngos},
+	"/api/v1/ngo/routes":   {"*": ngos},
+	"/api/v1/ngo/history":  {"*": ngos},
+	"/api/v1/ngo/partners": {"*": ngos},
+	"/api/v1/ngo/feedback": {"*": ngos},
+	"/api/v1/ngo/stories":  {"*": ngos},
+
+	// Shop module
+	"/api/v1/shop/analytics": {"*": shops},
+	"/api/v1/shop/inventory": {"*": shops},
+	"/api/v1/shop/markdown":  {"*": shops},
+	"/api/v1/shop/profile":   {"*": shops},
+	"/api/v1/shop/staff":     {"*": shops},
+	"/api/v1/shop/surplus":   {"*": shops},
+}
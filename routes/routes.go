@@ -3,6 +3,7 @@ package routes
 import (
 	"foodlink_backend/config"
 	_ "foodlink_backend/docs" // Import docs for Swagger
+	"foodlink_backend/events"
 	"foodlink_backend/features/auth"
 	"foodlink_backend/features/badges"
 	"foodlink_backend/features/community/kitchen_events"
@@ -12,6 +13,8 @@ import (
 	"foodlink_backend/features/community/surplus"
 	"foodlink_backend/features/consumption"
 	"foodlink_backend/features/food_items"
+	"foodlink_backend/features/households"
+	"foodlink_backend/features/impact"
 	"foodlink_backend/features/inventory"
 	"foodlink_backend/features/meal_plans"
 	ngo_capacity "foodlink_backend/features/ngo/capacity"
@@ -20,20 +23,36 @@ import (
 	ngo_offers "foodlink_backend/features/ngo/offers"
 	ngo_partners "foodlink_backend/features/ngo/partners"
 	ngo_pickups "foodlink_backend/features/ngo/pickups"
+	ngo_routes "foodlink_backend/features/ngo/routes"
+	"foodlink_backend/features/notifications"
 	"foodlink_backend/features/nutrition"
 	"foodlink_backend/features/preferences"
 	"foodlink_backend/features/price_comparisons"
+	"foodlink_backend/features/sales"
 	"foodlink_backend/features/shopping_list"
+	"foodlink_backend/features/stream"
+	"foodlink_backend/features/uploads"
+	"foodlink_backend/features/webhooks"
 	restaurant_donations "foodlink_backend/features/restaurant/donations"
+	restaurant_forecast "foodlink_backend/features/restaurant/forecast"
 	restaurant_inventory "foodlink_backend/features/restaurant/inventory"
 	restaurant_menu "foodlink_backend/features/restaurant/menu"
 	restaurant_preferences "foodlink_backend/features/restaurant/preferences"
 	restaurant_staff "foodlink_backend/features/restaurant/staff"
 	restaurant_surplus "foodlink_backend/features/restaurant/surplus"
+	shop_analytics "foodlink_backend/features/shop/analytics"
+	shop_inventory "foodlink_backend/features/shop/inventory"
+	shop_markdown "foodlink_backend/features/shop/markdown"
+	shop_profile "foodlink_backend/features/shop/profile"
+	shop_staff "foodlink_backend/features/shop/staff"
+	shop_surplus "foodlink_backend/features/shop/surplus"
 	"foodlink_backend/features/xp"
 	"foodlink_backend/handlers"
+	"foodlink_backend/jobs"
 	"foodlink_backend/middleware"
+	"log"
 	"net/http"
+	"strings"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -52,186 +71,345 @@ func mountWithOptionalSlash(mux *http.ServeMux, prefix string, handler http.Hand
 	}))
 }
 
+// router records every mount so each one is wrapped in its access policy
+type router struct {
+	mux         *http.ServeMux
+	authService *auth.Service
+	mounted     []string
+}
+
+// mount registers a feature router under prefix, guarded by the prefix's policy
+func (rt *router) mount(prefix string, handler http.Handler) {
+	mountWithOptionalSlash(rt.mux, prefix, rt.protect(prefix, handler))
+}
+
+// handle registers handler for an exact mux pattern, guarded by the pattern's policy
+func (rt *router) handle(pattern string, handler http.Handler) {
+	rt.mux.Handle(pattern, rt.protect(strings.TrimSuffix(pattern, "/"), handler))
+}
+
+func (rt *router) protect(key string, handler http.Handler) http.Handler {
+	rt.mounted = append(rt.mounted, key)
+
+	policy, ok := routePolicies[key]
+	if !ok {
+		log.Printf("Warning: no access policy for %s; all requests will be rejected", key)
+	}
+
+	return auth.Authorize(rt.authService, policy)(handler)
+}
+
 func SetupRoutes(cfg *config.Config) http.Handler {
-	mux := http.NewServeMux()
+	rt := registerRoutes(cfg, events.Default(), jobs.Default())
+
+	// Apply middleware chain
+	handler := middleware.Chain(
+		middleware.RecoverPanic,
+		middleware.RequestID,
+		middleware.Logging,
+		middleware.CORS,
+		middleware.ErrorHandler,
+	)(rt.mux)
+
+	return handler
+}
+
+// registerRoutes builds the mux with every feature mounted under its access
+// policy. Event subscribers and background jobs are registered on bus and
+// scheduler, so each call should be given its own.
+func registerRoutes(cfg *config.Config, bus *events.Bus, scheduler *jobs.Scheduler) *router {
+	authService := auth.NewService(cfg)
+	rt := &router{
+		mux:         http.NewServeMux(),
+		authService: authService,
+	}
 
 	// Health check endpoint (no middleware needed)
-	mux.HandleFunc("/health", handlers.HealthCheck)
+	rt.handle("/health", http.HandlerFunc(handlers.HealthCheck))
 
 	// API routes
-	mux.HandleFunc("/api/v1/", handlers.APIV1)
+	rt.handle("/api/v1/", http.HandlerFunc(handlers.APIV1))
 
 	// Authentication routes
-	authService := auth.NewService(cfg)
 	authHandler := auth.NewHandler(authService)
 	authRoutes := auth.SetupRoutes(authService, authHandler)
-	mountWithOptionalSlash(mux, "/api/v1/auth", authRoutes)
+	rt.mount("/api/v1/auth", authRoutes)
 
 	// Food Items routes (public, but admin-only for create/update/delete)
 	foodItemsService := food_items.NewService()
 	foodItemsHandler := food_items.NewHandler(foodItemsService)
 	foodItemsRoutes := food_items.SetupRoutes(foodItemsHandler)
-	mountWithOptionalSlash(mux, "/api/v1/food-items", foodItemsRoutes)
+	rt.mount("/api/v1/food-items", foodItemsRoutes)
+
+	// Households routes (protected)
+	householdsService := households.NewService()
+	householdsHandler := households.NewHandler(householdsService)
+	householdsRoutes := households.SetupRoutes(householdsService, householdsHandler, auth.AuthMiddleware(authService))
+	rt.mount("/api/v1/households", householdsRoutes)
 
 	// Inventory routes (protected)
 	inventoryService := inventory.NewService()
 	inventoryHandler := inventory.NewHandler(inventoryService)
 	inventoryRoutes := inventory.SetupRoutes(inventoryService, inventoryHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/inventory", inventoryRoutes)
+	rt.mount("/api/v1/inventory", inventoryRoutes)
 
 	// Shopping List routes (protected)
 	shoppingListService := shopping_list.NewService()
 	shoppingListHandler := shopping_list.NewHandler(shoppingListService)
 	shoppingListRoutes := shopping_list.SetupRoutes(shoppingListService, shoppingListHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/shopping-list", shoppingListRoutes)
+	rt.mount("/api/v1/shopping-list", shoppingListRoutes)
 
 	// Consumption routes (protected)
 	consumptionService := consumption.NewService()
 	consumptionHandler := consumption.NewHandler(consumptionService)
 	consumptionRoutes := consumption.SetupRoutes(consumptionService, consumptionHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/consumption", consumptionRoutes)
+	rt.mount("/api/v1/consumption", consumptionRoutes)
 
 	// Meal Plans routes (protected)
 	mealPlansService := meal_plans.NewService()
 	mealPlansHandler := meal_plans.NewHandler(mealPlansService)
 	mealPlansRoutes := meal_plans.SetupRoutes(mealPlansService, mealPlansHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/meal-plans", mealPlansRoutes)
+	rt.mount("/api/v1/meal-plans", mealPlansRoutes)
 
 	// Preferences routes (protected)
 	preferencesService := preferences.NewService()
 	preferencesHandler := preferences.NewHandler(preferencesService)
 	preferencesRoutes := preferences.SetupRoutes(preferencesService, preferencesHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/preferences", preferencesRoutes)
+	rt.mount("/api/v1/preferences", preferencesRoutes)
 
 	// Nutrition routes (protected)
 	nutritionService := nutrition.NewService()
 	nutritionHandler := nutrition.NewHandler(nutritionService)
 	nutritionRoutes := nutrition.SetupRoutes(nutritionService, nutritionHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/nutrition", nutritionRoutes)
+	rt.mount("/api/v1/nutrition", nutritionRoutes)
 
-	// Price Comparisons routes (public)
+	// Price Comparisons routes (public reads, shop/admin writes)
 	priceComparisonsService := price_comparisons.NewService()
 	priceComparisonsHandler := price_comparisons.NewHandler(priceComparisonsService)
 	priceComparisonsRoutes := price_comparisons.SetupRoutes(priceComparisonsHandler)
-	mountWithOptionalSlash(mux, "/api/v1/price-comparisons", priceComparisonsRoutes)
+	rt.mount("/api/v1/price-comparisons", priceComparisonsRoutes)
 
 	// Badges routes (protected)
 	badgesService := badges.NewService()
 	badgesHandler := badges.NewHandler(badgesService)
 	badgesRoutes := badges.SetupRoutes(badgesService, badgesHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/badges", badgesRoutes)
+	badgesService.RegisterEventHandlers(bus)
+	badgesService.RegisterJobs(scheduler, cfg.BadgeEvaluationInterval)
+	rt.mount("/api/v1/badges", badgesRoutes)
 
-	// XP routes (protected)
+	// XP routes (protected; grants and rules are admin-only)
 	xpService := xp.NewService()
 	xpHandler := xp.NewHandler(xpService)
 	xpRoutes := xp.SetupRoutes(xpService, xpHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/xp", xpRoutes)
+	xpService.RegisterEventHandlers(bus)
+	rt.mount("/api/v1/xp", xpRoutes)
+	rt.handle("/api/v1/xp/add", http.StripPrefix("/api/v1/xp", xpRoutes))
+	rt.handle("/api/v1/xp/rules", http.StripPrefix("/api/v1/xp", xpRoutes))
+	rt.handle("/api/v1/xp/rules/", http.StripPrefix("/api/v1/xp", xpRoutes))
+
+	// Notifications routes (protected)
+	notificationsService := notifications.NewService()
+	notificationsHandler := notifications.NewHandler(notificationsService)
+	notificationsRoutes := notifications.SetupRoutes(notificationsService, notificationsHandler, auth.AuthMiddleware(authService))
+	notificationsService.RegisterEventHandlers(bus)
+	rt.mount("/api/v1/notifications", notificationsRoutes)
+
+	// Live event stream (protected)
+	streamService := stream.NewService()
+	streamHandler := stream.NewHandler(streamService)
+	streamRoutes := stream.SetupRoutes(streamService, streamHandler, auth.AuthMiddleware(authService))
+	streamService.RegisterEventHandlers(bus)
+	rt.mount("/api/v1/stream", streamRoutes)
+
+	// File uploads (protected) and their signed downloads (public)
+	uploadsService := uploads.NewService(cfg)
+	uploadsHandler := uploads.NewHandler(uploadsService)
+	rt.mount("/api/v1/uploads", uploads.SetupRoutes(uploadsService, uploadsHandler, auth.AuthMiddleware(authService)))
+	rt.mount("/api/v1/files", uploads.SetupContentRoutes(uploadsService, uploadsHandler))
+
+	// Outbound webhooks (protected)
+	webhooksService := webhooks.NewService()
+	webhooksHandler := webhooks.NewHandler(webhooksService)
+	webhooksRoutes := webhooks.SetupRoutes(webhooksService, webhooksHandler, auth.AuthMiddleware(authService))
+	webhooksService.RegisterEventHandlers(bus)
+	webhooksService.RegisterJobs(scheduler, cfg.WebhookDeliveryInterval)
+	rt.mount("/api/v1/webhooks", webhooksRoutes)
+
+	// Point-of-sale ingestion routes (protected)
+	salesService := sales.NewService()
+	salesHandler := sales.NewHandler(salesService)
+	salesRoutes := sales.SetupRoutes(salesService, salesHandler, auth.AuthMiddleware(authService))
+	rt.mount("/api/v1/sales", salesRoutes)
 
 	// Community Surplus routes (protected)
 	surplusService := surplus.NewService()
 	surplusHandler := surplus.NewHandler(surplusService)
 	surplusRoutes := surplus.SetupRoutes(surplusService, surplusHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/community/surplus", surplusRoutes)
+	rt.mount("/api/v1/community/surplus", surplusRoutes)
 
 	// Community Leftovers routes (protected)
 	leftoversService := leftovers.NewService()
 	leftoversHandler := leftovers.NewHandler(leftoversService)
 	leftoversRoutes := leftovers.SetupRoutes(leftoversService, leftoversHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/community/leftovers", leftoversRoutes)
+	rt.mount("/api/v1/community/leftovers", leftoversRoutes)
 
 	// Community Kitchen Events routes (protected)
 	kitchenEventsService := kitchen_events.NewService()
 	kitchenEventsHandler := kitchen_events.NewHandler(kitchenEventsService)
 	kitchenEventsRoutes := kitchen_events.SetupRoutes(kitchenEventsService, kitchenEventsHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/community/kitchen-events", kitchenEventsRoutes)
+	rt.mount("/api/v1/community/kitchen-events", kitchenEventsRoutes)
 
 	// Community Leaderboard & Impact routes (protected)
 	leaderboardService := leaderboard.NewService()
 	leaderboardHandler := leaderboard.NewHandler(leaderboardService)
 	leaderboardRoutes := leaderboard.SetupRoutes(leaderboardService, leaderboardHandler, auth.AuthMiddleware(authService))
-	mux.Handle("/api/v1/community/leaderboard", http.StripPrefix("/api/v1/community", leaderboardRoutes))
-	mux.Handle("/api/v1/community/impact/", http.StripPrefix("/api/v1/community", leaderboardRoutes))
+	leaderboardService.RegisterEventHandlers(bus)
+	leaderboardService.RegisterJobs(scheduler, cfg.LeaderboardRefreshInterval, cfg.ImpactRefreshInterval)
+	rt.handle("/api/v1/community/leaderboard", http.StripPrefix("/api/v1/community", leaderboardRoutes))
+	rt.handle("/api/v1/community/impact/", http.StripPrefix("/api/v1/community", leaderboardRoutes))
+
+	// Impact factor routes (protected)
+	impactService := impact.NewService()
+	impactHandler := impact.NewHandler(impactService)
+	impactRoutes := impact.SetupRoutes(impactService, impactHandler, auth.AuthMiddleware(authService))
+	rt.mount("/api/v1/impact", impactRoutes)
 
 	// Community Profiles routes (protected)
 	profilesService := profiles.NewService()
 	profilesHandler := profiles.NewHandler(profilesService)
 	profilesRoutes := profiles.SetupRoutes(profilesService, profilesHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/community/profile", profilesRoutes)
+	rt.mount("/api/v1/community/profile", profilesRoutes)
 
 	// Restaurant Inventory routes (protected)
 	restaurantInventoryService := restaurant_inventory.NewService()
 	restaurantInventoryHandler := restaurant_inventory.NewHandler(restaurantInventoryService)
 	restaurantInventoryRoutes := restaurant_inventory.SetupRoutes(restaurantInventoryService, restaurantInventoryHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/restaurant/inventory", restaurantInventoryRoutes)
+	rt.mount("/api/v1/restaurant/inventory", restaurantInventoryRoutes)
 
 	// Restaurant Menu routes (protected)
 	restaurantMenuService := restaurant_menu.NewService()
 	restaurantMenuHandler := restaurant_menu.NewHandler(restaurantMenuService)
 	restaurantMenuRoutes := restaurant_menu.SetupRoutes(restaurantMenuService, restaurantMenuHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/restaurant/menu", restaurantMenuRoutes)
+	restaurantMenuService.RegisterEventHandlers(bus)
+	rt.mount("/api/v1/restaurant/menu", restaurantMenuRoutes)
 
 	// Restaurant Surplus routes (protected)
 	restaurantSurplusService := restaurant_surplus.NewService()
 	restaurantSurplusHandler := restaurant_surplus.NewHandler(restaurantSurplusService)
 	restaurantSurplusRoutes := restaurant_surplus.SetupRoutes(restaurantSurplusService, restaurantSurplusHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/restaurant/surplus", restaurantSurplusRoutes)
+	rt.mount("/api/v1/restaurant/surplus", restaurantSurplusRoutes)
 
 	// Restaurant Donations & Impact routes (protected)
 	restaurantDonationsService := restaurant_donations.NewService()
 	restaurantDonationsHandler := restaurant_donations.NewHandler(restaurantDonationsService)
 	restaurantDonationsRoutes := restaurant_donations.SetupRoutes(restaurantDonationsService, restaurantDonationsHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/restaurant/donations", restaurantDonationsRoutes)
-	mux.Handle("/api/v1/restaurant/impact", http.StripPrefix("/api/v1/restaurant", restaurantDonationsRoutes))
+	restaurantDonationsService.RegisterEventHandlers(bus)
+	restaurantDonationsService.RegisterJobs(scheduler, cfg.ImpactRefreshInterval)
+	rt.mount("/api/v1/restaurant/donations", restaurantDonationsRoutes)
+	rt.handle("/api/v1/restaurant/impact", http.StripPrefix("/api/v1/restaurant", restaurantDonationsRoutes))
 
 	// Restaurant Staff Management routes (protected)
 	restaurantStaffService := restaurant_staff.NewService()
 	restaurantStaffHandler := restaurant_staff.NewHandler(restaurantStaffService)
 	restaurantStaffRoutes := restaurant_staff.SetupRoutes(restaurantStaffService, restaurantStaffHandler, auth.AuthMiddleware(authService))
-	mux.Handle("/api/v1/restaurant/tasks/", http.StripPrefix("/api/v1/restaurant", restaurantStaffRoutes))
-	mux.Handle("/api/v1/restaurant/shifts", http.StripPrefix("/api/v1/restaurant", restaurantStaffRoutes))
+	rt.handle("/api/v1/restaurant/tasks/", http.StripPrefix("/api/v1/restaurant", restaurantStaffRoutes))
+	rt.handle("/api/v1/restaurant/shifts", http.StripPrefix("/api/v1/restaurant", restaurantStaffRoutes))
 
 	// Restaurant Preferences routes (protected)
 	restaurantPreferencesService := restaurant_preferences.NewService()
 	restaurantPreferencesHandler := restaurant_preferences.NewHandler(restaurantPreferencesService)
 	restaurantPreferencesRoutes := restaurant_preferences.SetupRoutes(restaurantPreferencesService, restaurantPreferencesHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/restaurant/preferences", restaurantPreferencesRoutes)
+	rt.mount("/api/v1/restaurant/preferences", restaurantPreferencesRoutes)
+
+	// Restaurant Forecast routes (protected)
+	restaurantForecastService := restaurant_forecast.NewService()
+	restaurantForecastHandler := restaurant_forecast.NewHandler(restaurantForecastService)
+	restaurantForecastRoutes := restaurant_forecast.SetupRoutes(restaurantForecastService, restaurantForecastHandler, auth.AuthMiddleware(authService))
+	rt.mount("/api/v1/restaurant/forecast", restaurantForecastRoutes)
 
 	// NGO Capacity Settings routes (protected)
 	ngoCapacityService := ngo_capacity.NewService()
 	ngoCapacityHandler := ngo_capacity.NewHandler(ngoCapacityService)
 	ngoCapacityRoutes := ngo_capacity.SetupRoutes(ngoCapacityService, ngoCapacityHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/ngo/capacity", ngoCapacityRoutes)
+	rt.mount("/api/v1/ngo/capacity", ngoCapacityRoutes)
+	rt.mount("/api/v1/ngos", ngo_capacity.SetupDirectoryRoutes(ngoCapacityService, ngoCapacityHandler, auth.AuthMiddleware(authService)))
 
 	// NGO Donation Offers routes (protected)
 	ngoOffersService := ngo_offers.NewService()
 	ngoOffersHandler := ngo_offers.NewHandler(ngoOffersService)
 	ngoOffersRoutes := ngo_offers.SetupRoutes(ngoOffersService, ngoOffersHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/ngo/offers", ngoOffersRoutes)
+	ngoOffersService.RegisterEventHandlers(bus)
+	rt.mount("/api/v1/ngo/offers", ngoOffersRoutes)
 
 	// NGO Pickup Schedules routes (protected)
 	ngoPickupsService := ngo_pickups.NewService()
 	ngoPickupsHandler := ngo_pickups.NewHandler(ngoPickupsService)
 	ngoPickupsRoutes := ngo_pickups.SetupRoutes(ngoPickupsService, ngoPickupsHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/ngo/pickups", ngoPickupsRoutes)
+	rt.mount("/api/v1/ngo/pickups", ngoPickupsRoutes)
+
+	// NGO Pickup Route Planning routes (protected)
+	ngoRoutesService := ngo_routes.NewService()
+	ngoRoutesHandler := ngo_routes.NewHandler(ngoRoutesService)
+	ngoRoutesRoutes := ngo_routes.SetupRoutes(ngoRoutesService, ngoRoutesHandler, auth.AuthMiddleware(authService))
+	rt.mount("/api/v1/ngo/routes", ngoRoutesRoutes)
 
 	// NGO Donation History routes (protected)
 	ngoHistoryService := ngo_history.NewService()
 	ngoHistoryHandler := ngo_history.NewHandler(ngoHistoryService)
 	ngoHistoryRoutes := ngo_history.SetupRoutes(ngoHistoryService, ngoHistoryHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/ngo/history", ngoHistoryRoutes)
+	rt.mount("/api/v1/ngo/history", ngoHistoryRoutes)
 
 	// NGO Partner Management routes (protected)
 	ngoPartnersService := ngo_partners.NewService()
 	ngoPartnersHandler := ngo_partners.NewHandler(ngoPartnersService)
 	ngoPartnersRoutes := ngo_partners.SetupRoutes(ngoPartnersService, ngoPartnersHandler, auth.AuthMiddleware(authService))
-	mountWithOptionalSlash(mux, "/api/v1/ngo/partners", ngoPartnersRoutes)
+	rt.mount("/api/v1/ngo/partners", ngoPartnersRoutes)
 
 	// NGO Feedback & Impact routes (protected)
 	ngoFeedbackService := ngo_feedback.NewService()
 	ngoFeedbackHandler := ngo_feedback.NewHandler(ngoFeedbackService)
 	ngoFeedbackRoutes := ngo_feedback.SetupRoutes(ngoFeedbackService, ngoFeedbackHandler, auth.AuthMiddleware(authService))
-	mux.Handle("/api/v1/ngo/feedback", http.StripPrefix("/api/v1/ngo", ngoFeedbackRoutes))
-	mux.Handle("/api/v1/ngo/stories", http.StripPrefix("/api/v1/ngo", ngoFeedbackRoutes))
+	rt.handle("/api/v1/ngo/feedback", http.StripPrefix("/api/v1/ngo", ngoFeedbackRoutes))
+	rt.handle("/api/v1/ngo/stories", http.StripPrefix("/api/v1/ngo", ngoFeedbackRoutes))
+
+	// Shop Analytics routes (protected)
+	shopAnalyticsService := shop_analytics.NewService()
+	shopAnalyticsHandler := shop_analytics.NewHandler(shopAnalyticsService)
+	shopAnalyticsRoutes := shop_analytics.SetupRoutes(shopAnalyticsService, shopAnalyticsHandler, auth.AuthMiddleware(authService))
+	shopAnalyticsService.RegisterJobs(scheduler, cfg.ShopAnalyticsInterval)
+	rt.mount("/api/v1/shop/analytics", shopAnalyticsRoutes)
+
+	// Shop Inventory routes (protected)
+	shopInventoryService := shop_inventory.NewService()
+	shopInventoryHandler := shop_inventory.NewHandler(shopInventoryService)
+	shopInventoryRoutes := shop_inventory.SetupRoutes(shopInventoryService, shopInventoryHandler, auth.AuthMiddleware(authService))
+	rt.mount("/api/v1/shop/inventory", shopInventoryRoutes)
+
+	// Shop Markdown Pricing routes (protected)
+	shopMarkdownService := shop_markdown.NewService()
+	shopMarkdownHandler := shop_markdown.NewHandler(shopMarkdownService)
+	shopMarkdownRoutes := shop_markdown.SetupRoutes(shopMarkdownService, shopMarkdownHandler, auth.AuthMiddleware(authService))
+	shopMarkdownService.RegisterJobs(scheduler, cfg.ShopMarkdownInterval, cfg.ShopPriceApplyInterval)
+	rt.mount("/api/v1/shop/markdown", shopMarkdownRoutes)
+
+	// Shop Profile routes (protected)
+	shopProfileService := shop_profile.NewService()
+	shopProfileHandler := shop_profile.NewHandler(shopProfileService)
+	shopProfileRoutes := shop_profile.SetupRoutes(shopProfileService, shopProfileHandler, auth.AuthMiddleware(authService))
+	rt.mount("/api/v1/shop/profile", shopProfileRoutes)
+
+	// Shop Staff, Tasks & Shifts routes (protected)
+	shopStaffService := shop_staff.NewService()
+	shopStaffHandler := shop_staff.NewHandler(shopStaffService)
+	shopStaffRoutes := shop_staff.SetupRoutes(shopStaffService, shopStaffHandler, auth.AuthMiddleware(authService))
+	rt.mount("/api/v1/shop/staff", shopStaffRoutes)
+
+	// Shop Surplus routes (protected)
+	shopSurplusService := shop_surplus.NewService()
+	shopSurplusHandler := shop_surplus.NewHandler(shopSurplusService)
+	shopSurplusRoutes := shop_surplus.SetupRoutes(shopSurplusService, shopSurplusHandler, auth.AuthMiddleware(authService))
+	shopSurplusService.RegisterJobs(scheduler, cfg.ShopSurplusInterval, cfg.ShopSurplusReminderLead)
+	rt.mount("/api/v1/shop/surplus", shopSurplusRoutes)
 
 	// Swagger documentation with CORS support
 	swaggerHandler := httpSwagger.Handler(
@@ -255,21 +433,12 @@ func SetupRoutes(cfg *config.Config) http.Handler {
 		swaggerHandler.ServeHTTP(w, r)
 	})
 
-	mux.Handle("/swagger/", corsSwaggerHandler)
+	rt.handle("/swagger/", corsSwaggerHandler)
 
 	// Redirect /swagger to /swagger/index.html
-	mux.HandleFunc("/swagger", func(w http.ResponseWriter, r *http.Request) {
+	rt.handle("/swagger", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/swagger/index.html", http.StatusMovedPermanently)
-	})
-
-	// Apply middleware chain
-	handler := middleware.Chain(
-		middleware.RecoverPanic,
-		middleware.RequestID,
-		middleware.Logging,
-		middleware.CORS,
-		middleware.ErrorHandler,
-	)(mux)
+	}))
 
-	return handler
+	return rt
 }
@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"context"
+	"foodlink_backend/config"
+	"foodlink_backend/events"
+	"foodlink_backend/features/auth"
+	"foodlink_backend/jobs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var policyMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// testRouter builds a router whose subscribers and jobs go to a bus and
+// scheduler of its own, so routers built by different tests do not stack
+// handlers on the process-wide defaults
+func testRouter(t *testing.T) *router {
+	t.Helper()
+	bus := events.NewBus(events.Options{Workers: 1})
+	t.Cleanup(func() { bus.Shutdown(context.Background()) })
+	return registerRoutes(&config.Config{JWTSecret: "test-secret", JWTExpiry: "1h"}, bus, jobs.NewScheduler())
+}
+
+func TestEveryMountedRouteHasPolicy(t *testing.T) {
+	rt := testRouter(t)
+
+	for _, key := range rt.mounted {
+		policy, ok := routePolicies[key]
+		if !ok {
+			t.Errorf("route %s is mounted without an access policy", key)
+			continue
+		}
+		for _, method := range policyMethods {
+			if len(policy.RolesFor(method)) == 0 {
+				t.Errorf("route %s has no policy for %s", key, method)
+			}
+		}
+	}
+}
+
+func TestEveryPolicyIsMounted(t *testing.T) {
+	rt := testRouter(t)
+
+	mounted := make(map[string]bool, len(rt.mounted))
+	for _, key := range rt.mounted {
+		mounted[key] = true
+	}
+
+	for key := range routePolicies {
+		if !mounted[key] {
+			t.Errorf("policy for %s does not match any mounted route", key)
+		}
+	}
+}
+
+func TestRoleEnforcement(t *testing.T) {
+	rt := testRouter(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		role   string
+		want   int
+	}{
+		{"family blocked from restaurant", http.MethodGet, "/api/v1/restaurant/inventory", auth.RoleFamily, http.StatusForbidden},
+		{"family blocked from ngo", http.MethodGet, "/api/v1/ngo/offers", auth.RoleFamily, http.StatusForbidden},
+		{"family blocked from food item writes", http.MethodPost, "/api/v1/food-items", auth.RoleFamily, http.StatusForbidden},
+		{"restaurant blocked from household inventory", http.MethodGet, "/api/v1/inventory", auth.RoleRestaurant, http.StatusForbidden},
+		{"family blocked from granting xp", http.MethodPost, "/api/v1/xp/add", auth.RoleFamily, http.StatusForbidden},
+		{"family blocked from xp rules", http.MethodPut, "/api/v1/xp/rules/consumption_log", auth.RoleFamily, http.StatusForbidden},
+		{"restaurant blocked from impact factor writes", http.MethodPut, "/api/v1/impact/factors/meat", auth.RoleRestaurant, http.StatusForbidden},
+		{"family blocked from webhooks", http.MethodGet, "/api/v1/webhooks", auth.RoleFamily, http.StatusForbidden},
+		{"restaurant blocked from shop inventory", http.MethodGet, "/api/v1/shop/inventory/barcode/4006381333931", auth.RoleRestaurant, http.StatusForbidden},
+		{"anonymous price comparison create", http.MethodPost, "/api/v1/price-comparisons", "", http.StatusUnauthorized},
+		{"anonymous restaurant access", http.MethodGet, "/api/v1/restaurant/menu", "", http.StatusUnauthorized},
+		{"restaurant allowed on restaurant routes", http.MethodGet, "/api/v1/restaurant/inventory", auth.RoleRestaurant, 0},
+		{"ngo allowed on ngo routes", http.MethodGet, "/api/v1/ngo/offers", auth.RoleNGO, 0},
+		{"restaurant allowed on ngo directory", http.MethodGet, "/api/v1/ngos", auth.RoleRestaurant, 0},
+		{"restaurant allowed on webhooks", http.MethodGet, "/api/v1/webhooks", auth.RoleRestaurant, 0},
+		{"shop allowed on shop routes", http.MethodGet, "/api/v1/shop/staff/tasks", auth.RoleShop, 0},
+		{"admin allowed everywhere", http.MethodGet, "/api/v1/ngo/offers", auth.RoleAdmin, 0},
+		{"anonymous price comparison read", http.MethodGet, "/api/v1/price-comparisons", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.role != "" {
+				user := &auth.User{Email: "test@example.com", Role: tt.role}
+				req = req.WithContext(context.WithValue(req.Context(), "user", user))
+			}
+			rec := httptest.NewRecorder()
+
+			rt.mux.ServeHTTP(rec, req)
+
+			if tt.want != 0 {
+				if rec.Code != tt.want {
+					t.Fatalf("got status %d, want %d", rec.Code, tt.want)
+				}
+				return
+			}
+			if rec.Code == http.StatusUnauthorized || rec.Code == http.StatusForbidden {
+				t.Fatalf("request was rejected with status %d", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance in kilometers between two points
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// ParseGeoPoint reads a {lat, lng} JSON object. ok is false when either
+// coordinate is missing or out of range.
+func ParseGeoPoint(point map[string]interface{}) (lat, lng float64, ok bool) {
+	lat, latOK := point["lat"].(float64)
+	lng, lngOK := point["lng"].(float64)
+	if !latOK || !lngOK || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// GeoQuery is a search around a point, read from ?near=lat,lng&radius_km=
+type GeoQuery struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64 // 0 leaves the distance unbounded
+}
+
+// ParseGeoQuery reads near and radius_km from a query string. Without near the
+// search is centred on the caller's home location, if they have one. It
+// returns nil when there is no point to search around.
+func ParseGeoQuery(values url.Values, homeLat, homeLng *float64) (*GeoQuery, error) {
+	q := &GeoQuery{}
+	if near := strings.TrimSpace(values.Get("near")); near != "" {
+		parts := strings.Split(near, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("near must be lat,lng")
+		}
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			return nil, fmt.Errorf("near must be a valid lat,lng")
+		}
+		q.Lat, q.Lng = lat, lng
+	} else if homeLat != nil && homeLng != nil {
+		q.Lat, q.Lng = *homeLat, *homeLng
+	} else {
+		q = nil
+	}
+
+	if radius := strings.TrimSpace(values.Get("radius_km")); radius != "" {
+		radiusKm, err := strconv.ParseFloat(radius, 64)
+		if err != nil || radiusKm <= 0 {
+			return nil, fmt.Errorf("radius_km must be a positive number")
+		}
+		if q == nil {
+			return nil, fmt.Errorf("radius_km needs near or a saved home location")
+		}
+		q.RadiusKm = radiusKm
+	}
+	return q, nil
+}
+
+// GeoBox is a latitude/longitude range used to prefilter rows in SQL before the
+// exact haversine check
+type GeoBox struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// Box returns the box around the search radius. It is nil without a radius,
+// or when the box would reach past a pole or across the antimeridian, where a
+// plain range does not describe it.
+func (q *GeoQuery) Box() *GeoBox {
+	if q == nil || q.RadiusKm <= 0 {
+		return nil
+	}
+	dLat := q.RadiusKm / earthRadiusKm * 180 / math.Pi
+	minLat, maxLat := q.Lat-dLat, q.Lat+dLat
+	if minLat <= -90 || maxLat >= 90 {
+		return nil
+	}
+	dLng := dLat / math.Cos(q.Lat*math.Pi/180)
+	minLng, maxLng := q.Lng-dLng, q.Lng+dLng
+	if minLng < -180 || maxLng > 180 {
+		return nil
+	}
+	return &GeoBox{MinLat: minLat, MaxLat: maxLat, MinLng: minLng, MaxLng: maxLng}
+}
+
+// Args returns the box as four query arguments (min lat, max lat, min lng,
+// max lng), all nil for a nil box, for SQL such as
+// ($1::float8 IS NULL OR (latitude BETWEEN $1 AND $2 AND longitude BETWEEN $3 AND $4))
+func (b *GeoBox) Args() []interface{} {
+	if b == nil {
+		return []interface{}{nil, nil, nil, nil}
+	}
+	return []interface{}{b.MinLat, b.MaxLat, b.MinLng, b.MaxLng}
+}
+
+// Distance returns the distance in kilometers to a point, or nil when either
+// coordinate is missing
+func (q *GeoQuery) Distance(lat, lng *float64) *float64 {
+	if q == nil || lat == nil || lng == nil {
+		return nil
+	}
+	d := math.Round(HaversineKm(q.Lat, q.Lng, *lat, *lng)*100) / 100
+	return &d
+}
+
+// NearestFirst sets each item's distance from the search point, drops items
+// outside the radius and sorts the rest nearest first. Items without
+// coordinates have no distance; they are dropped when a radius is set and
+// listed last otherwise. A nil query leaves items as they are.
+func NearestFirst[T any](q *GeoQuery, items []T, locate func(T) (lat, lng *float64), setDistance func(T, *float64)) []T {
+	if q == nil {
+		return items
+	}
+	kept := items[:0]
+	for _, item := range items {
+		d := q.Distance(locate(item))
+		if q.RadiusKm > 0 && (d == nil || *d > q.RadiusKm) {
+			continue
+		}
+		setDistance(item, d)
+		kept = append(kept, item)
+	}
+	distance := func(item T) float64 {
+		if d := q.Distance(locate(item)); d != nil {
+			return *d
+		}
+		return math.Inf(1)
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return distance(kept[i]) < distance(kept[j]) })
+	return kept
+}
@@ -13,30 +13,52 @@ import (
 
 var jwtSecret []byte
 
+// ErrTokenRevoked is returned by ValidateToken for tokens whose jti has been revoked
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// RevocationChecker reports whether the access token with the given jti has been revoked
+type RevocationChecker func(jti string) (bool, error)
+
+var revocationChecker RevocationChecker
+
 // InitJWT initializes JWT with secret from config
 func InitJWT(cfg *config.Config) {
 	jwtSecret = []byte(cfg.JWTSecret)
 }
 
+// SetRevocationChecker installs the lookup ValidateToken uses to reject revoked tokens
+func SetRevocationChecker(checker RevocationChecker) {
+	revocationChecker = checker
+}
+
 // Claims represents JWT claims
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Role   string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	SessionID string    `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken generates a JWT token for a user
 func GenerateToken(userID uuid.UUID, email, role string, expiry time.Duration) (string, error) {
+	token, _, err := GenerateSessionToken(userID, email, role, "", expiry)
+	return token, err
+}
+
+// GenerateSessionToken generates a JWT token bound to a session (refresh token family)
+// and returns it together with its claims so callers can track the jti.
+func GenerateSessionToken(userID uuid.UUID, email, role, sessionID string, expiry time.Duration) (string, *Claims, error) {
 	if len(jwtSecret) == 0 {
-		return "", errors.New("JWT secret not initialized")
+		return "", nil, errors.New("JWT secret not initialized")
 	}
 
 	expirationTime := time.Now().Add(expiry)
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -49,10 +71,10 @@ func GenerateToken(userID uuid.UUID, email, role string, expiry time.Duration) (
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(jwtSecret)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return tokenString, nil
+	return tokenString, claims, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
@@ -77,6 +99,16 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	if revocationChecker != nil {
+		revoked, err := revocationChecker(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
 	return claims, nil
 }
 